@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/app"
+	"github.com/piotmni/go-mini-templates/minimal/internal/config"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db/migrations"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database schema migrations",
+	}
+
+	migrateCmd.AddCommand(
+		&cobra.Command{
+			Use:   "up [N]",
+			Short: "Apply the next N pending migrations, or all of them",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: withMigrator(func(cmd *cobra.Command, m *db.Migrator, args []string) error {
+				apply := m.Up
+				if len(args) == 1 {
+					n, err := strconv.Atoi(args[0])
+					if err != nil || n < 1 {
+						return fmt.Errorf("invalid number of migrations %q", args[0])
+					}
+					apply = func() error { return m.UpSteps(n) }
+				}
+				if err := apply(); err != nil {
+					return err
+				}
+				return printStatus(cmd, m)
+			}),
+		},
+		newMigrateDownCmd(),
+		&cobra.Command{
+			Use:   "to VERSION",
+			Short: "Migrate up or down to a specific version",
+			Args:  cobra.ExactArgs(1),
+			RunE: withMigrator(func(cmd *cobra.Command, m *db.Migrator, args []string) error {
+				version, err := strconv.ParseUint(args[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				if err := m.To(uint(version)); err != nil {
+					return err
+				}
+				return printStatus(cmd, m)
+			}),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show the applied and latest schema versions",
+			Args:  cobra.NoArgs,
+			RunE: withMigrator(func(cmd *cobra.Command, m *db.Migrator, args []string) error {
+				return printStatus(cmd, m)
+			}),
+		},
+		&cobra.Command{
+			Use:   "force VERSION",
+			Short: "Set the schema version without migrating and clear the dirty flag",
+			Args:  cobra.ExactArgs(1),
+			RunE: withMigrator(func(cmd *cobra.Command, m *db.Migrator, args []string) error {
+				version, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				if err := m.Force(version); err != nil {
+					return err
+				}
+				return printStatus(cmd, m)
+			}),
+		},
+		newMigrateCreateCmd(),
+	)
+
+	return migrateCmd
+}
+
+func newMigrateDownCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "down [N]",
+		Short: "Roll back the last N migrations (default 1)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: withMigrator(func(cmd *cobra.Command, m *db.Migrator, args []string) error {
+			if all {
+				if err := m.DownAll(); err != nil {
+					return err
+				}
+				return printStatus(cmd, m)
+			}
+
+			steps := 1
+			if len(args) == 1 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid number of migrations %q", args[0])
+				}
+				steps = n
+			}
+			if err := m.Down(steps); err != nil {
+				return err
+			}
+			return printStatus(cmd, m)
+		}),
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "roll back every migration")
+
+	return cmd
+}
+
+func newMigrateCreateCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a new empty up/down migration pair",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Versions are shared with the migrations of every module
+			sources, err := app.Migrations()
+			if err != nil {
+				return err
+			}
+			up, down, err := db.CreateMigration(dir, args[0], append(sources, migrations.FS)...)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), up)
+			fmt.Fprintln(cmd.OutOrStdout(), down)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "internal/db/migrations", "migrations directory, e.g. internal/modules/note/migrations")
+
+	return cmd
+}
+
+// withMigrator opens a Migrator for the configured database, with the
+// migrations of every module, around run.
+func withMigrator(run func(cmd *cobra.Command, m *db.Migrator, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		zapLogger, _, err := logger.New("info", true)
+		if err != nil {
+			return fmt.Errorf("failed to create logger: %w", err)
+		}
+		defer zapLogger.Sync()
+
+		sources, err := app.Migrations()
+		if err != nil {
+			return err
+		}
+		m, err := db.NewMigrator(cfg.Database.URL, zapLogger, sources...)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		return run(cmd, m, args)
+	}
+}
+
+func printStatus(cmd *cobra.Command, m *db.Migrator) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	state := "current"
+	if status.Dirty {
+		state = "dirty"
+	} else if status.Pending() {
+		state = "pending"
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "version: %d\n", status.Version)
+	fmt.Fprintf(out, "latest:  %d\n", status.Latest)
+	fmt.Fprintf(out, "state:   %s\n", state)
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newRootCmd() *cobra.Command {
+	serveCmd := newServeCmd()
+
+	rootCmd := &cobra.Command{
+		Use:          "app",
+		Short:        "Notes API server",
+		Long:         "Notes API server. Without a subcommand it runs `serve`.",
+		SilenceUsage: true,
+		RunE:         serveCmd.RunE,
+	}
+
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(newMigrateCmd())
+	rootCmd.AddCommand(newUserCmd())
+	rootCmd.AddCommand(newWorkspaceCmd())
+
+	return rootCmd
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/app"
+	"github.com/piotmni/go-mini-templates/minimal/internal/config"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the HTTP server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			zapLogger, level, err := logger.New("info", true)
+			if err != nil {
+				return fmt.Errorf("failed to create logger: %w", err)
+			}
+			defer zapLogger.Sync()
+
+			application, err := app.New(cfg, zapLogger, level)
+			if err != nil {
+				zapLogger.Error("failed to create application", zap.Error(err))
+				return err
+			}
+
+			if err := application.Run(); err != nil {
+				zapLogger.Error("application error", zap.Error(err))
+				return err
+			}
+			return nil
+		},
+	}
+}
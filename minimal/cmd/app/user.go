@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/config"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newUserCmd() *cobra.Command {
+	userCmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users and their API keys",
+	}
+
+	userCmd.AddCommand(newUserCreateCmd(), newUserIssueKeyCmd())
+
+	return userCmd
+}
+
+func newUserCreateCmd() *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "create EMAIL",
+		Short: "Create a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: withServices(func(cmd *cobra.Command, s services, args []string) error {
+			u, err := s.users.Create(cmd.Context(), user.CreateInput{Email: args[0], Name: name})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), u.ID)
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&name, "name", "", "display name")
+
+	return cmd
+}
+
+func newUserIssueKeyCmd() *cobra.Command {
+	var (
+		name        string
+		scopes      []string
+		expiresIn   time.Duration
+		workspaceID string
+	)
+
+	allScopes := make([]string, len(auth.AllScopes))
+	for i, s := range auth.AllScopes {
+		allScopes[i] = string(s)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "issue-key EMAIL",
+		Short: "Issue an API key for a user and print it",
+		Long:  "Issue an API key for a user and print it. The key cannot be shown again.",
+		Args:  cobra.ExactArgs(1),
+		RunE: withServices(func(cmd *cobra.Command, s services, args []string) error {
+			parsed, err := auth.ParseScopes(scopes)
+			if err != nil {
+				return err
+			}
+
+			u, err := s.users.GetByEmail(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			wsID, err := keyWorkspace(cmd.Context(), s.workspaces, u.ID, workspaceID)
+			if err != nil {
+				return err
+			}
+
+			input := user.IssueKeyInput{UserID: u.ID, WorkspaceID: wsID, Name: name, Scopes: parsed}
+			if expiresIn > 0 {
+				expiresAt := time.Now().UTC().Add(expiresIn)
+				input.ExpiresAt = &expiresAt
+			}
+
+			_, raw, err := s.users.IssueKey(cmd.Context(), input)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), raw)
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&name, "name", "cli", "key name")
+	cmd.Flags().StringSliceVar(&scopes, "scope", allScopes,
+		"granted scope, repeatable ("+strings.Join(allScopes, ", ")+")")
+	cmd.Flags().DurationVar(&expiresIn, "expires-in", 0, "key lifetime, e.g. 720h (default never)")
+	cmd.Flags().StringVar(&workspaceID, "workspace", "", "workspace ID the key acts in (default the user's only workspace)")
+
+	return cmd
+}
+
+// keyWorkspace resolves the workspace a key for userID acts in. Without an
+// explicit ID the user must belong to exactly one workspace.
+func keyWorkspace(ctx context.Context, s *workspace.Service, userID user.ID, id string) (workspace.ID, error) {
+	if id != "" {
+		wsID, err := workspace.ParseID(id)
+		if err != nil {
+			return workspace.ID{}, fmt.Errorf("invalid workspace id: %w", err)
+		}
+		if _, err := s.GetMember(ctx, wsID, userID); err != nil {
+			return workspace.ID{}, fmt.Errorf("workspace %s: %w", wsID, err)
+		}
+		return wsID, nil
+	}
+
+	workspaces, err := s.ListForUser(ctx, userID)
+	if err != nil {
+		return workspace.ID{}, err
+	}
+	switch len(workspaces) {
+	case 0:
+		return workspace.ID{}, errors.New("user is not a member of any workspace")
+	case 1:
+		return workspaces[0].ID, nil
+	}
+	return workspace.ID{}, errors.New("user belongs to several workspaces, pass --workspace")
+}
+
+// services holds the services available to management commands.
+type services struct {
+	users      *user.Service
+	workspaces *workspace.Service
+}
+
+// withServices connects to the configured database and builds the
+// management services around run.
+func withServices(run func(cmd *cobra.Command, s services, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		zapLogger, _, err := logger.New("info", true)
+		if err != nil {
+			return fmt.Errorf("failed to create logger: %w", err)
+		}
+		defer zapLogger.Sync()
+
+		if db.IsSQLite(cfg.Database.URL) {
+			database, err := db.NewSQLite(cmd.Context(), cfg.Database.URL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			return run(cmd, services{
+				users:      user.NewService(user.NewSQLiteRepository(database), database, zapLogger),
+				workspaces: workspace.NewService(workspace.NewSQLiteRepository(database), database, zapLogger),
+			}, args)
+		}
+
+		database, err := db.NewPostgres(cmd.Context(), db.PostgresConfig{URL: cfg.Database.URL})
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		return run(cmd, services{
+			users:      user.NewService(user.NewPostgresRepository(database), database, zapLogger),
+			workspaces: workspace.NewService(workspace.NewPostgresRepository(database), database, zapLogger),
+		}, args)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newWorkspaceCmd() *cobra.Command {
+	workspaceCmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Manage workspaces and their members",
+	}
+
+	workspaceCmd.AddCommand(newWorkspaceCreateCmd(), newWorkspaceAddMemberCmd())
+
+	return workspaceCmd
+}
+
+func newWorkspaceCreateCmd() *cobra.Command {
+	var owner string
+
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a workspace and print its ID",
+		Args:  cobra.ExactArgs(1),
+		RunE: withServices(func(cmd *cobra.Command, s services, args []string) error {
+			u, err := s.users.GetByEmail(cmd.Context(), owner)
+			if err != nil {
+				return err
+			}
+
+			w, err := s.workspaces.Create(cmd.Context(), workspace.CreateInput{Name: args[0], OwnerID: u.ID})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), w.ID)
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&owner, "owner", "", "email of the owning user")
+	cmd.MarkFlagRequired("owner")
+
+	return cmd
+}
+
+func newWorkspaceAddMemberCmd() *cobra.Command {
+	var role string
+
+	cmd := &cobra.Command{
+		Use:   "add-member WORKSPACE_ID EMAIL",
+		Short: "Add a user to a workspace",
+		Args:  cobra.ExactArgs(2),
+		RunE: withServices(func(cmd *cobra.Command, s services, args []string) error {
+			id, err := workspace.ParseID(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid workspace id: %w", err)
+			}
+			parsed, err := workspace.ParseRole(role)
+			if err != nil {
+				return err
+			}
+
+			u, err := s.users.GetByEmail(cmd.Context(), args[1])
+			if err != nil {
+				return err
+			}
+
+			// The CLI acts as the operator, so it bypasses role checks.
+			if _, err := s.workspaces.Join(cmd.Context(), workspace.MemberInput{
+				WorkspaceID: id,
+				UserID:      u.ID,
+				Role:        parsed,
+			}); err != nil {
+				return err
+			}
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&role, "role", string(workspace.RoleMember), "member role (owner, admin, member, viewer)")
+
+	return cmd
+}
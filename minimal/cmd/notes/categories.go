@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/piotmni/go-mini-templates/minimal/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+func newCategoriesCmd(g *globals) *cobra.Command {
+	categoriesCmd := &cobra.Command{
+		Use:     "categories",
+		Aliases: []string{"category"},
+		Short:   "Manage categories",
+	}
+
+	categoriesCmd.AddCommand(
+		newCategoriesListCmd(g),
+		newCategoriesNewCmd(g),
+		newCategoriesRemoveCmd(g),
+	)
+
+	return categoriesCmd
+}
+
+func newCategoriesListCmd(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List categories",
+		Args:  cobra.NoArgs,
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			categories, err := c.ListCategories(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return out.print(categories, func(tw *tabwriter.Writer) {
+				row(tw, "ID", "NAME", "UPDATED")
+				for _, cat := range categories {
+					row(tw, cat.ID, cat.Name, timestamp(cat.UpdatedAt))
+				}
+			})
+		}),
+	}
+}
+
+func newCategoriesNewCmd(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:   "new NAME",
+		Short: "Create a category",
+		Args:  cobra.ExactArgs(1),
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			cat, err := c.CreateCategory(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return out.print(cat, func(tw *tabwriter.Writer) {
+				row(tw, cat.ID)
+			})
+		}),
+	}
+}
+
+func newCategoriesRemoveCmd(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm ID|NAME...",
+		Short: "Delete categories",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			for _, ref := range args {
+				id, err := resolveCategory(cmd.Context(), c, ref)
+				if err != nil {
+					return err
+				}
+				if err := c.DeleteCategory(cmd.Context(), id); err != nil {
+					return fmt.Errorf("delete %s: %w", ref, err)
+				}
+				fmt.Fprintln(cmd.ErrOrStderr(), "deleted", ref)
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultProfile is used when no profile is selected.
+const defaultProfile = "default"
+
+// Profile is a named API endpoint and the token used with it.
+type Profile struct {
+	BaseURL string `yaml:"base_url"`
+	Token   string `yaml:"token,omitempty"`
+}
+
+// Config is the CLI configuration file.
+type Config struct {
+	CurrentProfile string             `yaml:"current_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// defaultConfigPath returns the configuration file in the user's config
+// directory, e.g. ~/.config/notes/config.yaml.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "notes.yaml"
+	}
+	return filepath.Join(dir, "notes", "config.yaml")
+}
+
+// loadConfig reads the configuration at path. A missing file yields an
+// empty configuration.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]Profile{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	return cfg, nil
+}
+
+// save writes the configuration to path. The file holds tokens, so it is
+// only readable by its owner.
+func (c *Config) save(path string) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// profile returns the named profile, or the current one if name is empty.
+func (c *Config) profile(name string) (string, Profile, error) {
+	if name == "" {
+		name = c.CurrentProfile
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	p, ok := c.Profiles[name]
+	if !ok && name != defaultProfile {
+		return name, Profile{}, fmt.Errorf("unknown profile %q", name)
+	}
+	return name, p, nil
+}
+
+// profileNames returns the profile names in sorted order.
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+func newListCmd(g *globals) *cobra.Command {
+	var (
+		categoryRef string
+		limit       int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List notes, newest first",
+		Args:  cobra.NoArgs,
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			ctx := cmd.Context()
+			opts := client.ListNotesOptions{}
+			if categoryRef != "" {
+				id, err := resolveCategory(ctx, c, categoryRef)
+				if err != nil {
+					return err
+				}
+				opts.CategoryID = id
+			}
+			if limit > 0 {
+				opts.Limit = min(limit, 100)
+			}
+
+			notes := []client.Note{}
+			for n, err := range c.Notes(ctx, opts) {
+				if err != nil {
+					return err
+				}
+				notes = append(notes, n)
+				if limit > 0 && len(notes) == limit {
+					break
+				}
+			}
+
+			var names map[string]string
+			if out.format == formatTable {
+				var err error
+				if names, err = categoryNames(ctx, c); err != nil {
+					return err
+				}
+			}
+			return out.print(notes, func(tw *tabwriter.Writer) {
+				row(tw, "ID", "CATEGORY", "TITLE", "UPDATED")
+				for _, n := range notes {
+					row(tw, n.ID, cmp.Or(names[n.CategoryID], n.CategoryID), n.Title, timestamp(n.UpdatedAt))
+				}
+			})
+		}),
+	}
+	cmd.Flags().StringVarP(&categoryRef, "category", "c", "", "only list notes in this category (ID or name)")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 50, "maximum number of notes to list, 0 for all")
+
+	return cmd
+}
+
+func newShowCmd(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show ID",
+		Short: "Show a note",
+		Args:  cobra.ExactArgs(1),
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			n, err := c.GetNote(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printNote(cmd.Context(), c, out, n)
+		}),
+	}
+}
+
+func newNewCmd(g *globals) *cobra.Command {
+	var (
+		categoryRef string
+		title       string
+		content     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Create a note",
+		Long: `Create a note. The content is taken from --content, where "-" reads
+standard input, or from standard input when it is not a terminal.`,
+		Example: `  notes new -c Work -t "Standup" --content "Ship the CLI"
+  git log -1 --format=%B | notes new -c Work -t "Release notes"`,
+		Args: cobra.NoArgs,
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			ctx := cmd.Context()
+			categoryID, err := resolveCategory(ctx, c, categoryRef)
+			if err != nil {
+				return err
+			}
+
+			if !cmd.Flags().Changed("content") && !isTerminal(cmd.InOrStdin()) {
+				content = "-"
+			}
+			body, err := readContent(cmd, content)
+			if err != nil {
+				return err
+			}
+
+			n, err := c.CreateNote(ctx, client.NoteInput{CategoryID: categoryID, Title: title, Content: body})
+			if err != nil {
+				return err
+			}
+			return printNote(ctx, c, out, n)
+		}),
+	}
+	cmd.Flags().StringVarP(&categoryRef, "category", "c", "", "category of the note (ID or name)")
+	cmd.Flags().StringVarP(&title, "title", "t", "", "note title")
+	cmd.Flags().StringVar(&content, "content", "", `note content, "-" to read standard input`)
+	cmd.MarkFlagRequired("category")
+	cmd.MarkFlagRequired("title")
+
+	return cmd
+}
+
+func newEditCmd(g *globals) *cobra.Command {
+	var (
+		categoryRef string
+		title       string
+		content     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit ID",
+		Short: "Edit a note in $EDITOR",
+		Long: `Edit a note. Unless --content is given, the content is opened in
+$VISUAL or $EDITOR and saved back when the editor exits. --content "-"
+reads the new content from standard input instead.`,
+		Args: cobra.ExactArgs(1),
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			ctx := cmd.Context()
+			n, err := c.GetNote(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			in := client.NoteInput{CategoryID: n.CategoryID, Title: n.Title, Content: n.Content}
+			if categoryRef != "" {
+				if in.CategoryID, err = resolveCategory(ctx, c, categoryRef); err != nil {
+					return err
+				}
+			}
+			if title != "" {
+				in.Title = title
+			}
+			if cmd.Flags().Changed("content") {
+				in.Content, err = readContent(cmd, content)
+			} else {
+				in.Content, err = editInEditor(cmd, n.Content)
+			}
+			if err != nil {
+				return err
+			}
+
+			if in.CategoryID == n.CategoryID && in.Title == n.Title && in.Content == n.Content {
+				fmt.Fprintln(cmd.ErrOrStderr(), "no changes")
+				return nil
+			}
+
+			updated, err := c.UpdateNote(ctx, n.ID, in)
+			if err != nil {
+				return err
+			}
+			return printNote(ctx, c, out, updated)
+		}),
+	}
+	cmd.Flags().StringVarP(&categoryRef, "category", "c", "", "move the note to this category (ID or name)")
+	cmd.Flags().StringVarP(&title, "title", "t", "", "new title")
+	cmd.Flags().StringVar(&content, "content", "", `new content, "-" to read standard input`)
+
+	return cmd
+}
+
+func newRemoveCmd(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm ID...",
+		Short: "Delete notes",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withClient(g, func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error {
+			for _, id := range args {
+				if err := c.DeleteNote(cmd.Context(), id); err != nil {
+					return fmt.Errorf("delete %s: %w", id, err)
+				}
+				fmt.Fprintln(cmd.ErrOrStderr(), "deleted", id)
+			}
+			return nil
+		}),
+	}
+}
+
+// printNote prints a note with its content below the header fields.
+func printNote(ctx context.Context, c *client.Client, out *printer, n client.Note) error {
+	categoryName := n.CategoryID
+	if out.format == formatTable {
+		if cat, err := c.GetCategory(ctx, n.CategoryID); err == nil {
+			categoryName = cat.Name
+		}
+	}
+	err := out.print(n, func(tw *tabwriter.Writer) {
+		row(tw, "ID:", n.ID)
+		row(tw, "Title:", n.Title)
+		row(tw, "Category:", categoryName)
+		row(tw, "Created:", timestamp(n.CreatedAt))
+		row(tw, "Updated:", timestamp(n.UpdatedAt))
+	})
+	if err != nil || out.format != formatTable || n.Content == "" {
+		return err
+	}
+	_, err = fmt.Fprintf(out.w, "\n%s\n", strings.TrimRight(n.Content, "\n"))
+	return err
+}
+
+// resolveCategory returns the ID of the category ref names, either by ID or
+// by exact name.
+func resolveCategory(ctx context.Context, c *client.Client, ref string) (string, error) {
+	if _, err := uuid.Parse(ref); err == nil {
+		return ref, nil
+	}
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, cat := range categories {
+		if cat.Name == ref {
+			return cat.ID, nil
+		}
+	}
+	return "", fmt.Errorf("unknown category %q", ref)
+}
+
+// categoryNames maps category IDs to names for tables.
+func categoryNames(ctx context.Context, c *client.Client) (map[string]string, error) {
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(categories))
+	for _, cat := range categories {
+		names[cat.ID] = cat.Name
+	}
+	return names, nil
+}
+
+// readContent returns v, or standard input if v is "-".
+func readContent(cmd *cobra.Command, v string) (string, error) {
+	if v != "-" {
+		return v, nil
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("read standard input: %w", err)
+	}
+	return string(data), nil
+}
+
+// isTerminal reports whether r is an interactive terminal.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// editInEditor opens content in the user's editor and returns the saved
+// result.
+func editInEditor(cmd *cobra.Command, content string) (string, error) {
+	editor := strings.Fields(cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi"))
+	if len(editor) == 0 {
+		return "", errors.New("no editor configured, set $EDITOR")
+	}
+
+	f, err := os.CreateTemp("", "note-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	run := exec.CommandContext(cmd.Context(), editor[0], append(editor[1:], f.Name())...)
+	run.Stdin = os.Stdin
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	if err := run.Run(); err != nil {
+		return "", fmt.Errorf("editor %s: %w", editor[0], err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
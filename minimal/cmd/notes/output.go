@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// format is an output format selected with --output.
+type format string
+
+const (
+	formatTable format = "table"
+	formatJSON  format = "json"
+	formatYAML  format = "yaml"
+)
+
+// printer writes command results in the selected format.
+type printer struct {
+	w      io.Writer
+	format format
+}
+
+func newPrinter(w io.Writer, f string) (*printer, error) {
+	switch format(f) {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{w: w, format: format(f)}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, want table, json or yaml", f)
+}
+
+// print writes v as JSON or YAML, or as the table that table writes for the
+// table format. JSON and YAML share the field names of the API.
+func (p *printer) print(v any, table func(tw *tabwriter.Writer)) error {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(p.w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// row writes tab separated columns to tw.
+func row(tw *tabwriter.Writer, columns ...any) {
+	for i, c := range columns {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, c)
+	}
+	fmt.Fprintln(tw)
+}
+
+// timestamp formats t for tables.
+func timestamp(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd(g *globals) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage connection profiles",
+		Long: `Manage connection profiles. A profile names an API base URL and the
+token used with it; --profile selects one for a single command.`,
+	}
+
+	configCmd.AddCommand(
+		newConfigSetCmd(g),
+		newConfigUseCmd(g),
+		newConfigListCmd(g),
+	)
+
+	return configCmd
+}
+
+func newConfigSetCmd(g *globals) *cobra.Command {
+	var (
+		baseURL string
+		token   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set PROFILE",
+		Short: "Create or update a profile",
+		Example: `  notes config set work --base-url https://notes.example.com --token "$NOTES_KEY"
+  notes config use work`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(g.configPath)
+			if err != nil {
+				return err
+			}
+
+			p := cfg.Profiles[args[0]]
+			if cmd.Flags().Changed("base-url") {
+				p.BaseURL = baseURL
+			}
+			if cmd.Flags().Changed("token") {
+				p.Token = token
+			}
+			cfg.Profiles[args[0]] = p
+			if cfg.CurrentProfile == "" {
+				cfg.CurrentProfile = args[0]
+			}
+
+			return cfg.save(g.configPath)
+		},
+	}
+	// Local flags shadow the persistent ones of the same name, so that
+	// setting a profile does not pick up NOTES_BASE_URL or NOTES_TOKEN.
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "API base URL")
+	cmd.Flags().StringVar(&token, "token", "", "API key or JWT")
+
+	return cmd
+}
+
+func newConfigUseCmd(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use PROFILE",
+		Short: "Select the profile used by default",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(g.configPath)
+			if err != nil {
+				return err
+			}
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				return fmt.Errorf("unknown profile %q", args[0])
+			}
+			cfg.CurrentProfile = args[0]
+			return cfg.save(g.configPath)
+		},
+	}
+}
+
+func newConfigListCmd(g *globals) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := newPrinter(cmd.OutOrStdout(), g.output)
+			if err != nil {
+				return err
+			}
+			cfg, err := loadConfig(g.configPath)
+			if err != nil {
+				return err
+			}
+			current, _, _ := cfg.profile("")
+
+			type profileView struct {
+				Name    string `json:"name"`
+				BaseURL string `json:"base_url"`
+				Token   bool   `json:"token"`
+				Current bool   `json:"current"`
+			}
+			var profiles []profileView
+			for _, name := range cfg.profileNames() {
+				p := cfg.Profiles[name]
+				profiles = append(profiles, profileView{
+					Name:    name,
+					BaseURL: p.BaseURL,
+					Token:   p.Token != "",
+					Current: name == current,
+				})
+			}
+
+			return out.print(profiles, func(tw *tabwriter.Writer) {
+				row(tw, "", "NAME", "BASE URL", "TOKEN")
+				for _, p := range profiles {
+					marker, token := "", "-"
+					if p.Current {
+						marker = "*"
+					}
+					if p.Token {
+						token = "set"
+					}
+					row(tw, marker, p.Name, p.BaseURL, token)
+				}
+			})
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"cmp"
+	"os"
+
+	"github.com/piotmni/go-mini-templates/minimal/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// defaultBaseURL is used when neither flags, environment nor the profile
+// name a server.
+const defaultBaseURL = "http://localhost:8080"
+
+// globals holds the persistent flags shared by all commands.
+type globals struct {
+	configPath string
+	profile    string
+	baseURL    string
+	token      string
+	output     string
+}
+
+func newRootCmd() *cobra.Command {
+	g := &globals{}
+
+	rootCmd := &cobra.Command{
+		Use:   "notes",
+		Short: "Command line client for the notes API",
+		Long: `Command line client for the notes API.
+
+The server and token are taken from --base-url and --token, then from
+NOTES_BASE_URL and NOTES_TOKEN, then from the selected profile of the
+config file. Manage profiles with "notes config".`,
+		SilenceUsage: true,
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&g.configPath, "config", cmp.Or(os.Getenv("NOTES_CONFIG"), defaultConfigPath()), "config file")
+	flags.StringVarP(&g.profile, "profile", "p", os.Getenv("NOTES_PROFILE"), "profile to use (default the current profile)")
+	flags.StringVar(&g.baseURL, "base-url", os.Getenv("NOTES_BASE_URL"), "API base URL")
+	flags.StringVar(&g.token, "token", os.Getenv("NOTES_TOKEN"), "API key or JWT")
+	flags.StringVarP(&g.output, "output", "o", string(formatTable), "output format (table, json, yaml)")
+
+	rootCmd.AddCommand(
+		newListCmd(g),
+		newShowCmd(g),
+		newNewCmd(g),
+		newEditCmd(g),
+		newRemoveCmd(g),
+		newCategoriesCmd(g),
+		newConfigCmd(g),
+	)
+
+	return rootCmd
+}
+
+// withClient resolves the endpoint and output format from g and builds an
+// API client around run.
+func withClient(g *globals, run func(cmd *cobra.Command, c *client.Client, out *printer, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		out, err := newPrinter(cmd.OutOrStdout(), g.output)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := loadConfig(g.configPath)
+		if err != nil {
+			return err
+		}
+		_, p, err := cfg.profile(g.profile)
+		if err != nil {
+			return err
+		}
+
+		c, err := client.New(
+			cmp.Or(g.baseURL, p.BaseURL, defaultBaseURL),
+			client.WithToken(cmp.Or(g.token, p.Token)),
+			client.WithUserAgent("notes-cli"),
+		)
+		if err != nil {
+			return err
+		}
+
+		return run(cmd, c, out, args)
+	}
+}
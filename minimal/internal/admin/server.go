@@ -0,0 +1,120 @@
+// Package admin serves operational endpoints on a listener of their own:
+// profiling, the runtime log level, build information and the effective
+// configuration. The endpoints are not authenticated, so the listener is
+// bound to localhost unless configured otherwise.
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+// ServerConfig holds the admin server configuration.
+type ServerConfig struct {
+	Host string
+	Port int
+}
+
+// Server is the admin HTTP server.
+type Server struct {
+	echo   *echo.Echo
+	cfg    ServerConfig
+	logger *zap.Logger
+}
+
+// NewServer creates a new admin server. level is the level of the
+// application logger and settings the redacted configuration to show.
+func NewServer(cfg ServerConfig, level zap.AtomicLevel, settings map[string]string, logger *zap.Logger) *Server {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Use(echomiddleware.Recover())
+
+	// pprof.Index serves the named profiles, e.g. /debug/pprof/heap
+	e.GET("/debug/pprof/*", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	e.GET("/debug/pprof/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	e.GET("/debug/pprof/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	e.Match([]string{http.MethodGet, http.MethodPost}, "/debug/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	e.GET("/debug/pprof/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+
+	// The atomic level reads and changes itself as {"level":"debug"}
+	e.Match([]string{http.MethodGet, http.MethodPut}, "/admin/loglevel", echo.WrapHandler(level))
+
+	e.GET("/admin/buildinfo", buildInfo)
+	e.GET("/admin/config", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, settings)
+	})
+
+	return &Server{
+		echo:   e,
+		cfg:    cfg,
+		logger: logger.Named("admin.server"),
+	}
+}
+
+// Handler returns the server's routes as an http.Handler.
+func (s *Server) Handler() http.Handler {
+	return s.echo
+}
+
+// moduleResponse is the JSON response for a Go module.
+type moduleResponse struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+// buildInfoResponse is the JSON response for the build of the binary.
+type buildInfoResponse struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Main      moduleResponse    `json:"main"`
+	Settings  map[string]string `json:"settings"`
+	Deps      []moduleResponse  `json:"deps"`
+}
+
+// buildInfo handles GET /admin/buildinfo
+func buildInfo(c echo.Context) error {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "build info not available")
+	}
+
+	res := buildInfoResponse{
+		GoVersion: info.GoVersion,
+		Path:      info.Path,
+		Main:      moduleResponse{Path: info.Main.Path, Version: info.Main.Version, Sum: info.Main.Sum},
+		Settings:  make(map[string]string, len(info.Settings)),
+		Deps:      make([]moduleResponse, len(info.Deps)),
+	}
+	for _, s := range info.Settings {
+		res.Settings[s.Key] = s.Value
+	}
+	for i, d := range info.Deps {
+		if d.Replace != nil {
+			d = d.Replace
+		}
+		res.Deps[i] = moduleResponse{Path: d.Path, Version: d.Version, Sum: d.Sum}
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// Start starts the admin server.
+func (s *Server) Start() error {
+	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+	s.logger.Info("starting admin server", zap.String("addr", addr))
+	return s.echo.Start(addr)
+}
+
+// Shutdown gracefully shuts down the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Info("shutting down admin server")
+	return s.echo.Shutdown(ctx)
+}
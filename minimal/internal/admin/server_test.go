@@ -0,0 +1,106 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/admin"
+	"github.com/piotmni/go-mini-templates/minimal/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newServer(t *testing.T, level zap.AtomicLevel, settings map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(admin.NewServer(admin.ServerConfig{}, level, settings, zap.NewNop()).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func do(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read response body: %v", err)
+	}
+	return res.StatusCode, string(data)
+}
+
+func TestLogLevel(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	srv := newServer(t, level, nil)
+
+	if status, body := do(t, http.MethodGet, srv.URL+"/admin/loglevel", ""); status != http.StatusOK || !strings.Contains(body, `"info"`) {
+		t.Fatalf("GET loglevel = %d %s", status, body)
+	}
+	if status, body := do(t, http.MethodPut, srv.URL+"/admin/loglevel", `{"level":"debug"}`); status != http.StatusOK {
+		t.Fatalf("PUT loglevel = %d %s", status, body)
+	}
+	if level.Level() != zapcore.DebugLevel {
+		t.Errorf("level = %s, want debug", level.Level())
+	}
+	if status, _ := do(t, http.MethodPut, srv.URL+"/admin/loglevel", `{"level":"loud"}`); status != http.StatusBadRequest {
+		t.Errorf("PUT unknown level = %d, want 400", status)
+	}
+}
+
+func TestConfigIsRedacted(t *testing.T) {
+	for _, tt := range []struct {
+		url, want string
+	}{
+		{"postgres://app:secret@db:5432/notes", "postgres://app:xxxxx@db:5432/notes"},
+		{"postgres://app@db/notes?password=secret&sslmode=require", "postgres://app@db/notes?password=xxxxx&sslmode=require"},
+		{"postgres://app@db/notes?sslpassword=secret", "postgres://app@db/notes?sslpassword=xxxxx"},
+		{"host=db password=secret", "xxxxx"},
+	} {
+		t.Run(tt.url, func(t *testing.T) {
+			cfg := &config.Config{
+				Database:   config.DatabaseConfig{URL: tt.url},
+				Encryption: config.EncryptionConfig{Keys: "v1:c2VjcmV0"},
+			}
+			srv := newServer(t, zap.NewAtomicLevel(), cfg.Redacted())
+
+			status, body := do(t, http.MethodGet, srv.URL+"/admin/config", "")
+			if status != http.StatusOK {
+				t.Fatalf("GET config = %d %s", status, body)
+			}
+			var settings map[string]string
+			if err := json.Unmarshal([]byte(body), &settings); err != nil {
+				t.Fatalf("decode config: %v", err)
+			}
+			if got := settings["DB_URL"]; got != tt.want {
+				t.Errorf("DB_URL = %q, want %q", got, tt.want)
+			}
+			if got := settings["NOTES_ENCRYPTION_KEYS"]; got != "xxxxx" {
+				t.Errorf("NOTES_ENCRYPTION_KEYS = %q", got)
+			}
+		})
+	}
+}
+
+func TestBuildInfoAndProfiles(t *testing.T) {
+	srv := newServer(t, zap.NewAtomicLevel(), nil)
+
+	status, body := do(t, http.MethodGet, srv.URL+"/admin/buildinfo", "")
+	if status != http.StatusOK || !strings.Contains(body, `"go_version":"go`) {
+		t.Errorf("GET buildinfo = %d %s", status, body)
+	}
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/pprof/cmdline"} {
+		if status, body := do(t, http.MethodGet, srv.URL+path, ""); status != http.StatusOK {
+			t.Errorf("GET %s = %d %s", path, status, body)
+		}
+	}
+}
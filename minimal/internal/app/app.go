@@ -2,72 +2,290 @@ package app
 
 import (
 	"context"
+	"io/fs"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/piotmni/go-mini-templates/minimal/internal/admin"
 	"github.com/piotmni/go-mini-templates/minimal/internal/config"
-	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/dav"
+	"github.com/piotmni/go-mini-templates/minimal/internal/graphql"
 	"github.com/piotmni/go-mini-templates/minimal/internal/http"
 	"github.com/piotmni/go-mini-templates/minimal/internal/http/handlers"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/mirror"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/batch"
 	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/idempotency"
 	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/savedsearch"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/transfer"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+	"github.com/piotmni/go-mini-templates/minimal/internal/rpc"
 	"go.uber.org/zap"
 )
 
 type App struct {
 	cfg    *config.Config
 	logger *zap.Logger
-	db     *pgx.Conn
+	store  *storage
 	server *http.Server
+	// admin is nil when the admin listener is disabled.
+	admin *admin.Server
+	jobs  *jobs.Queue
+	// cancel stops background work such as JWKS refreshes.
+	cancel context.CancelFunc
 }
 
-func New(cfg *config.Config, logger *zap.Logger) (*App, error) {
+// New wires the application. level is the level of logger, changed at
+// runtime through the admin server.
+func New(cfg *config.Config, logger *zap.Logger, level zap.AtomicLevel) (*App, error) {
 	ctx := context.Background()
 
-	// Initialize database
-	database, err := db.NewPostgres(ctx, cfg.Database.URL)
+	// Initialize storage
+	store, err := openStorage(ctx, cfg, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	// Initialize repositories
-	categoryRepo := category.NewPostgresRepository(database)
-	noteRepo := note.NewPostgresRepository(database)
-
 	// Initialize services
-	categoryService := category.NewService(categoryRepo, logger)
-	noteService := note.NewService(noteRepo, logger)
+	auditService := audit.NewService(store.audit, logger)
+	categoryService := category.NewService(store.categories, store.tx, auditService, logger)
+	noteService := note.NewService(store.notes, categoryService, store.tx, auditService, logger)
+	idempotencyService := idempotency.NewService(store.idempotency, cfg.Idempotency.TTL, logger)
+	transferService := transfer.NewService(categoryService, noteService, store.tx, logger)
+	batchService := batch.NewService(categoryService, noteService, store.tx, cfg.Batch.MaxOperations, logger)
+	userService := user.NewService(store.users, store.tx, logger)
+	workspaceService := workspace.NewService(store.workspaces, store.tx, logger)
+	savedSearchService := savedsearch.NewService(store.savedSearches, noteService, store.tx, auditService, logger)
+
+	// Initialize authentication
+	background, cancel := context.WithCancel(context.Background())
+	authenticator, err := newAuthenticator(background, cfg.Auth, userService, workspaceService)
+	if err != nil {
+		cancel()
+		store.close()
+		return nil, err
+	}
+
+	// Initialize background jobs
+	queue := jobs.NewQueue(store.jobs, store.tx, jobs.Config{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+	}, logger)
+	if err := registerJobs(ctx, cfg, queue); err != nil {
+		cancel()
+		store.close()
+		return nil, err
+	}
+
+	// Initialize the git mirror
+	var gitMirror *mirror.Mirror
+	if cfg.Mirror.Dir != "" {
+		gitMirror, err = mirror.New(ctx, mirror.Config{
+			Dir:         cfg.Mirror.Dir,
+			AuthorName:  cfg.Mirror.AuthorName,
+			AuthorEmail: cfg.Mirror.AuthorEmail,
+		}, transferService, userService, queue, logger)
+		if err != nil {
+			cancel()
+			store.close()
+			return nil, err
+		}
+	}
 
-	// Initialize handlers
-	categoryHandler := handlers.NewCategoryHandler(categoryService)
-	noteHandler := handlers.NewNoteHandler(noteService)
+	// Initialize modules
+	registry, err := NewModules(Services{
+		Audit:         auditService,
+		Categories:    categoryService,
+		Notes:         noteService,
+		Idempotency:   idempotencyService,
+		Transfer:      transferService,
+		Batch:         batchService,
+		Users:         userService,
+		Workspaces:    workspaceService,
+		SavedSearches: savedSearchService,
+		Mirror:        gitMirror,
+		Authenticator: authenticator,
+		DB:            store.db,
+		Logger:        logger,
+	})
+	if err != nil {
+		cancel()
+		store.close()
+		return nil, err
+	}
+	if err := registry.RegisterJobs(ctx, queue); err != nil {
+		cancel()
+		store.close()
+		return nil, err
+	}
 
 	// Initialize HTTP server
 	server := http.NewServer(
 		http.ServerConfig{
-			Host:  cfg.Server.Host,
-			Port:  cfg.Server.Port,
-			Token: cfg.Auth.Token,
+			Host:              cfg.Server.Host,
+			Port:              cfg.Server.Port,
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+			ReadTimeout:       cfg.Server.ReadTimeout,
+			WriteTimeout:      cfg.Server.WriteTimeout,
+			IdleTimeout:       cfg.Server.IdleTimeout,
+			BodyLimit:         cfg.Server.BodyLimit,
+			CORS: http.CORSConfig{
+				AllowOrigins:     cfg.Server.CORS.AllowOrigins,
+				AllowCredentials: cfg.Server.CORS.AllowCredentials,
+				MaxAge:           cfg.Server.CORS.MaxAge,
+			},
+			SecurityHeaders: cfg.Server.SecurityHeaders,
+			HSTSMaxAge:      cfg.Server.HSTSMaxAge,
+			TLS: http.TLSConfig{
+				CertFile:       cfg.Server.TLS.CertFile,
+				KeyFile:        cfg.Server.TLS.KeyFile,
+				ClientCAFile:   cfg.Server.TLS.ClientCAFile,
+				ClientAuth:     cfg.Server.TLS.ClientAuth,
+				ReloadInterval: cfg.Server.TLS.ReloadInterval,
+			},
 		},
 		logger,
-		categoryHandler,
-		noteHandler,
+		authenticator,
+		idempotencyService,
+		registry,
 	)
 
+	// Initialize admin server
+	var adminServer *admin.Server
+	if cfg.Admin.Port != 0 {
+		adminServer = admin.NewServer(
+			admin.ServerConfig{
+				Host: cfg.Admin.Host,
+				Port: cfg.Admin.Port,
+			},
+			level,
+			cfg.Redacted(),
+			logger,
+		)
+	}
+
 	return &App{
 		cfg:    cfg,
 		logger: logger,
-		db:     database,
+		store:  store,
 		server: server,
+		admin:  adminServer,
+		jobs:   queue,
+		cancel: cancel,
 	}, nil
 }
 
+// Services holds what the modules of the application are built from.
+type Services struct {
+	Audit         *audit.Service
+	Categories    *category.Service
+	Notes         *note.Service
+	Idempotency   *idempotency.Service
+	Transfer      *transfer.Service
+	Batch         *batch.Service
+	Users         *user.Service
+	Workspaces    *workspace.Service
+	SavedSearches *savedsearch.Service
+	// Mirror is nil when the git mirror is disabled.
+	Mirror        *mirror.Mirror
+	Authenticator middleware.Authenticator
+	// DB is the database the services store data in, which health checks
+	// ping, or nil if they keep data in memory.
+	DB     modules.Pinger
+	Logger *zap.Logger
+}
+
+// NewModules returns the registry of the application's modules, followed
+// by extra ones. Tests serve the API with it, so it is the only place
+// modules are wired.
+func NewModules(s Services, extra ...modules.Module) (*modules.Registry, error) {
+	return modules.NewRegistry(append([]modules.Module{
+		category.NewModule(handlers.NewCategoryHandler(s.Categories), s.DB),
+		note.NewModule(s.Notes, handlers.NewNoteHandler(s.Notes), s.DB),
+		user.NewModule(handlers.NewAPIKeyHandler(s.Users, s.Workspaces), s.DB),
+		workspace.NewModule(handlers.NewWorkspaceHandler(s.Workspaces, s.Users), s.DB),
+		audit.NewModule(handlers.NewAuditHandler(s.Audit), s.DB),
+		idempotency.NewModule(s.Idempotency, s.DB),
+		transfer.NewModule(handlers.NewTransferHandler(s.Transfer)),
+		batch.NewModule(handlers.NewBatchHandler(s.Batch)),
+		savedsearch.NewModule(handlers.NewSavedSearchHandler(s.SavedSearches), s.DB),
+		mirror.NewModule(s.Mirror, handlers.NewMirrorHandler(s.Mirror), s.Audit),
+		graphql.NewHandler(s.Categories, s.Notes, s.Logger),
+		rpc.NewServer(s.Authenticator, s.Categories, s.Notes, s.Logger),
+		dav.NewHandler(s.Authenticator, s.Categories, s.Notes, s.Logger),
+	}, extra...)...)
+}
+
+// Migrations returns the migrations modules add to the embedded ones, for
+// migrating without the application. Modules describe their migrations
+// without their services, so none are wired.
+func Migrations() ([]fs.FS, error) {
+	registry, err := NewModules(Services{Logger: zap.NewNop()})
+	if err != nil {
+		return nil, err
+	}
+	return registry.Migrations(), nil
+}
+
+// registerJobs registers the jobs of the application itself, which no
+// module owns, and schedules the recurring ones.
+func registerJobs(ctx context.Context, cfg *config.Config, queue *jobs.Queue) error {
+	queue.Register("jobs.purge", jobs.Handle(func(ctx context.Context, _ struct{}) error {
+		_, err := queue.Purge(ctx, cfg.Jobs.Retention)
+		return err
+	}))
+	return queue.Schedule(ctx, "jobs.purge", "@daily", struct{}{})
+}
+
+// newAuthenticator builds the credential check for the configured auth
+// mode. Client certificates are tried first, then JWTs before API keys
+// when both are accepted, and any of them must belong to a member of the
+// workspace it acts in.
+func newAuthenticator(ctx context.Context, cfg config.AuthConfig, users *user.Service, workspaces *workspace.Service) (middleware.Authenticator, error) {
+	var authenticators []middleware.Authenticator
+	if cfg.ClientCertPrincipalsFile != "" {
+		principals, err := middleware.LoadCertificatePrincipals(cfg.ClientCertPrincipalsFile)
+		if err != nil {
+			return nil, err
+		}
+		certAuth, err := middleware.NewCertificateAuthenticator(principals, users)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, certAuth)
+	}
+	if cfg.JWT() {
+		jwtAuth, err := middleware.NewJWTAuthenticator(ctx, middleware.JWTConfig{
+			JWKSURL:  cfg.JWKSURL,
+			Issuer:   cfg.Issuer,
+			Audience: cfg.Audience,
+			Leeway:   cfg.Leeway,
+		}, users)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, jwtAuth)
+	}
+	if cfg.APIKeys() {
+		authenticators = append(authenticators, users)
+	}
+	return workspace.NewAuthenticator(middleware.Chain(authenticators...), workspaces), nil
+}
+
 func (a *App) Run() error {
 	// Channel for server errors
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
+
+	// Start background job workers
+	a.jobs.Start()
 
 	// Start HTTP server in a goroutine
 	go func() {
@@ -76,6 +294,15 @@ func (a *App) Run() error {
 		}
 	}()
 
+	// Start admin server in a goroutine
+	if a.admin != nil {
+		go func() {
+			if err := a.admin.Start(); err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -100,11 +327,24 @@ func (a *App) Shutdown() error {
 		a.logger.Error("failed to shutdown HTTP server", zap.Error(err))
 	}
 
-	// Close database connection
-	if err := a.db.Close(ctx); err != nil {
-		a.logger.Error("failed to close database connection", zap.Error(err))
+	// Shutdown admin server
+	if a.admin != nil {
+		if err := a.admin.Shutdown(ctx); err != nil {
+			a.logger.Error("failed to shutdown admin server", zap.Error(err))
+		}
+	}
+
+	// Wait for running jobs
+	if err := a.jobs.Stop(ctx); err != nil {
+		a.logger.Error("failed to stop job workers", zap.Error(err))
 	}
 
+	// Stop background work
+	a.cancel()
+
+	// Close database connection
+	a.store.close()
+
 	a.logger.Info("application stopped")
 	return nil
 }
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/config"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/idempotency"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/savedsearch"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+	"go.uber.org/zap"
+)
+
+// storage holds the repositories of the database named by DB_URL.
+type storage struct {
+	// db is pinged by the health checks of the modules storing data in it.
+	db            modules.Pinger
+	tx            db.Transactor
+	categories    category.Repository
+	notes         note.Repository
+	audit         audit.Repository
+	idempotency   idempotency.Repository
+	users         user.Repository
+	workspaces    workspace.Repository
+	savedSearches savedsearch.Repository
+	jobs          jobs.Repository
+	// close closes the database.
+	close func()
+}
+
+// openStorage opens the database named by cfg.Database.URL and verifies
+// its schema is current, applying pending migrations if auto-migrate is
+// enabled.
+func openStorage(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*storage, error) {
+	if db.IsSQLite(cfg.Database.URL) {
+		return openSQLite(ctx, cfg, logger)
+	}
+
+	keyring, err := loadKeyring(cfg.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.NewPostgres(ctx, db.PostgresConfig{
+		URL:              cfg.Database.URL,
+		RowLevelSecurity: cfg.Database.RowLevelSecurity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse to serve on an outdated schema
+	if err := checkSchema(cfg, logger, func(migrator *db.Migrator) error {
+		return migrator.UpLocked(ctx, database)
+	}); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	// Refuse to serve notes that cannot be decrypted
+	notes := note.NewPostgresRepository(database, keyring)
+	if err := notes.CheckKeys(ctx); err != nil {
+		database.Close()
+		if errors.Is(err, encryption.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w; add it to NOTES_ENCRYPTION_KEYS or NOTES_ENCRYPTION_KEYS_FILE", err)
+		}
+		return nil, fmt.Errorf("failed to check encryption keys: %w", err)
+	}
+
+	return &storage{
+		db:            database,
+		tx:            database,
+		categories:    category.NewPostgresRepository(database),
+		notes:         notes,
+		audit:         audit.NewPostgresRepository(database),
+		idempotency:   idempotency.NewPostgresRepository(database, keyring),
+		users:         user.NewPostgresRepository(database),
+		workspaces:    workspace.NewPostgresRepository(database),
+		savedSearches: savedsearch.NewPostgresRepository(database),
+		jobs:          jobs.NewPostgresRepository(database),
+		close:         database.Close,
+	}, nil
+}
+
+// openSQLite opens a SQLite database for local development. Categories,
+// notes, users and workspaces are stored in it; audit events, idempotency
+// keys, saved searches and jobs are kept in memory and lost on restart.
+func openSQLite(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*storage, error) {
+	// Refuse to store notes in the clear that were meant to be encrypted
+	if cfg.Encryption.Enabled() {
+		return nil, errors.New("note encryption is not supported by sqlite; unset NOTES_ENCRYPTION_KEYS and NOTES_ENCRYPTION_KEYS_FILE or use postgres")
+	}
+
+	database, err := db.NewSQLite(ctx, cfg.Database.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single process serves a SQLite database, so no lock is needed
+	if err := checkSchema(cfg, logger, (*db.Migrator).Up); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	if cfg.Database.RowLevelSecurity {
+		logger.Warn("row-level security is not supported by sqlite, ignoring it")
+	}
+	logger.Warn("sqlite keeps audit events, idempotency keys, saved searches and jobs in memory")
+
+	return &storage{
+		db:            database,
+		tx:            database,
+		categories:    category.NewSQLiteRepository(database),
+		notes:         note.NewSQLiteRepository(database),
+		audit:         audit.NewMemoryRepository(),
+		idempotency:   idempotency.NewMemoryRepository(),
+		users:         user.NewSQLiteRepository(database),
+		workspaces:    workspace.NewSQLiteRepository(database),
+		savedSearches: savedsearch.NewMemoryRepository(),
+		jobs:          jobs.NewMemoryRepository(),
+		close:         func() { database.Close() },
+	}, nil
+}
+
+// loadKeyring returns the keys encrypting notes, or nil if notes are
+// stored in the clear.
+func loadKeyring(cfg config.EncryptionConfig) (*encryption.Keyring, error) {
+	switch {
+	case cfg.KeysFile != "":
+		return encryption.LoadKeyring(cfg.KeysFile)
+	case cfg.Keys != "":
+		keyring, err := encryption.ParseKeyring(cfg.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("NOTES_ENCRYPTION_KEYS: %w", err)
+		}
+		return keyring, nil
+	}
+	return nil, nil
+}
+
+// checkSchema applies pending migrations with up if auto-migrate is
+// enabled and verifies that the database schema is current.
+func checkSchema(cfg *config.Config, logger *zap.Logger, up func(*db.Migrator) error) error {
+	sources, err := Migrations()
+	if err != nil {
+		return err
+	}
+	migrator, err := db.NewMigrator(cfg.Database.URL, logger, sources...)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if cfg.Database.AutoMigrate {
+		if err := up(migrator); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	return migrator.EnsureCurrent()
+}
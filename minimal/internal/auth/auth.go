@@ -0,0 +1,83 @@
+// Package auth defines the authenticated caller of a request and the scopes
+// that grant access to API operations.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Scope is a permission granted to a credential.
+type Scope string
+
+const (
+	ScopeNotesRead       Scope = "notes:read"
+	ScopeNotesWrite      Scope = "notes:write"
+	ScopeCategoriesAdmin Scope = "categories:admin"
+	ScopeAuditRead       Scope = "audit:read"
+)
+
+// AllScopes lists every known scope.
+var AllScopes = []Scope{
+	ScopeNotesRead,
+	ScopeNotesWrite,
+	ScopeCategoriesAdmin,
+	ScopeAuditRead,
+}
+
+// ParseScope validates s as a known scope.
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(s)
+	if !slices.Contains(AllScopes, scope) {
+		return "", fmt.Errorf("unknown scope %q", s)
+	}
+	return scope, nil
+}
+
+// ParseScopes validates every entry of s as a known scope.
+func ParseScopes(s []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(s))
+	for _, v := range s {
+		scope, err := ParseScope(v)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID uuid.UUID
+	// KeyID is the API key the caller authenticated with.
+	KeyID uuid.UUID
+	// WorkspaceID is the workspace the caller acts in.
+	WorkspaceID uuid.UUID
+	// Role is the caller's role in the workspace.
+	Role   string
+	Scopes []Scope
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored in ctx, if any.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"github.com/joho/godotenv"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // read from environment variables
@@ -13,19 +18,148 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Auth     AuthConfig
+	// Idempotency configures replay of requests sent with an
+	// Idempotency-Key header.
+	Idempotency IdempotencyConfig
+	Batch       BatchConfig
+	Jobs        JobsConfig
+	Mirror      MirrorConfig
+	// Encryption configures encryption of notes at rest.
+	Encryption EncryptionConfig
+	// Admin configures the listener of operational endpoints.
+	Admin AdminConfig
 }
 
 type ServerConfig struct {
 	Host string
 	Port int
+	// Timeouts of the HTTP server; zero disables one.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// BodyLimit caps request bodies, e.g. "32M"; empty disables it.
+	BodyLimit       string
+	SecurityHeaders bool
+	// HSTSMaxAge is sent as Strict-Transport-Security over TLS; zero
+	// leaves the header out.
+	HSTSMaxAge time.Duration
+	CORS       CORSConfig
+	TLS        TLSConfig
 }
 
-type AuthConfig struct {
-	Token string
+type CORSConfig struct {
+	// AllowOrigins lists the origins allowed cross-origin requests; empty
+	// refuses them.
+	AllowOrigins     []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+type TLSConfig struct {
+	// CertFile and KeyFile enable TLS. They are reloaded when they change,
+	// checked every ReloadInterval.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile verifies client certificates, asked for according to
+	// ClientAuth: none, request or require.
+	ClientCAFile string
+	ClientAuth   string
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+type AdminConfig struct {
+	// Host defaults to localhost: the admin endpoints are not authenticated.
+	Host string
+	// Port 0 disables the admin listener.
+	Port int
 }
 
 type DatabaseConfig struct {
+	// URL is a postgres:// URL, or sqlite:// followed by a file path.
 	URL string
+	// AutoMigrate applies pending migrations on startup.
+	AutoMigrate bool
+	// RowLevelSecurity sets app.workspace_id on every pooled connection so
+	// the row-level security policies apply to the application role.
+	RowLevelSecurity bool
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long responses are kept for replay.
+	TTL time.Duration
+}
+
+type BatchConfig struct {
+	// MaxOperations is the largest number of operations in a batch request.
+	MaxOperations int
+}
+
+type JobsConfig struct {
+	// Workers is the number of background jobs run concurrently.
+	Workers int
+	// PollInterval is how often idle workers look for due jobs.
+	PollInterval time.Duration
+	// Retention is how long succeeded jobs are kept before being purged.
+	Retention time.Duration
+}
+
+type MirrorConfig struct {
+	// Dir is the git working tree notes are mirrored into; empty disables
+	// the mirror. Notes are written to it in the clear, so it cannot be
+	// enabled with encryption.
+	Dir string
+	// AuthorName and AuthorEmail commit changes, and author those made
+	// outside the API.
+	AuthorName  string
+	AuthorEmail string
+}
+
+type EncryptionConfig struct {
+	// Keys are the key-encryption keys of notes, and of the idempotent
+	// responses that may contain them, as id:base64 entries, the current
+	// one first. KeysFile holds them instead. With neither, both are
+	// stored in the clear.
+	Keys     string
+	KeysFile string
+}
+
+// Enabled reports whether notes are encrypted.
+func (c EncryptionConfig) Enabled() bool {
+	return c.Keys != "" || c.KeysFile != ""
+}
+
+// Auth modes select which credentials the API accepts.
+const (
+	AuthModeAPIKey = "apikey"
+	AuthModeJWT    = "jwt"
+	AuthModeBoth   = "both"
+)
+
+type AuthConfig struct {
+	// Mode is one of AuthModeAPIKey, AuthModeJWT or AuthModeBoth.
+	Mode string
+	// JWKSURL, Issuer and Audience are required for JWT modes.
+	JWKSURL  string
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated when validating JWT times.
+	Leeway time.Duration
+	// ClientCertPrincipalsFile maps verified client certificate subjects
+	// to principals, letting requests without a bearer credential
+	// authenticate with their certificate. It works with any mode.
+	ClientCertPrincipalsFile string
+}
+
+// JWT reports whether JWTs are accepted.
+func (c AuthConfig) JWT() bool {
+	return c.Mode == AuthModeJWT || c.Mode == AuthModeBoth
+}
+
+// APIKeys reports whether API keys are accepted.
+func (c AuthConfig) APIKeys() bool {
+	return c.Mode == AuthModeAPIKey || c.Mode == AuthModeBoth
 }
 
 func Load() (*Config, error) {
@@ -36,14 +170,65 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-			Port: getEnvAsInt("SERVER_PORT", 8080),
+			Host:              getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:              getEnvAsInt("SERVER_PORT", 8080),
+			ReadHeaderTimeout: getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", 10*time.Second),
+			ReadTimeout:       getEnvAsDuration("SERVER_READ_TIMEOUT", time.Minute),
+			WriteTimeout:      getEnvAsDuration("SERVER_WRITE_TIMEOUT", 2*time.Minute),
+			IdleTimeout:       getEnvAsDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+			BodyLimit:         getEnv("SERVER_BODY_LIMIT", "32M"),
+			SecurityHeaders:   getEnvAsBool("SERVER_SECURITY_HEADERS", true),
+			HSTSMaxAge:        getEnvAsDuration("SERVER_HSTS_MAX_AGE", 0),
+			CORS: CORSConfig{
+				AllowOrigins:     getEnvAsList("CORS_ALLOW_ORIGINS"),
+				AllowCredentials: getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
+				MaxAge:           getEnvAsDuration("CORS_MAX_AGE", 10*time.Minute),
+			},
+			TLS: TLSConfig{
+				CertFile:       getEnv("TLS_CERT_FILE", ""),
+				KeyFile:        getEnv("TLS_KEY_FILE", ""),
+				ClientCAFile:   getEnv("TLS_CLIENT_CA_FILE", ""),
+				ClientAuth:     getEnv("TLS_CLIENT_AUTH", "none"),
+				ReloadInterval: getEnvAsDuration("TLS_RELOAD_INTERVAL", time.Minute),
+			},
 		},
 		Database: DatabaseConfig{
-			URL: getEnv("DB_URL", "localhost"),
+			URL:              getEnv("DB_URL", "localhost"),
+			AutoMigrate:      getEnvAsBool("DB_AUTO_MIGRATE", false),
+			RowLevelSecurity: getEnvAsBool("DB_ROW_LEVEL_SECURITY", false),
 		},
 		Auth: AuthConfig{
-			Token: getEnv("AUTH_TOKEN", "pass"),
+			Mode:     getEnv("AUTH_MODE", AuthModeAPIKey),
+			JWKSURL:  getEnv("AUTH_JWKS_URL", ""),
+			Issuer:   getEnv("AUTH_JWT_ISSUER", ""),
+			Audience: getEnv("AUTH_JWT_AUDIENCE", ""),
+			Leeway:   getEnvAsDuration("AUTH_JWT_LEEWAY", 30*time.Second),
+
+			ClientCertPrincipalsFile: getEnv("AUTH_CLIENT_CERT_PRINCIPALS_FILE", ""),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		},
+		Batch: BatchConfig{
+			MaxOperations: getEnvAsInt("BATCH_MAX_OPERATIONS", 100),
+		},
+		Jobs: JobsConfig{
+			Workers:      getEnvAsInt("JOBS_WORKERS", 4),
+			PollInterval: getEnvAsDuration("JOBS_POLL_INTERVAL", time.Second),
+			Retention:    getEnvAsDuration("JOBS_RETENTION", 7*24*time.Hour),
+		},
+		Mirror: MirrorConfig{
+			Dir:         getEnv("GIT_MIRROR_DIR", ""),
+			AuthorName:  getEnv("GIT_MIRROR_AUTHOR_NAME", "Notes"),
+			AuthorEmail: getEnv("GIT_MIRROR_AUTHOR_EMAIL", "notes@localhost"),
+		},
+		Encryption: EncryptionConfig{
+			Keys:     getEnv("NOTES_ENCRYPTION_KEYS", ""),
+			KeysFile: getEnv("NOTES_ENCRYPTION_KEYS_FILE", ""),
+		},
+		Admin: AdminConfig{
+			Host: getEnv("ADMIN_HOST", "127.0.0.1"),
+			Port: getEnvAsInt("ADMIN_PORT", 9090),
 		},
 	}
 
@@ -55,10 +240,132 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) validate() error {
-	// can add validation login if needed
+	switch c.Auth.Mode {
+	case AuthModeAPIKey, AuthModeJWT, AuthModeBoth:
+	default:
+		return fmt.Errorf("AUTH_MODE must be %s, %s or %s", AuthModeAPIKey, AuthModeJWT, AuthModeBoth)
+	}
+	if c.Auth.JWT() && (c.Auth.JWKSURL == "" || c.Auth.Issuer == "" || c.Auth.Audience == "") {
+		return fmt.Errorf("AUTH_MODE %s requires AUTH_JWKS_URL, AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE", c.Auth.Mode)
+	}
+
+	tlsCfg := c.Server.TLS
+	if (tlsCfg.CertFile == "") != (tlsCfg.KeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	switch tlsCfg.ClientAuth {
+	case "none":
+	case "request", "require":
+		if tlsCfg.CertFile == "" || tlsCfg.ClientCAFile == "" {
+			return fmt.Errorf("TLS_CLIENT_AUTH %s requires TLS_CERT_FILE, TLS_KEY_FILE and TLS_CLIENT_CA_FILE", tlsCfg.ClientAuth)
+		}
+	default:
+		return fmt.Errorf("TLS_CLIENT_AUTH must be none, request or require")
+	}
+	if c.Auth.ClientCertPrincipalsFile != "" && tlsCfg.ClientAuth == "none" {
+		return fmt.Errorf("AUTH_CLIENT_CERT_PRINCIPALS_FILE requires TLS_CLIENT_AUTH request or require")
+	}
+	if c.Server.CORS.AllowCredentials && slices.Contains(c.Server.CORS.AllowOrigins, "*") {
+		return fmt.Errorf("CORS_ALLOW_CREDENTIALS cannot be used with CORS_ALLOW_ORIGINS=*")
+	}
+	if c.Batch.MaxOperations < 1 {
+		return fmt.Errorf("BATCH_MAX_OPERATIONS must be at least 1")
+	}
+	if c.Encryption.Keys != "" && c.Encryption.KeysFile != "" {
+		return fmt.Errorf("NOTES_ENCRYPTION_KEYS and NOTES_ENCRYPTION_KEYS_FILE cannot be set together")
+	}
+	// The mirror commits notes in the clear
+	if c.Mirror.Dir != "" && c.Encryption.Enabled() {
+		return fmt.Errorf("GIT_MIRROR_DIR cannot be used with note encryption, as the mirror stores notes in the clear")
+	}
 	return nil
 }
 
+// Redacted returns the settings by environment variable for display, with
+// secrets masked. Keep it in line with Load.
+func (c *Config) Redacted() map[string]string {
+	return map[string]string{
+		"SERVER_HOST":                      c.Server.Host,
+		"SERVER_PORT":                      strconv.Itoa(c.Server.Port),
+		"SERVER_READ_HEADER_TIMEOUT":       c.Server.ReadHeaderTimeout.String(),
+		"SERVER_READ_TIMEOUT":              c.Server.ReadTimeout.String(),
+		"SERVER_WRITE_TIMEOUT":             c.Server.WriteTimeout.String(),
+		"SERVER_IDLE_TIMEOUT":              c.Server.IdleTimeout.String(),
+		"SERVER_BODY_LIMIT":                c.Server.BodyLimit,
+		"SERVER_SECURITY_HEADERS":          strconv.FormatBool(c.Server.SecurityHeaders),
+		"SERVER_HSTS_MAX_AGE":              c.Server.HSTSMaxAge.String(),
+		"CORS_ALLOW_ORIGINS":               strings.Join(c.Server.CORS.AllowOrigins, ","),
+		"CORS_ALLOW_CREDENTIALS":           strconv.FormatBool(c.Server.CORS.AllowCredentials),
+		"CORS_MAX_AGE":                     c.Server.CORS.MaxAge.String(),
+		"TLS_CERT_FILE":                    c.Server.TLS.CertFile,
+		"TLS_KEY_FILE":                     c.Server.TLS.KeyFile,
+		"TLS_CLIENT_CA_FILE":               c.Server.TLS.ClientCAFile,
+		"TLS_CLIENT_AUTH":                  c.Server.TLS.ClientAuth,
+		"TLS_RELOAD_INTERVAL":              c.Server.TLS.ReloadInterval.String(),
+		"DB_URL":                           redactURL(c.Database.URL),
+		"DB_AUTO_MIGRATE":                  strconv.FormatBool(c.Database.AutoMigrate),
+		"DB_ROW_LEVEL_SECURITY":            strconv.FormatBool(c.Database.RowLevelSecurity),
+		"AUTH_MODE":                        c.Auth.Mode,
+		"AUTH_JWKS_URL":                    c.Auth.JWKSURL,
+		"AUTH_JWT_ISSUER":                  c.Auth.Issuer,
+		"AUTH_JWT_AUDIENCE":                c.Auth.Audience,
+		"AUTH_JWT_LEEWAY":                  c.Auth.Leeway.String(),
+		"AUTH_CLIENT_CERT_PRINCIPALS_FILE": c.Auth.ClientCertPrincipalsFile,
+		"IDEMPOTENCY_TTL":                  c.Idempotency.TTL.String(),
+		"BATCH_MAX_OPERATIONS":             strconv.Itoa(c.Batch.MaxOperations),
+		"JOBS_WORKERS":                     strconv.Itoa(c.Jobs.Workers),
+		"JOBS_POLL_INTERVAL":               c.Jobs.PollInterval.String(),
+		"JOBS_RETENTION":                   c.Jobs.Retention.String(),
+		"GIT_MIRROR_DIR":                   c.Mirror.Dir,
+		"GIT_MIRROR_AUTHOR_NAME":           c.Mirror.AuthorName,
+		"GIT_MIRROR_AUTHOR_EMAIL":          c.Mirror.AuthorEmail,
+		"NOTES_ENCRYPTION_KEYS":            redactSecret(c.Encryption.Keys),
+		"NOTES_ENCRYPTION_KEYS_FILE":       c.Encryption.KeysFile,
+		"ADMIN_HOST":                       c.Admin.Host,
+		"ADMIN_PORT":                       strconv.Itoa(c.Admin.Port),
+	}
+}
+
+// redacted replaces secrets in displayed settings.
+const redacted = "xxxxx"
+
+// redactSecret masks s unless it is empty.
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// secretURLParams are the query parameters of database URLs holding
+// passwords, which pgx accepts in place of the userinfo password.
+var secretURLParams = []string{"password", "sslpassword"}
+
+// redactURL masks the password of a URL, in its userinfo or query. Values
+// that do not parse as a URL with a scheme, such as key=value connection
+// strings, are masked whole.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return redactSecret(s)
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return redactSecret(s)
+	}
+	masked := false
+	for _, param := range secretURLParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+			masked = true
+		}
+	}
+	if masked {
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -66,6 +373,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsList splits a comma separated value, dropping empty entries.
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -74,3 +392,21 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultValue
+}
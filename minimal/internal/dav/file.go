@@ -0,0 +1,186 @@
+package dav
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+)
+
+// fileInfo describes a directory or note file.
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+func rootInfo() fileInfo {
+	return fileInfo{name: "/", dir: true}
+}
+
+func categoryInfo(c category.Category) fileInfo {
+	return fileInfo{name: toName(c.Name), modTime: c.UpdatedAt, dir: true}
+}
+
+func noteInfo(name string, n note.Note) fileInfo {
+	return fileInfo{name: name, size: int64(len(n.Content)), modTime: n.UpdatedAt}
+}
+
+// dirFile is an open directory.
+type dirFile struct {
+	info     fileInfo
+	children []os.FileInfo
+}
+
+func (f *dirFile) Close() error { return nil }
+
+func (f *dirFile) Read(p []byte) (int, error) {
+	return 0, fs.ErrInvalid
+}
+
+func (f *dirFile) Write(p []byte) (int, error) {
+	return 0, fs.ErrPermission
+}
+
+func (f *dirFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, fs.ErrInvalid
+}
+
+// Readdir returns the next count children, or all remaining ones if count
+// is not positive, as os.File.Readdir does.
+func (f *dirFile) Readdir(count int) ([]fs.FileInfo, error) {
+	if count <= 0 {
+		children := f.children
+		f.children = nil
+		return children, nil
+	}
+	if len(f.children) == 0 {
+		return nil, io.EOF
+	}
+	count = min(count, len(f.children))
+	children := f.children[:count]
+	f.children = f.children[count:]
+	return children, nil
+}
+
+func (f *dirFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// noteFile is an open note file. Writes are buffered and saved through the
+// note service on Close, creating the note if the file is new.
+type noteFile struct {
+	fs       *fileSystem
+	ctx      context.Context
+	name     string
+	category category.Category
+	// note is nil until a new file is saved.
+	note  *note.Note
+	title string
+
+	content  []byte
+	pos      int64
+	writable bool
+	// created marks a new file, saved even if nothing is written to it.
+	created bool
+	dirty   bool
+}
+
+func (f *noteFile) Read(p []byte) (int, error) {
+	if f.pos >= int64(len(f.content)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.content[f.pos:])
+	f.pos += int64(n)
+	return n, nil
+}
+
+func (f *noteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += int64(len(f.content))
+	}
+	if offset < 0 {
+		return 0, fs.ErrInvalid
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *noteFile) Write(p []byte) (int, error) {
+	if !f.writable {
+		return 0, fs.ErrPermission
+	}
+	if end := f.pos + int64(len(p)); end > int64(len(f.content)) {
+		f.content = append(f.content, make([]byte, end-int64(len(f.content)))...)
+	}
+	n := copy(f.content[f.pos:], p)
+	f.pos += int64(n)
+	f.dirty = true
+	return n, nil
+}
+
+func (f *noteFile) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, fs.ErrInvalid
+}
+
+func (f *noteFile) Stat() (fs.FileInfo, error) {
+	info := fileInfo{name: f.name, size: int64(len(f.content)), modTime: time.Now().UTC()}
+	if f.note != nil && !f.dirty {
+		info.modTime = f.note.UpdatedAt
+	}
+	return info, nil
+}
+
+// Close saves the content of new and written files.
+func (f *noteFile) Close() error {
+	if !f.created && !f.dirty {
+		return nil
+	}
+	f.created, f.dirty = false, false
+
+	if f.note == nil {
+		n, err := f.fs.notes.Create(f.ctx, note.CreateInput{
+			CategoryID: f.category.ID,
+			Title:      f.title,
+			Content:    string(f.content),
+		})
+		if err != nil {
+			return fsError(err)
+		}
+		f.note = &n
+		return nil
+	}
+
+	n, err := f.fs.notes.Update(f.ctx, note.UpdateInput{
+		ID:         f.note.ID,
+		CategoryID: f.note.CategoryID,
+		Title:      f.note.Title,
+		Content:    string(f.content),
+	})
+	if err != nil {
+		return fsError(err)
+	}
+	f.note = &n
+	return nil
+}
@@ -0,0 +1,319 @@
+package dav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"golang.org/x/net/webdav"
+)
+
+// noteExt is the extension of note files.
+const noteExt = ".md"
+
+// slash stands in for "/" in the names of categories and notes, which
+// cannot contain a path separator.
+const slash = "∕"
+
+func toName(s string) string {
+	return strings.ReplaceAll(s, "/", slash)
+}
+
+func fromName(name string) string {
+	return strings.ReplaceAll(name, slash, "/")
+}
+
+// fileSystem presents the categories of the workspace in the context as
+// directories and their notes as <title>.md files.
+type fileSystem struct {
+	categories *category.Service
+	notes      *note.Service
+}
+
+var _ webdav.FileSystem = (*fileSystem)(nil)
+
+// davPath is a resolved path: the root, a category or a note file.
+type davPath struct {
+	category *category.Category
+	// file is the name of a note file in category. The note is nil if it
+	// does not exist yet.
+	file string
+	note *note.Note
+}
+
+func (p davPath) isDir() bool {
+	return p.file == ""
+}
+
+// split splits a cleaned path into its directory and file names. Paths
+// deeper than a note file do not exist.
+func split(name string) (dir, file string, err error) {
+	parts := strings.Split(strings.Trim(path.Clean("/"+name), "/"), "/")
+	switch {
+	case parts[0] == "":
+		return "", "", nil
+	case len(parts) == 1:
+		return parts[0], "", nil
+	case len(parts) == 2:
+		return parts[0], parts[1], nil
+	}
+	return "", "", os.ErrNotExist
+}
+
+// resolve looks up the category and note of name. The category must exist;
+// the note may not.
+func (fs *fileSystem) resolve(ctx context.Context, name string) (davPath, error) {
+	dir, file, err := split(name)
+	if err != nil || dir == "" {
+		return davPath{}, err
+	}
+	c, err := fs.category(ctx, dir)
+	if err != nil {
+		return davPath{}, err
+	}
+	p := davPath{category: &c, file: file}
+	if file == "" {
+		return p, nil
+	}
+	notes, err := fs.files(ctx, c)
+	if err != nil {
+		return davPath{}, err
+	}
+	if n, ok := notes[file]; ok {
+		p.note = &n
+	}
+	return p, nil
+}
+
+// category returns the category shown as the directory dir.
+func (fs *fileSystem) category(ctx context.Context, dir string) (category.Category, error) {
+	categories, err := fs.categories.GetAll(ctx)
+	if err != nil {
+		return category.Category{}, err
+	}
+	for _, c := range categories {
+		if toName(c.Name) == dir {
+			return c, nil
+		}
+	}
+	return category.Category{}, os.ErrNotExist
+}
+
+// files returns the notes of c by file name. Notes are named after their
+// title; when titles collide, all but the oldest note get the start of
+// their ID appended, e.g. "Todo (1b4e28ba).md".
+func (fs *fileSystem) files(ctx context.Context, c category.Category) (map[string]note.Note, error) {
+	notes, err := fs.notes.GetByCategory(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(notes, func(a, b note.Note) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	files := make(map[string]note.Note, len(notes))
+	for _, n := range notes {
+		name := toName(n.Title) + noteExt
+		if _, taken := files[name]; taken {
+			name = fmt.Sprintf("%s (%s)%s", toName(n.Title), n.ID.String()[:8], noteExt)
+		}
+		files[name] = n
+	}
+	return files, nil
+}
+
+// title returns the note title for a file name, which must end in .md.
+func title(file string) (string, error) {
+	base, ok := strings.CutSuffix(file, noteExt)
+	if !ok || base == "" || strings.HasPrefix(file, ".") {
+		return "", os.ErrPermission
+	}
+	return fromName(base), nil
+}
+
+// requireAdmin fails unless the principal may manage categories, which
+// creating, renaming and deleting directories does.
+func requireAdmin(ctx context.Context) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok || !principal.HasScope(auth.ScopeCategoriesAdmin) {
+		return os.ErrPermission
+	}
+	return nil
+}
+
+// fsError translates service errors into the errors webdav expects.
+func fsError(err error) error {
+	switch {
+	case errors.Is(err, category.ErrNotFound), errors.Is(err, note.ErrNotFound):
+		return os.ErrNotExist
+	case errors.Is(err, category.ErrAlreadyExists):
+		return os.ErrExist
+	case errors.Is(err, note.ErrInvalidMetadata):
+		return os.ErrPermission
+	}
+	return err
+}
+
+func (fs *fileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	dir, file, err := split(name)
+	if err != nil || dir == "" || file != "" {
+		return os.ErrPermission
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	_, err = fs.categories.Create(ctx, category.CreateInput{Name: fromName(dir)})
+	return fsError(err)
+}
+
+func (fs *fileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	p, err := fs.resolve(ctx, name)
+	if err != nil {
+		return nil, fsError(err)
+	}
+	if p.category == nil {
+		return fs.openRoot(ctx)
+	}
+	if p.isDir() {
+		return fs.openCategory(ctx, *p.category)
+	}
+
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	if p.note == nil {
+		if flag&os.O_CREATE == 0 {
+			return nil, os.ErrNotExist
+		}
+		t, err := title(p.file)
+		if err != nil {
+			return nil, err
+		}
+		return &noteFile{fs: fs, ctx: ctx, name: p.file, category: *p.category, title: t, writable: true, created: true}, nil
+	}
+
+	f := &noteFile{fs: fs, ctx: ctx, name: p.file, category: *p.category, note: p.note, title: p.note.Title, writable: writable}
+	if flag&os.O_TRUNC == 0 || !writable {
+		f.content = []byte(p.note.Content)
+	} else {
+		f.dirty = true
+	}
+	return f, nil
+}
+
+func (fs *fileSystem) openRoot(ctx context.Context) (webdav.File, error) {
+	categories, err := fs.categories.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(categories, func(a, b category.Category) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	children := make([]os.FileInfo, len(categories))
+	for i, c := range categories {
+		children[i] = categoryInfo(c)
+	}
+	return &dirFile{info: rootInfo(), children: children}, nil
+}
+
+func (fs *fileSystem) openCategory(ctx context.Context, c category.Category) (webdav.File, error) {
+	files, err := fs.files(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	children := make([]os.FileInfo, len(names))
+	for i, name := range names {
+		children[i] = noteInfo(name, files[name])
+	}
+	return &dirFile{info: categoryInfo(c), children: children}, nil
+}
+
+func (fs *fileSystem) RemoveAll(ctx context.Context, name string) error {
+	p, err := fs.resolve(ctx, name)
+	if err != nil {
+		return fsError(err)
+	}
+	switch {
+	case p.category == nil:
+		return os.ErrPermission
+	case p.isDir():
+		if err := requireAdmin(ctx); err != nil {
+			return err
+		}
+		return fsError(fs.categories.Delete(ctx, p.category.ID))
+	case p.note == nil:
+		return os.ErrNotExist
+	}
+	return fsError(fs.notes.Delete(ctx, p.note.ID))
+}
+
+// Rename renames or recategorises a note, or renames a category.
+func (fs *fileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	from, err := fs.resolve(ctx, oldName)
+	if err != nil {
+		return fsError(err)
+	}
+	if from.category == nil {
+		return os.ErrPermission
+	}
+
+	if from.isDir() {
+		dir, file, err := split(newName)
+		if err != nil || dir == "" || file != "" {
+			return os.ErrPermission
+		}
+		if err := requireAdmin(ctx); err != nil {
+			return err
+		}
+		_, err = fs.categories.Update(ctx, category.UpdateInput{ID: from.category.ID, Name: fromName(dir)})
+		return fsError(err)
+	}
+
+	if from.note == nil {
+		return os.ErrNotExist
+	}
+	to, err := fs.resolve(ctx, newName)
+	if err != nil {
+		return fsError(err)
+	}
+	if to.category == nil || to.isDir() {
+		return os.ErrPermission
+	}
+	t, err := title(to.file)
+	if err != nil {
+		return err
+	}
+	_, err = fs.notes.Update(ctx, note.UpdateInput{
+		ID:         from.note.ID,
+		CategoryID: to.category.ID,
+		Title:      t,
+		Content:    from.note.Content,
+	})
+	return fsError(err)
+}
+
+func (fs *fileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	p, err := fs.resolve(ctx, name)
+	if err != nil {
+		return nil, fsError(err)
+	}
+	switch {
+	case p.category == nil:
+		return rootInfo(), nil
+	case p.isDir():
+		return categoryInfo(*p.category), nil
+	case p.note == nil:
+		return nil, os.ErrNotExist
+	}
+	return noteInfo(p.file, *p.note), nil
+}
@@ -0,0 +1,142 @@
+// Package dav serves the notes of a workspace over WebDAV, so they can be
+// edited from any editor or file manager. Categories appear as directories
+// and notes as <title>.md files holding their content.
+package dav
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+	"golang.org/x/net/webdav"
+)
+
+// Prefix is the path WebDAV is served under.
+const Prefix = "/dav"
+
+// methods are the methods the WebDAV handler serves.
+var methods = []string{
+	http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK",
+}
+
+// methodScopes holds the scope of methods that do not write notes; the
+// others require notes:write. MKCOL only ever creates a category.
+var methodScopes = map[string]auth.Scope{
+	http.MethodOptions: auth.ScopeNotesRead,
+	http.MethodGet:     auth.ScopeNotesRead,
+	http.MethodHead:    auth.ScopeNotesRead,
+	"PROPFIND":         auth.ScopeNotesRead,
+	"MKCOL":            auth.ScopeCategoriesAdmin,
+}
+
+// Handler serves WebDAV.
+//
+// Writing a file creates or updates its note, MOVE renames a note or moves
+// it to another category, and DELETE removes it. Creating, renaming and
+// deleting directories manages categories, which requires the
+// categories:admin scope. Only .md files can be created; a "/" in a
+// category name or title is shown as "∕".
+//
+// Handler is the module serving WebDAV.
+type Handler struct {
+	modules.Base
+	authenticator middleware.Authenticator
+	fs            *fileSystem
+	logger        *zap.Logger
+
+	mu sync.Mutex
+	// locks holds the WebDAV locks of each workspace, whose paths overlap.
+	locks map[uuid.UUID]webdav.LockSystem
+}
+
+// NewHandler creates a new Handler.
+func NewHandler(authenticator middleware.Authenticator, categories *category.Service, notes *note.Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		authenticator: authenticator,
+		fs:            &fileSystem{categories: categories, notes: notes},
+		logger:        logger.Named("dav.handler"),
+		locks:         make(map[uuid.UUID]webdav.LockSystem),
+	}
+}
+
+func (h *Handler) Name() string { return "dav" }
+
+// Directories are categories and files notes.
+func (h *Handler) Dependencies() []string { return []string{"category", "note"} }
+
+// RegisterRoutes registers nothing under /api/v1; WebDAV is served by
+// RegisterRootRoutes.
+func (h *Handler) RegisterRoutes(*echo.Group) {}
+
+// RegisterRootRoutes mounts WebDAV under Prefix on e, authenticated with
+// the same bearer credentials as the REST API.
+func (h *Handler) RegisterRootRoutes(e *echo.Echo) {
+	g := e.Group(Prefix, middleware.Auth(h.authenticator), requireMethodScope)
+	g.Match(methods, "", h.serve)
+	g.Match(methods, "/*", h.serve)
+}
+
+// requireMethodScope checks the scope the request method requires.
+func requireMethodScope(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		scope, ok := methodScopes[c.Request().Method]
+		if !ok {
+			scope = auth.ScopeNotesWrite
+		}
+		if err := middleware.CheckScopes(c.Request().Context(), scope); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+func (h *Handler) serve(c echo.Context) error {
+	workspaceID, err := tenant.WorkspaceID(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "not authenticated")
+	}
+
+	dav := &webdav.Handler{
+		Prefix:     Prefix,
+		FileSystem: h.fs,
+		LockSystem: h.lockSystem(workspaceID),
+		Logger:     h.logError,
+	}
+	dav.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// lockSystem returns the lock system of a workspace.
+func (h *Handler) lockSystem(workspaceID uuid.UUID) webdav.LockSystem {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ls, ok := h.locks[workspaceID]
+	if !ok {
+		ls = webdav.NewMemLS()
+		h.locks[workspaceID] = ls
+	}
+	return ls
+}
+
+func (h *Handler) logError(r *http.Request, err error) {
+	if err == nil || errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+		return
+	}
+	h.logger.Warn("webdav request failed",
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.Error(err),
+	)
+}
@@ -0,0 +1,35 @@
+// Package dbtest provides databases for repository tests.
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"go.uber.org/zap"
+)
+
+// NewSQLite creates a migrated SQLite database in a temporary directory
+// and closes it when the test ends.
+func NewSQLite(t *testing.T) *db.SQLite {
+	t.Helper()
+	url := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+
+	m, err := db.NewMigrator(url, zap.NewNop())
+	if err != nil {
+		t.Fatalf("create migrator: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("close migrator: %v", err)
+	}
+
+	database, err := db.NewSQLite(t.Context(), url)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
@@ -0,0 +1,371 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db/migrations"
+	"go.uber.org/zap"
+)
+
+// migrationLockKey is the advisory lock key held while migrating on startup.
+const migrationLockKey int64 = 0x6d696e696d616c // "minimal"
+
+var (
+	ErrSchemaDirty  = errors.New("database schema is dirty")
+	ErrSchemaBehind = errors.New("database schema is behind")
+)
+
+// MigrationStatus describes the schema version of a database.
+type MigrationStatus struct {
+	// Version is the currently applied migration, 0 if none.
+	Version uint
+	// Dirty is set when a migration failed halfway.
+	Dirty bool
+	// Latest is the newest migration embedded in the binary.
+	Latest uint
+}
+
+// Pending reports whether embedded migrations have not been applied yet.
+func (s MigrationStatus) Pending() bool {
+	return s.Version < s.Latest
+}
+
+// Migrator applies the embedded migrations, and those of modules, to a
+// database.
+type Migrator struct {
+	m      *migrate.Migrate
+	src    source.Driver
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewMigrator creates a Migrator for the database at databaseURL. sources
+// are added to the embedded migrations; every version must be unique
+// across them. SQLite databases get their own migrations instead, and
+// sources, written for Postgres, are not applied to them.
+func NewMigrator(databaseURL string, logger *zap.Logger, sources ...fs.FS) (*Migrator, error) {
+	if IsSQLite(databaseURL) {
+		return newSQLiteMigrator(databaseURL, logger)
+	}
+
+	merged, err := mergeMigrations(append([]fs.FS{migrations.FS}, sources...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	src, err := iofs.New(merged, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	sqlDB, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	driver, err := pgxmigrate.WithInstance(sqlDB, &pgxmigrate.Config{})
+	if err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	return newMigrator(src, "pgx5", driver, sqlDB, logger)
+}
+
+// newSQLiteMigrator creates a Migrator for the SQLite database at
+// databaseURL.
+func newSQLiteMigrator(databaseURL string, logger *zap.Logger) (*Migrator, error) {
+	src, err := iofs.New(migrations.SQLite, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	sqlDB, err := openSQLite(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlitemigrate.WithInstance(sqlDB, &sqlitemigrate.Config{})
+	if err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	return newMigrator(src, "sqlite", driver, sqlDB, logger)
+}
+
+func newMigrator(src source.Driver, driverName string, driver database.Driver, sqlDB *sql.DB, logger *zap.Logger) (*Migrator, error) {
+	m, err := migrate.NewWithInstance("iofs", src, driverName, driver)
+	if err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+
+	logger = logger.Named("db.migrate")
+	m.Log = migrateLogger{logger: logger}
+
+	return &Migrator{m: m, src: src, db: sqlDB, logger: logger}, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up())
+}
+
+// UpSteps applies the given number of pending migrations.
+func (m *Migrator) UpSteps(steps int) error {
+	return ignoreNoChange(m.m.Steps(steps))
+}
+
+// Down rolls back the given number of migrations.
+func (m *Migrator) Down(steps int) error {
+	return ignoreNoChange(m.m.Steps(-steps))
+}
+
+// DownAll rolls back every applied migration.
+func (m *Migrator) DownAll() error {
+	return ignoreNoChange(m.m.Down())
+}
+
+// To migrates up or down to the given version.
+func (m *Migrator) To(version uint) error {
+	return ignoreNoChange(m.m.Migrate(version))
+}
+
+// Force sets the schema version without running migrations and clears the
+// dirty flag. Use it to recover from a failed migration.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Status returns the applied and latest available schema versions.
+func (m *Migrator) Status() (MigrationStatus, error) {
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return MigrationStatus{}, err
+	}
+
+	latest, err := m.latest()
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+
+	return MigrationStatus{Version: version, Dirty: dirty, Latest: latest}, nil
+}
+
+// EnsureCurrent returns an error unless every embedded migration is applied.
+func (m *Migrator) EnsureCurrent() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w at version %d, fix it and run `migrate force`", ErrSchemaDirty, status.Version)
+	}
+	if status.Pending() {
+		return fmt.Errorf("%w: at version %d, latest is %d; run `migrate up`", ErrSchemaBehind, status.Version, status.Latest)
+	}
+	return nil
+}
+
+// UpLocked applies pending migrations while holding a Postgres advisory lock,
+// so that replicas starting at the same time migrate one by one.
+func (m *Migrator) UpLocked(ctx context.Context, database *DB) error {
+	conn, err := database.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			m.logger.Error("failed to release migration lock", zap.Error(err))
+		}
+	}()
+
+	return m.Up()
+}
+
+// Close releases the migrator's database connection.
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr, m.db.Close())
+}
+
+// latest returns the highest embedded migration version.
+func (m *Migrator) latest() (uint, error) {
+	version, err := m.src.First()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	for {
+		next, err := m.src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// migrationsFS presents the migration files of several file systems as one
+// directory.
+type migrationsFS map[string]fs.FS
+
+// mergeMigrations merges the migration files at the root of sources.
+func mergeMigrations(sources []fs.FS) (migrationsFS, error) {
+	merged := make(migrationsFS)
+	// versions maps versions to a file and the index of its source
+	type origin struct {
+		file   string
+		source int
+	}
+	versions := make(map[uint64]origin)
+	for i, src := range sources {
+		entries, err := fs.ReadDir(src, ".")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			match := migrationFilePattern.FindStringSubmatch(e.Name())
+			if e.IsDir() || match == nil {
+				continue
+			}
+			version, err := strconv.ParseUint(match[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid migration %s: %w", e.Name(), err)
+			}
+			// The up and down files of a version come from the same source
+			if other, ok := versions[version]; ok && other.source != i {
+				return nil, fmt.Errorf("migrations %s and %s share version %d", other.file, e.Name(), version)
+			}
+			versions[version] = origin{file: e.Name(), source: i}
+			merged[e.Name()] = src
+		}
+	}
+	return merged, nil
+}
+
+func (m migrationsFS) Open(name string) (fs.File, error) {
+	src, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return src.Open(name)
+}
+
+func (m migrationsFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entries := make([]fs.DirEntry, 0, len(m))
+	for file, src := range m {
+		info, err := fs.Stat(src, file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.+\.(up|down)\.sql$`)
+
+// CreateMigration writes an empty up/down migration pair named name into dir,
+// numbered after the highest existing migration in dir and sources, the
+// migrations of the other modules sharing the sequence. It returns the
+// file paths.
+func CreateMigration(dir, name string, sources ...fs.FS) (up, down string, err error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", errors.New("migration name is required")
+	}
+	name = strings.ReplaceAll(name, " ", "_")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for _, src := range sources {
+		srcEntries, err := fs.ReadDir(src, ".")
+		if err != nil {
+			return "", "", err
+		}
+		entries = append(entries, srcEntries...)
+	}
+
+	var last uint64
+	for _, e := range entries {
+		match := migrationFilePattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		n, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		last = max(last, n)
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", last+1, name))
+	up, down = base+".up.sql", base+".down.sql"
+
+	for _, path := range []string{up, down} {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", err
+		}
+		if err := f.Close(); err != nil {
+			return "", "", err
+		}
+	}
+
+	return up, down, nil
+}
+
+// migrateLogger adapts zap to migrate.Logger.
+type migrateLogger struct {
+	logger *zap.Logger
+}
+
+func (l migrateLogger) Printf(format string, v ...any) {
+	l.logger.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l migrateLogger) Verbose() bool {
+	return false
+}
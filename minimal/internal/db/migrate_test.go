@@ -0,0 +1,159 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db/dbtest"
+	"go.uber.org/zap"
+)
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	up, down, err := db.CreateMigration(dir, " create notes ")
+	if err != nil {
+		t.Fatalf("CreateMigration: %v", err)
+	}
+	if want := filepath.Join(dir, "000001_create_notes.up.sql"); up != want {
+		t.Errorf("up = %s, want %s", up, want)
+	}
+	if want := filepath.Join(dir, "000001_create_notes.down.sql"); down != want {
+		t.Errorf("down = %s, want %s", down, want)
+	}
+	for _, path := range []string{up, down} {
+		if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+			t.Errorf("%s = %q, %v, want an empty file", path, data, err)
+		}
+	}
+
+	// Numbering continues after the highest migration, ignoring other files
+	for _, name := range []string{"000007_tags.up.sql", "000007_tags.down.sql", "README.md", "000099_notes.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	up, _, err = db.CreateMigration(dir, "add_links")
+	if err != nil {
+		t.Fatalf("CreateMigration: %v", err)
+	}
+	if want := filepath.Join(dir, "000008_add_links.up.sql"); up != want {
+		t.Errorf("up = %s, want %s", up, want)
+	}
+
+	// and after the migrations of other modules
+	module := fstest.MapFS{"000011_tags.up.sql": {}, "000011_tags.down.sql": {}}
+	up, _, err = db.CreateMigration(dir, "add_tags", module)
+	if err != nil {
+		t.Fatalf("CreateMigration: %v", err)
+	}
+	if want := filepath.Join(dir, "000012_add_tags.up.sql"); up != want {
+		t.Errorf("up = %s, want %s", up, want)
+	}
+
+	if _, _, err := db.CreateMigration(dir, "  "); err == nil {
+		t.Error("CreateMigration without a name succeeded")
+	}
+	if _, _, err := db.CreateMigration(filepath.Join(dir, "missing"), "tags"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("CreateMigration in missing dir = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	m, err := db.NewMigrator("sqlite://"+filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	status := func(t *testing.T, want uint) {
+		t.Helper()
+		got, err := m.Status()
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		if got.Version != want || got.Dirty {
+			t.Errorf("Status = %+v, want version %d", got, want)
+		}
+	}
+	step := func(t *testing.T, name string, err error, want uint) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		status(t, want)
+	}
+
+	got, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	latest := got.Latest
+	if got.Version != 0 || latest < 2 || !got.Pending() {
+		t.Fatalf("Status of new database = %+v", got)
+	}
+	if err := m.EnsureCurrent(); !errors.Is(err, db.ErrSchemaBehind) {
+		t.Errorf("EnsureCurrent of new database = %v, want ErrSchemaBehind", err)
+	}
+
+	step(t, "UpSteps", m.UpSteps(1), 1)
+	step(t, "Up", m.Up(), latest)
+	step(t, "Up when current", m.Up(), latest)
+	if err := m.EnsureCurrent(); err != nil {
+		t.Errorf("EnsureCurrent = %v", err)
+	}
+	step(t, "Down", m.Down(1), latest-1)
+	step(t, "To latest", m.To(latest), latest)
+	step(t, "To 1", m.To(1), 1)
+	step(t, "Force", m.Force(int(latest)), latest)
+	step(t, "Force back", m.Force(1), 1)
+	step(t, "DownAll", m.DownAll(), 0)
+	step(t, "DownAll when empty", m.DownAll(), 0)
+}
+
+// TestUpLocked runs migrators concurrently, as replicas do on startup.
+func TestUpLocked(t *testing.T) {
+	url := dbtest.PostgresURL(t)
+	database, err := db.NewPostgres(t.Context(), db.PostgresConfig{URL: url})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(database.Close)
+
+	const replicas = 3
+	migrators := make([]*db.Migrator, replicas)
+	for i := range migrators {
+		m, err := db.NewMigrator(url, zap.NewNop())
+		if err != nil {
+			t.Fatalf("NewMigrator: %v", err)
+		}
+		t.Cleanup(func() { m.Close() })
+		migrators[i] = m
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, replicas)
+	for i, m := range migrators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.UpLocked(context.Background(), database)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Fatalf("UpLocked: %v", err)
+	}
+
+	for _, m := range migrators {
+		if err := m.EnsureCurrent(); err != nil {
+			t.Errorf("EnsureCurrent = %v", err)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+-- Drop api_keys table first (depends on users)
+DROP TABLE IF EXISTS api_keys;
+
+-- Drop users table
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,23 @@
+DROP POLICY IF EXISTS notes_workspace_isolation ON notes;
+DROP POLICY IF EXISTS categories_workspace_isolation ON categories;
+ALTER TABLE notes DISABLE ROW LEVEL SECURITY;
+ALTER TABLE categories DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_api_keys_workspace_id;
+DROP INDEX IF EXISTS idx_notes_workspace_id;
+
+ALTER TABLE notes DROP CONSTRAINT IF EXISTS notes_workspace_id_category_id_fkey;
+ALTER TABLE notes ADD CONSTRAINT notes_category_id_fkey
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_workspace_id_id_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_workspace_id_name_key;
+-- Fails if several workspaces used the same category name
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE notes DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,69 @@
+-- Create workspaces table
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create workspace_members table
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+-- Create index for memberships by user
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- Add workspace columns to tenant tables
+ALTER TABLE categories ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE notes ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE api_keys ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+
+-- Move existing data into a default workspace owned by every existing user
+DO $$
+DECLARE
+    default_id UUID := gen_random_uuid();
+BEGIN
+    IF EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM categories) THEN
+        INSERT INTO workspaces (id, name) VALUES (default_id, 'Default');
+        INSERT INTO workspace_members (workspace_id, user_id, role)
+            SELECT default_id, id, 'owner' FROM users;
+        UPDATE categories SET workspace_id = default_id;
+        UPDATE notes SET workspace_id = default_id;
+        UPDATE api_keys SET workspace_id = default_id;
+    END IF;
+END $$;
+
+ALTER TABLE categories ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE notes ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE api_keys ALTER COLUMN workspace_id SET NOT NULL;
+
+-- Category names are unique per workspace
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+ALTER TABLE categories ADD CONSTRAINT categories_workspace_id_name_key UNIQUE (workspace_id, name);
+ALTER TABLE categories ADD CONSTRAINT categories_workspace_id_id_key UNIQUE (workspace_id, id);
+
+-- Notes may only reference categories of their own workspace
+ALTER TABLE notes DROP CONSTRAINT IF EXISTS notes_category_id_fkey;
+ALTER TABLE notes ADD CONSTRAINT notes_workspace_id_category_id_fkey
+    FOREIGN KEY (workspace_id, category_id) REFERENCES categories(workspace_id, id) ON DELETE CASCADE;
+
+-- Create indexes for tenant lookups
+CREATE INDEX IF NOT EXISTS idx_notes_workspace_id ON notes(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_workspace_id ON api_keys(workspace_id);
+
+-- Row-level security; policies apply to roles that do not own the tables
+ALTER TABLE categories ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notes ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY categories_workspace_isolation ON categories
+    USING (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid)
+    WITH CHECK (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid);
+
+CREATE POLICY notes_workspace_isolation ON notes
+    USING (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid)
+    WITH CHECK (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid);
@@ -0,0 +1,27 @@
+-- Create jobs table; running jobs are held by a worker until locked_until,
+-- after which another worker may claim them again
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    kind TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    state TEXT NOT NULL CHECK (state IN ('pending', 'running', 'succeeded', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    schedule TEXT NOT NULL DEFAULT '',
+    key TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create indexes for claiming due jobs
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_run_at ON jobs(run_at) WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_running_locked_until ON jobs(locked_until) WHERE state = 'running';
+
+-- Allow one pending or running job per key
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_active_key ON jobs(key) WHERE state IN ('pending', 'running');
+
+-- No row-level security: workers claim jobs of every workspace
@@ -0,0 +1,23 @@
+// Package migrations embeds the SQL schema migrations so that the
+// application binary can apply them without the migration files on disk.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the numbered *.up.sql and *.down.sql migration files of the
+// core schema: categories, notes, users, workspaces and jobs. Modules add
+// the migrations of their own tables, numbered in the same sequence.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite holds the migrations of SQLite databases, which store categories,
+// notes and their links, users, workspaces and API keys; everything else
+// stays in memory.
+var SQLite, _ = fs.Sub(sqliteFS, "sqlite")
@@ -0,0 +1,4 @@
+-- Drop tables in dependency order
+DROP TABLE IF EXISTS note_links;
+DROP TABLE IF EXISTS notes;
+DROP TABLE IF EXISTS categories;
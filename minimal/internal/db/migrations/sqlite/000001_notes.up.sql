@@ -0,0 +1,48 @@
+-- Times are text in db.SQLiteTimeFormat and JSON is text. workspace_id is
+-- not a foreign key, as workspaces are created by the next migration.
+
+-- Create categories table
+CREATE TABLE IF NOT EXISTS categories (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    metadata_schema TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    UNIQUE (workspace_id, name),
+    UNIQUE (workspace_id, id)
+);
+
+-- Create notes table; notes may only reference categories of their own
+-- workspace
+CREATE TABLE IF NOT EXISTS notes (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL,
+    category_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    metadata TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    FOREIGN KEY (workspace_id, category_id) REFERENCES categories (workspace_id, id) ON DELETE CASCADE
+);
+
+-- Create indexes for listing notes and resolving links by title
+CREATE INDEX IF NOT EXISTS idx_notes_workspace_created ON notes (workspace_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_notes_category_id ON notes (category_id);
+CREATE INDEX IF NOT EXISTS idx_notes_workspace_title ON notes (workspace_id, title);
+
+-- Create note_links table, as in Postgres
+CREATE TABLE IF NOT EXISTS note_links (
+    workspace_id TEXT NOT NULL,
+    source_id TEXT NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('title', 'id')),
+    ref TEXT NOT NULL,
+    target_id TEXT REFERENCES notes (id) ON DELETE SET NULL,
+    PRIMARY KEY (source_id, position)
+);
+
+-- Create indexes for backlinks and resolving dangling links
+CREATE INDEX IF NOT EXISTS idx_note_links_target_id ON note_links (target_id);
+CREATE INDEX IF NOT EXISTS idx_note_links_dangling ON note_links (workspace_id, ref) WHERE target_id IS NULL;
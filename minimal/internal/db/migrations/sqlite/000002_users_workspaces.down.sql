@@ -0,0 +1,5 @@
+-- Drop tables in dependency order
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+DROP TABLE IF EXISTS users;
@@ -3,19 +3,130 @@ package db
 import (
 	"context"
 	"fmt"
+
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
 )
 
-func NewPostgres(ctx context.Context, connectionString string) (*pgx.Conn, error) {
-	conn, err := pgx.Connect(ctx, connectionString)
+// Querier executes statements. It is implemented by both the connection
+// pool and a transaction, so repositories work the same inside and outside
+// of InTx.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PostgresConfig holds the database connection settings.
+type PostgresConfig struct {
+	URL string
+	// RowLevelSecurity sets the app.workspace_id session variable to the
+	// workspace of the context on every connection checkout, which the
+	// row-level security policies on tenant tables compare against.
+	RowLevelSecurity bool
+}
+
+// DB is a PostgreSQL connection pool that tracks transactions in contexts.
+type DB struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(ctx context.Context, cfg PostgresConfig) (*DB, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database url: %w", err)
+	}
+
+	if cfg.RowLevelSecurity {
+		poolCfg.PrepareConn = setWorkspace
+	}
 
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	if err := conn.Ping(ctx); err != nil {
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return conn, nil
+	return &DB{pool: pool}, nil
+}
+
+// setWorkspace stores the workspace of ctx, or an empty string if there is
+// none, in the app.workspace_id session variable of conn. Transactions
+// acquire their connection with the caller's context, so each transaction
+// runs with the variable of the workspace it was started for.
+func setWorkspace(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	var workspace string
+	if id, err := tenant.WorkspaceID(ctx); err == nil {
+		workspace = id.String()
+	}
+	if _, err := conn.Exec(ctx, `SELECT set_config('app.workspace_id', $1, false)`, workspace); err != nil {
+		return false, fmt.Errorf("failed to set workspace: %w", err)
+	}
+	return true, nil
+}
+
+// Q returns the transaction started by InTx for ctx, or the pool.
+func (d *DB) Q(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return d.pool
+}
+
+type txKey struct{}
+
+// InTx runs fn in a transaction. Repositories called with the context passed
+// to fn take part in the transaction. The transaction is committed if fn
+// returns nil and rolled back otherwise. Nested calls join the outer
+// transaction.
+func (d *DB) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Pool returns the underlying connection pool.
+func (d *DB) Pool() *pgxpool.Pool {
+	return d.pool
+}
+
+// Ping checks that the database can be reached.
+func (d *DB) Ping(ctx context.Context) error {
+	return d.pool.Ping(ctx)
+}
+
+// Close closes every connection in the pool.
+func (d *DB) Close() {
+	d.pool.Close()
+}
+
+// Transactor runs functions in a transaction. It is implemented by DB.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NopTransactor implements Transactor without a transaction. It is meant for
+// in-memory repositories, which have nothing to commit or roll back.
+type NopTransactor struct{}
+
+func (NopTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteScheme prefixes database URLs of SQLite databases, e.g.
+// sqlite://notes.db or sqlite:///var/lib/notes.db.
+const sqliteScheme = "sqlite://"
+
+// SQLiteTimeFormat is the layout times are stored in by SQLite
+// repositories. It is fixed-width UTC, so times sort as text.
+const SQLiteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+var (
+	// ErrUniqueViolation is returned by SQLiteError for statements
+	// violating a unique or primary key constraint.
+	ErrUniqueViolation = errors.New("unique constraint violated")
+	// ErrForeignKeyViolation is returned by SQLiteError for statements
+	// violating a foreign key constraint.
+	ErrForeignKeyViolation = errors.New("foreign key constraint violated")
+)
+
+// IsSQLite reports whether databaseURL names a SQLite database.
+func IsSQLite(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, sqliteScheme)
+}
+
+// SQLiteQuerier executes statements. It is implemented by both the
+// database and a transaction, like Querier.
+type SQLiteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLite is a SQLite database that tracks transactions in contexts like
+// DB. It is meant for local development and tests: writers are
+// serialized on a single connection and tables are not row-level secured.
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLite opens the SQLite database at databaseURL, creating the file if
+// it does not exist.
+func NewSQLite(ctx context.Context, databaseURL string) (*SQLite, error) {
+	sqlDB, err := openSQLite(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	// A single connection avoids "database is locked" errors between
+	// concurrent writers, and transactions hold it until they end
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &SQLite{db: sqlDB}, nil
+}
+
+// openSQLite opens the database at databaseURL with foreign keys enforced.
+func openSQLite(databaseURL string) (*sql.DB, error) {
+	path, ok := strings.CutPrefix(databaseURL, sqliteScheme)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid sqlite database url %q", databaseURL)
+	}
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return sqlDB, nil
+}
+
+// Q returns the transaction started by InTx for ctx, or the database.
+func (s *SQLite) Q(ctx context.Context) SQLiteQuerier {
+	if tx, ok := ctx.Value(sqliteTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return s.db
+}
+
+type sqliteTxKey struct{}
+
+// InTx runs fn in a transaction like DB.InTx. As the database has a
+// single connection, fn must pass its context to every repository call.
+func (s *SQLite) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(sqliteTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, sqliteTxKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Ping checks that the database can be reached.
+func (s *SQLite) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Close closes the database.
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// SQLiteError translates constraint violations in err to
+// ErrUniqueViolation and ErrForeignKeyViolation, and returns other errors
+// unchanged.
+func SQLiteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return ErrUniqueViolation
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return ErrForeignKeyViolation
+	}
+	return err
+}
+
+// SQLiteTime formats t for storage in SQLite.
+func SQLiteTime(t time.Time) string {
+	return t.UTC().Format(SQLiteTimeFormat)
+}
+
+// ParseSQLiteTime parses a time stored by SQLiteTime.
+func ParseSQLiteTime(s string) (time.Time, error) {
+	return time.Parse(SQLiteTimeFormat, s)
+}
+
+// SQLiteNullTime formats t like SQLiteTime, or returns NULL if t is nil.
+func SQLiteNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return SQLiteTime(*t)
+}
+
+// ParseSQLiteNullTime parses a time stored by SQLiteNullTime.
+func ParseSQLiteNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := ParseSQLiteTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SQLiteArray encodes values as a JSON array, which statements expand with
+// json_each where Postgres would take an array.
+func SQLiteArray(values []string) string {
+	if values == nil {
+		return "[]"
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
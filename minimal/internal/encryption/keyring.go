@@ -0,0 +1,263 @@
+// Package encryption implements envelope encryption: every record is
+// sealed with its own data key, which is stored alongside it wrapped by a
+// key-encryption key. A database dump is unreadable without it. Rotating
+// the key-encryption key only requires rewrapping data keys and
+// recomputing index hashes, which are keyed by it; records are not sealed
+// again.
+//
+// Key-encryption keys are named, so records remember the key that wrapped
+// their data key and old keys can stay configured until every record has
+// moved to the current one.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrKeyNotFound is the error of records wrapped by a key that is not
+	// configured.
+	ErrKeyNotFound = errors.New("encryption key not configured")
+	// ErrDecrypt is the error of ciphertexts that fail authentication,
+	// because they were altered or belong to another record.
+	ErrDecrypt = errors.New("decryption failed")
+)
+
+// KeySize is the size of keys in bytes, selecting AES-256.
+const KeySize = 32
+
+// Keyring holds the key-encryption keys by ID. The current key wraps new
+// data keys; the others only unwrap existing ones.
+type Keyring struct {
+	current string
+	keys    map[string]kek
+}
+
+// kek is a key-encryption key with the key of its index hashes, derived
+// from it.
+type kek struct {
+	aead  cipher.AEAD
+	index []byte
+}
+
+// ParseKeyring parses keys given as id:base64 entries separated by commas
+// or newlines, the first being the current one. Blank lines and lines
+// starting with # are ignored, so the keys may be kept in a file.
+func ParseKeyring(s string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]kek)}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, entry := range strings.Split(line, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			if err := k.add(entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if k.current == "" {
+		return nil, errors.New("no encryption keys given")
+	}
+	return k, nil
+}
+
+// LoadKeyring parses the keys in the file at path, see ParseKeyring.
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read encryption keys: %w", err)
+	}
+	k, err := ParseKeyring(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+func (k *Keyring) add(entry string) error {
+	id, encoded, ok := strings.Cut(entry, ":")
+	if !ok || id == "" {
+		return fmt.Errorf("encryption key %q must be given as id:base64", entry)
+	}
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("encryption key %q is given twice", id)
+	}
+	secret, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("encryption key %q is not valid base64: %w", id, err)
+	}
+	if len(secret) != KeySize {
+		return fmt.Errorf("encryption key %q has %d bytes, want %d", id, len(secret), KeySize)
+	}
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return err
+	}
+	index := hmac.New(sha256.New, secret)
+	index.Write([]byte("index"))
+	k.keys[id] = kek{aead: aead, index: index.Sum(nil)}
+	if k.current == "" {
+		k.current = id
+	}
+	return nil
+}
+
+// Current returns the ID of the key wrapping new data keys.
+func (k *Keyring) Current() string {
+	return k.current
+}
+
+// IDs returns the IDs of the keys, current first.
+func (k *Keyring) IDs() []string {
+	ids := []string{k.current}
+	for id := range k.keys {
+		if id != k.current {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// NewDataKey generates a data key and returns it with its wrapping by the
+// current key.
+func (k *Keyring) NewDataKey() (*DataKey, []byte, error) {
+	secret := make([]byte, KeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, fmt.Errorf("generate data key: %w", err)
+	}
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := k.wrap(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &DataKey{aead: aead}, wrapped, nil
+}
+
+// wrap wraps secret by the current key. The key ID is authenticated so a
+// wrapping cannot be relabelled.
+func (k *Keyring) wrap(secret []byte) ([]byte, error) {
+	return seal(k.keys[k.current].aead, secret, []byte(k.current))
+}
+
+// Rewrap returns the data key wrapped by the key with keyID wrapped by the
+// current key instead, leaving what it sealed as is. It returns
+// ErrKeyNotFound if that key is not configured.
+func (k *Keyring) Rewrap(keyID string, wrapped []byte) ([]byte, error) {
+	secret, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return k.wrap(secret)
+}
+
+// Unwrap returns the data key wrapped by the key with keyID. It returns
+// ErrKeyNotFound if that key is not configured.
+func (k *Keyring) Unwrap(keyID string, wrapped []byte) (*DataKey, error) {
+	secret, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{aead: aead}, nil
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+	return open(key.aead, wrapped, []byte(keyID))
+}
+
+// Index returns a keyed hash of value for looking records up by it
+// without storing it in the clear, one for every key, current first.
+// Records store the hash of their own key, which may not be the current
+// one until they are rewrapped. Equal values have equal hashes, so scope
+// value to where equality may be revealed, e.g. by prefixing a tenant.
+func (k *Keyring) Index(value []byte) [][]byte {
+	hashes := [][]byte{k.index(k.current, value)}
+	for id := range k.keys {
+		if id != k.current {
+			hashes = append(hashes, k.index(id, value))
+		}
+	}
+	return hashes
+}
+
+// IndexWith returns the hash of value by the key with keyID, see Index.
+func (k *Keyring) IndexWith(keyID string, value []byte) ([]byte, error) {
+	if _, ok := k.keys[keyID]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+	return k.index(keyID, value), nil
+}
+
+func (k *Keyring) index(keyID string, value []byte) []byte {
+	h := hmac.New(sha256.New, k.keys[keyID].index)
+	h.Write(value)
+	return h.Sum(nil)
+}
+
+// DataKey encrypts the fields of one record.
+type DataKey struct {
+	aead cipher.AEAD
+}
+
+// Seal encrypts plaintext, binding it to aad, e.g. the record ID and
+// field name, so it cannot be moved to another record or field.
+func (d *DataKey) Seal(plaintext, aad []byte) ([]byte, error) {
+	return seal(d.aead, plaintext, aad)
+}
+
+// Open decrypts a ciphertext sealed with the same aad.
+func (d *DataKey) Open(ciphertext, aad []byte) ([]byte, error) {
+	return open(d.aead, ciphertext, aad)
+}
+
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, which prefixes the result.
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
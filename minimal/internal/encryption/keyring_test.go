@@ -0,0 +1,141 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
+)
+
+// key returns an id:base64 entry of a key filled with b.
+func key(id string, b byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, encryption.KeySize))
+}
+
+func parse(t *testing.T, s string) *encryption.Keyring {
+	t.Helper()
+	k, err := encryption.ParseKeyring(s)
+	if err != nil {
+		t.Fatalf("parse keyring: %v", err)
+	}
+	return k
+}
+
+func TestParseKeyring(t *testing.T) {
+	k := parse(t, "\n# rotated 2026-10\n"+key("v2", 2)+",\n"+key("v1", 1)+"\n")
+	if k.Current() != "v2" {
+		t.Errorf("current = %q, want v2", k.Current())
+	}
+
+	tests := []struct {
+		name, keys, message string
+	}{
+		{"empty", " # none\n", "no encryption keys given"},
+		{"no id", base64.StdEncoding.EncodeToString(make([]byte, 32)), "must be given as id:base64"},
+		{"bad base64", "v1:not base64", "not valid base64"},
+		{"short", "v1:" + base64.StdEncoding.EncodeToString(make([]byte, 16)), "has 16 bytes, want 32"},
+		{"duplicate", key("v1", 1) + "," + key("v1", 2), `"v1" is given twice`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := encryption.ParseKeyring(tt.keys)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte(key("v1", 1)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	k, err := encryption.LoadKeyring(path)
+	if err != nil || k.Current() != "v1" {
+		t.Fatalf("load = %v, %v", k, err)
+	}
+
+	if _, err := encryption.LoadKeyring(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("load missing file = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	old := parse(t, key("v1", 1))
+	dk, wrapped, err := old.NewDataKey()
+	if err != nil {
+		t.Fatalf("new data key: %v", err)
+	}
+	aad := []byte("note-1/content")
+	sealed, err := dk.Seal([]byte("secret"), aad)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+
+	// After rotation the old key still opens existing records
+	rotated := parse(t, key("v2", 2)+","+key("v1", 1))
+	dk, err = rotated.Unwrap("v1", wrapped)
+	if err != nil {
+		t.Fatalf("unwrap: %v", err)
+	}
+	plaintext, err := dk.Open(sealed, aad)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatalf("open = %q, %v", plaintext, err)
+	}
+
+	if _, err := dk.Open(sealed, []byte("note-2/content")); !errors.Is(err, encryption.ErrDecrypt) {
+		t.Errorf("open with other aad = %v, want ErrDecrypt", err)
+	}
+	if _, err := rotated.Unwrap("v2", wrapped); !errors.Is(err, encryption.ErrDecrypt) {
+		t.Errorf("unwrap relabelled key = %v, want ErrDecrypt", err)
+	}
+	if _, err := parse(t, key("v2", 2)).Unwrap("v1", wrapped); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Errorf("unwrap without key = %v, want ErrKeyNotFound", err)
+	}
+
+	// Rewrapping moves the data key to the current key, which opens the
+	// record without the old one
+	rewrapped, err := rotated.Rewrap("v1", wrapped)
+	if err != nil {
+		t.Fatalf("rewrap: %v", err)
+	}
+	dk, err = parse(t, key("v2", 2)).Unwrap("v2", rewrapped)
+	if err != nil {
+		t.Fatalf("unwrap rewrapped: %v", err)
+	}
+	if plaintext, err := dk.Open(sealed, aad); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("open rewrapped = %q, %v", plaintext, err)
+	}
+	if _, err := rotated.Rewrap("v3", wrapped); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Errorf("rewrap without key = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	old := parse(t, key("v1", 1))
+	rotated := parse(t, key("v2", 2)+","+key("v1", 1))
+
+	stored, err := old.IndexWith("v1", []byte("Plan"))
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	hashes := rotated.Index([]byte("Plan"))
+	if len(hashes) != 2 || bytes.Equal(hashes[0], stored) || !bytes.Equal(hashes[1], stored) {
+		t.Errorf("index after rotation does not find the stored hash last")
+	}
+	if bytes.Equal(old.Index([]byte("plan"))[0], stored) {
+		t.Error("different values have equal hashes")
+	}
+	if _, err := old.IndexWith("v2", []byte("Plan")); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Errorf("index with unknown key = %v, want ErrKeyNotFound", err)
+	}
+}
@@ -0,0 +1,419 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: notes/v1/notes.proto
+
+package notesv1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	v1 "github.com/piotmni/go-mini-templates/minimal/internal/gen/notes/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// CategoryServiceName is the fully-qualified name of the CategoryService service.
+	CategoryServiceName = "notes.v1.CategoryService"
+	// NoteServiceName is the fully-qualified name of the NoteService service.
+	NoteServiceName = "notes.v1.NoteService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// CategoryServiceCreateCategoryProcedure is the fully-qualified name of the CategoryService's
+	// CreateCategory RPC.
+	CategoryServiceCreateCategoryProcedure = "/notes.v1.CategoryService/CreateCategory"
+	// CategoryServiceGetCategoryProcedure is the fully-qualified name of the CategoryService's
+	// GetCategory RPC.
+	CategoryServiceGetCategoryProcedure = "/notes.v1.CategoryService/GetCategory"
+	// CategoryServiceListCategoriesProcedure is the fully-qualified name of the CategoryService's
+	// ListCategories RPC.
+	CategoryServiceListCategoriesProcedure = "/notes.v1.CategoryService/ListCategories"
+	// CategoryServiceUpdateCategoryProcedure is the fully-qualified name of the CategoryService's
+	// UpdateCategory RPC.
+	CategoryServiceUpdateCategoryProcedure = "/notes.v1.CategoryService/UpdateCategory"
+	// CategoryServiceDeleteCategoryProcedure is the fully-qualified name of the CategoryService's
+	// DeleteCategory RPC.
+	CategoryServiceDeleteCategoryProcedure = "/notes.v1.CategoryService/DeleteCategory"
+	// NoteServiceCreateNoteProcedure is the fully-qualified name of the NoteService's CreateNote RPC.
+	NoteServiceCreateNoteProcedure = "/notes.v1.NoteService/CreateNote"
+	// NoteServiceGetNoteProcedure is the fully-qualified name of the NoteService's GetNote RPC.
+	NoteServiceGetNoteProcedure = "/notes.v1.NoteService/GetNote"
+	// NoteServiceListNotesProcedure is the fully-qualified name of the NoteService's ListNotes RPC.
+	NoteServiceListNotesProcedure = "/notes.v1.NoteService/ListNotes"
+	// NoteServiceUpdateNoteProcedure is the fully-qualified name of the NoteService's UpdateNote RPC.
+	NoteServiceUpdateNoteProcedure = "/notes.v1.NoteService/UpdateNote"
+	// NoteServiceDeleteNoteProcedure is the fully-qualified name of the NoteService's DeleteNote RPC.
+	NoteServiceDeleteNoteProcedure = "/notes.v1.NoteService/DeleteNote"
+)
+
+// CategoryServiceClient is a client for the notes.v1.CategoryService service.
+type CategoryServiceClient interface {
+	CreateCategory(context.Context, *connect.Request[v1.CreateCategoryRequest]) (*connect.Response[v1.CreateCategoryResponse], error)
+	GetCategory(context.Context, *connect.Request[v1.GetCategoryRequest]) (*connect.Response[v1.GetCategoryResponse], error)
+	ListCategories(context.Context, *connect.Request[v1.ListCategoriesRequest]) (*connect.Response[v1.ListCategoriesResponse], error)
+	UpdateCategory(context.Context, *connect.Request[v1.UpdateCategoryRequest]) (*connect.Response[v1.UpdateCategoryResponse], error)
+	DeleteCategory(context.Context, *connect.Request[v1.DeleteCategoryRequest]) (*connect.Response[v1.DeleteCategoryResponse], error)
+}
+
+// NewCategoryServiceClient constructs a client for the notes.v1.CategoryService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewCategoryServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) CategoryServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	categoryServiceMethods := v1.File_notes_v1_notes_proto.Services().ByName("CategoryService").Methods()
+	return &categoryServiceClient{
+		createCategory: connect.NewClient[v1.CreateCategoryRequest, v1.CreateCategoryResponse](
+			httpClient,
+			baseURL+CategoryServiceCreateCategoryProcedure,
+			connect.WithSchema(categoryServiceMethods.ByName("CreateCategory")),
+			connect.WithClientOptions(opts...),
+		),
+		getCategory: connect.NewClient[v1.GetCategoryRequest, v1.GetCategoryResponse](
+			httpClient,
+			baseURL+CategoryServiceGetCategoryProcedure,
+			connect.WithSchema(categoryServiceMethods.ByName("GetCategory")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		listCategories: connect.NewClient[v1.ListCategoriesRequest, v1.ListCategoriesResponse](
+			httpClient,
+			baseURL+CategoryServiceListCategoriesProcedure,
+			connect.WithSchema(categoryServiceMethods.ByName("ListCategories")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		updateCategory: connect.NewClient[v1.UpdateCategoryRequest, v1.UpdateCategoryResponse](
+			httpClient,
+			baseURL+CategoryServiceUpdateCategoryProcedure,
+			connect.WithSchema(categoryServiceMethods.ByName("UpdateCategory")),
+			connect.WithClientOptions(opts...),
+		),
+		deleteCategory: connect.NewClient[v1.DeleteCategoryRequest, v1.DeleteCategoryResponse](
+			httpClient,
+			baseURL+CategoryServiceDeleteCategoryProcedure,
+			connect.WithSchema(categoryServiceMethods.ByName("DeleteCategory")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// categoryServiceClient implements CategoryServiceClient.
+type categoryServiceClient struct {
+	createCategory *connect.Client[v1.CreateCategoryRequest, v1.CreateCategoryResponse]
+	getCategory    *connect.Client[v1.GetCategoryRequest, v1.GetCategoryResponse]
+	listCategories *connect.Client[v1.ListCategoriesRequest, v1.ListCategoriesResponse]
+	updateCategory *connect.Client[v1.UpdateCategoryRequest, v1.UpdateCategoryResponse]
+	deleteCategory *connect.Client[v1.DeleteCategoryRequest, v1.DeleteCategoryResponse]
+}
+
+// CreateCategory calls notes.v1.CategoryService.CreateCategory.
+func (c *categoryServiceClient) CreateCategory(ctx context.Context, req *connect.Request[v1.CreateCategoryRequest]) (*connect.Response[v1.CreateCategoryResponse], error) {
+	return c.createCategory.CallUnary(ctx, req)
+}
+
+// GetCategory calls notes.v1.CategoryService.GetCategory.
+func (c *categoryServiceClient) GetCategory(ctx context.Context, req *connect.Request[v1.GetCategoryRequest]) (*connect.Response[v1.GetCategoryResponse], error) {
+	return c.getCategory.CallUnary(ctx, req)
+}
+
+// ListCategories calls notes.v1.CategoryService.ListCategories.
+func (c *categoryServiceClient) ListCategories(ctx context.Context, req *connect.Request[v1.ListCategoriesRequest]) (*connect.Response[v1.ListCategoriesResponse], error) {
+	return c.listCategories.CallUnary(ctx, req)
+}
+
+// UpdateCategory calls notes.v1.CategoryService.UpdateCategory.
+func (c *categoryServiceClient) UpdateCategory(ctx context.Context, req *connect.Request[v1.UpdateCategoryRequest]) (*connect.Response[v1.UpdateCategoryResponse], error) {
+	return c.updateCategory.CallUnary(ctx, req)
+}
+
+// DeleteCategory calls notes.v1.CategoryService.DeleteCategory.
+func (c *categoryServiceClient) DeleteCategory(ctx context.Context, req *connect.Request[v1.DeleteCategoryRequest]) (*connect.Response[v1.DeleteCategoryResponse], error) {
+	return c.deleteCategory.CallUnary(ctx, req)
+}
+
+// CategoryServiceHandler is an implementation of the notes.v1.CategoryService service.
+type CategoryServiceHandler interface {
+	CreateCategory(context.Context, *connect.Request[v1.CreateCategoryRequest]) (*connect.Response[v1.CreateCategoryResponse], error)
+	GetCategory(context.Context, *connect.Request[v1.GetCategoryRequest]) (*connect.Response[v1.GetCategoryResponse], error)
+	ListCategories(context.Context, *connect.Request[v1.ListCategoriesRequest]) (*connect.Response[v1.ListCategoriesResponse], error)
+	UpdateCategory(context.Context, *connect.Request[v1.UpdateCategoryRequest]) (*connect.Response[v1.UpdateCategoryResponse], error)
+	DeleteCategory(context.Context, *connect.Request[v1.DeleteCategoryRequest]) (*connect.Response[v1.DeleteCategoryResponse], error)
+}
+
+// NewCategoryServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewCategoryServiceHandler(svc CategoryServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	categoryServiceMethods := v1.File_notes_v1_notes_proto.Services().ByName("CategoryService").Methods()
+	categoryServiceCreateCategoryHandler := connect.NewUnaryHandler(
+		CategoryServiceCreateCategoryProcedure,
+		svc.CreateCategory,
+		connect.WithSchema(categoryServiceMethods.ByName("CreateCategory")),
+		connect.WithHandlerOptions(opts...),
+	)
+	categoryServiceGetCategoryHandler := connect.NewUnaryHandler(
+		CategoryServiceGetCategoryProcedure,
+		svc.GetCategory,
+		connect.WithSchema(categoryServiceMethods.ByName("GetCategory")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	categoryServiceListCategoriesHandler := connect.NewUnaryHandler(
+		CategoryServiceListCategoriesProcedure,
+		svc.ListCategories,
+		connect.WithSchema(categoryServiceMethods.ByName("ListCategories")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	categoryServiceUpdateCategoryHandler := connect.NewUnaryHandler(
+		CategoryServiceUpdateCategoryProcedure,
+		svc.UpdateCategory,
+		connect.WithSchema(categoryServiceMethods.ByName("UpdateCategory")),
+		connect.WithHandlerOptions(opts...),
+	)
+	categoryServiceDeleteCategoryHandler := connect.NewUnaryHandler(
+		CategoryServiceDeleteCategoryProcedure,
+		svc.DeleteCategory,
+		connect.WithSchema(categoryServiceMethods.ByName("DeleteCategory")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/notes.v1.CategoryService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case CategoryServiceCreateCategoryProcedure:
+			categoryServiceCreateCategoryHandler.ServeHTTP(w, r)
+		case CategoryServiceGetCategoryProcedure:
+			categoryServiceGetCategoryHandler.ServeHTTP(w, r)
+		case CategoryServiceListCategoriesProcedure:
+			categoryServiceListCategoriesHandler.ServeHTTP(w, r)
+		case CategoryServiceUpdateCategoryProcedure:
+			categoryServiceUpdateCategoryHandler.ServeHTTP(w, r)
+		case CategoryServiceDeleteCategoryProcedure:
+			categoryServiceDeleteCategoryHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedCategoryServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedCategoryServiceHandler struct{}
+
+func (UnimplementedCategoryServiceHandler) CreateCategory(context.Context, *connect.Request[v1.CreateCategoryRequest]) (*connect.Response[v1.CreateCategoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.CategoryService.CreateCategory is not implemented"))
+}
+
+func (UnimplementedCategoryServiceHandler) GetCategory(context.Context, *connect.Request[v1.GetCategoryRequest]) (*connect.Response[v1.GetCategoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.CategoryService.GetCategory is not implemented"))
+}
+
+func (UnimplementedCategoryServiceHandler) ListCategories(context.Context, *connect.Request[v1.ListCategoriesRequest]) (*connect.Response[v1.ListCategoriesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.CategoryService.ListCategories is not implemented"))
+}
+
+func (UnimplementedCategoryServiceHandler) UpdateCategory(context.Context, *connect.Request[v1.UpdateCategoryRequest]) (*connect.Response[v1.UpdateCategoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.CategoryService.UpdateCategory is not implemented"))
+}
+
+func (UnimplementedCategoryServiceHandler) DeleteCategory(context.Context, *connect.Request[v1.DeleteCategoryRequest]) (*connect.Response[v1.DeleteCategoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.CategoryService.DeleteCategory is not implemented"))
+}
+
+// NoteServiceClient is a client for the notes.v1.NoteService service.
+type NoteServiceClient interface {
+	CreateNote(context.Context, *connect.Request[v1.CreateNoteRequest]) (*connect.Response[v1.CreateNoteResponse], error)
+	GetNote(context.Context, *connect.Request[v1.GetNoteRequest]) (*connect.Response[v1.GetNoteResponse], error)
+	ListNotes(context.Context, *connect.Request[v1.ListNotesRequest]) (*connect.Response[v1.ListNotesResponse], error)
+	UpdateNote(context.Context, *connect.Request[v1.UpdateNoteRequest]) (*connect.Response[v1.UpdateNoteResponse], error)
+	DeleteNote(context.Context, *connect.Request[v1.DeleteNoteRequest]) (*connect.Response[v1.DeleteNoteResponse], error)
+}
+
+// NewNoteServiceClient constructs a client for the notes.v1.NoteService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewNoteServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) NoteServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	noteServiceMethods := v1.File_notes_v1_notes_proto.Services().ByName("NoteService").Methods()
+	return &noteServiceClient{
+		createNote: connect.NewClient[v1.CreateNoteRequest, v1.CreateNoteResponse](
+			httpClient,
+			baseURL+NoteServiceCreateNoteProcedure,
+			connect.WithSchema(noteServiceMethods.ByName("CreateNote")),
+			connect.WithClientOptions(opts...),
+		),
+		getNote: connect.NewClient[v1.GetNoteRequest, v1.GetNoteResponse](
+			httpClient,
+			baseURL+NoteServiceGetNoteProcedure,
+			connect.WithSchema(noteServiceMethods.ByName("GetNote")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		listNotes: connect.NewClient[v1.ListNotesRequest, v1.ListNotesResponse](
+			httpClient,
+			baseURL+NoteServiceListNotesProcedure,
+			connect.WithSchema(noteServiceMethods.ByName("ListNotes")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		updateNote: connect.NewClient[v1.UpdateNoteRequest, v1.UpdateNoteResponse](
+			httpClient,
+			baseURL+NoteServiceUpdateNoteProcedure,
+			connect.WithSchema(noteServiceMethods.ByName("UpdateNote")),
+			connect.WithClientOptions(opts...),
+		),
+		deleteNote: connect.NewClient[v1.DeleteNoteRequest, v1.DeleteNoteResponse](
+			httpClient,
+			baseURL+NoteServiceDeleteNoteProcedure,
+			connect.WithSchema(noteServiceMethods.ByName("DeleteNote")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// noteServiceClient implements NoteServiceClient.
+type noteServiceClient struct {
+	createNote *connect.Client[v1.CreateNoteRequest, v1.CreateNoteResponse]
+	getNote    *connect.Client[v1.GetNoteRequest, v1.GetNoteResponse]
+	listNotes  *connect.Client[v1.ListNotesRequest, v1.ListNotesResponse]
+	updateNote *connect.Client[v1.UpdateNoteRequest, v1.UpdateNoteResponse]
+	deleteNote *connect.Client[v1.DeleteNoteRequest, v1.DeleteNoteResponse]
+}
+
+// CreateNote calls notes.v1.NoteService.CreateNote.
+func (c *noteServiceClient) CreateNote(ctx context.Context, req *connect.Request[v1.CreateNoteRequest]) (*connect.Response[v1.CreateNoteResponse], error) {
+	return c.createNote.CallUnary(ctx, req)
+}
+
+// GetNote calls notes.v1.NoteService.GetNote.
+func (c *noteServiceClient) GetNote(ctx context.Context, req *connect.Request[v1.GetNoteRequest]) (*connect.Response[v1.GetNoteResponse], error) {
+	return c.getNote.CallUnary(ctx, req)
+}
+
+// ListNotes calls notes.v1.NoteService.ListNotes.
+func (c *noteServiceClient) ListNotes(ctx context.Context, req *connect.Request[v1.ListNotesRequest]) (*connect.Response[v1.ListNotesResponse], error) {
+	return c.listNotes.CallUnary(ctx, req)
+}
+
+// UpdateNote calls notes.v1.NoteService.UpdateNote.
+func (c *noteServiceClient) UpdateNote(ctx context.Context, req *connect.Request[v1.UpdateNoteRequest]) (*connect.Response[v1.UpdateNoteResponse], error) {
+	return c.updateNote.CallUnary(ctx, req)
+}
+
+// DeleteNote calls notes.v1.NoteService.DeleteNote.
+func (c *noteServiceClient) DeleteNote(ctx context.Context, req *connect.Request[v1.DeleteNoteRequest]) (*connect.Response[v1.DeleteNoteResponse], error) {
+	return c.deleteNote.CallUnary(ctx, req)
+}
+
+// NoteServiceHandler is an implementation of the notes.v1.NoteService service.
+type NoteServiceHandler interface {
+	CreateNote(context.Context, *connect.Request[v1.CreateNoteRequest]) (*connect.Response[v1.CreateNoteResponse], error)
+	GetNote(context.Context, *connect.Request[v1.GetNoteRequest]) (*connect.Response[v1.GetNoteResponse], error)
+	ListNotes(context.Context, *connect.Request[v1.ListNotesRequest]) (*connect.Response[v1.ListNotesResponse], error)
+	UpdateNote(context.Context, *connect.Request[v1.UpdateNoteRequest]) (*connect.Response[v1.UpdateNoteResponse], error)
+	DeleteNote(context.Context, *connect.Request[v1.DeleteNoteRequest]) (*connect.Response[v1.DeleteNoteResponse], error)
+}
+
+// NewNoteServiceHandler builds an HTTP handler from the service implementation. It returns the path
+// on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewNoteServiceHandler(svc NoteServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	noteServiceMethods := v1.File_notes_v1_notes_proto.Services().ByName("NoteService").Methods()
+	noteServiceCreateNoteHandler := connect.NewUnaryHandler(
+		NoteServiceCreateNoteProcedure,
+		svc.CreateNote,
+		connect.WithSchema(noteServiceMethods.ByName("CreateNote")),
+		connect.WithHandlerOptions(opts...),
+	)
+	noteServiceGetNoteHandler := connect.NewUnaryHandler(
+		NoteServiceGetNoteProcedure,
+		svc.GetNote,
+		connect.WithSchema(noteServiceMethods.ByName("GetNote")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	noteServiceListNotesHandler := connect.NewUnaryHandler(
+		NoteServiceListNotesProcedure,
+		svc.ListNotes,
+		connect.WithSchema(noteServiceMethods.ByName("ListNotes")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	noteServiceUpdateNoteHandler := connect.NewUnaryHandler(
+		NoteServiceUpdateNoteProcedure,
+		svc.UpdateNote,
+		connect.WithSchema(noteServiceMethods.ByName("UpdateNote")),
+		connect.WithHandlerOptions(opts...),
+	)
+	noteServiceDeleteNoteHandler := connect.NewUnaryHandler(
+		NoteServiceDeleteNoteProcedure,
+		svc.DeleteNote,
+		connect.WithSchema(noteServiceMethods.ByName("DeleteNote")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/notes.v1.NoteService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case NoteServiceCreateNoteProcedure:
+			noteServiceCreateNoteHandler.ServeHTTP(w, r)
+		case NoteServiceGetNoteProcedure:
+			noteServiceGetNoteHandler.ServeHTTP(w, r)
+		case NoteServiceListNotesProcedure:
+			noteServiceListNotesHandler.ServeHTTP(w, r)
+		case NoteServiceUpdateNoteProcedure:
+			noteServiceUpdateNoteHandler.ServeHTTP(w, r)
+		case NoteServiceDeleteNoteProcedure:
+			noteServiceDeleteNoteHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedNoteServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedNoteServiceHandler struct{}
+
+func (UnimplementedNoteServiceHandler) CreateNote(context.Context, *connect.Request[v1.CreateNoteRequest]) (*connect.Response[v1.CreateNoteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.NoteService.CreateNote is not implemented"))
+}
+
+func (UnimplementedNoteServiceHandler) GetNote(context.Context, *connect.Request[v1.GetNoteRequest]) (*connect.Response[v1.GetNoteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.NoteService.GetNote is not implemented"))
+}
+
+func (UnimplementedNoteServiceHandler) ListNotes(context.Context, *connect.Request[v1.ListNotesRequest]) (*connect.Response[v1.ListNotesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.NoteService.ListNotes is not implemented"))
+}
+
+func (UnimplementedNoteServiceHandler) UpdateNote(context.Context, *connect.Request[v1.UpdateNoteRequest]) (*connect.Response[v1.UpdateNoteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.NoteService.UpdateNote is not implemented"))
+}
+
+func (UnimplementedNoteServiceHandler) DeleteNote(context.Context, *connect.Request[v1.DeleteNoteRequest]) (*connect.Response[v1.DeleteNoteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("notes.v1.NoteService.DeleteNote is not implemented"))
+}
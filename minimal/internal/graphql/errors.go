@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+)
+
+// Error codes reported in the extensions of GraphQL errors.
+const (
+	codeBadRequest = "BAD_REQUEST"
+	codeForbidden  = "FORBIDDEN"
+	codeNotFound   = "NOT_FOUND"
+	codeConflict   = "CONFLICT"
+	codeInternal   = "INTERNAL"
+)
+
+// Error is a resolver error with a machine readable code.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements the graphql-go ResolverError interface.
+func (e *Error) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
+// domainCodes maps domain errors to the codes reported for them. With
+// detail, the message of the error itself is reported, as it describes
+// what is wrong with the request.
+var domainCodes = []struct {
+	err    error
+	code   string
+	detail bool
+}{
+	{category.ErrNotFound, codeNotFound, false},
+	{category.ErrAlreadyExists, codeConflict, false},
+	{note.ErrNotFound, codeNotFound, false},
+	// Moving a note to a category whose schema its metadata fails
+	{note.ErrInvalidMetadata, codeBadRequest, true},
+}
+
+// toError translates a service error. Unknown errors are reported with the
+// generic message so that details do not leak, and logged with their
+// cause.
+func (r *Resolver) toError(ctx context.Context, err error, message string) error {
+	for _, d := range domainCodes {
+		if errors.Is(err, d.err) {
+			if d.detail {
+				return &Error{Code: d.code, Message: err.Error()}
+			}
+			return &Error{Code: d.code, Message: d.err.Error()}
+		}
+	}
+	logger.FromContext(ctx, r.logger).Error(message, zap.Error(err))
+	return &Error{Code: codeInternal, Message: message}
+}
+
+// badRequest reports a malformed argument.
+func badRequest(format string, args ...any) error {
+	return &Error{Code: codeBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// requireScope checks that the caller holds scope, like the REST routes do.
+func requireScope(ctx context.Context, scope auth.Scope) error {
+	if he := middleware.CheckScopes(ctx, scope); he != nil {
+		return &Error{Code: codeForbidden, Message: fmt.Sprintf("%v", he.Message)}
+	}
+	return nil
+}
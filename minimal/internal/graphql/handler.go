@@ -0,0 +1,63 @@
+// Package graphql serves notes and categories over GraphQL, next to the
+// REST API and backed by the same services. Note metadata and category
+// metadata schemas are not exposed.
+package graphql
+
+import (
+	_ "embed"
+	"net/http"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// maxDepth bounds how deeply queries may nest category and note fields.
+const maxDepth = 8
+
+// Handler executes GraphQL requests. It is the module serving /graphql.
+type Handler struct {
+	modules.Base
+	categories *category.Service
+	notes      *note.Service
+	relay      *relay.Handler
+}
+
+// NewHandler creates a new GraphQL handler.
+func NewHandler(categories *category.Service, notes *note.Service, logger *zap.Logger) *Handler {
+	resolver := &Resolver{categories: categories, notes: notes, logger: logger.Named("graphql.resolver")}
+	return &Handler{
+		categories: categories,
+		notes:      notes,
+		relay: &relay.Handler{
+			Schema: gographql.MustParseSchema(schema, resolver,
+				gographql.MaxDepth(maxDepth),
+			),
+		},
+	}
+}
+
+func (h *Handler) Name() string { return "graphql" }
+
+// Queries resolve categories and notes.
+func (h *Handler) Dependencies() []string { return []string{"category", "note"} }
+
+// RegisterRoutes registers the GraphQL endpoint on api, which must
+// authenticate requests.
+func (h *Handler) RegisterRoutes(api *echo.Group) {
+	api.POST("/graphql", echo.WrapHandler(h))
+}
+
+// ServeHTTP executes a request with loaders scoped to it, so lookups are
+// only shared within one query.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := withLoaders(r.Context(), newLoaders(h.categories, h.notes))
+	h.relay.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,114 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+)
+
+// CategoryLookup is the part of the category service the loaders use.
+type CategoryLookup interface {
+	GetByIDs(ctx context.Context, ids []category.ID) ([]category.Category, error)
+}
+
+// NoteLookup is the part of the note service the loaders use.
+type NoteLookup interface {
+	ListByCategories(ctx context.Context, categoryIDs []category.ID, limit int) ([]note.Note, error)
+}
+
+// loaders batch and cache lookups for the duration of one request.
+type loaders struct {
+	categories *dataloader.Loader[category.ID, category.Category]
+	// firstPages loads the first page of notes of categories.
+	firstPages *dataloader.Loader[firstPage, []note.Note]
+}
+
+func newLoaders(categories CategoryLookup, notes NoteLookup) *loaders {
+	return &loaders{
+		categories: dataloader.NewBatchedLoader(batchCategories(categories)),
+		firstPages: dataloader.NewBatchedLoader(batchFirstPages(notes)),
+	}
+}
+
+// batchCategories fetches the categories collected during one batch window
+// with a single repository call.
+func batchCategories(categories CategoryLookup) dataloader.BatchFunc[category.ID, category.Category] {
+	return func(ctx context.Context, ids []category.ID) []*dataloader.Result[category.Category] {
+		results := make([]*dataloader.Result[category.Category], len(ids))
+
+		found, err := categories.GetByIDs(ctx, ids)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[category.Category]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[category.ID]category.Category, len(found))
+		for _, c := range found {
+			byID[c.ID] = c
+		}
+		for i, id := range ids {
+			c, ok := byID[id]
+			if !ok {
+				results[i] = &dataloader.Result[category.Category]{Error: category.ErrNotFound}
+				continue
+			}
+			results[i] = &dataloader.Result[category.Category]{Data: c}
+		}
+		return results
+	}
+}
+
+// firstPage identifies the first limit notes of a category.
+type firstPage struct {
+	categoryID category.ID
+	limit      int
+}
+
+// batchFirstPages fetches the pages collected during one batch window with
+// a repository call per distinct page size, rather than one per category.
+func batchFirstPages(notes NoteLookup) dataloader.BatchFunc[firstPage, []note.Note] {
+	return func(ctx context.Context, pages []firstPage) []*dataloader.Result[[]note.Note] {
+		results := make([]*dataloader.Result[[]note.Note], len(pages))
+
+		byLimit := make(map[int][]category.ID)
+		for _, p := range pages {
+			byLimit[p.limit] = append(byLimit[p.limit], p.categoryID)
+		}
+		found := make(map[firstPage][]note.Note, len(pages))
+		for limit, ids := range byLimit {
+			listed, err := notes.ListByCategories(ctx, ids, limit)
+			if err != nil {
+				for i, p := range pages {
+					if p.limit == limit {
+						results[i] = &dataloader.Result[[]note.Note]{Error: err}
+					}
+				}
+				continue
+			}
+			for _, n := range listed {
+				p := firstPage{categoryID: n.CategoryID, limit: limit}
+				found[p] = append(found[p], n)
+			}
+		}
+		for i, p := range pages {
+			if results[i] == nil {
+				results[i] = &dataloader.Result[[]note.Note]{Data: found[p]}
+			}
+		}
+		return results
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
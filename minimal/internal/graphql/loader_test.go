@@ -0,0 +1,233 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/graphql"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// countingRepository counts the batched category lookups.
+type countingRepository struct {
+	*category.MemoryRepository
+	calls atomic.Int32
+}
+
+func (r *countingRepository) GetByIDs(ctx context.Context, ids []category.ID) ([]category.Category, error) {
+	r.calls.Add(1)
+	return r.MemoryRepository.GetByIDs(ctx, ids)
+}
+
+func TestCategoriesAreBatched(t *testing.T) {
+	logger := zap.NewNop()
+	auditor := audit.NewService(audit.NewMemoryRepository(), logger)
+	repo := &countingRepository{MemoryRepository: category.NewMemoryRepository()}
+	categories := category.NewService(repo, db.NopTransactor{}, auditor, logger)
+	notes := note.NewService(note.NewMemoryRepository(), categories, db.NopTransactor{}, auditor, logger)
+
+	workspaceID := uuid.New()
+	ctx := tenant.WithWorkspace(context.Background(), workspaceID)
+	ctx = auth.WithPrincipal(ctx, auth.Principal{WorkspaceID: workspaceID, Scopes: auth.AllScopes})
+
+	for i := range 5 {
+		c, err := categories.Create(ctx, category.CreateInput{Name: fmt.Sprintf("Category %d", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range 5 {
+			if _, err := notes.Create(ctx, note.CreateInput{CategoryID: c.ID, Title: fmt.Sprintf("Note %d", j)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	body := `{"query": "{ notes(first: 25) { edges { node { title category { name } } } } }"}`
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	graphql.NewHandler(categories, notes, logger).ServeHTTP(rec, req)
+
+	var res struct {
+		Data struct {
+			Notes struct {
+				Edges []struct {
+					Node struct {
+						Category struct{ Name string }
+					}
+				}
+			}
+		}
+		Errors []struct{ Message string }
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode response: %v\n%s", err, rec.Body)
+	}
+	if len(res.Errors) > 0 {
+		t.Fatalf("errors: %+v", res.Errors)
+	}
+	if got := len(res.Data.Notes.Edges); got != 25 {
+		t.Fatalf("got %d notes, want 25", got)
+	}
+	for _, e := range res.Data.Notes.Edges {
+		if e.Node.Category.Name == "" {
+			t.Fatal("note without category name")
+		}
+	}
+	if got := repo.calls.Load(); got != 1 {
+		t.Fatalf("GetByIDs called %d times, want 1", got)
+	}
+}
+
+// countingNotes counts the note queries.
+type countingNotes struct {
+	*note.MemoryRepository
+	calls atomic.Int32
+}
+
+func (r *countingNotes) List(ctx context.Context, f note.ListFilter) ([]note.Note, error) {
+	r.calls.Add(1)
+	return r.MemoryRepository.List(ctx, f)
+}
+
+func (r *countingNotes) ListByCategories(ctx context.Context, categoryIDs []category.ID, limit int) ([]note.Note, error) {
+	r.calls.Add(1)
+	return r.MemoryRepository.ListByCategories(ctx, categoryIDs, limit)
+}
+
+func TestCategoryNotesAreBatched(t *testing.T) {
+	logger := zap.NewNop()
+	auditor := audit.NewService(audit.NewMemoryRepository(), logger)
+	categories := category.NewService(category.NewMemoryRepository(), db.NopTransactor{}, auditor, logger)
+	repo := &countingNotes{MemoryRepository: note.NewMemoryRepository()}
+	notes := note.NewService(repo, categories, db.NopTransactor{}, auditor, logger)
+
+	workspaceID := uuid.New()
+	ctx := tenant.WithWorkspace(context.Background(), workspaceID)
+	ctx = auth.WithPrincipal(ctx, auth.Principal{WorkspaceID: workspaceID, Scopes: auth.AllScopes})
+
+	for i := range 5 {
+		c, err := categories.Create(ctx, category.CreateInput{Name: fmt.Sprintf("Category %d", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range i {
+			if _, err := notes.Create(ctx, note.CreateInput{CategoryID: c.ID, Title: fmt.Sprintf("Note %d", j)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	body := `{"query": "{ categories { name notes(first: 2) { edges { node { title } } pageInfo { hasNextPage } } } }"}`
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	graphql.NewHandler(categories, notes, logger).ServeHTTP(rec, req)
+
+	var res struct {
+		Data struct {
+			Categories []struct {
+				Name  string
+				Notes struct {
+					Edges    []struct{}
+					PageInfo struct{ HasNextPage bool }
+				}
+			}
+		}
+		Errors []struct{ Message string }
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode response: %v\n%s", err, rec.Body)
+	}
+	if len(res.Errors) > 0 {
+		t.Fatalf("errors: %+v", res.Errors)
+	}
+	if len(res.Data.Categories) != 5 {
+		t.Fatalf("got %d categories, want 5", len(res.Data.Categories))
+	}
+	for _, c := range res.Data.Categories {
+		// Category i holds i notes
+		var i int
+		fmt.Sscanf(c.Name, "Category %d", &i)
+		if want := min(i, 2); len(c.Notes.Edges) != want || c.Notes.PageInfo.HasNextPage != (i > 2) {
+			t.Errorf("%s: got %d notes, next page %t", c.Name, len(c.Notes.Edges), c.Notes.PageInfo.HasNextPage)
+		}
+	}
+	if got := repo.calls.Load(); got != 1 {
+		t.Fatalf("notes queried %d times, want 1", got)
+	}
+}
+
+// failingRepository fails the batched category lookups.
+type failingRepository struct {
+	*category.MemoryRepository
+}
+
+var errLookup = errors.New("connection reset")
+
+func (r *failingRepository) GetByIDs(context.Context, []category.ID) ([]category.Category, error) {
+	return nil, errLookup
+}
+
+func TestInternalErrorsAreLogged(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+	auditor := audit.NewService(audit.NewMemoryRepository(), zap.NewNop())
+	repo := &failingRepository{MemoryRepository: category.NewMemoryRepository()}
+	categories := category.NewService(repo, db.NopTransactor{}, auditor, zap.NewNop())
+	notes := note.NewService(note.NewMemoryRepository(), categories, db.NopTransactor{}, auditor, zap.NewNop())
+
+	workspaceID := uuid.New()
+	ctx := tenant.WithWorkspace(context.Background(), workspaceID)
+	ctx = auth.WithPrincipal(ctx, auth.Principal{WorkspaceID: workspaceID, Scopes: auth.AllScopes})
+
+	c, err := categories.Create(ctx, category.CreateInput{Name: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := notes.Create(ctx, note.CreateInput{CategoryID: c.ID, Title: "Note"}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"query": "{ notes(first: 1) { edges { node { category { name } } } } }"}`
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	graphql.NewHandler(categories, notes, logger).ServeHTTP(rec, req)
+
+	var res struct {
+		Errors []struct {
+			Message    string
+			Extensions struct{ Code string }
+		}
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode response: %v\n%s", err, rec.Body)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Extensions.Code != "INTERNAL" {
+		t.Fatalf("errors: %+v", res.Errors)
+	}
+	if strings.Contains(res.Errors[0].Message, errLookup.Error()) {
+		t.Fatalf("cause leaked: %q", res.Errors[0].Message)
+	}
+
+	entries := logs.FilterMessage("failed to get category").All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	if got := entries[0].ContextMap()["error"]; got != errLookup.Error() {
+		t.Fatalf("logged error %v, want %q", got, errLookup)
+	}
+}
@@ -0,0 +1,243 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+)
+
+// Resolver is the root resolver for queries and mutations.
+type Resolver struct {
+	categories *category.Service
+	notes      *note.Service
+	logger     *zap.Logger
+}
+
+func (r *Resolver) Categories(ctx context.Context) ([]*categoryResolver, error) {
+	if err := requireScope(ctx, auth.ScopeNotesRead); err != nil {
+		return nil, err
+	}
+	categories, err := r.categories.GetAll(ctx)
+	if err != nil {
+		return nil, r.toError(ctx, err, "failed to get categories")
+	}
+
+	res := make([]*categoryResolver, len(categories))
+	for i, c := range categories {
+		res[i] = r.category(c)
+	}
+	return res, nil
+}
+
+func (r *Resolver) Category(ctx context.Context, args struct{ ID gographql.ID }) (*categoryResolver, error) {
+	if err := requireScope(ctx, auth.ScopeNotesRead); err != nil {
+		return nil, err
+	}
+	id, err := category.ParseID(string(args.ID))
+	if err != nil {
+		return nil, badRequest("invalid category id")
+	}
+
+	c, err := r.categories.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, category.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, r.toError(ctx, err, "failed to get category")
+	}
+	return r.category(c), nil
+}
+
+func (r *Resolver) Notes(ctx context.Context, args struct {
+	CategoryID *gographql.ID
+	First      int32
+	After      *string
+}) (*noteConnectionResolver, error) {
+	if err := requireScope(ctx, auth.ScopeNotesRead); err != nil {
+		return nil, err
+	}
+	var categoryID category.ID
+	if args.CategoryID != nil {
+		id, err := category.ParseID(string(*args.CategoryID))
+		if err != nil {
+			return nil, badRequest("invalid categoryId")
+		}
+		categoryID = id
+	}
+	return r.listNotes(ctx, categoryID, args.First, args.After)
+}
+
+func (r *Resolver) Note(ctx context.Context, args struct{ ID gographql.ID }) (*noteResolver, error) {
+	if err := requireScope(ctx, auth.ScopeNotesRead); err != nil {
+		return nil, err
+	}
+	id, err := note.ParseID(string(args.ID))
+	if err != nil {
+		return nil, badRequest("invalid note id")
+	}
+
+	n, err := r.notes.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, note.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, r.toError(ctx, err, "failed to get note")
+	}
+	return r.note(n), nil
+}
+
+type categoryInput struct {
+	Name string
+}
+
+func (in categoryInput) validate() error {
+	if in.Name == "" {
+		return badRequest("name is required")
+	}
+	return nil
+}
+
+func (r *Resolver) CreateCategory(ctx context.Context, args struct{ Input categoryInput }) (*categoryResolver, error) {
+	if err := requireScope(ctx, auth.ScopeCategoriesAdmin); err != nil {
+		return nil, err
+	}
+	if err := args.Input.validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := r.categories.Create(ctx, category.CreateInput{Name: args.Input.Name})
+	if err != nil {
+		return nil, r.toError(ctx, err, "failed to create category")
+	}
+	return r.category(c), nil
+}
+
+func (r *Resolver) UpdateCategory(ctx context.Context, args struct {
+	ID    gographql.ID
+	Input categoryInput
+}) (*categoryResolver, error) {
+	if err := requireScope(ctx, auth.ScopeCategoriesAdmin); err != nil {
+		return nil, err
+	}
+	id, err := category.ParseID(string(args.ID))
+	if err != nil {
+		return nil, badRequest("invalid category id")
+	}
+	if err := args.Input.validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := r.categories.Update(ctx, category.UpdateInput{ID: id, Name: args.Input.Name})
+	if err != nil {
+		return nil, r.toError(ctx, err, "failed to update category")
+	}
+	return r.category(c), nil
+}
+
+func (r *Resolver) DeleteCategory(ctx context.Context, args struct{ ID gographql.ID }) (gographql.ID, error) {
+	if err := requireScope(ctx, auth.ScopeCategoriesAdmin); err != nil {
+		return "", err
+	}
+	id, err := category.ParseID(string(args.ID))
+	if err != nil {
+		return "", badRequest("invalid category id")
+	}
+
+	if err := r.categories.Delete(ctx, id); err != nil {
+		return "", r.toError(ctx, err, "failed to delete category")
+	}
+	return args.ID, nil
+}
+
+type noteInput struct {
+	CategoryID gographql.ID
+	Title      string
+	Content    *string
+}
+
+// parse validates the input and returns its category ID.
+func (in noteInput) parse() (category.ID, error) {
+	if in.Title == "" {
+		return category.ID{}, badRequest("title is required")
+	}
+	id, err := category.ParseID(string(in.CategoryID))
+	if err != nil {
+		return category.ID{}, badRequest("invalid categoryId")
+	}
+	return id, nil
+}
+
+func (in noteInput) content() string {
+	if in.Content == nil {
+		return ""
+	}
+	return *in.Content
+}
+
+func (r *Resolver) CreateNote(ctx context.Context, args struct{ Input noteInput }) (*noteResolver, error) {
+	if err := requireScope(ctx, auth.ScopeNotesWrite); err != nil {
+		return nil, err
+	}
+	categoryID, err := args.Input.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := r.notes.Create(ctx, note.CreateInput{
+		CategoryID: categoryID,
+		Title:      args.Input.Title,
+		Content:    args.Input.content(),
+	})
+	if err != nil {
+		return nil, r.toError(ctx, err, "failed to create note")
+	}
+	return r.note(n), nil
+}
+
+func (r *Resolver) UpdateNote(ctx context.Context, args struct {
+	ID    gographql.ID
+	Input noteInput
+}) (*noteResolver, error) {
+	if err := requireScope(ctx, auth.ScopeNotesWrite); err != nil {
+		return nil, err
+	}
+	id, err := note.ParseID(string(args.ID))
+	if err != nil {
+		return nil, badRequest("invalid note id")
+	}
+	categoryID, err := args.Input.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := r.notes.Update(ctx, note.UpdateInput{
+		ID:         id,
+		CategoryID: categoryID,
+		Title:      args.Input.Title,
+		Content:    args.Input.content(),
+	})
+	if err != nil {
+		return nil, r.toError(ctx, err, "failed to update note")
+	}
+	return r.note(n), nil
+}
+
+func (r *Resolver) DeleteNote(ctx context.Context, args struct{ ID gographql.ID }) (gographql.ID, error) {
+	if err := requireScope(ctx, auth.ScopeNotesWrite); err != nil {
+		return "", err
+	}
+	id, err := note.ParseID(string(args.ID))
+	if err != nil {
+		return "", badRequest("invalid note id")
+	}
+
+	if err := r.notes.Delete(ctx, id); err != nil {
+		return "", r.toError(ctx, err, "failed to delete note")
+	}
+	return args.ID, nil
+}
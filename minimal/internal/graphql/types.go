@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"context"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+)
+
+// maxPageSize bounds the first argument of note connections.
+const maxPageSize = 100
+
+type categoryResolver struct {
+	root     *Resolver
+	category category.Category
+}
+
+func (r *Resolver) category(c category.Category) *categoryResolver {
+	return &categoryResolver{root: r, category: c}
+}
+
+func (c *categoryResolver) ID() gographql.ID {
+	return gographql.ID(c.category.ID.String())
+}
+
+func (c *categoryResolver) Name() string {
+	return c.category.Name
+}
+
+// Notes resolves the first page through the request's loader, so the notes
+// of all categories in a response are fetched together. Later pages are
+// listed one category at a time.
+func (c *categoryResolver) Notes(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*noteConnectionResolver, error) {
+	if args.After != nil {
+		return c.root.listNotes(ctx, c.category.ID, args.First, args.After)
+	}
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := loadersFrom(ctx).firstPages.Load(ctx, firstPage{categoryID: c.category.ID, limit: limit + 1})()
+	if err != nil {
+		return nil, c.root.toError(ctx, err, "failed to get notes")
+	}
+	return c.root.connection(ctx, notes, limit), nil
+}
+
+func (c *categoryResolver) CreatedAt() gographql.Time {
+	return gographql.Time{Time: c.category.CreatedAt}
+}
+
+func (c *categoryResolver) UpdatedAt() gographql.Time {
+	return gographql.Time{Time: c.category.UpdatedAt}
+}
+
+type noteResolver struct {
+	root *Resolver
+	note note.Note
+}
+
+func (r *Resolver) note(n note.Note) *noteResolver {
+	return &noteResolver{root: r, note: n}
+}
+
+func (n *noteResolver) ID() gographql.ID {
+	return gographql.ID(n.note.ID.String())
+}
+
+func (n *noteResolver) Title() string {
+	return n.note.Title
+}
+
+func (n *noteResolver) Content() string {
+	return n.note.Content
+}
+
+// Category resolves through the request's loader, so the categories of all
+// notes in a response are fetched together.
+func (n *noteResolver) Category(ctx context.Context) (*categoryResolver, error) {
+	c, err := loadersFrom(ctx).categories.Load(ctx, n.note.CategoryID)()
+	if err != nil {
+		return nil, n.root.toError(ctx, err, "failed to get category")
+	}
+	return n.root.category(c), nil
+}
+
+func (n *noteResolver) CreatedAt() gographql.Time {
+	return gographql.Time{Time: n.note.CreatedAt}
+}
+
+func (n *noteResolver) UpdatedAt() gographql.Time {
+	return gographql.Time{Time: n.note.UpdatedAt}
+}
+
+type noteConnectionResolver struct {
+	edges       []*noteEdgeResolver
+	hasNextPage bool
+}
+
+func (c *noteConnectionResolver) Edges() []*noteEdgeResolver {
+	return c.edges
+}
+
+func (c *noteConnectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNextPage: c.hasNextPage}
+	if len(c.edges) > 0 {
+		cursor := c.edges[len(c.edges)-1].Cursor()
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+type noteEdgeResolver struct {
+	node *noteResolver
+}
+
+func (e *noteEdgeResolver) Cursor() string {
+	return note.CursorOf(e.node.note).String()
+}
+
+func (e *noteEdgeResolver) Node() *noteResolver {
+	return e.node
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	return p.endCursor
+}
+
+// listNotes resolves a page of notes, in categoryID unless it is zero.
+func (r *Resolver) listNotes(ctx context.Context, categoryID category.ID, first int32, after *string) (*noteConnectionResolver, error) {
+	limit, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := note.ListFilter{CategoryID: categoryID, Limit: limit + 1}
+	if after != nil {
+		cursor, err := note.ParseCursor(*after)
+		if err != nil {
+			return nil, badRequest("invalid after cursor")
+		}
+		filter.After = &cursor
+	}
+
+	notes, err := r.notes.List(ctx, filter)
+	if err != nil {
+		return nil, r.toError(ctx, err, "failed to get notes")
+	}
+	return r.connection(ctx, notes, limit), nil
+}
+
+// pageSize validates the first argument of a note connection.
+func pageSize(first int32) (int, error) {
+	limit := int(first)
+	if limit < 1 || limit > maxPageSize {
+		return 0, badRequest("first must be between 1 and %d", maxPageSize)
+	}
+	return limit, nil
+}
+
+// connection resolves a page of up to limit notes, listed with one more to
+// tell whether another page follows.
+func (r *Resolver) connection(ctx context.Context, notes []note.Note, limit int) *noteConnectionResolver {
+	conn := &noteConnectionResolver{hasNextPage: len(notes) > limit}
+	if conn.hasNextPage {
+		notes = notes[:limit]
+	}
+
+	// Queue the categories of the whole page at once rather than relying on
+	// the node resolvers reaching the loader within one batch window.
+	if gographql.HasSelectedField(ctx, "edges.node.category") {
+		ids := make([]category.ID, len(notes))
+		for i, n := range notes {
+			ids[i] = n.CategoryID
+		}
+		loadersFrom(ctx).categories.LoadMany(ctx, ids)
+	}
+
+	conn.edges = make([]*noteEdgeResolver, len(notes))
+	for i, n := range notes {
+		conn.edges[i] = &noteEdgeResolver{node: r.note(n)}
+	}
+	return conn
+}
@@ -0,0 +1,166 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+)
+
+type apiKeyBody struct {
+	ID     string   `json:"id"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	Key    string   `json:"key"`
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	h := apitest.New(t)
+
+	res := h.Post("/api/v1/keys", map[string]any{
+		"name":   "ci",
+		"scopes": []string{"notes:read"},
+	}).AssertStatus(http.StatusCreated)
+
+	var key apiKeyBody
+	res.Decode(&key)
+	if key.Key == "" || key.Prefix == "" {
+		t.Fatalf("issued key = %+v, want key and prefix", key)
+	}
+
+	h.Get("/api/v1/notes", apitest.WithToken(key.Key)).AssertStatus(http.StatusOK)
+	h.Post("/api/v1/notes", map[string]string{}, apitest.WithToken(key.Key)).
+		AssertError(http.StatusForbidden, "missing scope notes:write")
+}
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Post("/api/v1/keys", map[string]any{"scopes": []string{"notes:read"}}).
+		AssertError(http.StatusBadRequest, "name is required")
+	h.Post("/api/v1/keys", map[string]any{"name": "ci"}).
+		AssertError(http.StatusBadRequest, "scopes are required")
+	h.Post("/api/v1/keys", map[string]any{"name": "ci", "scopes": []string{"root"}}).
+		AssertError(http.StatusBadRequest, `unknown scope "root"`)
+	h.Post("/api/v1/keys", map[string]any{
+		"name":       "ci",
+		"scopes":     []string{"notes:read"},
+		"expires_at": time.Now().Add(-time.Hour),
+	}).AssertError(http.StatusBadRequest, "expires_at must be in the future")
+
+	reader := apitest.WithToken(h.Key(auth.ScopeNotesRead))
+	h.Post("/api/v1/keys", map[string]any{"name": "ci", "scopes": []string{"notes:write"}}, reader).
+		AssertError(http.StatusForbidden, "cannot grant scope notes:write")
+}
+
+func TestListAPIKeys(t *testing.T) {
+	h := apitest.New(t)
+	h.Key(auth.ScopeNotesRead)
+
+	h.Get("/api/v1/keys").
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	h := apitest.New(t)
+	old := h.Key(auth.ScopeNotesRead)
+
+	var keys []apiKeyBody
+	h.Get("/api/v1/keys", apitest.WithToken(old)).Decode(&keys)
+	var oldID string
+	for _, k := range keys {
+		if len(k.Scopes) == 1 {
+			oldID = k.ID
+		}
+	}
+
+	var rotated apiKeyBody
+	h.Post("/api/v1/keys/"+oldID+"/rotate", nil).
+		AssertStatus(http.StatusCreated).
+		Decode(&rotated)
+
+	h.Get("/api/v1/notes", apitest.WithToken(old)).
+		AssertError(http.StatusUnauthorized, "api key revoked")
+	h.Get("/api/v1/notes", apitest.WithToken(rotated.Key)).
+		AssertStatus(http.StatusOK)
+
+	h.Post("/api/v1/keys/"+oldID+"/rotate", nil).
+		AssertError(http.StatusNotFound, "api key not found")
+	h.Post("/api/v1/keys/"+missingID+"/rotate", nil).
+		AssertError(http.StatusNotFound, "api key not found")
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	h := apitest.New(t)
+
+	var key apiKeyBody
+	h.Post("/api/v1/keys", map[string]any{"name": "ci", "scopes": []string{"notes:read"}}).
+		Decode(&key)
+
+	h.Delete("/api/v1/keys/" + key.ID).AssertStatus(http.StatusNoContent)
+	h.Get("/api/v1/notes", apitest.WithToken(key.Key)).
+		AssertError(http.StatusUnauthorized, "api key revoked")
+	h.Delete("/api/v1/keys/"+key.ID).
+		AssertError(http.StatusNotFound, "api key not found")
+	h.Delete("/api/v1/keys/not-a-uuid").
+		AssertError(http.StatusBadRequest, "invalid api key id")
+}
+
+func TestRevokeAPIKeyOfOtherUser(t *testing.T) {
+	h := apitest.New(t)
+
+	other, err := h.Users.Create(context.Background(), user.CreateInput{Email: "other@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, _, err := h.Users.IssueKey(context.Background(), user.IssueKeyInput{
+		UserID: other.ID,
+		Name:   "other",
+		Scopes: auth.AllScopes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Delete("/api/v1/keys/"+k.ID.String()).
+		AssertError(http.StatusNotFound, "api key not found")
+}
+
+func TestManageAPIKeyWithMoreScopes(t *testing.T) {
+	h := apitest.New(t)
+
+	var broad apiKeyBody
+	h.Post("/api/v1/keys", map[string]any{"name": "admin", "scopes": []string{"notes:read", "categories:admin"}}).
+		Decode(&broad)
+	reader := apitest.WithToken(h.Key(auth.ScopeNotesRead))
+
+	h.Post("/api/v1/keys/"+broad.ID+"/rotate", nil, reader).
+		AssertError(http.StatusForbidden, "cannot rotate api key: api key has a scope the caller lacks: categories:admin")
+	h.Delete("/api/v1/keys/"+broad.ID, reader).
+		AssertError(http.StatusForbidden, "cannot revoke api key: api key has a scope the caller lacks: categories:admin")
+	h.Get("/api/v1/notes", apitest.WithToken(broad.Key)).
+		AssertStatus(http.StatusOK)
+}
+
+func TestExpiredAPIKey(t *testing.T) {
+	h := apitest.New(t)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	_, raw, err := h.Users.IssueKey(context.Background(), user.IssueKeyInput{
+		UserID:    h.User.ID,
+		Name:      "expired",
+		Scopes:    auth.AllScopes,
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Get("/api/v1/notes", apitest.WithToken(raw)).
+		AssertError(http.StatusUnauthorized, "api key expired")
+}
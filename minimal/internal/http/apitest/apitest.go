@@ -0,0 +1,365 @@
+// Package apitest boots the HTTP server against in-memory repositories and
+// provides helpers for end-to-end tests of the API: a request builder,
+// response assertions, fixture builders and golden-file JSON comparison.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/app"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	apphttp "github.com/piotmni/go-mini-templates/minimal/internal/http"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/mirror"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/batch"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/idempotency"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/savedsearch"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/transfer"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// Harness is a running API server backed by in-memory repositories.
+type Harness struct {
+	t      *testing.T
+	server *httptest.Server
+	idp    *identityProvider
+	seq    *int
+
+	Categories  *category.Service
+	Notes       *note.Service
+	Users       *user.Service
+	Workspaces  *workspace.Service
+	Idempotency *idempotency.Service
+
+	// User owns Workspace, Token and every key issued through Key.
+	User user.User
+	// Workspace is the workspace Token acts in and fixtures are created in.
+	Workspace workspace.Workspace
+	// Token is an API key of User holding every scope. Requests are
+	// authenticated with it by default.
+	Token string
+}
+
+// New starts a test server and registers its shutdown with t.Cleanup.
+func New(t *testing.T, opts ...Option) *Harness {
+	t.Helper()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	logger := zap.NewNop()
+	if o.logger != nil {
+		logger = o.logger
+	}
+
+	auditService := audit.NewService(audit.NewMemoryRepository(), logger)
+	categoryService := category.NewService(category.NewMemoryRepository(), db.NopTransactor{}, auditService, logger)
+	noteService := note.NewService(note.NewMemoryRepository(), categoryService, db.NopTransactor{}, auditService, logger)
+	userService := user.NewService(user.NewMemoryRepository(), db.NopTransactor{}, logger)
+	workspaceService := workspace.NewService(workspace.NewMemoryRepository(), db.NopTransactor{}, logger)
+	idempotencyService := idempotency.NewService(idempotency.NewMemoryRepository(), idempotency.DefaultTTL, logger)
+	transferService := transfer.NewService(categoryService, noteService, db.NopTransactor{}, logger)
+
+	var gitMirror *mirror.Mirror
+	if o.mirrorDir != "" {
+		gitMirror = newMirror(t, o.mirrorDir, auditService, transferService, userService, logger)
+	}
+
+	var idp *identityProvider
+	authenticator := middleware.Authenticator(userService)
+	if o.jwt {
+		idp = newIdentityProvider(t)
+		authenticator = middleware.Chain(idp.authenticator(t, userService), userService)
+	}
+
+	// Client certificates map to the harness user, which exists only once
+	// the server runs
+	var certAuth *middleware.CertificateAuthenticator
+	if len(o.clientCerts) > 0 {
+		authenticator = middleware.Chain(middleware.AuthenticatorFunc(func(ctx context.Context, token string) (auth.Principal, error) {
+			return certAuth.Authenticate(ctx, token)
+		}), authenticator)
+	}
+
+	authenticator = workspace.NewAuthenticator(authenticator, workspaceService)
+	// The mirror registers its jobs when it is created
+	registry, err := app.NewModules(app.Services{
+		Audit:         auditService,
+		Categories:    categoryService,
+		Notes:         noteService,
+		Idempotency:   idempotencyService,
+		Transfer:      transferService,
+		Batch:         batch.NewService(categoryService, noteService, db.NopTransactor{}, batch.DefaultMaxOperations, logger),
+		Users:         userService,
+		Workspaces:    workspaceService,
+		SavedSearches: savedsearch.NewService(savedsearch.NewMemoryRepository(), noteService, db.NopTransactor{}, auditService, logger),
+		Mirror:        gitMirror,
+		Authenticator: authenticator,
+		Logger:        logger,
+	}, o.modules...)
+	if err != nil {
+		t.Fatalf("register modules: %v", err)
+	}
+
+	srv := apphttp.NewServer(o.server, logger, authenticator, idempotencyService, registry)
+
+	// Serve h2c like the real server so gRPC clients can connect
+	ts := httptest.NewUnstartedServer(srv.Handler())
+	ts.Config.Protocols = h2c()
+	if o.server.TLS.Enabled() {
+		tlsConfig, err := apphttp.NewTLSConfig(t.Context(), o.server.TLS, logger)
+		if err != nil {
+			t.Fatalf("load TLS config: %v", err)
+		}
+		ts.TLS = tlsConfig
+		ts.StartTLS()
+	} else {
+		ts.Start()
+	}
+	t.Cleanup(ts.Close)
+
+	u, err := userService.Create(context.Background(), user.CreateInput{Email: "test@example.com", Name: "Test"})
+	if err != nil {
+		t.Fatalf("create test user: %v", err)
+	}
+	w, err := workspaceService.Create(context.Background(), workspace.CreateInput{Name: "Test", OwnerID: u.ID})
+	if err != nil {
+		t.Fatalf("create test workspace: %v", err)
+	}
+
+	h := &Harness{
+		t:           t,
+		server:      ts,
+		idp:         idp,
+		seq:         new(int),
+		Categories:  categoryService,
+		Notes:       noteService,
+		Users:       userService,
+		Workspaces:  workspaceService,
+		Idempotency: idempotencyService,
+		User:        u,
+		Workspace:   w,
+	}
+	h.Token = h.Key(auth.AllScopes...)
+
+	if len(o.clientCerts) > 0 {
+		principals := make(map[string]middleware.CertificatePrincipal, len(o.clientCerts))
+		for subject, scopes := range o.clientCerts {
+			principals[subject] = middleware.CertificatePrincipal{
+				Email:       u.Email,
+				WorkspaceID: w.ID.String(),
+				Scopes:      scopes,
+			}
+		}
+		if certAuth, err = middleware.NewCertificateAuthenticator(principals, userService); err != nil {
+			t.Fatalf("create certificate authenticator: %v", err)
+		}
+	}
+
+	return h
+}
+
+// Key issues an API key for User in Workspace with the given scopes.
+func (h *Harness) Key(scopes ...auth.Scope) string {
+	h.t.Helper()
+	_, raw, err := h.Users.IssueKey(context.Background(), user.IssueKeyInput{
+		UserID:      h.User.ID,
+		WorkspaceID: h.Workspace.ID,
+		Name:        "test",
+		Scopes:      scopes,
+	})
+	if err != nil {
+		h.t.Fatalf("issue api key: %v", err)
+	}
+	return raw
+}
+
+// Member creates a user with the given email and adds it to Workspace with
+// role. It returns a harness sharing h's server that acts as the new user,
+// authenticated with a key holding every scope.
+func (h *Harness) Member(email string, role workspace.Role) *Harness {
+	h.t.Helper()
+	ctx := context.Background()
+	u, err := h.Users.Create(ctx, user.CreateInput{Email: email})
+	if err != nil {
+		h.t.Fatalf("create member user: %v", err)
+	}
+	_, err = h.Workspaces.AddMember(ctx, h.User.ID, workspace.MemberInput{
+		WorkspaceID: h.Workspace.ID,
+		UserID:      u.ID,
+		Role:        role,
+	})
+	if err != nil {
+		h.t.Fatalf("add workspace member: %v", err)
+	}
+
+	c := *h
+	c.User = u
+	c.Token = c.Key(auth.AllScopes...)
+	return &c
+}
+
+// InNewWorkspace creates another workspace owned by User and returns a
+// harness sharing h's server whose Token and fixtures act in it.
+func (h *Harness) InNewWorkspace(name string) *Harness {
+	h.t.Helper()
+	w, err := h.Workspaces.Create(context.Background(), workspace.CreateInput{Name: name, OwnerID: h.User.ID})
+	if err != nil {
+		h.t.Fatalf("create workspace: %v", err)
+	}
+
+	c := *h
+	c.Workspace = w
+	c.Token = c.Key(auth.AllScopes...)
+	return &c
+}
+
+// Context returns a context scoped to Workspace for calling services directly.
+func (h *Harness) Context() context.Context {
+	return tenant.WithWorkspace(context.Background(), h.Workspace.ID)
+}
+
+// For returns a harness sharing h's server and data that reports failures
+// to t and names golden files after it. Use it inside subtests.
+func (h *Harness) For(t *testing.T) *Harness {
+	c := *h
+	c.t = t
+	return &c
+}
+
+// URL returns the base URL of the test server.
+func (h *Harness) URL() string {
+	return h.server.URL
+}
+
+// H2CClient returns a client speaking HTTP/2 without TLS to the test
+// server, as gRPC and streaming RPCs require.
+func (h *Harness) H2CClient() *http.Client {
+	var p http.Protocols
+	p.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: &http.Transport{Protocols: &p}}
+}
+
+// h2c returns the protocols of a server that allows HTTP/2 without TLS.
+func h2c() *http.Protocols {
+	var p http.Protocols
+	p.SetHTTP1(true)
+	p.SetUnencryptedHTTP2(true)
+	return &p
+}
+
+// RequestOption modifies a request before it is sent.
+type RequestOption func(*http.Request)
+
+// WithToken authenticates the request with token instead of the harness Token.
+func WithToken(token string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithHeader sets a request header.
+func WithHeader(key, value string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// WithoutAuth removes the Authorization header.
+func WithoutAuth() RequestOption {
+	return func(r *http.Request) {
+		r.Header.Del("Authorization")
+	}
+}
+
+// Do sends a request to path and returns the recorded response.
+// A body that is a string or []byte is sent as is, anything else is
+// encoded as JSON. Requests are authenticated with h.Token unless an
+// option says otherwise.
+func (h *Harness) Do(method, path string, body any, opts ...RequestOption) *Response {
+	h.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	case []byte:
+		reader = bytes.NewBuffer(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			h.t.Fatalf("encode request body: %v", err)
+		}
+		reader = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequest(method, h.server.URL+path, reader)
+	if err != nil {
+		h.t.Fatalf("build request: %v", err)
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+h.Token)
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	res, err := h.server.Client().Do(req)
+	if err != nil {
+		h.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		h.t.Fatalf("read response body: %v", err)
+	}
+
+	return &Response{
+		t:      h.t,
+		Status: res.StatusCode,
+		Header: res.Header,
+		Body:   data,
+	}
+}
+
+// Get sends a GET request.
+func (h *Harness) Get(path string, opts ...RequestOption) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodGet, path, nil, opts...)
+}
+
+// Post sends a POST request.
+func (h *Harness) Post(path string, body any, opts ...RequestOption) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodPost, path, body, opts...)
+}
+
+// Put sends a PUT request.
+func (h *Harness) Put(path string, body any, opts ...RequestOption) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodPut, path, body, opts...)
+}
+
+// Delete sends a DELETE request.
+func (h *Harness) Delete(path string, opts ...RequestOption) *Response {
+	h.t.Helper()
+	return h.Do(http.MethodDelete, path, nil, opts...)
+}
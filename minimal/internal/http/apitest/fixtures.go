@@ -0,0 +1,94 @@
+package apitest
+
+import (
+	"fmt"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+)
+
+// CategoryBuilder creates a category fixture.
+type CategoryBuilder struct {
+	h     *Harness
+	input category.CreateInput
+}
+
+// Category starts building a category with a unique generated name.
+func (h *Harness) Category() *CategoryBuilder {
+	*h.seq++
+	return &CategoryBuilder{
+		h:     h,
+		input: category.CreateInput{Name: fmt.Sprintf("Category %d", *h.seq)},
+	}
+}
+
+// Named sets the category name.
+func (b *CategoryBuilder) Named(name string) *CategoryBuilder {
+	b.input.Name = name
+	return b
+}
+
+// Create stores the category through the category service.
+func (b *CategoryBuilder) Create() category.Category {
+	b.h.t.Helper()
+	c, err := b.h.Categories.Create(b.h.Context(), b.input)
+	if err != nil {
+		b.h.t.Fatalf("create category fixture: %v", err)
+	}
+	return c
+}
+
+// NoteBuilder creates a note fixture.
+type NoteBuilder struct {
+	h        *Harness
+	input    note.CreateInput
+	category *CategoryBuilder
+}
+
+// Note starts building a note titled "My Note". Unless InCategory is called,
+// a fresh category is created for it.
+func (h *Harness) Note() *NoteBuilder {
+	return &NoteBuilder{
+		h:        h,
+		input:    note.CreateInput{Title: "My Note", Content: "Some content."},
+		category: h.Category(),
+	}
+}
+
+// InCategory places the note in an existing category.
+func (b *NoteBuilder) InCategory(c category.Category) *NoteBuilder {
+	b.input.CategoryID = c.ID
+	b.category = nil
+	return b
+}
+
+// Titled sets the note title.
+func (b *NoteBuilder) Titled(title string) *NoteBuilder {
+	b.input.Title = title
+	return b
+}
+
+// WithContent sets the note content.
+func (b *NoteBuilder) WithContent(content string) *NoteBuilder {
+	b.input.Content = content
+	return b
+}
+
+// WithMetadata sets the note metadata.
+func (b *NoteBuilder) WithMetadata(metadata map[string]any) *NoteBuilder {
+	b.input.Metadata = metadata
+	return b
+}
+
+// Create stores the note, and its category if needed, through the services.
+func (b *NoteBuilder) Create() note.Note {
+	b.h.t.Helper()
+	if b.category != nil {
+		b.input.CategoryID = b.category.Create().ID
+	}
+	n, err := b.h.Notes.Create(b.h.Context(), b.input)
+	if err != nil {
+		b.h.t.Fatalf("create note fixture: %v", err)
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+var (
+	uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	timePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+	keyPattern  = regexp.MustCompile(`mk_[0-9a-f]{12}(_[A-Za-z0-9_-]+)?`)
+)
+
+// AssertGolden compares the JSON body with testdata/<test name>.golden.json.
+//
+// Values that differ between runs are normalised before comparing: UUIDs
+// become <uuid-N>, numbered in order of first appearance, RFC 3339
+// timestamps become <time> and API keys or their prefixes become <api-key>.
+// Run the tests with -update to rewrite the golden files from the current
+// responses.
+func (r *Response) AssertGolden() *Response {
+	r.t.Helper()
+
+	got := normalize(r.Body)
+	if got == nil {
+		r.t.Fatalf("response body is not JSON: %s", r.Body)
+	}
+
+	name := strings.ReplaceAll(r.t.Name(), "/", "_")
+	path := filepath.Join("testdata", name+".golden.json")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("create testdata dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			r.t.Fatalf("write golden file: %v", err)
+		}
+		return r
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		r.t.Fatalf("response does not match %s\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+	return r
+}
+
+// normalize replaces run-specific values and re-indents the JSON document.
+// It returns nil if data is not valid JSON.
+func normalize(data []byte) []byte {
+	ids := make(map[string]string)
+	data = uuidPattern.ReplaceAllFunc(data, func(id []byte) []byte {
+		placeholder, ok := ids[string(id)]
+		if !ok {
+			placeholder = "<uuid-" + strconv.Itoa(len(ids)+1) + ">"
+			ids[string(id)] = placeholder
+		}
+		return []byte(placeholder)
+	})
+	data = timePattern.ReplaceAll(data, []byte("<time>"))
+	data = keyPattern.ReplaceAll(data, []byte("<api-key>"))
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil
+	}
+	out.WriteByte('\n')
+	return out.Bytes()
+}
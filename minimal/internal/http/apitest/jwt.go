@@ -0,0 +1,148 @@
+package apitest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+)
+
+// JWT issuer and audience used by harnesses created with WithJWT.
+const (
+	JWTIssuer   = "https://auth.example.com"
+	JWTAudience = "notes-api"
+)
+
+// WithJWT makes the server accept JWTs signed by a test identity provider
+// alongside API keys. Use Harness.JWT to issue them.
+func WithJWT() Option {
+	return func(o *options) {
+		o.jwt = true
+	}
+}
+
+// identityProvider signs JWTs with keys it publishes on a JWKS endpoint,
+// and with an HMAC secret it does not publish.
+type identityProvider struct {
+	server *httptest.Server
+	keys   map[jwa.SignatureAlgorithm]jwk.Key
+}
+
+func newIdentityProvider(t *testing.T) *identityProvider {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	secret := make([]byte, 32)
+	rand.Read(secret)
+
+	idp := &identityProvider{keys: make(map[jwa.SignatureAlgorithm]jwk.Key)}
+	published := jwk.NewSet()
+	for alg, raw := range map[jwa.SignatureAlgorithm]any{
+		jwa.RS256(): rsaKey,
+		jwa.EdDSA(): edKey,
+		jwa.HS256(): secret,
+	} {
+		key, err := jwk.Import(raw)
+		if err != nil {
+			t.Fatalf("import %s key: %v", alg, err)
+		}
+		key.Set(jwk.KeyIDKey, alg.String())
+		key.Set(jwk.AlgorithmKey, alg)
+		idp.keys[alg] = key
+
+		if alg == jwa.HS256() {
+			continue
+		}
+		public, err := key.PublicKey()
+		if err != nil {
+			t.Fatalf("public %s key: %v", alg, err)
+		}
+		published.AddKey(public)
+	}
+
+	idp.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(published)
+	}))
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// authenticator returns a JWT authenticator trusting the provider.
+func (idp *identityProvider) authenticator(t *testing.T, users middleware.UserLookup) *middleware.JWTAuthenticator {
+	t.Helper()
+	a, err := middleware.NewJWTAuthenticator(t.Context(), middleware.JWTConfig{
+		JWKSURL:  idp.server.URL,
+		Issuer:   JWTIssuer,
+		Audience: JWTAudience,
+		Leeway:   time.Minute,
+	}, users)
+	if err != nil {
+		t.Fatalf("create jwt authenticator: %v", err)
+	}
+	return a
+}
+
+// JWT issues an RS256 token for User in Workspace valid for an hour.
+// Entries in claims override the defaults; a nil value removes the claim.
+func (h *Harness) JWT(claims map[string]any) string {
+	h.t.Helper()
+	return h.SignedJWT(jwa.RS256(), claims)
+}
+
+// SignedJWT is like JWT but signs with alg, one of RS256, EdDSA or HS256.
+// HS256 tokens use a secret the server does not trust.
+func (h *Harness) SignedJWT(alg jwa.SignatureAlgorithm, claims map[string]any) string {
+	h.t.Helper()
+	if h.idp == nil {
+		h.t.Fatal("harness was created without WithJWT")
+	}
+
+	now := time.Now()
+	values := map[string]any{
+		jwt.IssuerKey:     JWTIssuer,
+		jwt.AudienceKey:   JWTAudience,
+		jwt.SubjectKey:    h.User.ID.String(),
+		jwt.IssuedAtKey:   now,
+		jwt.ExpirationKey: now.Add(time.Hour),
+		"email":           h.User.Email,
+		"workspace_id":    h.Workspace.ID.String(),
+	}
+	for k, v := range claims {
+		if v == nil {
+			delete(values, k)
+			continue
+		}
+		values[k] = v
+	}
+
+	token := jwt.New()
+	for k, v := range values {
+		if err := token.Set(k, v); err != nil {
+			h.t.Fatalf("set claim %s: %v", k, err)
+		}
+	}
+
+	signed, err := jwt.Sign(token, jwt.WithKey(alg, h.idp.keys[alg]))
+	if err != nil {
+		h.t.Fatalf("sign jwt: %v", err)
+	}
+	return string(signed)
+}
@@ -0,0 +1,48 @@
+package apitest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/mirror"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/transfer"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"go.uber.org/zap"
+)
+
+// WithMirror mirrors notes into a git repository in dir. Sync jobs run one
+// at a time on an in-memory queue, so every change gets its own commit.
+func WithMirror(dir string) Option {
+	return func(o *options) {
+		o.mirrorDir = dir
+	}
+}
+
+func newMirror(t *testing.T, dir string, auditor *audit.Service, transfer *transfer.Service, users *user.Service, logger *zap.Logger) *mirror.Mirror {
+	t.Helper()
+
+	queue := jobs.NewQueue(jobs.NewMemoryRepository(), db.NopTransactor{}, jobs.Config{
+		Workers:      1,
+		PollInterval: time.Millisecond,
+		MaxAttempts:  1,
+	}, logger)
+	m, err := mirror.New(context.Background(), mirror.Config{Dir: dir}, transfer, users, queue, logger)
+	if err != nil {
+		t.Fatalf("create git mirror: %v", err)
+	}
+	m.Register(auditor)
+
+	queue.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := queue.Stop(ctx); err != nil {
+			t.Errorf("stop job workers: %v", err)
+		}
+	})
+	return m
+}
@@ -0,0 +1,60 @@
+package apitest
+
+import (
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	apphttp "github.com/piotmni/go-mini-templates/minimal/internal/http"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"go.uber.org/zap"
+)
+
+// Option configures a harness created by New.
+type Option func(*options)
+
+type options struct {
+	jwt       bool
+	mirrorDir string
+	server    apphttp.ServerConfig
+	// clientCerts maps client certificate subjects to scopes.
+	clientCerts map[string][]string
+	modules     []modules.Module
+	logger      *zap.Logger
+}
+
+// WithServerConfig configures the server like the real one, e.g. with
+// limits, CORS or TLS. The address is ignored. With TLS, requests must be
+// sent with a client trusting the configured certificate.
+func WithServerConfig(cfg apphttp.ServerConfig) Option {
+	return func(o *options) {
+		o.server = cfg
+	}
+}
+
+// WithClientCertificate authenticates requests without a bearer credential
+// that present a verified client certificate with subject as User in
+// Workspace, holding scopes, or every scope if none are given.
+func WithClientCertificate(subject string, scopes ...auth.Scope) Option {
+	return func(o *options) {
+		if o.clientCerts == nil {
+			o.clientCerts = make(map[string][]string)
+		}
+		var names []string
+		for _, s := range scopes {
+			names = append(names, string(s))
+		}
+		o.clientCerts[subject] = names
+	}
+}
+
+// WithModule registers m alongside the built-in modules.
+func WithModule(m modules.Module) Option {
+	return func(o *options) {
+		o.modules = append(o.modules, m)
+	}
+}
+
+// WithLogger logs through l instead of discarding logs.
+func WithLogger(l *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
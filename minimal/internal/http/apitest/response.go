@@ -0,0 +1,58 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Response is a fully read HTTP response.
+type Response struct {
+	t      *testing.T
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// AssertStatus fails the test if the response status is not want.
+func (r *Response) AssertStatus(want int) *Response {
+	r.t.Helper()
+	if r.Status != want {
+		r.t.Fatalf("status = %d, want %d; body: %s", r.Status, want, r.Body)
+	}
+	return r
+}
+
+// AssertError fails the test unless the response has the given status and
+// an error body carrying message.
+func (r *Response) AssertError(status int, message string) *Response {
+	r.t.Helper()
+	r.AssertStatus(status)
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	r.Decode(&body)
+	if body.Message != message {
+		r.t.Fatalf("error message = %q, want %q", body.Message, message)
+	}
+	return r
+}
+
+// AssertHeader fails the test unless the response header key is want. An
+// empty want asserts that the header is absent.
+func (r *Response) AssertHeader(key, want string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); got != want {
+		r.t.Fatalf("header %s = %q, want %q", key, got, want)
+	}
+	return r
+}
+
+// Decode unmarshals the JSON body into v.
+func (r *Response) Decode(v any) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("decode response body: %v; body: %s", err, r.Body)
+	}
+}
@@ -0,0 +1,136 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+)
+
+type auditEventBody struct {
+	ActorID    *string `json:"actor_id"`
+	RequestID  string  `json:"request_id"`
+	ClientIP   string  `json:"client_ip"`
+	EntityType string  `json:"entity_type"`
+	EntityID   string  `json:"entity_id"`
+	Action     string  `json:"action"`
+	Changes    map[string]struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	} `json:"changes"`
+}
+
+func TestAuditLog(t *testing.T) {
+	h := apitest.New(t)
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	res := h.Post("/api/v1/categories", map[string]string{"name": "Work"}, apitest.WithHeader("X-Request-ID", "req-1")).
+		AssertStatus(http.StatusCreated)
+	res.Decode(&created)
+	h.Put("/api/v1/categories/"+created.ID, map[string]string{"name": "Office"}).AssertStatus(http.StatusOK)
+	h.Delete("/api/v1/categories/" + created.ID).AssertStatus(http.StatusNoContent)
+
+	var events []auditEventBody
+	h.Get("/api/v1/audit?entity_id=" + created.ID).AssertStatus(http.StatusOK).Decode(&events)
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+
+	deleted, updated, inserted := events[0], events[1], events[2]
+	if inserted.Action != "create" || updated.Action != "update" || deleted.Action != "delete" {
+		t.Fatalf("actions = %s, %s, %s; want create, update, delete", inserted.Action, updated.Action, deleted.Action)
+	}
+	if inserted.RequestID != "req-1" || inserted.ClientIP != "127.0.0.1" {
+		t.Errorf("origin = %q from %q, want req-1 from 127.0.0.1", inserted.RequestID, inserted.ClientIP)
+	}
+	if inserted.ActorID == nil || *inserted.ActorID != h.User.ID.String() {
+		t.Errorf("actor_id = %v, want %s", inserted.ActorID, h.User.ID)
+	}
+	if c := updated.Changes["name"]; c.Before != "Work" || c.After != "Office" {
+		t.Errorf("update changes = %+v, want name Work -> Office", updated.Changes)
+	}
+	if c := deleted.Changes["name"]; c.Before != "Office" || c.After != nil {
+		t.Errorf("delete changes = %+v, want name Office -> null", deleted.Changes)
+	}
+}
+
+func TestAuditLogCategoryDelete(t *testing.T) {
+	h := apitest.New(t)
+
+	c := h.Category().Create()
+	var n struct {
+		ID string `json:"id"`
+	}
+	h.Post("/api/v1/notes", map[string]string{"category_id": c.ID.String(), "title": "Plan"}).
+		AssertStatus(http.StatusCreated).Decode(&n)
+	h.Delete("/api/v1/categories/" + c.ID.String()).AssertStatus(http.StatusNoContent)
+
+	var events []auditEventBody
+	h.Get("/api/v1/audit?entity_id=" + n.ID).AssertStatus(http.StatusOK).Decode(&events)
+	if len(events) != 2 || events[0].Action != "delete" {
+		t.Fatalf("events of note = %+v, want create and delete", events)
+	}
+	if c := events[0].Changes["title"]; c.Before != "Plan" || c.After != nil {
+		t.Errorf("delete changes = %+v, want title Plan -> null", events[0].Changes)
+	}
+	h.Get("/api/v1/notes/"+n.ID).AssertError(http.StatusNotFound, "note not found")
+}
+
+func TestAuditLogFilters(t *testing.T) {
+	h := apitest.New(t)
+	member := h.Member("member@example.com", workspace.RoleMember)
+
+	start := time.Now().Add(-time.Second)
+	c := h.Category().Create()
+	var n struct {
+		ID string `json:"id"`
+	}
+	member.Post("/api/v1/notes", map[string]string{"category_id": c.ID.String(), "title": "Draft"}).
+		AssertStatus(http.StatusCreated).Decode(&n)
+	h.Put("/api/v1/notes/"+n.ID, map[string]string{"category_id": c.ID.String(), "title": "Renamed"}).
+		AssertStatus(http.StatusOK)
+
+	count := func(t *testing.T, query url.Values) int {
+		var events []auditEventBody
+		h.For(t).Get("/api/v1/audit?" + query.Encode()).AssertStatus(http.StatusOK).Decode(&events)
+		return len(events)
+	}
+
+	for name, tc := range map[string]struct {
+		query url.Values
+		want  int
+	}{
+		"all":         {url.Values{}, 3},
+		"entity type": {url.Values{"entity_type": {"note"}}, 2},
+		"actor":       {url.Values{"actor_id": {member.User.ID.String()}}, 1},
+		"since":       {url.Values{"since": {start.Format(time.RFC3339)}}, 3},
+		"until":       {url.Values{"until": {start.Format(time.RFC3339)}}, 0},
+		"limit":       {url.Values{"limit": {"1"}}, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := count(t, tc.query); got != tc.want {
+				t.Errorf("got %d events, want %d", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		h := h.For(t)
+		h.Get("/api/v1/audit?entity_id=nope").AssertError(http.StatusBadRequest, "invalid entity_id")
+		h.Get("/api/v1/audit?since=yesterday").AssertError(http.StatusBadRequest, "invalid since")
+		h.Get("/api/v1/audit?limit=0").AssertError(http.StatusBadRequest, "invalid limit")
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		h := h.For(t)
+		h.Get("/api/v1/audit", apitest.WithToken(h.Key(auth.ScopeNotesRead))).
+			AssertError(http.StatusForbidden, "missing scope audit:read")
+		member.For(t).Get("/api/v1/audit").AssertError(http.StatusForbidden, "missing scope audit:read")
+	})
+}
@@ -0,0 +1,187 @@
+package http_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+type batchOp = map[string]string
+
+type batchResultBody struct {
+	Ref      string        `json:"ref"`
+	Type     string        `json:"type"`
+	Action   string        `json:"action"`
+	ID       string        `json:"id"`
+	Category *categoryBody `json:"category"`
+	Note     *struct {
+		noteBody
+		CategoryID string `json:"category_id"`
+	} `json:"note"`
+}
+
+type batchErrorBody struct {
+	Message string `json:"message"`
+	Index   int    `json:"index"`
+}
+
+func postBatch(h *apitest.Harness, ops ...batchOp) *apitest.Response {
+	return h.Post("/api/v1/batch", map[string][]batchOp{"operations": ops})
+}
+
+func TestBatch(t *testing.T) {
+	h := apitest.New(t)
+	old := h.Category().Named("Old").Create()
+	moved := h.Note().InCategory(old).Titled("Moved").Create()
+	gone := h.Note().InCategory(old).Titled("Gone").Create()
+
+	var res struct {
+		Results []batchResultBody `json:"results"`
+	}
+	postBatch(h,
+		batchOp{"ref": "work", "type": "category", "action": "create", "name": "Work"},
+		batchOp{"ref": "plan", "type": "note", "action": "create", "category_id": "$work", "title": "Plan"},
+		batchOp{"type": "note", "action": "update", "id": "$plan", "category_id": "$work", "title": "Plan v2", "content": "draft"},
+		batchOp{"type": "note", "action": "update", "id": moved.ID.String(), "category_id": "$work", "title": "Moved"},
+		batchOp{"type": "note", "action": "delete", "id": gone.ID.String()},
+		batchOp{"type": "category", "action": "update", "id": old.ID.String(), "name": "Archive"},
+	).AssertStatus(http.StatusOK).Decode(&res)
+
+	if len(res.Results) != 6 {
+		t.Fatalf("got %d results, want 6", len(res.Results))
+	}
+	work, plan := res.Results[0], res.Results[2]
+	if work.Category == nil || work.Category.Name != "Work" || work.Ref != "work" {
+		t.Errorf("result 0 = %+v, want category Work", work)
+	}
+	if plan.ID != res.Results[1].ID || plan.Note == nil || plan.Note.Title != "Plan v2" || plan.Note.CategoryID != work.ID {
+		t.Errorf("result 2 = %+v, want the note created by op 1 updated in Work", plan)
+	}
+	if r := res.Results[4]; r.Action != "delete" || r.ID != gone.ID.String() || r.Note != nil {
+		t.Errorf("result 4 = %+v, want delete of %s", r, gone.ID)
+	}
+
+	var got struct {
+		CategoryID string `json:"category_id"`
+	}
+	h.Get("/api/v1/notes/" + moved.ID.String()).AssertStatus(http.StatusOK).Decode(&got)
+	if got.CategoryID != work.ID {
+		t.Errorf("moved note is in %s, want %s", got.CategoryID, work.ID)
+	}
+	h.Get("/api/v1/notes/"+gone.ID.String()).AssertError(http.StatusNotFound, "note not found")
+}
+
+func TestBatchValidation(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+
+	tests := []struct {
+		name    string
+		ops     []batchOp
+		status  int
+		message string
+		index   int
+	}{
+		{
+			name:    "unknown ref",
+			ops:     []batchOp{{"type": "category", "action": "create", "name": "A"}, {"type": "note", "action": "create", "category_id": "$a", "title": "T"}},
+			status:  http.StatusBadRequest,
+			message: `category_id refers to unknown ref "a"`,
+			index:   1,
+		},
+		{
+			name:    "ref to wrong type",
+			ops:     []batchOp{{"ref": "n", "type": "note", "action": "create", "category_id": c.ID.String(), "title": "T"}, {"type": "note", "action": "create", "category_id": "$n", "title": "T"}},
+			status:  http.StatusBadRequest,
+			message: "category_id refers to a note",
+			index:   1,
+		},
+		{
+			name:    "duplicate ref",
+			ops:     []batchOp{{"ref": "a", "type": "category", "action": "create", "name": "A"}, {"ref": "a", "type": "category", "action": "create", "name": "B"}},
+			status:  http.StatusBadRequest,
+			message: `ref "a" is already defined`,
+			index:   1,
+		},
+		{
+			name:    "unknown type",
+			ops:     []batchOp{{"type": "tag", "action": "create"}},
+			status:  http.StatusBadRequest,
+			message: "type must be category or note",
+		},
+		{
+			name:    "unknown action",
+			ops:     []batchOp{{"type": "note", "action": "move"}},
+			status:  http.StatusBadRequest,
+			message: "action must be create, update or delete",
+		},
+		{
+			name:    "missing title",
+			ops:     []batchOp{{"type": "note", "action": "create", "category_id": c.ID.String()}},
+			status:  http.StatusBadRequest,
+			message: "title is required",
+		},
+		{
+			name:    "invalid id",
+			ops:     []batchOp{{"type": "note", "action": "delete", "id": "nope"}},
+			status:  http.StatusBadRequest,
+			message: "invalid id",
+		},
+		{
+			name:    "not found",
+			ops:     []batchOp{{"type": "category", "action": "create", "name": "Later"}, {"type": "note", "action": "delete", "id": missingID}},
+			status:  http.StatusNotFound,
+			message: "note not found",
+			index:   1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got batchErrorBody
+			postBatch(h.For(t), tc.ops...).AssertStatus(tc.status).Decode(&got)
+			if got.Message != tc.message || got.Index != tc.index {
+				t.Errorf("error = %q at %d, want %q at %d", got.Message, got.Index, tc.message, tc.index)
+			}
+		})
+	}
+
+	// Validation runs before anything is written
+	var categories []categoryBody
+	h.Get("/api/v1/categories").AssertStatus(http.StatusOK).Decode(&categories)
+	for _, c := range categories {
+		if c.Name == "A" {
+			t.Errorf("invalid batch created category %s", c.Name)
+		}
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		postBatch(h.For(t)).AssertError(http.StatusBadRequest, "operations are required")
+	})
+
+	t.Run("too many", func(t *testing.T) {
+		ops := make([]batchOp, 101)
+		for i := range ops {
+			ops[i] = batchOp{"type": "category", "action": "create", "name": strings.Repeat("x", i+1)}
+		}
+		postBatch(h.For(t), ops...).
+			AssertError(http.StatusBadRequest, "too many operations: at most 100 are allowed")
+	})
+}
+
+func TestBatchScopes(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	writer := h.Key(auth.ScopeNotesRead, auth.ScopeNotesWrite)
+
+	var got batchErrorBody
+	h.Post("/api/v1/batch", map[string][]batchOp{"operations": {
+		{"type": "note", "action": "create", "category_id": c.ID.String(), "title": "Allowed"},
+		{"type": "category", "action": "create", "name": "Denied"},
+	}}, apitest.WithToken(writer)).AssertStatus(http.StatusForbidden).Decode(&got)
+	if got.Message != "missing scope categories:admin" || got.Index != 1 {
+		t.Errorf("error = %q at %d, want missing scope categories:admin at 1", got.Message, got.Index)
+	}
+}
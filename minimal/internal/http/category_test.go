@@ -0,0 +1,107 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+const missingID = "00000000-0000-0000-0000-000000000000"
+
+func TestCreateCategory(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Post("/api/v1/categories", map[string]string{"name": "Work"}).
+		AssertStatus(http.StatusCreated).
+		AssertGolden()
+}
+
+func TestCreateCategoryValidation(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Post("/api/v1/categories", map[string]string{}).
+		AssertError(http.StatusBadRequest, "name is required")
+	h.Post("/api/v1/categories", `{"name":`).
+		AssertError(http.StatusBadRequest, "invalid request body")
+}
+
+func TestCreateCategoryConflict(t *testing.T) {
+	h := apitest.New(t)
+	h.Category().Named("Work").Create()
+
+	h.Post("/api/v1/categories", map[string]string{"name": "Work"}).
+		AssertError(http.StatusConflict, "category already exists")
+}
+
+func TestListCategories(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Get("/api/v1/categories").
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+
+	h.Category().Named("Work").Create()
+	h.Category().Named("Personal").Create()
+
+	t.Run("populated", func(t *testing.T) {
+		h.For(t).Get("/api/v1/categories").
+			AssertStatus(http.StatusOK).
+			AssertGolden()
+	})
+}
+
+func TestGetCategory(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Named("Work").Create()
+
+	h.Get("/api/v1/categories/" + c.ID.String()).
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+	h.Get("/api/v1/categories/"+missingID).
+		AssertError(http.StatusNotFound, "category not found")
+	h.Get("/api/v1/categories/not-a-uuid").
+		AssertError(http.StatusBadRequest, "invalid category id")
+}
+
+func TestUpdateCategory(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Named("Work").Create()
+
+	h.Put("/api/v1/categories/"+c.ID.String(), map[string]string{"name": "Work Updated"}).
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+
+	h.Get("/api/v1/categories/" + c.ID.String()).
+		AssertStatus(http.StatusOK)
+}
+
+func TestUpdateCategoryErrors(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Named("Work").Create()
+	h.Category().Named("Personal").Create()
+	path := "/api/v1/categories/" + c.ID.String()
+
+	h.Put(path, map[string]string{}).
+		AssertError(http.StatusBadRequest, "name is required")
+	h.Put(path, `not json`).
+		AssertError(http.StatusBadRequest, "invalid request body")
+	h.Put("/api/v1/categories/not-a-uuid", map[string]string{"name": "x"}).
+		AssertError(http.StatusBadRequest, "invalid category id")
+	h.Put("/api/v1/categories/"+missingID, map[string]string{"name": "x"}).
+		AssertError(http.StatusNotFound, "category not found")
+	h.Put(path, map[string]string{"name": "Personal"}).
+		AssertError(http.StatusConflict, "category already exists")
+}
+
+func TestDeleteCategory(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	path := "/api/v1/categories/" + c.ID.String()
+
+	h.Delete(path).AssertStatus(http.StatusNoContent)
+	h.Get(path).AssertError(http.StatusNotFound, "category not found")
+	h.Delete(path).AssertError(http.StatusNotFound, "category not found")
+	h.Delete("/api/v1/categories/not-a-uuid").
+		AssertError(http.StatusBadRequest, "invalid category id")
+}
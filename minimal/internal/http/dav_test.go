@@ -0,0 +1,154 @@
+package http_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+const propfind = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><allprop/></propfind>`
+
+// davList returns the body of a depth 1 PROPFIND of path.
+func davList(t *testing.T, h *apitest.Harness, path string) string {
+	t.Helper()
+	res := h.For(t).Do("PROPFIND", path, propfind, apitest.WithHeader("Depth", "1"))
+	res.AssertStatus(http.StatusMultiStatus)
+	return string(res.Body)
+}
+
+func TestDAVListsCategoriesAndNotes(t *testing.T) {
+	h := apitest.New(t)
+	work := h.Category().Named("Work").Create()
+	h.Category().Named("Home/Garden").Create()
+	h.Note().InCategory(work).Titled("Standup").WithContent("Daily sync.").Create()
+	h.Note().InCategory(work).Titled("Standup").WithContent("Again.").Create()
+
+	root := davList(t, h, "/dav/")
+	for _, want := range []string{"/dav/Work/", "/dav/Home%E2%88%95Garden/"} {
+		if !strings.Contains(root, want) {
+			t.Errorf("root listing misses %s:\n%s", want, root)
+		}
+	}
+
+	dir := davList(t, h, "/dav/Work/")
+	if !strings.Contains(dir, "/dav/Work/Standup.md") {
+		t.Errorf("category listing misses Standup.md:\n%s", dir)
+	}
+	if strings.Count(dir, "/dav/Work/Standup") != 2 {
+		t.Errorf("colliding titles not listed twice:\n%s", dir)
+	}
+
+	h.Get("/dav/Work/Standup.md").AssertStatus(http.StatusOK)
+	if got := string(h.Get("/dav/Work/Standup.md").Body); got != "Daily sync." {
+		t.Errorf("content = %q, want oldest note's content", got)
+	}
+	h.Get("/dav/Work/Missing.md").AssertStatus(http.StatusNotFound)
+	h.Do("PROPFIND", "/dav/Nowhere/", propfind).AssertStatus(http.StatusNotFound)
+}
+
+func TestDAVWritesNotes(t *testing.T) {
+	h := apitest.New(t)
+	h.Category().Named("Work").Create()
+	h.Category().Named("Archive").Create()
+
+	h.Put("/dav/Work/Plan.md", "# Plan\n").AssertStatus(http.StatusCreated)
+	var notes []noteBody
+	h.Get("/api/v1/notes").AssertStatus(http.StatusOK).Decode(&notes)
+	if len(notes) != 1 || notes[0].Title != "Plan" || notes[0].Content != "# Plan\n" {
+		t.Fatalf("notes after PUT = %+v", notes)
+	}
+
+	h.Put("/dav/Work/Plan.md", "# Plan\n\nShip it.\n").AssertStatus(http.StatusCreated)
+	if got := string(h.Get("/dav/Work/Plan.md").Body); got != "# Plan\n\nShip it.\n" {
+		t.Errorf("content after update = %q", got)
+	}
+
+	// webdav reports files that cannot be created as not found, and files in
+	// missing directories as conflicts.
+	h.Put("/dav/Work/plan.txt", "text").AssertStatus(http.StatusNotFound)
+	h.Put("/dav/Nowhere/Plan.md", "text").AssertStatus(http.StatusConflict)
+
+	h.Do("MOVE", "/dav/Work/Plan.md", nil,
+		apitest.WithHeader("Destination", h.URL()+"/dav/Archive/Old%20plan.md"),
+	).AssertStatus(http.StatusCreated)
+	h.Get("/dav/Work/Plan.md").AssertStatus(http.StatusNotFound)
+	h.Get("/dav/Archive/Old%20plan.md").AssertStatus(http.StatusOK)
+
+	notes = nil
+	h.Get("/api/v1/notes").AssertStatus(http.StatusOK).Decode(&notes)
+	if len(notes) != 1 || notes[0].Title != "Old plan" || notes[0].ID == "" {
+		t.Fatalf("notes after MOVE = %+v", notes)
+	}
+
+	h.Delete("/dav/Archive/Old%20plan.md").AssertStatus(http.StatusNoContent)
+	h.Get("/api/v1/notes/" + notes[0].ID).AssertStatus(http.StatusNotFound)
+}
+
+func TestDAVManagesCategories(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Do("MKCOL", "/dav/Work", nil).AssertStatus(http.StatusCreated)
+	h.Do("MKCOL", "/dav/Work", nil).AssertStatus(http.StatusMethodNotAllowed)
+	h.Do("MOVE", "/dav/Work/", nil,
+		apitest.WithHeader("Destination", h.URL()+"/dav/Office/"),
+	).AssertStatus(http.StatusCreated)
+	davList(t, h, "/dav/Office/")
+	h.Delete("/dav/Office/").AssertStatus(http.StatusNoContent)
+	h.Do("PROPFIND", "/dav/Office/", propfind).AssertStatus(http.StatusNotFound)
+
+	writer := h.Key(auth.ScopeNotesRead, auth.ScopeNotesWrite)
+	h.Do("MKCOL", "/dav/Home", nil, apitest.WithToken(writer)).AssertStatus(http.StatusForbidden)
+}
+
+func TestDAVLocks(t *testing.T) {
+	h := apitest.New(t)
+	work := h.Category().Named("Work").Create()
+	h.Note().InCategory(work).Titled("Plan").Create()
+
+	lock := `<?xml version="1.0" encoding="utf-8"?>
+<lockinfo xmlns="DAV:"><lockscope><exclusive/></lockscope><locktype><write/></locktype></lockinfo>`
+	res := h.Do("LOCK", "/dav/Work/Plan.md", lock).AssertStatus(http.StatusOK)
+	token := res.Header.Get("Lock-Token")
+	if token == "" {
+		t.Fatal("LOCK returned no Lock-Token")
+	}
+
+	h.Put("/dav/Work/Plan.md", "changed").AssertStatus(http.StatusLocked)
+	h.Put("/dav/Work/Plan.md", "changed",
+		apitest.WithHeader("If", "("+token+")"),
+	).AssertStatus(http.StatusCreated)
+
+	h.Do("UNLOCK", "/dav/Work/Plan.md", nil, apitest.WithHeader("Lock-Token", token)).
+		AssertStatus(http.StatusNoContent)
+	h.Put("/dav/Work/Plan.md", "again").AssertStatus(http.StatusCreated)
+}
+
+func TestDAVRequiresScopes(t *testing.T) {
+	h := apitest.New(t)
+	work := h.Category().Named("Work").Create()
+	h.Note().InCategory(work).Titled("Plan").Create()
+
+	h.Do("PROPFIND", "/dav/", propfind, apitest.WithoutAuth()).AssertStatus(http.StatusUnauthorized)
+
+	reader := h.Key(auth.ScopeNotesRead)
+	h.Get("/dav/Work/Plan.md", apitest.WithToken(reader)).AssertStatus(http.StatusOK)
+	h.Put("/dav/Work/Plan.md", "changed", apitest.WithToken(reader)).AssertStatus(http.StatusForbidden)
+	h.Delete("/dav/Work/Plan.md", apitest.WithToken(reader)).AssertStatus(http.StatusForbidden)
+}
+
+func TestDAVIsolatesWorkspaces(t *testing.T) {
+	h := apitest.New(t)
+	work := h.Category().Named("Work").Create()
+	h.Note().InCategory(work).Titled("Plan").Create()
+
+	other := h.InNewWorkspace("Other")
+	other.Category().Named("Work").Create()
+
+	if strings.Contains(davList(t, other, "/dav/Work/"), "Plan.md") {
+		t.Error("note of another workspace listed")
+	}
+	other.Get("/dav/Work/Plan.md").AssertStatus(http.StatusNotFound)
+}
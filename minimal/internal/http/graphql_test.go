@@ -0,0 +1,244 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+type graphqlError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// graphql runs query as h and decodes its data into data, failing the
+// test on errors.
+func graphql(t *testing.T, h *apitest.Harness, query string, variables map[string]any, data any, opts ...apitest.RequestOption) {
+	t.Helper()
+	errs := graphqlErrors(t, h, query, variables, data, opts...)
+	if len(errs) > 0 {
+		t.Fatalf("graphql errors: %+v", errs)
+	}
+}
+
+// graphqlErrors runs query as h, decodes its data into data and returns
+// the reported errors.
+func graphqlErrors(t *testing.T, h *apitest.Harness, query string, variables map[string]any, data any, opts ...apitest.RequestOption) []graphqlError {
+	t.Helper()
+	var res struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}
+	h.Post("/api/v1/graphql", map[string]any{"query": query, "variables": variables}, opts...).
+		AssertStatus(http.StatusOK).Decode(&res)
+	if data != nil && len(res.Data) > 0 {
+		if err := json.Unmarshal(res.Data, data); err != nil {
+			t.Fatalf("decode graphql data: %v", err)
+		}
+	}
+	return res.Errors
+}
+
+func assertGraphQLError(t *testing.T, errs []graphqlError, code, message string) {
+	t.Helper()
+	if len(errs) != 1 || errs[0].Extensions.Code != code || errs[0].Message != message {
+		t.Fatalf("errors = %+v, want %s %q", errs, code, message)
+	}
+}
+
+type noteConnection struct {
+	Edges []struct {
+		Cursor string `json:"cursor"`
+		Node   struct {
+			ID       string `json:"id"`
+			Title    string `json:"title"`
+			Category struct {
+				Name string `json:"name"`
+			} `json:"category"`
+		} `json:"node"`
+	} `json:"edges"`
+	PageInfo struct {
+		HasNextPage bool    `json:"hasNextPage"`
+		EndCursor   *string `json:"endCursor"`
+	} `json:"pageInfo"`
+}
+
+const notesQuery = `query($first: Int, $after: String) {
+  notes(first: $first, after: $after) {
+    edges { cursor node { id title category { name } } }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+func TestGraphQLPagination(t *testing.T) {
+	h := apitest.New(t)
+	work := h.Category().Named("Work").Create()
+	for _, title := range []string{"One", "Two", "Three"} {
+		h.Note().InCategory(work).Titled(title).Create()
+	}
+
+	seen := map[string]bool{}
+	var after any
+	for page := 0; ; page++ {
+		var data struct {
+			Notes noteConnection `json:"notes"`
+		}
+		graphql(t, h, notesQuery, map[string]any{"first": 2, "after": after}, &data)
+
+		for _, e := range data.Notes.Edges {
+			if e.Node.Category.Name != "Work" {
+				t.Fatalf("category = %q, want Work", e.Node.Category.Name)
+			}
+			if seen[e.Node.Title] {
+				t.Fatalf("note %q listed twice", e.Node.Title)
+			}
+			seen[e.Node.Title] = true
+		}
+		if !data.Notes.PageInfo.HasNextPage {
+			if page != 1 || len(data.Notes.Edges) != 1 {
+				t.Fatalf("last page %d has %d notes, want page 1 with 1", page, len(data.Notes.Edges))
+			}
+			break
+		}
+		after = *data.Notes.PageInfo.EndCursor
+	}
+	if len(seen) != 3 {
+		t.Fatalf("saw %d notes, want 3", len(seen))
+	}
+}
+
+func TestGraphQLRelationships(t *testing.T) {
+	h := apitest.New(t)
+	work := h.Category().Named("Work").Create()
+	h.Note().InCategory(work).Titled("Plan").Create()
+	h.Category().Named("Empty").Create()
+
+	var data struct {
+		Categories []struct {
+			Name  string         `json:"name"`
+			Notes noteConnection `json:"notes"`
+		} `json:"categories"`
+	}
+	graphql(t, h, `{ categories { name notes { edges { node { title category { name } } } } } }`, nil, &data)
+
+	got := map[string]int{}
+	for _, c := range data.Categories {
+		got[c.Name] = len(c.Notes.Edges)
+		for _, e := range c.Notes.Edges {
+			if e.Node.Category.Name != c.Name {
+				t.Fatalf("note %q in %q resolves category %q", e.Node.Title, c.Name, e.Node.Category.Name)
+			}
+		}
+	}
+	if len(got) != 2 || got["Work"] != 1 || got["Empty"] != 0 {
+		t.Fatalf("notes per category = %v, want Work:1 Empty:0", got)
+	}
+}
+
+func TestGraphQLMutations(t *testing.T) {
+	h := apitest.New(t)
+
+	var created struct {
+		CreateCategory struct {
+			ID string `json:"id"`
+		} `json:"createCategory"`
+	}
+	graphql(t, h, `mutation { createCategory(input: {name: "Work"}) { id } }`, nil, &created)
+	categoryID := created.CreateCategory.ID
+
+	var note struct {
+		CreateNote struct {
+			ID       string `json:"id"`
+			Category struct {
+				Name string `json:"name"`
+			} `json:"category"`
+		} `json:"createNote"`
+	}
+	graphql(t, h, `mutation($c: ID!) { createNote(input: {categoryId: $c, title: "Plan"}) { id category { name } } }`,
+		map[string]any{"c": categoryID}, &note)
+	if note.CreateNote.Category.Name != "Work" {
+		t.Fatalf("category = %q, want Work", note.CreateNote.Category.Name)
+	}
+	noteID := note.CreateNote.ID
+
+	var updated struct {
+		UpdateNote struct {
+			Title   string `json:"title"`
+			Content string `json:"content"`
+		} `json:"updateNote"`
+	}
+	graphql(t, h, `mutation($id: ID!, $c: ID!) { updateNote(id: $id, input: {categoryId: $c, title: "Ship", content: "Now"}) { title content } }`,
+		map[string]any{"id": noteID, "c": categoryID}, &updated)
+	if updated.UpdateNote.Title != "Ship" || updated.UpdateNote.Content != "Now" {
+		t.Fatalf("updated note = %+v", updated.UpdateNote)
+	}
+
+	graphql(t, h, `mutation($id: ID!) { deleteNote(id: $id) }`, map[string]any{"id": noteID}, nil)
+
+	var fetched struct {
+		Note *struct{} `json:"note"`
+	}
+	graphql(t, h, `query($id: ID!) { note(id: $id) { id } }`, map[string]any{"id": noteID}, &fetched)
+	if fetched.Note != nil {
+		t.Fatal("deleted note is still returned")
+	}
+
+	var audited []map[string]any
+	h.Get("/api/v1/audit?entity_id=" + noteID).AssertStatus(http.StatusOK).Decode(&audited)
+	if len(audited) != 3 {
+		t.Fatalf("got %d audit events for the note, want 3", len(audited))
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	h := apitest.New(t)
+	readOnly := h.Key(auth.ScopeNotesRead)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h.For(t).Post("/api/v1/graphql", map[string]string{"query": "{ categories { id } }"}, apitest.WithoutAuth()).
+			AssertError(http.StatusUnauthorized, "missing authorization header")
+	})
+
+	t.Run("missing scope", func(t *testing.T) {
+		errs := graphqlErrors(t, h.For(t), `mutation { createCategory(input: {name: "Work"}) { id } }`, nil, nil,
+			apitest.WithToken(readOnly))
+		assertGraphQLError(t, errs, "FORBIDDEN", "missing scope categories:admin")
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		errs := graphqlErrors(t, h.For(t), `{ note(id: "nope") { id } }`, nil, nil)
+		assertGraphQLError(t, errs, "BAD_REQUEST", "invalid note id")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		c := h.Category().Create()
+		errs := graphqlErrors(t, h.For(t), `mutation($id: ID!, $c: ID!) { updateNote(id: $id, input: {categoryId: $c, title: "X"}) { id } }`,
+			map[string]any{"id": missingID, "c": c.ID.String()}, nil)
+		assertGraphQLError(t, errs, "NOT_FOUND", "note not found")
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		n := h.Note().Create()
+		errs := graphqlErrors(t, h.For(t), `mutation($id: ID!, $c: ID!) { updateNote(id: $id, input: {categoryId: $c, title: "X"}) { id } }`,
+			map[string]any{"id": n.ID.String(), "c": createSchemaCategory(h.For(t))}, nil)
+		if len(errs) != 1 || errs[0].Extensions.Code != "BAD_REQUEST" || !strings.HasPrefix(errs[0].Message, "invalid metadata: ") {
+			t.Fatalf("errors = %+v, want BAD_REQUEST invalid metadata", errs)
+		}
+	})
+
+	t.Run("page size", func(t *testing.T) {
+		errs := graphqlErrors(t, h.For(t), notesQuery, map[string]any{"first": 0}, nil)
+		assertGraphQLError(t, errs, "BAD_REQUEST", "first must be between 1 and 100")
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		errs := graphqlErrors(t, h.For(t), notesQuery, map[string]any{"first": 1, "after": "!"}, nil)
+		assertGraphQLError(t, errs, "BAD_REQUEST", "invalid after cursor")
+	})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+)
+
+// APIKeyHandler handles HTTP requests for the caller's API keys.
+type APIKeyHandler struct {
+	service    *user.Service
+	workspaces *workspace.Service
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(service *user.Service, workspaces *workspace.Service) *APIKeyHandler {
+	return &APIKeyHandler{service: service, workspaces: workspaces}
+}
+
+// apiKeyResponse is the JSON response for an API key. Key is only set when
+// the key has just been issued.
+type apiKeyResponse struct {
+	ID          string       `json:"id"`
+	WorkspaceID string       `json:"workspace_id"`
+	Name        string       `json:"name"`
+	Prefix      string       `json:"prefix"`
+	Scopes      []auth.Scope `json:"scopes"`
+	ExpiresAt   *time.Time   `json:"expires_at"`
+	LastUsedAt  *time.Time   `json:"last_used_at"`
+	RevokedAt   *time.Time   `json:"revoked_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Key         string       `json:"key,omitempty"`
+}
+
+func toAPIKeyResponse(k user.APIKey) apiKeyResponse {
+	scopes := k.Scopes
+	if scopes == nil {
+		scopes = []auth.Scope{}
+	}
+	return apiKeyResponse{
+		ID:          k.ID.String(),
+		WorkspaceID: k.WorkspaceID.String(),
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		Scopes:      scopes,
+		ExpiresAt:   k.ExpiresAt,
+		LastUsedAt:  k.LastUsedAt,
+		RevokedAt:   k.RevokedAt,
+		CreatedAt:   k.CreatedAt,
+	}
+}
+
+type createAPIKeyRequest struct {
+	WorkspaceID string     `json:"workspace_id"`
+	Name        string     `json:"name" validate:"required"`
+	Scopes      []string   `json:"scopes" validate:"required"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// Create handles POST /keys
+func (h *APIKeyHandler) Create(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	var req createAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+	}
+	if len(req.Scopes) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "scopes are required")
+	}
+
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			return echo.NewHTTPError(http.StatusForbidden, "cannot grant scope "+string(scope))
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expires_at must be in the future")
+	}
+
+	workspaceID := principal.WorkspaceID
+	if req.WorkspaceID != "" {
+		workspaceID, err = workspace.ParseID(req.WorkspaceID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid workspace_id")
+		}
+		if _, err := h.workspaces.GetMember(c.Request().Context(), workspaceID, principal.UserID); err != nil {
+			if errors.Is(err, workspace.ErrMemberNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "workspace not found")
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to create api key").SetInternal(err)
+		}
+	}
+
+	k, raw, err := h.service.IssueKey(c.Request().Context(), user.IssueKeyInput{
+		UserID:      principal.UserID,
+		WorkspaceID: workspaceID,
+		Name:        req.Name,
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create api key").SetInternal(err)
+	}
+
+	res := toAPIKeyResponse(k)
+	res.Key = raw
+	return c.JSON(http.StatusCreated, res)
+}
+
+// GetAll handles GET /keys
+func (h *APIKeyHandler) GetAll(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	keys, err := h.service.ListKeys(c.Request().Context(), principal.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get api keys").SetInternal(err)
+	}
+
+	result := make([]apiKeyResponse, len(keys))
+	for i, k := range keys {
+		result[i] = toAPIKeyResponse(k)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Rotate handles POST /keys/:id/rotate
+func (h *APIKeyHandler) Rotate(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	id, err := user.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid api key id")
+	}
+
+	k, raw, err := h.service.RotateKey(c.Request().Context(), principal, id)
+	if err != nil {
+		if errors.Is(err, user.ErrKeyNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+		}
+		if errors.Is(err, user.ErrScopeNotHeld) {
+			return echo.NewHTTPError(http.StatusForbidden, "cannot rotate api key: "+err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to rotate api key").SetInternal(err)
+	}
+
+	res := toAPIKeyResponse(k)
+	res.Key = raw
+	return c.JSON(http.StatusCreated, res)
+}
+
+// Delete handles DELETE /keys/:id
+func (h *APIKeyHandler) Delete(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	id, err := user.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid api key id")
+	}
+
+	if err := h.service.RevokeKey(c.Request().Context(), principal, id); err != nil {
+		if errors.Is(err, user.ErrKeyNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+		}
+		if errors.Is(err, user.ErrScopeNotHeld) {
+			return echo.NewHTTPError(http.StatusForbidden, "cannot revoke api key: "+err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke api key").SetInternal(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RegisterRoutes registers API key routes. Every authenticated caller may
+// manage their own keys, as far as they hold the scopes of the key: a key
+// can neither grant, rotate nor revoke scopes it lacks.
+func (h *APIKeyHandler) RegisterRoutes(g *echo.Group) {
+	g.POST("", h.Create)
+	g.GET("", h.GetAll)
+	g.POST("/:id/rotate", h.Rotate)
+	g.DELETE("/:id", h.Delete)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+)
+
+// AuditHandler handles HTTP requests for the audit log.
+type AuditHandler struct {
+	service *audit.Service
+}
+
+// NewAuditHandler creates a new AuditHandler.
+func NewAuditHandler(service *audit.Service) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// auditEventResponse is the JSON response for an audit event.
+type auditEventResponse struct {
+	ID         string        `json:"id"`
+	ActorID    *string       `json:"actor_id"`
+	RequestID  string        `json:"request_id"`
+	ClientIP   string        `json:"client_ip"`
+	EntityType string        `json:"entity_type"`
+	EntityID   string        `json:"entity_id"`
+	Action     string        `json:"action"`
+	Changes    audit.Changes `json:"changes"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+func toAuditEventResponse(e audit.Event) auditEventResponse {
+	res := auditEventResponse{
+		ID:         e.ID.String(),
+		RequestID:  e.RequestID,
+		ClientIP:   e.ClientIP,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID.String(),
+		Action:     string(e.Action),
+		Changes:    e.Changes,
+		CreatedAt:  e.CreatedAt,
+	}
+	if e.ActorID != (uuid.UUID{}) {
+		actorID := e.ActorID.String()
+		res.ActorID = &actorID
+	}
+	return res
+}
+
+// GetAll handles GET /audit
+// Query parameters: entity_type, entity_id, actor_id, since and until
+// (RFC 3339) and limit.
+func (h *AuditHandler) GetAll(c echo.Context) error {
+	var (
+		f   audit.Filter
+		err error
+	)
+
+	f.EntityType = c.QueryParam("entity_type")
+	if v := c.QueryParam("entity_id"); v != "" {
+		if f.EntityID, err = uuid.Parse(v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid entity_id")
+		}
+	}
+	if v := c.QueryParam("actor_id"); v != "" {
+		if f.ActorID, err = uuid.Parse(v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid actor_id")
+		}
+	}
+	if v := c.QueryParam("since"); v != "" {
+		if f.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid since")
+		}
+	}
+	if v := c.QueryParam("until"); v != "" {
+		if f.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid until")
+		}
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+
+	events, err := h.service.List(c.Request().Context(), f)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get audit events").SetInternal(err)
+	}
+
+	result := make([]auditEventResponse, len(events))
+	for i, e := range events {
+		result[i] = toAuditEventResponse(e)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// RegisterRoutes registers audit routes.
+func (h *AuditHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("", h.GetAll, middleware.RequireScope(auth.ScopeAuditRead))
+}
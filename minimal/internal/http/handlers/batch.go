@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/batch"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+)
+
+// BatchHandler handles HTTP requests for batches of operations.
+type BatchHandler struct {
+	service *batch.Service
+}
+
+// NewBatchHandler creates a new BatchHandler.
+func NewBatchHandler(service *batch.Service) *BatchHandler {
+	return &BatchHandler{service: service}
+}
+
+type batchOperationRequest struct {
+	Ref            string          `json:"ref"`
+	Type           string          `json:"type"`
+	Action         string          `json:"action"`
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	MetadataSchema json.RawMessage `json:"metadata_schema"`
+	CategoryID     string          `json:"category_id"`
+	Title          string          `json:"title"`
+	Content        string          `json:"content"`
+	Metadata       map[string]any  `json:"metadata"`
+}
+
+type batchRequest struct {
+	Operations []batchOperationRequest `json:"operations"`
+}
+
+// batchResultResponse is the JSON response for one operation of a batch.
+type batchResultResponse struct {
+	Ref      string            `json:"ref,omitempty"`
+	Type     string            `json:"type"`
+	Action   string            `json:"action"`
+	ID       string            `json:"id"`
+	Category *categoryResponse `json:"category,omitempty"`
+	Note     *noteResponse     `json:"note,omitempty"`
+}
+
+type batchResponse struct {
+	Results []batchResultResponse `json:"results"`
+}
+
+// batchErrorResponse is the JSON response for a failed batch. Index is the
+// operation that failed.
+type batchErrorResponse struct {
+	Message string `json:"message"`
+	Index   int    `json:"index"`
+}
+
+func toBatchResultResponse(r batch.Result) batchResultResponse {
+	res := batchResultResponse{
+		Ref:    r.Ref,
+		Type:   r.Type,
+		Action: string(r.Action),
+		ID:     r.ID.String(),
+	}
+	if r.Category != nil {
+		c := toCategoryResponse(*r.Category)
+		res.Category = &c
+	}
+	if r.Note != nil {
+		n := toNoteResponse(*r.Note)
+		res.Note = &n
+	}
+	return res
+}
+
+// requiredScopes maps the entity types of operations to the scope needed
+// to change them.
+var requiredScopes = map[string]auth.Scope{
+	batch.TypeCategory: auth.ScopeCategoriesAdmin,
+	batch.TypeNote:     auth.ScopeNotesWrite,
+}
+
+// Execute handles POST /batch
+//
+// Operations run in order in one transaction: either all of them are
+// stored or, if one fails, none. A create may set ref, and later
+// operations refer to the entity it made with "$<ref>" in id or
+// category_id.
+func (h *BatchHandler) Execute(c echo.Context) error {
+	var req batchRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ops := make([]batch.Operation, len(req.Operations))
+	for i, op := range req.Operations {
+		if scope, ok := requiredScopes[op.Type]; ok {
+			if err := middleware.CheckScopes(c.Request().Context(), scope); err != nil {
+				return c.JSON(err.Code, batchErrorResponse{Message: fmt.Sprint(err.Message), Index: i})
+			}
+		}
+		ops[i] = batch.Operation{
+			Ref:            op.Ref,
+			Type:           op.Type,
+			Action:         batch.Action(op.Action),
+			ID:             op.ID,
+			Name:           op.Name,
+			MetadataSchema: op.MetadataSchema,
+			CategoryID:     op.CategoryID,
+			Title:          op.Title,
+			Content:        op.Content,
+			Metadata:       op.Metadata,
+		}
+	}
+
+	results, err := h.service.Execute(c.Request().Context(), ops)
+	if err != nil {
+		var opErr *batch.OpError
+		switch {
+		case errors.Is(err, batch.ErrEmpty), errors.Is(err, batch.ErrTooManyOperations):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case errors.As(err, &opErr):
+			// The cause is logged like that of other failures
+			status, message := batchErrorStatus(opErr.Err)
+			return echo.NewHTTPError(status, batchErrorResponse{Message: message, Index: opErr.Index}).SetInternal(opErr.Err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to execute batch").SetInternal(err)
+	}
+
+	res := batchResponse{Results: make([]batchResultResponse, len(results))}
+	for i, r := range results {
+		res.Results[i] = toBatchResultResponse(r)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// batchErrorStatus returns the status and message for the failure of an
+// operation.
+func batchErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, batch.ErrInvalidOperation),
+		errors.Is(err, category.ErrInvalidSchema),
+		errors.Is(err, note.ErrInvalidMetadata):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, category.ErrNotFound):
+		return http.StatusNotFound, "category not found"
+	case errors.Is(err, category.ErrAlreadyExists):
+		return http.StatusConflict, "category already exists"
+	case errors.Is(err, note.ErrNotFound):
+		return http.StatusNotFound, "note not found"
+	}
+	return http.StatusInternalServerError, "operation failed"
+}
+
+// RegisterRoutes registers batch routes. Scopes are checked per operation.
+func (h *BatchHandler) RegisterRoutes(g *echo.Group) {
+	g.POST("", h.Execute)
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
 	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
 )
 
@@ -21,18 +24,20 @@ func NewCategoryHandler(service *category.Service) *CategoryHandler {
 
 // categoryResponse is the JSON response for a category.
 type categoryResponse struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	MetadataSchema json.RawMessage `json:"metadata_schema,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
 
 func toCategoryResponse(c category.Category) categoryResponse {
 	return categoryResponse{
-		ID:        c.ID.String(),
-		Name:      c.Name,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: c.UpdatedAt,
+		ID:             c.ID.String(),
+		Name:           c.Name,
+		MetadataSchema: c.MetadataSchema,
+		CreatedAt:      c.CreatedAt,
+		UpdatedAt:      c.UpdatedAt,
 	}
 }
 
@@ -45,7 +50,8 @@ func toCategoryResponses(categories []category.Category) []categoryResponse {
 }
 
 type createCategoryRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name           string          `json:"name" validate:"required"`
+	MetadataSchema json.RawMessage `json:"metadata_schema"`
 }
 
 // Create handles POST /categories
@@ -60,13 +66,17 @@ func (h *CategoryHandler) Create(c echo.Context) error {
 	}
 
 	cat, err := h.service.Create(c.Request().Context(), category.CreateInput{
-		Name: req.Name,
+		Name:           req.Name,
+		MetadataSchema: req.MetadataSchema,
 	})
 	if err != nil {
+		if errors.Is(err, category.ErrInvalidSchema) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		if errors.Is(err, category.ErrAlreadyExists) {
 			return echo.NewHTTPError(http.StatusConflict, "category already exists")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create category")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create category").SetInternal(err)
 	}
 
 	return c.JSON(http.StatusCreated, toCategoryResponse(cat))
@@ -76,7 +86,7 @@ func (h *CategoryHandler) Create(c echo.Context) error {
 func (h *CategoryHandler) GetAll(c echo.Context) error {
 	categories, err := h.service.GetAll(c.Request().Context())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get categories")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get categories").SetInternal(err)
 	}
 
 	if categories == nil {
@@ -98,17 +108,21 @@ func (h *CategoryHandler) GetByID(c echo.Context) error {
 		if errors.Is(err, category.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "category not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get category")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get category").SetInternal(err)
 	}
 
 	return c.JSON(http.StatusOK, toCategoryResponse(cat))
 }
 
 type updateCategoryRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name           string          `json:"name" validate:"required"`
+	MetadataSchema json.RawMessage `json:"metadata_schema"`
 }
 
 // Update handles PUT /categories/:id
+//
+// The metadata schema is kept when metadata_schema is absent and removed
+// when it is null.
 func (h *CategoryHandler) Update(c echo.Context) error {
 	id, err := category.ParseID(c.Param("id"))
 	if err != nil {
@@ -125,14 +139,21 @@ func (h *CategoryHandler) Update(c echo.Context) error {
 	}
 
 	cat, err := h.service.Update(c.Request().Context(), category.UpdateInput{
-		ID:   id,
-		Name: req.Name,
+		ID:             id,
+		Name:           req.Name,
+		MetadataSchema: req.MetadataSchema,
 	})
 	if err != nil {
+		if errors.Is(err, category.ErrInvalidSchema) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		if errors.Is(err, category.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "category not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update category")
+		if errors.Is(err, category.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, "category already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update category").SetInternal(err)
 	}
 
 	return c.JSON(http.StatusOK, toCategoryResponse(cat))
@@ -149,7 +170,7 @@ func (h *CategoryHandler) Delete(c echo.Context) error {
 		if errors.Is(err, category.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "category not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete category")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete category").SetInternal(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -157,9 +178,12 @@ func (h *CategoryHandler) Delete(c echo.Context) error {
 
 // RegisterRoutes registers category routes.
 func (h *CategoryHandler) RegisterRoutes(g *echo.Group) {
-	g.POST("", h.Create)
-	g.GET("", h.GetAll)
-	g.GET("/:id", h.GetByID)
-	g.PUT("/:id", h.Update)
-	g.DELETE("/:id", h.Delete)
+	read := middleware.RequireScope(auth.ScopeNotesRead)
+	write := middleware.RequireScope(auth.ScopeCategoriesAdmin)
+
+	g.POST("", h.Create, write)
+	g.GET("", h.GetAll, read)
+	g.GET("/:id", h.GetByID, read)
+	g.PUT("/:id", h.Update, write)
+	g.DELETE("/:id", h.Delete, write)
 }
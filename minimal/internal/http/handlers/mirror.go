@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/mirror"
+)
+
+// MirrorHandler handles HTTP requests for the git mirror.
+type MirrorHandler struct {
+	mirror *mirror.Mirror
+}
+
+// NewMirrorHandler creates a new MirrorHandler. mirror is nil when the git
+// mirror is disabled.
+func NewMirrorHandler(mirror *mirror.Mirror) *MirrorHandler {
+	return &MirrorHandler{mirror: mirror}
+}
+
+// resyncResponse is the JSON response for a queued resync.
+type resyncResponse struct {
+	JobID string    `json:"job_id"`
+	RunAt time.Time `json:"run_at"`
+}
+
+// Resync handles POST /mirror/resync
+// Responds 202 once a full export of the workspace is queued.
+func (h *MirrorHandler) Resync(c echo.Context) error {
+	if h.mirror == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "git mirror is not enabled")
+	}
+
+	j, err := h.mirror.Resync(c.Request().Context())
+	if err != nil {
+		if errors.Is(err, mirror.ErrResyncPending) {
+			return echo.NewHTTPError(http.StatusConflict, "a resync is already pending")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to queue resync").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusAccepted, resyncResponse{JobID: j.ID.String(), RunAt: j.RunAt})
+}
+
+// RegisterRoutes registers git mirror routes.
+func (h *MirrorHandler) RegisterRoutes(g *echo.Group) {
+	g.POST("/resync", h.Resync, middleware.RequireScope(auth.ScopeCategoriesAdmin))
+}
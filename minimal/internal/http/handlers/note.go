@@ -2,10 +2,15 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
 	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
 	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
 )
@@ -22,20 +27,26 @@ func NewNoteHandler(service *note.Service) *NoteHandler {
 
 // noteResponse is the JSON response for a note.
 type noteResponse struct {
-	ID         string    `json:"id"`
-	CategoryID string    `json:"category_id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         string         `json:"id"`
+	CategoryID string         `json:"category_id"`
+	Title      string         `json:"title"`
+	Content    string         `json:"content"`
+	Metadata   map[string]any `json:"metadata"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
 }
 
 func toNoteResponse(n note.Note) noteResponse {
+	metadata := n.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
 	return noteResponse{
 		ID:         n.ID.String(),
 		CategoryID: n.CategoryID.String(),
 		Title:      n.Title,
 		Content:    n.Content,
+		Metadata:   metadata,
 		CreatedAt:  n.CreatedAt,
 		UpdatedAt:  n.UpdatedAt,
 	}
@@ -50,9 +61,10 @@ func toNoteResponses(notes []note.Note) []noteResponse {
 }
 
 type createNoteRequest struct {
-	CategoryID string `json:"category_id" validate:"required"`
-	Title      string `json:"title" validate:"required"`
-	Content    string `json:"content"`
+	CategoryID string         `json:"category_id" validate:"required"`
+	Title      string         `json:"title" validate:"required"`
+	Content    string         `json:"content"`
+	Metadata   map[string]any `json:"metadata"`
 }
 
 // Create handles POST /notes
@@ -75,39 +87,100 @@ func (h *NoteHandler) Create(c echo.Context) error {
 		CategoryID: categoryID,
 		Title:      req.Title,
 		Content:    req.Content,
+		Metadata:   req.Metadata,
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create note")
+		if status, message, ok := noteInputError(err); ok {
+			return echo.NewHTTPError(status, message)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create note").SetInternal(err)
 	}
 
 	return c.JSON(http.StatusCreated, toNoteResponse(n))
 }
 
+// noteInputError returns the response for errors caused by the category
+// or metadata of a note being written.
+func noteInputError(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, note.ErrInvalidMetadata):
+		return http.StatusBadRequest, err.Error(), true
+	case errors.Is(err, category.ErrNotFound):
+		return http.StatusNotFound, "category not found", true
+	}
+	return 0, "", false
+}
+
+// metadataFilterPrefix starts the query parameters of GET /notes that
+// filter by metadata.
+const metadataFilterPrefix = "meta."
+
+// metadataConditions parses the metadata filters of the query.
+func metadataConditions(c echo.Context) ([]note.MetadataCondition, error) {
+	var conditions []note.MetadataCondition
+	for name, values := range c.QueryParams() {
+		key, ok := strings.CutPrefix(name, metadataFilterPrefix)
+		if !ok {
+			continue
+		}
+		for _, value := range values {
+			cond, err := note.ParseMetadataCondition(key, value)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, cond)
+		}
+	}
+	return conditions, nil
+}
+
+// maxPageSize bounds the limit query parameter of GET /notes.
+const maxPageSize = 100
+
+// nextCursorHeader carries the cursor of the next page of a paginated
+// listing. It is absent on the last page.
+const nextCursorHeader = "X-Next-Cursor"
+
 // GetAll handles GET /notes
+//
+// Notes are listed newest first. With a limit query parameter the listing
+// is paginated: the after parameter takes the value of the previous
+// page's X-Next-Cursor header.
+//
+// Parameters named meta.<key> filter by metadata, e.g.
+// ?meta.status=open&meta.priority>=2. The operators are =, !=, >, >=, <
+// and <=.
 func (h *NoteHandler) GetAll(c echo.Context) error {
+	conditions, err := metadataConditions(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var categoryID category.ID
 	// Check if category filter is provided
 	categoryIDStr := c.QueryParam("category_id")
 	if categoryIDStr != "" {
-		categoryID, err := category.ParseID(categoryIDStr)
+		id, err := category.ParseID(categoryIDStr)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "invalid category_id")
 		}
+		categoryID = id
+	}
 
-		notes, err := h.service.GetByCategory(c.Request().Context(), categoryID)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "failed to get notes")
-		}
-
-		if notes == nil {
-			notes = []note.Note{}
-		}
-
-		return c.JSON(http.StatusOK, toNoteResponses(notes))
+	if c.QueryParam("limit") != "" {
+		return h.list(c, categoryID, conditions)
 	}
 
-	notes, err := h.service.GetAll(c.Request().Context())
+	var notes []note.Note
+	if len(conditions) > 0 {
+		notes, err = h.service.List(c.Request().Context(), note.ListFilter{CategoryID: categoryID, Metadata: conditions})
+	} else if categoryIDStr != "" {
+		notes, err = h.service.GetByCategory(c.Request().Context(), categoryID)
+	} else {
+		notes, err = h.service.GetAll(c.Request().Context())
+	}
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get notes")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get notes").SetInternal(err)
 	}
 
 	if notes == nil {
@@ -117,6 +190,50 @@ func (h *NoteHandler) GetAll(c echo.Context) error {
 	return c.JSON(http.StatusOK, toNoteResponses(notes))
 }
 
+// parsePage parses the limit and after query parameters of a paginated
+// listing of notes.
+func parsePage(c echo.Context) (int, *note.Cursor, error) {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 || limit > maxPageSize {
+		return 0, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+	}
+	after := c.QueryParam("after")
+	if after == "" {
+		return limit, nil, nil
+	}
+	cursor, err := note.ParseCursor(after)
+	if err != nil {
+		return 0, nil, echo.NewHTTPError(http.StatusBadRequest, "invalid after cursor")
+	}
+	return limit, &cursor, nil
+}
+
+// writePage responds with a page of notes fetched with a limit one above
+// limit, setting the cursor of the next page if there is one.
+func writePage(c echo.Context, notes []note.Note, limit int) error {
+	if len(notes) > limit {
+		notes = notes[:limit]
+		c.Response().Header().Set(nextCursorHeader, note.CursorOf(notes[limit-1]).String())
+	}
+	return c.JSON(http.StatusOK, toNoteResponses(notes))
+}
+
+// list serves one page of GET /notes.
+func (h *NoteHandler) list(c echo.Context, categoryID category.ID, conditions []note.MetadataCondition) error {
+	limit, after, err := parsePage(c)
+	if err != nil {
+		return err
+	}
+
+	filter := note.ListFilter{CategoryID: categoryID, Metadata: conditions, After: after, Limit: limit + 1}
+	notes, err := h.service.List(c.Request().Context(), filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get notes").SetInternal(err)
+	}
+
+	return writePage(c, notes, limit)
+}
+
 // GetByID handles GET /notes/:id
 func (h *NoteHandler) GetByID(c echo.Context) error {
 	id, err := note.ParseID(c.Param("id"))
@@ -129,19 +246,22 @@ func (h *NoteHandler) GetByID(c echo.Context) error {
 		if errors.Is(err, note.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "note not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get note")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get note").SetInternal(err)
 	}
 
 	return c.JSON(http.StatusOK, toNoteResponse(n))
 }
 
 type updateNoteRequest struct {
-	CategoryID string `json:"category_id" validate:"required"`
-	Title      string `json:"title" validate:"required"`
-	Content    string `json:"content"`
+	CategoryID string         `json:"category_id" validate:"required"`
+	Title      string         `json:"title" validate:"required"`
+	Content    string         `json:"content"`
+	Metadata   map[string]any `json:"metadata"`
 }
 
 // Update handles PUT /notes/:id
+//
+// The metadata is kept when the request has none.
 func (h *NoteHandler) Update(c echo.Context) error {
 	id, err := note.ParseID(c.Param("id"))
 	if err != nil {
@@ -167,12 +287,16 @@ func (h *NoteHandler) Update(c echo.Context) error {
 		CategoryID: categoryID,
 		Title:      req.Title,
 		Content:    req.Content,
+		Metadata:   req.Metadata,
 	})
 	if err != nil {
 		if errors.Is(err, note.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "note not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update note")
+		if status, message, ok := noteInputError(err); ok {
+			return echo.NewHTTPError(status, message)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update note").SetInternal(err)
 	}
 
 	return c.JSON(http.StatusOK, toNoteResponse(n))
@@ -189,17 +313,99 @@ func (h *NoteHandler) Delete(c echo.Context) error {
 		if errors.Is(err, note.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "note not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete note")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete note").SetInternal(err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// linkResponse is the JSON response for a link between notes. TargetID is
+// null while the link is dangling.
+type linkResponse struct {
+	SourceID string  `json:"source_id"`
+	Type     string  `json:"type"`
+	Ref      string  `json:"ref"`
+	TargetID *string `json:"target_id"`
+	Dangling bool    `json:"dangling"`
+}
+
+func toLinkResponses(links []note.Link) []linkResponse {
+	result := make([]linkResponse, len(links))
+	for i, l := range links {
+		result[i] = linkResponse{
+			SourceID: l.SourceID.String(),
+			Type:     string(l.Kind),
+			Ref:      l.Ref,
+			Dangling: l.Dangling(),
+		}
+		if !l.Dangling() {
+			target := l.TargetID.String()
+			result[i].TargetID = &target
+		}
+	}
+	return result
+}
+
+// Links handles GET /notes/:id/links
+//
+// Links are the [[Note Title]] and [[note:<id>]] references in the
+// content of the note, in order of appearance.
+func (h *NoteHandler) Links(c echo.Context) error {
+	id, err := note.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid note id")
+	}
+
+	links, err := h.service.Links(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, note.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "note not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get links").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, toLinkResponses(links))
+}
+
+// Backlinks handles GET /notes/:id/backlinks
+func (h *NoteHandler) Backlinks(c echo.Context) error {
+	id, err := note.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid note id")
+	}
+
+	notes, err := h.service.Backlinks(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, note.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "note not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get backlinks").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, toNoteResponses(notes))
+}
+
+// DanglingLinks handles GET /notes/dangling-links
+func (h *NoteHandler) DanglingLinks(c echo.Context) error {
+	links, err := h.service.DanglingLinks(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get dangling links").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, toLinkResponses(links))
+}
+
 // RegisterRoutes registers note routes.
 func (h *NoteHandler) RegisterRoutes(g *echo.Group) {
-	g.POST("", h.Create)
-	g.GET("", h.GetAll)
-	g.GET("/:id", h.GetByID)
-	g.PUT("/:id", h.Update)
-	g.DELETE("/:id", h.Delete)
+	read := middleware.RequireScope(auth.ScopeNotesRead)
+	write := middleware.RequireScope(auth.ScopeNotesWrite)
+
+	g.POST("", h.Create, write)
+	g.GET("", h.GetAll, read)
+	g.GET("/dangling-links", h.DanglingLinks, read)
+	g.GET("/:id", h.GetByID, read)
+	g.GET("/:id/links", h.Links, read)
+	g.GET("/:id/backlinks", h.Backlinks, read)
+	g.PUT("/:id", h.Update, write)
+	g.DELETE("/:id", h.Delete, write)
 }
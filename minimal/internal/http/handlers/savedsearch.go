@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/savedsearch"
+)
+
+// SavedSearchHandler handles HTTP requests for saved searches.
+type SavedSearchHandler struct {
+	service *savedsearch.Service
+}
+
+// NewSavedSearchHandler creates a new SavedSearchHandler.
+func NewSavedSearchHandler(service *savedsearch.Service) *SavedSearchHandler {
+	return &SavedSearchHandler{service: service}
+}
+
+// savedSearchResponse is the JSON response for a saved search.
+type savedSearchResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Filter    string    `json:"filter"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func toSavedSearchResponse(s savedsearch.SavedSearch) savedSearchResponse {
+	return savedSearchResponse{
+		ID:        s.ID.String(),
+		Name:      s.Name,
+		Filter:    s.Filter,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type savedSearchRequest struct {
+	Name   string `json:"name" validate:"required"`
+	Filter string `json:"filter" validate:"required"`
+}
+
+// bind reads and checks the body of create and update requests.
+func (r *savedSearchRequest) bind(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if r.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+	}
+	if r.Filter == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "filter is required")
+	}
+	return nil
+}
+
+// savedSearchInputError returns the response for errors caused by the
+// name or filter of a saved search being written.
+func savedSearchInputError(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, note.ErrInvalidFilter):
+		return http.StatusBadRequest, err.Error(), true
+	case errors.Is(err, savedsearch.ErrAlreadyExists):
+		return http.StatusConflict, "saved search already exists", true
+	}
+	return 0, "", false
+}
+
+// Create handles POST /saved-searches
+//
+// The filter is an expression over notes such as
+// `tag = urgent AND (title ~ "meeting" OR created >= -7d)`; see
+// note.ParseFilter for the fields and operators.
+func (h *SavedSearchHandler) Create(c echo.Context) error {
+	var req savedSearchRequest
+	if err := req.bind(c); err != nil {
+		return err
+	}
+
+	s, err := h.service.Create(c.Request().Context(), savedsearch.CreateInput{
+		Name:   req.Name,
+		Filter: req.Filter,
+	})
+	if err != nil {
+		if status, message, ok := savedSearchInputError(err); ok {
+			return echo.NewHTTPError(status, message)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create saved search").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, toSavedSearchResponse(s))
+}
+
+// GetAll handles GET /saved-searches
+func (h *SavedSearchHandler) GetAll(c echo.Context) error {
+	searches, err := h.service.GetAll(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved searches").SetInternal(err)
+	}
+
+	result := make([]savedSearchResponse, len(searches))
+	for i, s := range searches {
+		result[i] = toSavedSearchResponse(s)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles GET /saved-searches/:id
+func (h *SavedSearchHandler) GetByID(c echo.Context) error {
+	id, err := savedsearch.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid saved search id")
+	}
+
+	s, err := h.service.GetByID(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, savedsearch.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "saved search not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved search").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, toSavedSearchResponse(s))
+}
+
+// Update handles PUT /saved-searches/:id
+func (h *SavedSearchHandler) Update(c echo.Context) error {
+	id, err := savedsearch.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid saved search id")
+	}
+
+	var req savedSearchRequest
+	if err := req.bind(c); err != nil {
+		return err
+	}
+
+	s, err := h.service.Update(c.Request().Context(), savedsearch.UpdateInput{
+		ID:     id,
+		Name:   req.Name,
+		Filter: req.Filter,
+	})
+	if err != nil {
+		if errors.Is(err, savedsearch.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "saved search not found")
+		}
+		if status, message, ok := savedSearchInputError(err); ok {
+			return echo.NewHTTPError(status, message)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update saved search").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, toSavedSearchResponse(s))
+}
+
+// Delete handles DELETE /saved-searches/:id
+func (h *SavedSearchHandler) Delete(c echo.Context) error {
+	id, err := savedsearch.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid saved search id")
+	}
+
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		if errors.Is(err, savedsearch.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "saved search not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete saved search").SetInternal(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Notes handles GET /saved-searches/:id/notes
+//
+// The matching notes are listed newest first, paginated as GET /notes is
+// when a limit query parameter is given. When notes are encrypted at rest,
+// filters on title or content are evaluated after decrypting notes, a
+// batch at a time until the page is full, so pages of rare matches and
+// requests without a limit decrypt every note of the workspace.
+func (h *SavedSearchHandler) Notes(c echo.Context) error {
+	id, err := savedsearch.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid saved search id")
+	}
+
+	input := savedsearch.NotesInput{ID: id}
+	var limit int
+	if c.QueryParam("limit") != "" {
+		if limit, input.After, err = parsePage(c); err != nil {
+			return err
+		}
+		input.Limit = limit + 1
+	}
+
+	notes, err := h.service.Notes(c.Request().Context(), input)
+	if err != nil {
+		if errors.Is(err, savedsearch.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "saved search not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to run saved search").SetInternal(err)
+	}
+
+	if limit > 0 {
+		return writePage(c, notes, limit)
+	}
+	return c.JSON(http.StatusOK, toNoteResponses(notes))
+}
+
+// RegisterRoutes registers saved search routes.
+func (h *SavedSearchHandler) RegisterRoutes(g *echo.Group) {
+	read := middleware.RequireScope(auth.ScopeNotesRead)
+	write := middleware.RequireScope(auth.ScopeNotesWrite)
+
+	g.POST("", h.Create, write)
+	g.GET("", h.GetAll, read)
+	g.GET("/:id", h.GetByID, read)
+	g.GET("/:id/notes", h.Notes, read)
+	g.PUT("/:id", h.Update, write)
+	g.DELETE("/:id", h.Delete, write)
+}
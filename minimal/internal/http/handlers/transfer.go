@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/transfer"
+)
+
+// maxImportSize bounds the body of an import request.
+const maxImportSize = 32 << 20
+
+// Content types of the archive formats.
+const (
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeZip    = "application/zip"
+)
+
+// TransferHandler handles HTTP requests for bulk export and import.
+type TransferHandler struct {
+	service *transfer.Service
+}
+
+// NewTransferHandler creates a new TransferHandler.
+func NewTransferHandler(service *transfer.Service) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+// importItemResponse is the JSON response for one imported record.
+type importItemResponse struct {
+	Type     string `json:"type"`
+	Ref      string `json:"ref"`
+	SourceID string `json:"source_id,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Action   string `json:"action"`
+	Error    string `json:"error,omitempty"`
+}
+
+// importResponse is the JSON response for an import.
+type importResponse struct {
+	DryRun    bool                 `json:"dry_run"`
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Unchanged int                  `json:"unchanged"`
+	Failed    int                  `json:"failed"`
+	Items     []importItemResponse `json:"items"`
+}
+
+func toImportResponse(r transfer.Report) importResponse {
+	items := make([]importItemResponse, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = importItemResponse{
+			Type:     item.Type,
+			Ref:      item.Ref,
+			SourceID: item.SourceID,
+			ID:       item.ID,
+			Action:   string(item.Action),
+			Error:    item.Error,
+		}
+	}
+	return importResponse{
+		DryRun:    r.DryRun,
+		Created:   r.Created,
+		Updated:   r.Updated,
+		Unchanged: r.Unchanged,
+		Failed:    r.Failed,
+		Items:     items,
+	}
+}
+
+// Export handles GET /export
+// Query parameters: format (ndjson or markdown-zip, default ndjson).
+func (h *TransferHandler) Export(c echo.Context) error {
+	format := transfer.FormatNDJSON
+	if v := c.QueryParam("format"); v != "" {
+		var err error
+		if format, err = transfer.ParseFormat(v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "format must be ndjson or markdown-zip")
+		}
+	}
+
+	contentType, ext := contentTypeNDJSON, "ndjson"
+	if format == transfer.FormatMarkdownZip {
+		contentType, ext = contentTypeZip, "zip"
+	}
+	filename := fmt.Sprintf("notes-%s.%s", time.Now().UTC().Format("2006-01-02"), ext)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	res.WriteHeader(http.StatusOK)
+
+	// The status is sent, so a failure can only cut the archive short
+	return h.service.Export(c.Request().Context(), format, res)
+}
+
+// Import handles POST /import
+// Query parameters: format (ndjson or markdown-zip, by default taken from
+// the Content-Type) and dry_run.
+//
+// Responds 200 with the report, or 422 with the report if any record is
+// invalid, in which case nothing is imported.
+func (h *TransferHandler) Import(c echo.Context) error {
+	format, err := importFormat(c)
+	if err != nil {
+		return err
+	}
+
+	dryRun := false
+	if v := c.QueryParam("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid dry_run")
+		}
+	}
+
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, maxImportSize)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "archive too large")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	archive, err := transfer.Decode(format, data)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid archive")
+	}
+
+	report, err := h.service.Import(c.Request().Context(), archive, dryRun)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to import").SetInternal(err)
+	}
+
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusUnprocessableEntity
+	}
+	return c.JSON(status, toImportResponse(report))
+}
+
+// importFormat returns the format given in the query or by the
+// Content-Type of the request.
+func importFormat(c echo.Context) (transfer.Format, error) {
+	if v := c.QueryParam("format"); v != "" {
+		format, err := transfer.ParseFormat(v)
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "format must be ndjson or markdown-zip")
+		}
+		return format, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case contentTypeNDJSON:
+		return transfer.FormatNDJSON, nil
+	case contentTypeZip:
+		return transfer.FormatMarkdownZip, nil
+	}
+	return "", echo.NewHTTPError(http.StatusBadRequest, "format must be ndjson or markdown-zip")
+}
+
+// RegisterRoutes registers the export and import routes.
+func (h *TransferHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/export", h.Export, middleware.RequireScope(auth.ScopeNotesRead))
+	g.POST("/import", h.Import, middleware.RequireScope(auth.ScopeNotesWrite, auth.ScopeCategoriesAdmin))
+}
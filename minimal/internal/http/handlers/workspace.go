@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+)
+
+// WorkspaceHandler handles HTTP requests for workspaces and their members.
+type WorkspaceHandler struct {
+	service *workspace.Service
+	users   *user.Service
+}
+
+// NewWorkspaceHandler creates a new WorkspaceHandler.
+func NewWorkspaceHandler(service *workspace.Service, users *user.Service) *WorkspaceHandler {
+	return &WorkspaceHandler{service: service, users: users}
+}
+
+// workspaceResponse is the JSON response for a workspace.
+type workspaceResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func toWorkspaceResponse(w workspace.Workspace) workspaceResponse {
+	return workspaceResponse{
+		ID:        w.ID.String(),
+		Name:      w.Name,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// memberResponse is the JSON response for a workspace member.
+type memberResponse struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toMemberResponse(m workspace.Member) memberResponse {
+	return memberResponse{
+		UserID:    m.UserID.String(),
+		Role:      string(m.Role),
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// workspaceError maps workspace service errors to HTTP errors.
+func workspaceError(err error, fallback string) error {
+	switch {
+	case errors.Is(err, workspace.ErrNotMember), errors.Is(err, workspace.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "workspace not found")
+	case errors.Is(err, workspace.ErrMemberNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "member not found")
+	case errors.Is(err, workspace.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "insufficient workspace role")
+	case errors.Is(err, workspace.ErrMemberExists):
+		return echo.NewHTTPError(http.StatusConflict, "user is already a member")
+	case errors.Is(err, workspace.ErrLastOwner):
+		return echo.NewHTTPError(http.StatusConflict, "workspace must keep an owner")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback).SetInternal(err)
+}
+
+type createWorkspaceRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// Create handles POST /workspaces
+func (h *WorkspaceHandler) Create(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	var req createWorkspaceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+	}
+
+	w, err := h.service.Create(c.Request().Context(), workspace.CreateInput{
+		Name:    req.Name,
+		OwnerID: principal.UserID,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create workspace").SetInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, toWorkspaceResponse(w))
+}
+
+// GetAll handles GET /workspaces
+func (h *WorkspaceHandler) GetAll(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	workspaces, err := h.service.ListForUser(c.Request().Context(), principal.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get workspaces").SetInternal(err)
+	}
+
+	result := make([]workspaceResponse, len(workspaces))
+	for i, w := range workspaces {
+		result[i] = toWorkspaceResponse(w)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetMembers handles GET /workspaces/:id/members
+func (h *WorkspaceHandler) GetMembers(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	id, err := workspace.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid workspace id")
+	}
+
+	members, err := h.service.Members(c.Request().Context(), principal.UserID, id)
+	if err != nil {
+		return workspaceError(err, "failed to get members")
+	}
+
+	result := make([]memberResponse, len(members))
+	for i, m := range members {
+		result[i] = toMemberResponse(m)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+type addMemberRequest struct {
+	Email string `json:"email" validate:"required"`
+	Role  string `json:"role" validate:"required"`
+}
+
+// AddMember handles POST /workspaces/:id/members
+func (h *WorkspaceHandler) AddMember(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	id, err := workspace.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid workspace id")
+	}
+
+	var req addMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Email == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "email is required")
+	}
+	role, err := workspace.ParseRole(req.Role)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	u, err := h.users.GetByEmail(c.Request().Context(), req.Email)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to add member").SetInternal(err)
+	}
+
+	m, err := h.service.AddMember(c.Request().Context(), principal.UserID, workspace.MemberInput{
+		WorkspaceID: id,
+		UserID:      u.ID,
+		Role:        role,
+	})
+	if err != nil {
+		return workspaceError(err, "failed to add member")
+	}
+
+	return c.JSON(http.StatusCreated, toMemberResponse(m))
+}
+
+type updateMemberRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// UpdateMember handles PUT /workspaces/:id/members/:user_id
+func (h *WorkspaceHandler) UpdateMember(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	id, err := workspace.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid workspace id")
+	}
+	userID, err := user.ParseID(c.Param("user_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id")
+	}
+
+	var req updateMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	role, err := workspace.ParseRole(req.Role)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	m, err := h.service.UpdateMember(c.Request().Context(), principal.UserID, workspace.MemberInput{
+		WorkspaceID: id,
+		UserID:      userID,
+		Role:        role,
+	})
+	if err != nil {
+		return workspaceError(err, "failed to update member")
+	}
+
+	return c.JSON(http.StatusOK, toMemberResponse(m))
+}
+
+// RemoveMember handles DELETE /workspaces/:id/members/:user_id
+func (h *WorkspaceHandler) RemoveMember(c echo.Context) error {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+
+	id, err := workspace.ParseID(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid workspace id")
+	}
+	userID, err := user.ParseID(c.Param("user_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id")
+	}
+
+	if err := h.service.RemoveMember(c.Request().Context(), principal.UserID, id, userID); err != nil {
+		return workspaceError(err, "failed to remove member")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RegisterRoutes registers workspace routes. Access to a workspace's members
+// is governed by the caller's role in it rather than by scopes.
+func (h *WorkspaceHandler) RegisterRoutes(g *echo.Group) {
+	g.POST("", h.Create)
+	g.GET("", h.GetAll)
+	g.GET("/:id/members", h.GetMembers)
+	g.POST("/:id/members", h.AddMember)
+	g.PUT("/:id/members/:user_id", h.UpdateMember)
+	g.DELETE("/:id/members/:user_id", h.RemoveMember)
+}
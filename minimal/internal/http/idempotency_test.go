@@ -0,0 +1,81 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/idempotency"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	body := `{"category_id":"` + c.ID.String() + `","title":"Groceries"}`
+	key := apitest.WithHeader("Idempotency-Key", "retry-1")
+
+	var first, second noteBody
+	h.Post("/api/v1/notes", body, key).
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Idempotent-Replayed", "").
+		Decode(&first)
+	h.Post("/api/v1/notes", body, key).
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Idempotent-Replayed", "true").
+		Decode(&second)
+	if second.ID != first.ID {
+		t.Errorf("retry created note %s, want replay of %s", second.ID, first.ID)
+	}
+
+	var notes []noteBody
+	h.Get("/api/v1/notes").AssertStatus(http.StatusOK).Decode(&notes)
+	if len(notes) != 1 {
+		t.Errorf("got %d notes, want 1", len(notes))
+	}
+
+	t.Run("different body", func(t *testing.T) {
+		h.For(t).Post("/api/v1/notes", `{"category_id":"`+c.ID.String()+`","title":"Other"}`, key).
+			AssertError(http.StatusUnprocessableEntity, "idempotency key was used with a different request")
+	})
+
+	t.Run("errors are replayed", func(t *testing.T) {
+		errKey := apitest.WithHeader("Idempotency-Key", "retry-2")
+		h.For(t).Post("/api/v1/notes", `{"category_id":"`+c.ID.String()+`"}`, errKey).
+			AssertError(http.StatusBadRequest, "title is required").
+			AssertHeader("Idempotent-Replayed", "")
+		h.For(t).Post("/api/v1/notes", `{"category_id":"`+c.ID.String()+`"}`, errKey).
+			AssertError(http.StatusBadRequest, "title is required").
+			AssertHeader("Idempotent-Replayed", "true")
+	})
+
+	t.Run("keys are per user", func(t *testing.T) {
+		other := h.InNewWorkspace("Other").For(t)
+		oc := other.Category().Create()
+		other.Post("/api/v1/notes", `{"category_id":"`+oc.ID.String()+`","title":"Groceries"}`, key).
+			AssertStatus(http.StatusCreated)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		h.For(t).Post("/api/v1/notes", body).AssertStatus(http.StatusCreated)
+		h.For(t).Post("/api/v1/notes", body).AssertStatus(http.StatusCreated)
+	})
+}
+
+func TestIdempotencyKeyInFlight(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	body := `{"category_id":"` + c.ID.String() + `","title":"Slow"}`
+
+	// Claim the key as if a first request were still being handled
+	_, stored, err := h.Idempotency.Begin(h.Context(), idempotency.Request{
+		UserID:      h.User.ID,
+		Key:         "slow",
+		Fingerprint: idempotency.Fingerprint(http.MethodPost, "/api/v1/notes", []byte(body)),
+	})
+	if err != nil || stored != nil {
+		t.Fatalf("Begin = %v, %v; want a new claim", stored, err)
+	}
+
+	h.Post("/api/v1/notes", body, apitest.WithHeader("Idempotency-Key", "slow")).
+		AssertError(http.StatusConflict, "a request with this idempotency key is in progress")
+}
@@ -0,0 +1,92 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+)
+
+func TestJWTAuth(t *testing.T) {
+	h := apitest.New(t, apitest.WithJWT())
+
+	t.Run("accepted", func(t *testing.T) {
+		h := h.For(t)
+		h.Note().Titled("Visible").Create()
+		h.Get("/api/v1/notes", apitest.WithToken(h.JWT(nil))).AssertStatus(http.StatusOK)
+		h.Get("/api/v1/notes", apitest.WithToken(h.SignedJWT(jwa.EdDSA(), nil))).AssertStatus(http.StatusOK)
+	})
+
+	t.Run("api keys still work", func(t *testing.T) {
+		h.For(t).Get("/api/v1/notes").AssertStatus(http.StatusOK)
+	})
+
+	t.Run("scope claim", func(t *testing.T) {
+		h := h.For(t)
+		token := apitest.WithToken(h.JWT(map[string]any{"scope": "openid notes:read"}))
+		h.Get("/api/v1/notes", token).AssertStatus(http.StatusOK)
+		h.Post("/api/v1/categories", map[string]string{"name": "Nope"}, token).
+			AssertError(http.StatusForbidden, "missing scope categories:admin")
+	})
+
+	t.Run("role limits scopes", func(t *testing.T) {
+		viewer := h.Member("jwt-viewer@example.com", workspace.RoleViewer).For(t)
+		viewer.Post("/api/v1/categories", map[string]string{"name": "Nope"}, apitest.WithToken(viewer.JWT(nil))).
+			AssertError(http.StatusForbidden, "missing scope categories:admin")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		h := h.For(t)
+		for name, tc := range map[string]struct {
+			token   string
+			message string
+		}{
+			"expired": {
+				h.JWT(map[string]any{"exp": time.Now().Add(-2 * time.Minute)}),
+				"token expired",
+			},
+			"wrong issuer": {
+				h.JWT(map[string]any{"iss": "https://evil.example.com"}),
+				"invalid token",
+			},
+			"wrong audience": {
+				h.JWT(map[string]any{"aud": "other-api"}),
+				"invalid token",
+			},
+			"untrusted algorithm": {
+				h.SignedJWT(jwa.HS256(), nil),
+				"invalid token",
+			},
+			"no workspace": {
+				h.JWT(map[string]any{"workspace_id": nil}),
+				"invalid token",
+			},
+			"unknown user": {
+				h.JWT(map[string]any{"email": "stranger@example.com"}),
+				"unknown user",
+			},
+			"other workspace": {
+				h.JWT(map[string]any{"workspace_id": missingID}),
+				"not a member of the workspace",
+			},
+			"not a jwt": {
+				"opaque-token",
+				"invalid api key",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				h.For(t).Get("/api/v1/notes", apitest.WithToken(tc.token)).
+					AssertError(http.StatusUnauthorized, tc.message)
+			})
+		}
+	})
+
+	t.Run("leeway", func(t *testing.T) {
+		h := h.For(t)
+		token := h.JWT(map[string]any{"exp": time.Now().Add(-30 * time.Second)})
+		h.Get("/api/v1/notes", apitest.WithToken(token)).AssertStatus(http.StatusOK)
+	})
+}
@@ -0,0 +1,106 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+type linkBody struct {
+	SourceID string  `json:"source_id"`
+	Type     string  `json:"type"`
+	Ref      string  `json:"ref"`
+	TargetID *string `json:"target_id"`
+	Dangling bool    `json:"dangling"`
+}
+
+func getLinks(h *apitest.Harness, path string) []linkBody {
+	var links []linkBody
+	h.Get(path).AssertStatus(http.StatusOK).Decode(&links)
+	return links
+}
+
+func TestNoteLinks(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	target := h.Note().InCategory(c).Titled("Target").Create()
+	source := h.Note().InCategory(c).Titled("Source").
+		WithContent("See [[Target]], [[note:" + target.ID.String() + "]] and [[Later]]. Again: [[Target]]").
+		Create()
+
+	links := getLinks(h, "/api/v1/notes/"+source.ID.String()+"/links")
+	if len(links) != 3 {
+		t.Fatalf("got %d links, want 3: %+v", len(links), links)
+	}
+	for i, want := range []linkBody{
+		{Type: "title", Ref: "Target"},
+		{Type: "id", Ref: target.ID.String()},
+		{Type: "title", Ref: "Later", Dangling: true},
+	} {
+		got := links[i]
+		if got.SourceID != source.ID.String() || got.Type != want.Type || got.Ref != want.Ref || got.Dangling != want.Dangling {
+			t.Errorf("link %d = %+v, want %+v", i, got, want)
+		}
+		if !want.Dangling && (got.TargetID == nil || *got.TargetID != target.ID.String()) {
+			t.Errorf("link %d targets %v, want %s", i, got.TargetID, target.ID)
+		}
+	}
+
+	var backlinks []noteBody
+	h.Get("/api/v1/notes/" + target.ID.String() + "/backlinks").AssertStatus(http.StatusOK).Decode(&backlinks)
+	if len(backlinks) != 1 || backlinks[0].ID != source.ID.String() {
+		t.Errorf("backlinks = %+v, want %s", backlinks, source.ID)
+	}
+
+	dangling := getLinks(h, "/api/v1/notes/dangling-links")
+	if len(dangling) != 1 || dangling[0].Ref != "Later" {
+		t.Fatalf("dangling links = %+v, want Later", dangling)
+	}
+
+	// Creating the missing note resolves the link
+	later := h.Note().InCategory(c).Titled("Later").Create()
+	if dangling := getLinks(h, "/api/v1/notes/dangling-links"); len(dangling) != 0 {
+		t.Errorf("dangling links = %+v, want none", dangling)
+	}
+	h.Get("/api/v1/notes/" + later.ID.String() + "/backlinks").AssertStatus(http.StatusOK).Decode(&backlinks)
+	if len(backlinks) != 1 || backlinks[0].ID != source.ID.String() {
+		t.Errorf("backlinks of Later = %+v, want %s", backlinks, source.ID)
+	}
+
+	// Deleting a note leaves links to it dangling
+	h.Delete("/api/v1/notes/" + target.ID.String()).AssertStatus(http.StatusNoContent)
+	if dangling := getLinks(h, "/api/v1/notes/dangling-links"); len(dangling) != 2 {
+		t.Errorf("dangling links = %+v, want 2", dangling)
+	}
+}
+
+func TestNoteLinksRenamed(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	target := h.Note().InCategory(c).Titled("Old Name").Create()
+	source := h.Note().InCategory(c).Titled("Source").WithContent("Read [[Old Name]] and [[ Old Name ]].").Create()
+
+	h.Put("/api/v1/notes/"+target.ID.String(), map[string]string{
+		"category_id": c.ID.String(),
+		"title":       "New Name",
+	}).AssertStatus(http.StatusOK)
+
+	var got noteBody
+	h.Get("/api/v1/notes/" + source.ID.String()).AssertStatus(http.StatusOK).Decode(&got)
+	if want := "Read [[New Name]] and [[New Name]]."; got.Content != want {
+		t.Errorf("content = %q, want %q", got.Content, want)
+	}
+	links := getLinks(h, "/api/v1/notes/"+source.ID.String()+"/links")
+	if len(links) != 1 || links[0].Ref != "New Name" || links[0].TargetID == nil || *links[0].TargetID != target.ID.String() {
+		t.Errorf("links = %+v, want New Name to %s", links, target.ID)
+	}
+}
+
+func TestNoteLinksNotFound(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Get("/api/v1/notes/"+missingID+"/links").AssertError(http.StatusNotFound, "note not found")
+	h.Get("/api/v1/notes/"+missingID+"/backlinks").AssertError(http.StatusNotFound, "note not found")
+	h.Get("/api/v1/notes/invalid/links").AssertError(http.StatusBadRequest, "invalid note id")
+}
@@ -0,0 +1,171 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+type metadataNoteBody struct {
+	ID       string         `json:"id"`
+	Title    string         `json:"title"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+const statusSchema = `{
+	"type": "object",
+	"properties": {
+		"status": {"enum": ["open", "closed"]},
+		"priority": {"type": "integer"},
+		"due": {"type": "string"}
+	},
+	"required": ["status"]
+}`
+
+func createSchemaCategory(h *apitest.Harness) string {
+	var c categoryBody
+	h.Post("/api/v1/categories", map[string]any{
+		"name":            "Tasks",
+		"metadata_schema": json.RawMessage(statusSchema),
+	}).AssertStatus(http.StatusCreated).Decode(&c)
+	return c.ID
+}
+
+func TestNoteMetadata(t *testing.T) {
+	h := apitest.New(t)
+	categoryID := createSchemaCategory(h)
+
+	var n metadataNoteBody
+	h.Post("/api/v1/notes", map[string]any{
+		"category_id": categoryID,
+		"title":       "Ship it",
+		"metadata":    map[string]any{"status": "open", "priority": 2},
+	}).AssertStatus(http.StatusCreated).Decode(&n)
+	if n.Metadata["status"] != "open" || n.Metadata["priority"] != float64(2) {
+		t.Errorf("metadata = %v, want status open and priority 2", n.Metadata)
+	}
+
+	t.Run("kept on update without metadata", func(t *testing.T) {
+		var got metadataNoteBody
+		h.For(t).Put("/api/v1/notes/"+n.ID, map[string]any{
+			"category_id": categoryID,
+			"title":       "Ship it now",
+		}).AssertStatus(http.StatusOK).Decode(&got)
+		if got.Metadata["status"] != "open" {
+			t.Errorf("metadata = %v, want it kept", got.Metadata)
+		}
+	})
+
+	t.Run("validated against the schema", func(t *testing.T) {
+		h.For(t).Post("/api/v1/notes", map[string]any{
+			"category_id": categoryID,
+			"title":       "Bad",
+			"metadata":    map[string]any{"status": "maybe"},
+		}).AssertError(http.StatusBadRequest, "invalid metadata: at '/status': value must be one of 'open', 'closed'")
+		h.For(t).Put("/api/v1/notes/"+n.ID, map[string]any{
+			"category_id": categoryID,
+			"title":       "Ship it",
+			"metadata":    map[string]any{"priority": 1},
+		}).AssertError(http.StatusBadRequest, "invalid metadata: at '': missing property 'status'")
+	})
+
+	t.Run("unconstrained without a schema", func(t *testing.T) {
+		c := h.Category().Create()
+		h.For(t).Post("/api/v1/notes", map[string]any{
+			"category_id": c.ID.String(),
+			"title":       "Free",
+			"metadata":    map[string]any{"anything": []string{"goes"}},
+		}).AssertStatus(http.StatusCreated)
+	})
+
+	t.Run("missing category", func(t *testing.T) {
+		h.For(t).Post("/api/v1/notes", map[string]any{"category_id": missingID, "title": "Lost"}).
+			AssertError(http.StatusNotFound, "category not found")
+	})
+}
+
+func TestCategoryMetadataSchema(t *testing.T) {
+	h := apitest.New(t)
+	categoryID := createSchemaCategory(h)
+
+	h.Post("/api/v1/categories", map[string]any{
+		"name":            "Broken",
+		"metadata_schema": map[string]any{"type": "nope"},
+	}).AssertStatus(http.StatusBadRequest)
+
+	// Renaming keeps the schema, null removes it
+	var got struct {
+		MetadataSchema map[string]any `json:"metadata_schema"`
+	}
+	h.Put("/api/v1/categories/"+categoryID, map[string]any{"name": "Chores"}).
+		AssertStatus(http.StatusOK).Decode(&got)
+	if got.MetadataSchema == nil {
+		t.Error("schema removed by rename")
+	}
+	h.Put("/api/v1/categories/"+categoryID, map[string]any{"name": "Chores", "metadata_schema": nil}).
+		AssertStatus(http.StatusOK)
+	h.Post("/api/v1/notes", map[string]any{
+		"category_id": categoryID,
+		"title":       "Anything",
+		"metadata":    map[string]any{"status": "maybe"},
+	}).AssertStatus(http.StatusCreated)
+}
+
+func TestListNotesByMetadata(t *testing.T) {
+	h := apitest.New(t)
+	categoryID := createSchemaCategory(h)
+	for _, n := range []struct {
+		title    string
+		metadata map[string]any
+	}{
+		{"Low", map[string]any{"status": "open", "priority": 1, "due": "2026-01-10"}},
+		{"High", map[string]any{"status": "open", "priority": 3, "due": "2026-03-01"}},
+		{"Done", map[string]any{"status": "closed", "priority": 5}},
+	} {
+		h.Post("/api/v1/notes", map[string]any{
+			"category_id": categoryID,
+			"title":       n.title,
+			"metadata":    n.metadata,
+		}).AssertStatus(http.StatusCreated)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"meta.status=open", []string{"High", "Low"}},
+		{"meta.status=open&meta.priority>=2", []string{"High"}},
+		{"meta.priority>3", []string{"Done"}},
+		{"meta.priority<=3", []string{"High", "Low"}},
+		{"meta.priority=5", []string{"Done"}},
+		{"meta.status!=open", []string{"Done"}},
+		{"meta.due<2026-02-01", []string{"Low"}},
+		{"meta.owner=alice", nil},
+		{"meta.status=open&limit=1", []string{"High"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			var notes []metadataNoteBody
+			h.For(t).Get("/api/v1/notes?" + tc.query).AssertStatus(http.StatusOK).Decode(&notes)
+			var got []string
+			for _, n := range notes {
+				got = append(got, n.Title)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+
+	h.Get("/api/v1/notes?meta.bad%20key=1").
+		AssertError(http.StatusBadRequest, `invalid metadata filter: invalid key "bad key"`)
+	h.Get("/api/v1/notes?meta.priority<>2=1").
+		AssertError(http.StatusBadRequest, "invalid metadata filter: priority<>2")
+}
@@ -1,31 +1,114 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
 )
 
-// Auth creates a middleware that validates Bearer token authentication.
-func Auth(token string) echo.MiddlewareFunc {
+// Authenticator resolves a bearer credential into the principal it represents.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+}
+
+// credentialErrors are authenticator errors reported to the client as 401.
+var credentialErrors = []error{
+	user.ErrInvalidKey,
+	user.ErrKeyExpired,
+	user.ErrKeyRevoked,
+	workspace.ErrNotMember,
+	ErrUnsupportedCredential,
+	ErrInvalidToken,
+	ErrTokenExpired,
+	ErrUnknownUser,
+	ErrUnknownCertificate,
+}
+
+// Auth creates a middleware that validates Bearer token authentication and
+// stores the resolved principal and its workspace in the request context.
+func Auth(authenticator Authenticator) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			auth := c.Request().Header.Get("Authorization")
-			if auth == "" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "missing authorization header")
+			ctx, err := Authenticate(c.Request().Context(), authenticator, c.Request().Header.Get("Authorization"))
+			if err != nil {
+				return err
 			}
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
 
-			parts := strings.SplitN(auth, " ", 2)
-			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
-			}
+// Authenticate resolves an Authorization header into a context carrying the
+// principal and its workspace, which are also added to its log fields. It
+// is shared by the REST and RPC APIs, so failures are reported as
+// *echo.HTTPError for callers to translate. Requests with a verified client
+// certificate may omit the header, leaving the authenticator an empty
+// token.
+func Authenticate(ctx context.Context, authenticator Authenticator, header string) (context.Context, *echo.HTTPError) {
+	var token string
+	if header != "" {
+		parts := strings.SplitN(header, " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
+		}
+		token = parts[1]
+	} else if _, ok := ClientCertificateFrom(ctx); !ok {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "missing authorization header")
+	}
 
-			if parts[1] != token {
-				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+	principal, err := authenticator.Authenticate(ctx, token)
+	if err != nil {
+		for _, credErr := range credentialErrors {
+			if errors.Is(err, credErr) {
+				return nil, echo.NewHTTPError(http.StatusUnauthorized, credErr.Error())
 			}
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to authenticate").SetInternal(err)
+	}
+
+	ctx = auth.WithPrincipal(ctx, principal)
+	ctx = tenant.WithWorkspace(ctx, principal.WorkspaceID)
+	ctx = logger.WithFields(ctx, zap.Dict("principal",
+		zap.String("user_id", principal.UserID.String()),
+		zap.String("workspace_id", principal.WorkspaceID.String()),
+	))
+	return ctx, nil
+}
 
+// RequireScope creates a middleware that rejects principals lacking any of
+// the given scopes. It must run after Auth.
+func RequireScope(scopes ...auth.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := CheckScopes(c.Request().Context(), scopes...); err != nil {
+				return err
+			}
 			return next(c)
 		}
 	}
 }
+
+// CheckScopes reports an error unless the principal in ctx holds every one
+// of scopes.
+func CheckScopes(ctx context.Context, scopes ...auth.Scope) *echo.HTTPError {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "not authenticated")
+	}
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			return echo.NewHTTPError(http.StatusForbidden, "missing scope "+string(scope))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownCertificate is returned for verified client certificates whose
+// subject is not mapped to a principal.
+var ErrUnknownCertificate = errors.New("unknown client certificate")
+
+type clientCertificateKey struct{}
+
+// ClientCertificate creates a middleware that stores the verified client
+// certificate of TLS requests in the request context, for
+// CertificateAuthenticator.
+func ClientCertificate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			state := c.Request().TLS
+			if state != nil && len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+				ctx := context.WithValue(c.Request().Context(), clientCertificateKey{}, state.VerifiedChains[0][0])
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+			return next(c)
+		}
+	}
+}
+
+// ClientCertificateFrom returns the verified client certificate stored in
+// ctx, if any.
+func ClientCertificateFrom(ctx context.Context) (*x509.Certificate, bool) {
+	cert, ok := ctx.Value(clientCertificateKey{}).(*x509.Certificate)
+	return cert, ok
+}
+
+// CertificatePrincipal is the principal a client certificate subject maps
+// to. Without scopes the client gets every scope its workspace role
+// permits.
+type CertificatePrincipal struct {
+	Email       string   `yaml:"email"`
+	WorkspaceID string   `yaml:"workspace_id"`
+	Scopes      []string `yaml:"scopes"`
+}
+
+// LoadCertificatePrincipals reads a YAML file mapping client certificate
+// subjects, in the RFC 2253 form such as "CN=ci,O=Example", to principals.
+func LoadCertificatePrincipals(path string) (map[string]CertificatePrincipal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var principals map[string]CertificatePrincipal
+	if err := yaml.Unmarshal(data, &principals); err != nil {
+		return nil, fmt.Errorf("invalid client certificate principals: %w", err)
+	}
+	return principals, nil
+}
+
+// certificatePrincipal is a validated CertificatePrincipal.
+type certificatePrincipal struct {
+	email       string
+	workspaceID workspace.ID
+	scopes      []auth.Scope
+}
+
+// CertificateAuthenticator authenticates requests made without a bearer
+// credential by their verified client certificate, mapping its subject to
+// a local user acting in a workspace. Place it first in a Chain: it passes
+// on every request that carries a token.
+type CertificateAuthenticator struct {
+	principals map[string]certificatePrincipal
+	users      UserLookup
+}
+
+// NewCertificateAuthenticator creates a CertificateAuthenticator for the
+// given subjects.
+func NewCertificateAuthenticator(principals map[string]CertificatePrincipal, users UserLookup) (*CertificateAuthenticator, error) {
+	a := &CertificateAuthenticator{principals: make(map[string]certificatePrincipal, len(principals)), users: users}
+	for subject, p := range principals {
+		if p.Email == "" {
+			return nil, fmt.Errorf("client certificate %q: email is required", subject)
+		}
+		workspaceID, err := workspace.ParseID(p.WorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate %q: invalid workspace_id", subject)
+		}
+		scopes := auth.AllScopes
+		if p.Scopes != nil {
+			if scopes, err = auth.ParseScopes(p.Scopes); err != nil {
+				return nil, fmt.Errorf("client certificate %q: %w", subject, err)
+			}
+		}
+		a.principals[subject] = certificatePrincipal{email: p.Email, workspaceID: workspaceID, scopes: scopes}
+	}
+	return a, nil
+}
+
+func (a *CertificateAuthenticator) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	cert, ok := ClientCertificateFrom(ctx)
+	if token != "" || !ok {
+		return auth.Principal{}, ErrUnsupportedCredential
+	}
+
+	p, ok := a.principals[cert.Subject.String()]
+	if !ok {
+		return auth.Principal{}, ErrUnknownCertificate
+	}
+	u, err := a.users.GetByEmail(ctx, p.email)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return auth.Principal{}, ErrUnknownUser
+		}
+		return auth.Principal{}, err
+	}
+
+	return auth.Principal{
+		UserID:      u.ID,
+		WorkspaceID: p.workspaceID,
+		Scopes:      p.scopes,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/idempotency"
+)
+
+const (
+	// HeaderIdempotencyKey carries the client's key for an unsafe request.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks responses replayed from a stored key.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+const (
+	// maxIdempotencyKeyLength bounds the length of idempotency keys.
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize bounds the request bodies read to fingerprint
+	// requests.
+	maxIdempotentBodySize = 32 << 20
+)
+
+// Idempotency creates a middleware that makes unsafe requests carrying an
+// Idempotency-Key header safe to retry. The first response to a key is
+// stored and replayed for retries of the same request; server errors are
+// not stored, so such requests may be retried. Keys are scoped to the
+// principal, so the middleware must run after Auth.
+func Idempotency(service *idempotency.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" || safeMethod(c.Request().Method) {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return echo.NewHTTPError(http.StatusBadRequest, "idempotency key is too long")
+			}
+			principal, ok := auth.PrincipalFrom(c.Request().Context())
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "not authenticated")
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxIdempotentBodySize))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "request body too large")
+				}
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			req := idempotency.Request{
+				UserID:      principal.UserID,
+				Key:         key,
+				Fingerprint: idempotency.Fingerprint(c.Request().Method, c.Request().URL.RequestURI(), body),
+			}
+			ctx := c.Request().Context()
+			claim, stored, err := service.Begin(ctx, req)
+			switch {
+			case errors.Is(err, idempotency.ErrInFlight):
+				return echo.NewHTTPError(http.StatusConflict, err.Error())
+			case errors.Is(err, idempotency.ErrKeyReused):
+				return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+			case err != nil:
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to check idempotency key").SetInternal(err)
+			case stored != nil:
+				return replay(c, stored)
+			}
+
+			res := c.Response()
+			rec := &responseRecorder{ResponseWriter: res.Writer}
+			res.Writer = rec
+			// The error is handled here to record the response, and still
+			// returned for outer middleware to log
+			handlerErr := next(c)
+			if handlerErr != nil {
+				c.Error(handlerErr)
+			}
+			res.Writer = rec.ResponseWriter
+
+			// Store the response even if the client has gone away
+			ctx = context.WithoutCancel(ctx)
+			if !res.Committed || res.Status >= http.StatusInternalServerError {
+				_ = service.Abandon(ctx, claim)
+				return handlerErr
+			}
+			header := res.Header().Clone()
+			header.Del(echo.HeaderXRequestID)
+			err = service.Complete(ctx, claim, idempotency.Response{
+				StatusCode: res.Status,
+				Header:     header,
+				Body:       rec.body.Bytes(),
+			})
+			// Release a key that could not be completed, so retries are
+			// handled instead of conflicting until it expires, unless it
+			// belongs to another request by now
+			if err != nil && !errors.Is(err, idempotency.ErrClaimLost) {
+				_ = service.Abandon(ctx, claim)
+			}
+			return handlerErr
+		}
+	}
+}
+
+// safeMethod reports whether requests with method have no effects.
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// replay writes a stored response.
+func replay(c echo.Context, stored *idempotency.Response) error {
+	header := c.Response().Header()
+	for k, v := range stored.Header {
+		header[k] = v
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+	c.Response().WriteHeader(stored.StatusCode)
+	_, err := c.Response().Write(stored.Body)
+	return err
+}
+
+// responseRecorder copies the body written to a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
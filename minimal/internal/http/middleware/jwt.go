@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/httprc/v3"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+)
+
+var (
+	// ErrUnsupportedCredential is returned by authenticators that do not
+	// recognise the form of a credential, so that Chain tries the next one.
+	ErrUnsupportedCredential = errors.New("unsupported credential")
+	ErrInvalidToken          = errors.New("invalid token")
+	ErrTokenExpired          = errors.New("token expired")
+	ErrUnknownUser           = errors.New("unknown user")
+)
+
+// jwtAlgorithms are the signature algorithms accepted on JWTs.
+var jwtAlgorithms = []jwa.SignatureAlgorithm{jwa.RS256(), jwa.EdDSA()}
+
+// JWTConfig configures validation of JWTs issued by an external
+// identity provider such as better-auth.
+type JWTConfig struct {
+	// JWKSURL is where the provider publishes its signing keys.
+	JWKSURL  string
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated on exp, nbf and iat.
+	Leeway time.Duration
+	// RefreshInterval is the longest the key set is cached before it is
+	// fetched again. Defaults to 15 minutes.
+	RefreshInterval time.Duration
+}
+
+// UserLookup finds the local user a token's email claim refers to.
+type UserLookup interface {
+	GetByEmail(ctx context.Context, email string) (user.User, error)
+}
+
+// JWTAuthenticator authenticates RS256 and EdDSA signed JWTs against a
+// JWKS endpoint that is refreshed in the background.
+//
+// The email claim selects the local user and the workspace_id claim the
+// workspace the caller acts in. A space separated scope claim limits the
+// caller's scopes; without one the caller gets every scope its workspace
+// role permits.
+type JWTAuthenticator struct {
+	cfg   JWTConfig
+	keys  jwk.Set
+	users UserLookup
+}
+
+// NewJWTAuthenticator fetches the key set and keeps it fresh until ctx is
+// cancelled.
+func NewJWTAuthenticator(ctx context.Context, cfg JWTConfig, users UserLookup) (*JWTAuthenticator, error) {
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = 15 * time.Minute
+	}
+
+	cache, err := jwk.NewCache(ctx, httprc.NewClient())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS cache: %w", err)
+	}
+	if err := cache.Register(ctx, cfg.JWKSURL,
+		jwk.WithMinInterval(min(time.Minute, cfg.RefreshInterval)),
+		jwk.WithMaxInterval(cfg.RefreshInterval),
+	); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys, err := cache.CachedSet(cfg.JWKSURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JWKS: %w", err)
+	}
+
+	return &JWTAuthenticator{cfg: cfg, keys: keys, users: users}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	if strings.Count(token, ".") != 2 {
+		return auth.Principal{}, ErrUnsupportedCredential
+	}
+
+	if err := checkAlgorithm(token); err != nil {
+		return auth.Principal{}, err
+	}
+
+	t, err := jwt.Parse([]byte(token),
+		jwt.WithKeySet(a.keys),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(a.cfg.Issuer),
+		jwt.WithAudience(a.cfg.Audience),
+		jwt.WithAcceptableSkew(a.cfg.Leeway),
+		jwt.WithContext(ctx),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.TokenExpiredError()) {
+			return auth.Principal{}, ErrTokenExpired
+		}
+		return auth.Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return a.principal(ctx, t)
+}
+
+// principal maps the claims of a verified token to a principal.
+func (a *JWTAuthenticator) principal(ctx context.Context, t jwt.Token) (auth.Principal, error) {
+	var email, wsID, scope string
+	if err := t.Get("email", &email); err != nil || email == "" {
+		return auth.Principal{}, fmt.Errorf("%w: missing email claim", ErrInvalidToken)
+	}
+	if err := t.Get("workspace_id", &wsID); err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: missing workspace_id claim", ErrInvalidToken)
+	}
+	workspaceID, err := workspace.ParseID(wsID)
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: invalid workspace_id claim", ErrInvalidToken)
+	}
+
+	scopes := auth.AllScopes
+	if t.Has("scope") {
+		if err := t.Get("scope", &scope); err != nil {
+			return auth.Principal{}, fmt.Errorf("%w: invalid scope claim", ErrInvalidToken)
+		}
+		scopes = knownScopes(strings.Fields(scope))
+	}
+
+	u, err := a.users.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return auth.Principal{}, ErrUnknownUser
+		}
+		return auth.Principal{}, err
+	}
+
+	return auth.Principal{
+		UserID:      u.ID,
+		WorkspaceID: workspaceID,
+		Scopes:      scopes,
+	}, nil
+}
+
+// checkAlgorithm rejects tokens not signed with one of jwtAlgorithms before
+// any key is looked up.
+func checkAlgorithm(token string) error {
+	msg, err := jws.Parse([]byte(token))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	for _, sig := range msg.Signatures() {
+		alg, ok := sig.ProtectedHeaders().Algorithm()
+		if !ok || !slices.Contains(jwtAlgorithms, alg) {
+			return fmt.Errorf("%w: unsupported algorithm", ErrInvalidToken)
+		}
+	}
+	return nil
+}
+
+// knownScopes returns the scopes in s this API defines, ignoring ones the
+// identity provider issues for other audiences.
+func knownScopes(s []string) []auth.Scope {
+	scopes := make([]auth.Scope, 0, len(s))
+	for _, v := range s {
+		if scope, err := auth.ParseScope(v); err == nil {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(ctx context.Context, token string) (auth.Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	return f(ctx, token)
+}
+
+// Chain returns an Authenticator that tries authenticators in order and
+// uses the first one that recognises the credential.
+func Chain(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, token string) (auth.Principal, error) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(ctx, token)
+			if errors.Is(err, ErrUnsupportedCredential) {
+				continue
+			}
+			return p, err
+		}
+		return auth.Principal{}, ErrUnsupportedCredential
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"go.uber.org/zap"
+)
+
+// traceparentHeader carries the W3C trace context of a request.
+const traceparentHeader = "Traceparent"
+
+// LogFields creates a middleware that adds the request ID, route and trace
+// ID of a request to the fields logged through its context. It must run
+// after the request ID middleware. Auth adds the principal.
+func LogFields() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			fields := []zap.Field{
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)),
+			}
+			if route := c.Path(); route != "" {
+				fields = append(fields, zap.String("route", route))
+			}
+			if traceID, ok := parseTraceID(c.Request().Header.Get(traceparentHeader)); ok {
+				fields = append(fields, zap.String("trace_id", traceID))
+			}
+			ctx := logger.WithFields(c.Request().Context(), fields...)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// parseTraceID returns the trace ID of a traceparent header, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func parseTraceID(traceparent string) (string, bool) {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", false
+	}
+	traceID := parts[1]
+	if strings.Trim(traceID, "0") == "" || strings.Trim(traceID, "0123456789abcdef") != "" {
+		return "", false
+	}
+	return traceID, true
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+)
+
+// Origin creates a middleware that stores the request ID and client IP in
+// the request context for audit events. It must run after the request ID
+// middleware.
+func Origin() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := audit.WithOrigin(c.Request().Context(), audit.Origin{
+				RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+				ClientIP:  c.RealIP(),
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
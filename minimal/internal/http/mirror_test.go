@@ -0,0 +1,120 @@
+package http_test
+
+import (
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+// mirrorLog waits until the git mirror in dir has n commits and returns
+// them newest first as "author <email> | subject" lines.
+func mirrorLog(t *testing.T, dir string, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := exec.Command("git", "-C", dir, "log", "--format=%an <%ae> | %s").Output()
+		var lines []string
+		if err == nil {
+			lines = strings.Split(strings.TrimSpace(string(out)), "\n")
+		}
+		if len(lines) >= n {
+			return lines
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("mirror has %d commits, want %d: %v", len(lines), n, lines)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMirrorCommitsChanges(t *testing.T) {
+	dir := t.TempDir()
+	h := apitest.New(t, apitest.WithMirror(dir))
+	root := filepath.Join(dir, h.Workspace.ID.String())
+
+	var c categoryBody
+	h.Post("/api/v1/categories", map[string]string{"name": "Work"}).AssertStatus(http.StatusCreated).Decode(&c)
+	mirrorLog(t, dir, 1)
+
+	var n noteBody
+	h.Post("/api/v1/notes", map[string]string{
+		"category_id": c.ID,
+		"title":       "Plan",
+		"content":     "Ship it.",
+	}).AssertStatus(http.StatusCreated).Decode(&n)
+	mirrorLog(t, dir, 2)
+
+	file := filepath.Join(root, "work", "plan-"+n.ID[:8]+".md")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read mirrored note: %v", err)
+	}
+	for _, want := range []string{"id: " + n.ID, "category: Work", "title: Plan", "---\nShip it."} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("mirrored note misses %q:\n%s", want, data)
+		}
+	}
+
+	h.Put("/api/v1/notes/"+n.ID, map[string]string{
+		"category_id": c.ID,
+		"title":       "Plan",
+		"content":     "Shipped.",
+	}).AssertStatus(http.StatusOK)
+	mirrorLog(t, dir, 3)
+	h.Delete("/api/v1/notes/" + n.ID).AssertStatus(http.StatusNoContent)
+
+	got := mirrorLog(t, dir, 4)
+	want := []string{
+		`Test <test@example.com> | Delete note "Plan"`,
+		`Test <test@example.com> | Update note ` + n.ID,
+		`Test <test@example.com> | Create note "Plan"`,
+		`Test <test@example.com> | Create category "Work"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("log =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("deleted note still mirrored: %v", err)
+	}
+}
+
+func TestMirrorResync(t *testing.T) {
+	dir := t.TempDir()
+	h := apitest.New(t, apitest.WithMirror(dir))
+	h.Post("/api/v1/categories", map[string]string{"name": "Work"}).AssertStatus(http.StatusCreated)
+	mirrorLog(t, dir, 1)
+
+	// Commit a change made behind the mirror's back
+	index := filepath.Join(dir, h.Workspace.ID.String(), "categories.yaml")
+	if err := os.WriteFile(index, []byte("[]\n"), 0o644); err != nil {
+		t.Fatalf("write mirrored file: %v", err)
+	}
+	cmd := exec.Command("git", "-C", dir, "-c", "user.name=Someone", "-c", "user.email=someone@example.com",
+		"commit", "--quiet", "--all", "--message", "Drift")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("commit drift: %v: %s", err, out)
+	}
+
+	h.Post("/api/v1/mirror/resync", nil).AssertStatus(http.StatusAccepted)
+	if got := mirrorLog(t, dir, 3)[0]; got != "Test <test@example.com> | Resync" {
+		t.Errorf("resync commit = %q", got)
+	}
+	if data, _ := os.ReadFile(index); !strings.Contains(string(data), "name: Work") {
+		t.Errorf("resync did not restore categories.yaml:\n%s", data)
+	}
+
+	writer := h.Key(auth.ScopeNotesRead, auth.ScopeNotesWrite)
+	h.Post("/api/v1/mirror/resync", nil, apitest.WithToken(writer)).AssertStatus(http.StatusForbidden)
+}
+
+func TestMirrorResyncDisabled(t *testing.T) {
+	h := apitest.New(t)
+	h.Post("/api/v1/mirror/resync", nil).AssertError(http.StatusNotFound, "git mirror is not enabled")
+}
@@ -0,0 +1,155 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+func TestCreateNote(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+
+	h.Post("/api/v1/notes", map[string]string{
+		"category_id": c.ID.String(),
+		"title":       "My First Note",
+		"content":     "This is the content of my first note.",
+	}).
+		AssertStatus(http.StatusCreated).
+		AssertGolden()
+}
+
+func TestCreateNoteValidation(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+
+	h.Post("/api/v1/notes", map[string]string{"category_id": c.ID.String()}).
+		AssertError(http.StatusBadRequest, "title is required")
+	h.Post("/api/v1/notes", map[string]string{"category_id": "invalid", "title": "Test"}).
+		AssertError(http.StatusBadRequest, "invalid category_id")
+	h.Post("/api/v1/notes", map[string]string{"title": "Test"}).
+		AssertError(http.StatusBadRequest, "invalid category_id")
+	h.Post("/api/v1/notes", `[]`).
+		AssertError(http.StatusBadRequest, "invalid request body")
+}
+
+func TestListNotes(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Get("/api/v1/notes").
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+
+	work := h.Category().Named("Work").Create()
+	personal := h.Category().Named("Personal").Create()
+	h.Note().InCategory(work).Titled("Work Note").Create()
+	h.Note().InCategory(personal).Titled("Personal Note").Create()
+
+	t.Run("all", func(t *testing.T) {
+		h.For(t).Get("/api/v1/notes").
+			AssertStatus(http.StatusOK).
+			AssertGolden()
+	})
+
+	t.Run("by category", func(t *testing.T) {
+		h.For(t).Get("/api/v1/notes?category_id=" + work.ID.String()).
+			AssertStatus(http.StatusOK).
+			AssertGolden()
+	})
+
+	t.Run("empty category", func(t *testing.T) {
+		h.For(t).Get("/api/v1/notes?category_id=" + missingID).
+			AssertStatus(http.StatusOK).
+			AssertGolden()
+	})
+
+	t.Run("invalid category", func(t *testing.T) {
+		h.For(t).Get("/api/v1/notes?category_id=invalid").
+			AssertError(http.StatusBadRequest, "invalid category_id")
+	})
+}
+
+func TestListNotesPaginated(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	for range 3 {
+		h.Note().InCategory(c).Create()
+	}
+
+	first := h.Get("/api/v1/notes?limit=2").AssertStatus(http.StatusOK)
+	var page []map[string]any
+	first.Decode(&page)
+	cursor := first.Header.Get("X-Next-Cursor")
+	if len(page) != 2 || cursor == "" {
+		t.Fatalf("first page has %d notes and cursor %q", len(page), cursor)
+	}
+
+	last := h.Get("/api/v1/notes?limit=2&after=" + cursor).AssertStatus(http.StatusOK)
+	last.Decode(&page)
+	if len(page) != 1 || last.Header.Get("X-Next-Cursor") != "" {
+		t.Fatalf("last page has %d notes and cursor %q", len(page), last.Header.Get("X-Next-Cursor"))
+	}
+
+	h.Get("/api/v1/notes?limit=0").
+		AssertError(http.StatusBadRequest, "limit must be between 1 and 100")
+	h.Get("/api/v1/notes?limit=2&after=nope").
+		AssertError(http.StatusBadRequest, "invalid after cursor")
+}
+
+func TestGetNote(t *testing.T) {
+	h := apitest.New(t)
+	n := h.Note().Titled("Shopping").WithContent("Milk, eggs").Create()
+
+	h.Get("/api/v1/notes/" + n.ID.String()).
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+	h.Get("/api/v1/notes/"+missingID).
+		AssertError(http.StatusNotFound, "note not found")
+	h.Get("/api/v1/notes/not-a-uuid").
+		AssertError(http.StatusBadRequest, "invalid note id")
+}
+
+func TestUpdateNote(t *testing.T) {
+	h := apitest.New(t)
+	n := h.Note().Create()
+	other := h.Category().Named("Other").Create()
+
+	h.Put("/api/v1/notes/"+n.ID.String(), map[string]string{
+		"category_id": other.ID.String(),
+		"title":       "Updated Note Title",
+		"content":     "Updated content here.",
+	}).
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+}
+
+func TestUpdateNoteErrors(t *testing.T) {
+	h := apitest.New(t)
+	n := h.Note().Create()
+	path := "/api/v1/notes/" + n.ID.String()
+	valid := map[string]string{"category_id": n.CategoryID.String(), "title": "Title"}
+
+	h.Put(path, map[string]string{"category_id": n.CategoryID.String()}).
+		AssertError(http.StatusBadRequest, "title is required")
+	h.Put(path, map[string]string{"category_id": "invalid", "title": "Title"}).
+		AssertError(http.StatusBadRequest, "invalid category_id")
+	h.Put(path, `{`).
+		AssertError(http.StatusBadRequest, "invalid request body")
+	h.Put("/api/v1/notes/not-a-uuid", valid).
+		AssertError(http.StatusBadRequest, "invalid note id")
+	h.Put("/api/v1/notes/"+missingID, valid).
+		AssertError(http.StatusNotFound, "note not found")
+}
+
+func TestDeleteNote(t *testing.T) {
+	h := apitest.New(t)
+	n := h.Note().Create()
+	path := "/api/v1/notes/" + n.ID.String()
+
+	h.Delete(path).AssertStatus(http.StatusNoContent)
+	h.Get(path).AssertError(http.StatusNotFound, "note not found")
+	h.Delete(path).AssertError(http.StatusNotFound, "note not found")
+	h.Delete("/api/v1/notes/not-a-uuid").
+		AssertError(http.StatusBadRequest, "invalid note id")
+}
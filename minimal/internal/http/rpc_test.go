@@ -0,0 +1,174 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpcreflect"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	notesv1 "github.com/piotmni/go-mini-templates/minimal/internal/gen/notes/v1"
+	"github.com/piotmni/go-mini-templates/minimal/internal/gen/notes/v1/notesv1connect"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// bearer returns a client option authenticating every call with token.
+func bearer(token string) connect.ClientOption {
+	return connect.WithInterceptors(connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if token != "" {
+				req.Header().Set("Authorization", "Bearer "+token)
+			}
+			return next(ctx, req)
+		}
+	}))
+}
+
+// assertCode fails t unless err is a Connect error with code and message.
+func assertCode(t *testing.T, err error, code connect.Code, message string) {
+	t.Helper()
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("error = %v, want %s %q", err, code, message)
+	}
+	if connectErr.Code() != code || connectErr.Message() != message {
+		t.Fatalf("error = %s %q, want %s %q", connectErr.Code(), connectErr.Message(), code, message)
+	}
+}
+
+func TestRPCNotes(t *testing.T) {
+	h := apitest.New(t)
+	ctx := t.Context()
+	categories := notesv1connect.NewCategoryServiceClient(http.DefaultClient, h.URL(), bearer(h.Token))
+	notes := notesv1connect.NewNoteServiceClient(http.DefaultClient, h.URL(), bearer(h.Token))
+
+	c, err := categories.CreateCategory(ctx, connect.NewRequest(&notesv1.CreateCategoryRequest{Name: "Work"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	categoryID := c.Msg.Category.Id
+
+	created, err := notes.CreateNote(ctx, connect.NewRequest(&notesv1.CreateNoteRequest{
+		CategoryId: categoryID,
+		Title:      "Plan",
+		Content:    "Ship it",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noteID := created.Msg.Note.Id
+
+	updated, err := notes.UpdateNote(ctx, connect.NewRequest(&notesv1.UpdateNoteRequest{
+		Id:         noteID,
+		CategoryId: categoryID,
+		Title:      "Plan v2",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Msg.Note.Title != "Plan v2" {
+		t.Fatalf("title = %q, want Plan v2", updated.Msg.Note.Title)
+	}
+
+	// Both APIs share the same services
+	h.Get("/api/v1/notes/" + noteID).AssertStatus(http.StatusOK)
+
+	list, err := notes.ListNotes(ctx, connect.NewRequest(&notesv1.ListNotesRequest{CategoryId: categoryID}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Msg.Notes) != 1 {
+		t.Fatalf("got %d notes, want 1", len(list.Msg.Notes))
+	}
+
+	if _, err := notes.DeleteNote(ctx, connect.NewRequest(&notesv1.DeleteNoteRequest{Id: noteID})); err != nil {
+		t.Fatal(err)
+	}
+	_, err = notes.GetNote(ctx, connect.NewRequest(&notesv1.GetNoteRequest{Id: noteID}))
+	assertCode(t, err, connect.CodeNotFound, "note not found")
+}
+
+func TestRPCErrors(t *testing.T) {
+	h := apitest.New(t)
+	ctx := t.Context()
+	h.Category().Named("Taken").Create()
+
+	categories := notesv1connect.NewCategoryServiceClient(http.DefaultClient, h.URL(), bearer(h.Token))
+
+	_, err := categories.CreateCategory(ctx, connect.NewRequest(&notesv1.CreateCategoryRequest{}))
+	assertCode(t, err, connect.CodeInvalidArgument, "name is required")
+	_, err = categories.CreateCategory(ctx, connect.NewRequest(&notesv1.CreateCategoryRequest{Name: "Taken"}))
+	assertCode(t, err, connect.CodeAlreadyExists, "category already exists")
+	_, err = categories.GetCategory(ctx, connect.NewRequest(&notesv1.GetCategoryRequest{Id: "nope"}))
+	assertCode(t, err, connect.CodeInvalidArgument, "invalid category id")
+	_, err = categories.GetCategory(ctx, connect.NewRequest(&notesv1.GetCategoryRequest{Id: missingID}))
+	assertCode(t, err, connect.CodeNotFound, "category not found")
+
+	// Moving a note without metadata to a category that requires some
+	notes := notesv1connect.NewNoteServiceClient(http.DefaultClient, h.URL(), bearer(h.Token))
+	n := h.Note().Create()
+	_, err = notes.UpdateNote(ctx, connect.NewRequest(&notesv1.UpdateNoteRequest{
+		Id: n.ID.String(), CategoryId: createSchemaCategory(h), Title: n.Title,
+	}))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument || !strings.Contains(err.Error(), "invalid metadata: ") {
+		t.Errorf("error = %v, want invalid argument invalid metadata", err)
+	}
+
+	anonymous := notesv1connect.NewCategoryServiceClient(http.DefaultClient, h.URL(), bearer(""))
+	_, err = anonymous.ListCategories(ctx, connect.NewRequest(&notesv1.ListCategoriesRequest{}))
+	assertCode(t, err, connect.CodeUnauthenticated, "missing authorization header")
+
+	reader := notesv1connect.NewCategoryServiceClient(http.DefaultClient, h.URL(), bearer(h.Key(auth.ScopeNotesRead)))
+	if _, err := reader.ListCategories(ctx, connect.NewRequest(&notesv1.ListCategoriesRequest{})); err != nil {
+		t.Fatal(err)
+	}
+	_, err = reader.CreateCategory(ctx, connect.NewRequest(&notesv1.CreateCategoryRequest{Name: "New"}))
+	assertCode(t, err, connect.CodePermissionDenied, "missing scope categories:admin")
+}
+
+func TestRPCOverGRPC(t *testing.T) {
+	h := apitest.New(t)
+	h.Note().Create()
+
+	notes := notesv1connect.NewNoteServiceClient(h.H2CClient(), h.URL(), connect.WithGRPC(), bearer(h.Token))
+	list, err := notes.ListNotes(t.Context(), connect.NewRequest(&notesv1.ListNotesRequest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Msg.Notes) != 1 {
+		t.Fatalf("got %d notes, want 1", len(list.Msg.Notes))
+	}
+
+	_, err = notes.GetNote(t.Context(), connect.NewRequest(&notesv1.GetNoteRequest{Id: missingID}))
+	assertCode(t, err, connect.CodeNotFound, "note not found")
+}
+
+func TestRPCReflection(t *testing.T) {
+	h := apitest.New(t)
+	client := grpcreflect.NewClient(h.H2CClient(), h.URL())
+
+	anonymous := client.NewStream(t.Context())
+	defer anonymous.Close()
+	_, err := anonymous.ListServices()
+	assertCode(t, err, connect.CodeUnauthenticated, "missing authorization header")
+
+	stream := client.NewStream(t.Context(), grpcreflect.WithRequestHeaders(http.Header{
+		"Authorization": []string{"Bearer " + h.Token},
+	}))
+	defer stream.Close()
+
+	services, err := stream.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{notesv1connect.CategoryServiceName, notesv1connect.NoteServiceName} {
+		if !slices.Contains(services, protoreflect.FullName(name)) {
+			t.Errorf("reflection services = %v, missing %s", services, name)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package http_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+type savedSearchBody struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+}
+
+// runSavedSearch returns the sorted titles of the notes a saved search
+// matches.
+func runSavedSearch(t *testing.T, h *apitest.Harness, id string) []string {
+	t.Helper()
+	var notes []noteBody
+	h.For(t).Get("/api/v1/saved-searches/" + id + "/notes").AssertStatus(http.StatusOK).Decode(&notes)
+	titles := make([]string, len(notes))
+	for i, n := range notes {
+		titles[i] = n.Title
+	}
+	slices.Sort(titles)
+	return titles
+}
+
+func TestSavedSearchFilters(t *testing.T) {
+	h := apitest.New(t)
+	work := h.Category().Named("Work").Create()
+	home := h.Category().Named("Home").Create()
+	h.Note().InCategory(work).Titled("Standup meeting").WithContent("Daily sync.").
+		WithMetadata(map[string]any{"tags": []any{"urgent", "team"}}).Create()
+	h.Note().InCategory(work).Titled("Roadmap").WithContent("Plan the next MEETING.").
+		WithMetadata(map[string]any{"tags": []any{"team"}}).Create()
+	h.Note().InCategory(home).Titled("Groceries").WithContent("Milk, eggs.").
+		WithMetadata(map[string]any{"tags": []any{"urgent"}}).Create()
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{`title ~ meeting`, []string{"Standup meeting"}},
+		{`title = "roadmap"`, []string{"Roadmap"}},
+		{`content ~ "meeting"`, []string{"Roadmap"}},
+		{`content !~ meeting`, []string{"Groceries", "Standup meeting"}},
+		{`category = Work`, []string{"Roadmap", "Standup meeting"}},
+		{`category != Work`, []string{"Groceries"}},
+		{`category = ` + home.ID.String(), []string{"Groceries"}},
+		{`category = Nowhere`, []string{}},
+		{`tag = urgent`, []string{"Groceries", "Standup meeting"}},
+		{`tags = team AND NOT tag = urgent`, []string{"Roadmap"}},
+		{`tag = urgent category = Work`, []string{"Standup meeting"}},
+		{`title ~ roadmap OR (category = Home AND tag = urgent)`, []string{"Groceries", "Roadmap"}},
+		{`created = today`, []string{"Groceries", "Roadmap", "Standup meeting"}},
+		{`created >= -7d AND updated < today`, []string{}},
+		{`created > 2000-01-01 and created <= today`, []string{"Groceries", "Roadmap", "Standup meeting"}},
+		{`created < 2000-01-01`, []string{}},
+	}
+	for i, tc := range tests {
+		var s savedSearchBody
+		h.For(t).Post("/api/v1/saved-searches", map[string]string{
+			"name":   "search " + string(rune('a'+i)),
+			"filter": tc.filter,
+		}).AssertStatus(http.StatusCreated).Decode(&s)
+		if got := runSavedSearch(t, h, s.ID); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.filter, got, tc.want)
+		}
+	}
+}
+
+func TestSavedSearchInvalidFilters(t *testing.T) {
+	h := apitest.New(t)
+
+	tests := []struct {
+		filter, message string
+	}{
+		{`author = me`, `invalid filter: unknown field "author" at offset 0`},
+		{`content = x`, `invalid filter: operator = is not supported by content at offset 8`},
+		{`created >= yesterday`, `invalid filter: invalid date "yesterday", want YYYY-MM-DD, today or -<n>d at offset 11`},
+		{`title ~`, `invalid filter: expected a value after title ~, got end of expression at offset 7`},
+		{`(tag = a`, `invalid filter: expected ")", got end of expression at offset 8`},
+		{`tag = a OR`, `invalid filter: expected a field, got end of expression at offset 10`},
+		{`title ~ "open`, `invalid filter: unterminated string at offset 8`},
+		{`tag = a)`, `invalid filter: unexpected ")" at offset 7`},
+		{`   `, `invalid filter: empty expression`},
+	}
+	for _, tc := range tests {
+		h.For(t).Post("/api/v1/saved-searches", map[string]string{"name": "bad", "filter": tc.filter}).
+			AssertError(http.StatusBadRequest, tc.message)
+	}
+	h.Post("/api/v1/saved-searches", map[string]string{"name": "no filter"}).
+		AssertError(http.StatusBadRequest, "filter is required")
+}
+
+func TestSavedSearchCRUD(t *testing.T) {
+	h := apitest.New(t)
+
+	var s savedSearchBody
+	h.Post("/api/v1/saved-searches", map[string]string{"name": "Urgent", "filter": "tag = urgent"}).
+		AssertStatus(http.StatusCreated).Decode(&s)
+	h.Post("/api/v1/saved-searches", map[string]string{"name": "Urgent", "filter": "tag = later"}).
+		AssertError(http.StatusConflict, "saved search already exists")
+
+	var updated savedSearchBody
+	h.Put("/api/v1/saved-searches/"+s.ID, map[string]string{"name": "Hot", "filter": `tag = urgent OR tag = hot`}).
+		AssertStatus(http.StatusOK).Decode(&updated)
+	if updated.Name != "Hot" || updated.Filter != `tag = urgent OR tag = hot` {
+		t.Errorf("updated = %+v", updated)
+	}
+	h.Put("/api/v1/saved-searches/"+s.ID, map[string]string{"name": "Hot", "filter": `tag urgent`}).
+		AssertStatus(http.StatusBadRequest)
+
+	var all []savedSearchBody
+	h.Get("/api/v1/saved-searches").AssertStatus(http.StatusOK).Decode(&all)
+	if len(all) != 1 || all[0].ID != s.ID {
+		t.Errorf("saved searches = %+v, want the one created", all)
+	}
+
+	// Saved searches are private to their workspace
+	other := h.InNewWorkspace("Other")
+	other.Get("/api/v1/saved-searches/"+s.ID).AssertError(http.StatusNotFound, "saved search not found")
+	other.Get("/api/v1/saved-searches/"+s.ID+"/notes").AssertError(http.StatusNotFound, "saved search not found")
+
+	h.Delete("/api/v1/saved-searches/" + s.ID).AssertStatus(http.StatusNoContent)
+	h.Get("/api/v1/saved-searches/"+s.ID).AssertError(http.StatusNotFound, "saved search not found")
+	h.Delete("/api/v1/saved-searches/"+missingID).AssertError(http.StatusNotFound, "saved search not found")
+}
+
+func TestSavedSearchPagination(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	for _, title := range []string{"one", "two", "three"} {
+		h.Note().InCategory(c).Titled(title).WithMetadata(map[string]any{"tags": []any{"x"}}).Create()
+	}
+	h.Note().InCategory(c).Titled("untagged").Create()
+
+	var s savedSearchBody
+	h.Post("/api/v1/saved-searches", map[string]string{"name": "x", "filter": "tag = x"}).
+		AssertStatus(http.StatusCreated).Decode(&s)
+
+	var titles []string
+	next := ""
+	for range 3 {
+		path := "/api/v1/saved-searches/" + s.ID + "/notes?limit=2"
+		if next != "" {
+			path += "&after=" + next
+		}
+		var page []noteBody
+		res := h.Get(path).AssertStatus(http.StatusOK)
+		res.Decode(&page)
+		for _, n := range page {
+			titles = append(titles, n.Title)
+		}
+		if next = res.Header.Get("X-Next-Cursor"); next == "" {
+			break
+		}
+	}
+	slices.Sort(titles)
+	if !slices.Equal(titles, []string{"one", "three", "two"}) {
+		t.Errorf("paged titles = %v, want each tagged note once", titles)
+	}
+}
@@ -2,50 +2,109 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
-	"github.com/piotmni/go-mini-templates/minimal/internal/http/handlers"
+	"github.com/piotmni/go-mini-templates/minimal/internal/dav"
 	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/idempotency"
 	"go.uber.org/zap"
 )
 
-// ServerConfig holds the HTTP server configuration.
+// ServerConfig holds the HTTP server configuration. Zero timeouts and
+// limits leave the corresponding protection off.
 type ServerConfig struct {
-	Host  string
-	Port  int
-	Token string
+	Host string
+	Port int
+	// ReadHeaderTimeout bounds reading the request headers, ReadTimeout
+	// the whole request and WriteTimeout writing the response.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	// IdleTimeout is how long keep-alive connections wait for the next
+	// request.
+	IdleTimeout time.Duration
+	// BodyLimit caps request bodies, e.g. "32M". Larger requests get 413.
+	BodyLimit string
+	CORS      CORSConfig
+	// SecurityHeaders adds headers that keep browsers from sniffing,
+	// framing or rendering responses as documents.
+	SecurityHeaders bool
+	// HSTSMaxAge is sent as Strict-Transport-Security on TLS requests
+	// when SecurityHeaders is set.
+	HSTSMaxAge time.Duration
+	TLS        TLSConfig
+}
+
+// CORSConfig configures cross-origin requests, which are refused unless
+// AllowOrigins is set.
+type CORSConfig struct {
+	// AllowOrigins lists the origins allowed, or "*" for any.
+	AllowOrigins     []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache preflight responses.
+	MaxAge time.Duration
+}
+
+// contentSecurityPolicy forbids rendering API responses as documents.
+const contentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// exposedHeaders are the response headers cross-origin clients may read.
+var exposedHeaders = []string{
+	echo.HeaderXRequestID,
+	"X-Next-Cursor",
+	middleware.HeaderIdempotentReplayed,
 }
 
 // Server is the HTTP server.
 type Server struct {
-	echo            *echo.Echo
-	cfg             ServerConfig
-	logger          *zap.Logger
-	categoryHandler *handlers.CategoryHandler
-	noteHandler     *handlers.NoteHandler
+	echo          *echo.Echo
+	cfg           ServerConfig
+	logger        *zap.Logger
+	authenticator middleware.Authenticator
+	idempotency   *idempotency.Service
+	modules       *modules.Registry
+	// stopTLS stops reloading the TLS certificates.
+	stopTLS context.CancelFunc
 }
 
-// NewServer creates a new HTTP server.
+// NewServer creates a new HTTP server serving the routes of the modules
+// of registry.
 func NewServer(
 	cfg ServerConfig,
 	logger *zap.Logger,
-	categoryHandler *handlers.CategoryHandler,
-	noteHandler *handlers.NoteHandler,
+	authenticator middleware.Authenticator,
+	idempotency *idempotency.Service,
+	registry *modules.Registry,
 ) *Server {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
 
-	return &Server{
-		echo:            e,
-		cfg:             cfg,
-		logger:          logger.Named("http.server"),
-		categoryHandler: categoryHandler,
-		noteHandler:     noteHandler,
+	s := &Server{
+		echo:          e,
+		cfg:           cfg,
+		logger:        logger.Named("http.server"),
+		authenticator: authenticator,
+		idempotency:   idempotency,
+		modules:       registry,
 	}
+	s.setupRoutes()
+
+	return s
+}
+
+// Handler returns the server's routes as an http.Handler.
+// It allows the server to be mounted without listening, e.g. in tests.
+func (s *Server) Handler() http.Handler {
+	return s.echo
 }
 
 // setupRoutes configures all routes.
@@ -53,42 +112,96 @@ func (s *Server) setupRoutes() {
 	// Global middleware
 	s.echo.Use(echomiddleware.Recover())
 	s.echo.Use(echomiddleware.RequestID())
+	s.echo.Use(middleware.Origin())
+	s.echo.Use(middleware.LogFields())
 	s.echo.Use(s.requestLogger())
+	s.echo.Use(middleware.ClientCertificate())
+	if s.cfg.BodyLimit != "" {
+		s.echo.Use(echomiddleware.BodyLimit(s.cfg.BodyLimit))
+	}
+	if s.cfg.SecurityHeaders {
+		s.echo.Use(echomiddleware.SecureWithConfig(echomiddleware.SecureConfig{
+			ContentTypeNosniff:    "nosniff",
+			XFrameOptions:         "DENY",
+			HSTSMaxAge:            int(s.cfg.HSTSMaxAge.Seconds()),
+			ContentSecurityPolicy: contentSecurityPolicy,
+			ReferrerPolicy:        "no-referrer",
+		}))
+	}
+	if len(s.cfg.CORS.AllowOrigins) > 0 {
+		s.echo.Use(echomiddleware.CORSWithConfig(echomiddleware.CORSConfig{
+			// WebDAV answers OPTIONS itself
+			Skipper: func(c echo.Context) bool {
+				return strings.HasPrefix(c.Request().URL.Path, dav.Prefix)
+			},
+			AllowOrigins:     s.cfg.CORS.AllowOrigins,
+			AllowCredentials: s.cfg.CORS.AllowCredentials,
+			ExposeHeaders:    exposedHeaders,
+			MaxAge:           int(s.cfg.CORS.MaxAge.Seconds()),
+		}))
+	}
 
 	// Health check (no auth required)
-	s.echo.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
-	})
+	s.echo.GET("/health", s.health)
 
 	// API routes with auth
 	api := s.echo.Group("/api/v1")
-	api.Use(middleware.Auth(s.cfg.Token))
+	api.Use(middleware.Auth(s.authenticator))
+	api.Use(middleware.Idempotency(s.idempotency))
 
 	// Register routes
-	categories := api.Group("/categories")
-	s.categoryHandler.RegisterRoutes(categories)
+	s.modules.RegisterRoutes(api)
+
+	// RPC and WebDAV authenticate requests themselves
+	s.modules.RegisterRootRoutes(s.echo)
+}
 
-	notes := api.Group("/notes")
-	s.noteHandler.RegisterRoutes(notes)
+// healthResponse is the JSON response for the health check.
+type healthResponse struct {
+	Status string `json:"status"`
+	// Checks holds "unavailable" for the unhealthy modules by name. Their
+	// errors are logged, as the health check is not authenticated.
+	Checks map[string]string `json:"checks,omitempty"`
 }
 
-// requestLogger returns a middleware that logs requests.
+// health handles GET /health
+func (s *Server) health(c echo.Context) error {
+	ctx := c.Request().Context()
+	failed := s.modules.Health(ctx)
+	if len(failed) == 0 {
+		return c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	res := healthResponse{Status: "unavailable", Checks: make(map[string]string, len(failed))}
+	for name, err := range failed {
+		log.Error("module unhealthy", zap.String("module", name), zap.Error(err))
+		res.Checks[name] = "unavailable"
+	}
+	return c.JSON(http.StatusServiceUnavailable, res)
+}
+
+// requestLogger returns a middleware that logs requests with the fields
+// of their context, and the cause of every 5xx response.
 func (s *Server) requestLogger() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			req := c.Request()
-			res := c.Response()
-
 			err := next(c)
 			if err != nil {
 				c.Error(err)
 			}
 
-			s.logger.Info("request",
+			// Auth replaces the request, so its context has every field
+			req := c.Request()
+			res := c.Response()
+			log := logger.FromContext(req.Context(), s.logger)
+			if err != nil && res.Status >= http.StatusInternalServerError {
+				log.Error("request failed", zap.Int("status", res.Status), zap.Error(errorCause(err)))
+			}
+			log.Info("request",
 				zap.String("method", req.Method),
 				zap.String("path", req.URL.Path),
 				zap.Int("status", res.Status),
-				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)),
 			)
 
 			return nil
@@ -96,18 +209,56 @@ func (s *Server) requestLogger() echo.MiddlewareFunc {
 	}
 }
 
-// Start starts the HTTP server.
+// errorCause returns the error a handler converted into err, or err
+// itself.
+func errorCause(err error) error {
+	var he *echo.HTTPError
+	if errors.As(err, &he) && he.Internal != nil {
+		return he.Internal
+	}
+	return err
+}
+
+// Start starts the HTTP server, serving TLS if it is configured.
 func (s *Server) Start() error {
-	s.setupRoutes()
+	srv := s.echo.Server
+	srv.Addr = fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+	srv.ReadHeaderTimeout = s.cfg.ReadHeaderTimeout
+	srv.ReadTimeout = s.cfg.ReadTimeout
+	srv.WriteTimeout = s.cfg.WriteTimeout
+	srv.IdleTimeout = s.cfg.IdleTimeout
 
-	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
-	s.logger.Info("starting HTTP server", zap.String("addr", addr))
+	// Accept HTTP/2 without TLS (h2c) so gRPC clients can share the port
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Protocols = &protocols
 
-	return s.echo.Start(addr)
+	if s.cfg.TLS.Enabled() {
+		// Certificates are reloaded until the server shuts down
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopTLS = cancel
+		tlsConfig, err := NewTLSConfig(ctx, s.cfg.TLS, s.logger)
+		if err != nil {
+			cancel()
+			return err
+		}
+		srv.TLSConfig = tlsConfig
+	}
+
+	s.logger.Info("starting HTTP server",
+		zap.String("addr", srv.Addr),
+		zap.Bool("tls", srv.TLSConfig != nil),
+	)
+	return s.echo.StartServer(srv)
 }
 
 // Shutdown gracefully shuts down the server.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("shutting down HTTP server")
+	if s.stopTLS != nil {
+		s.stopTLS()
+	}
 	return s.echo.Shutdown(ctx)
 }
@@ -0,0 +1,262 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	apphttp "github.com/piotmni/go-mini-templates/minimal/internal/http"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHealth(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Get("/health", apitest.WithoutAuth()).
+		AssertStatus(http.StatusOK).
+		AssertGolden()
+}
+
+// pingModule serves /ping and is unhealthy while err is set.
+type pingModule struct {
+	modules.Base
+	err error
+}
+
+func (m *pingModule) Name() string { return "ping" }
+
+func (m *pingModule) Dependencies() []string { return []string{"note"} }
+
+func (m *pingModule) RegisterRoutes(api *echo.Group) {
+	api.GET("/ping", func(c echo.Context) error {
+		return c.String(http.StatusOK, "pong")
+	}, middleware.RequireScope(auth.ScopeNotesRead))
+}
+
+func (m *pingModule) Health(context.Context) error { return m.err }
+
+func TestModules(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ping := &pingModule{}
+	h := apitest.New(t, apitest.WithLogger(zap.New(core)), apitest.WithModule(ping))
+
+	h.Get("/api/v1/ping").AssertStatus(http.StatusOK)
+	h.Get("/api/v1/ping", apitest.WithoutAuth()).
+		AssertError(http.StatusUnauthorized, "missing authorization header")
+	h.Get("/api/v1/ping", apitest.WithToken(h.Key(auth.ScopeNotesWrite))).
+		AssertError(http.StatusForbidden, "missing scope notes:read")
+
+	ping.err = errors.New("upstream unreachable")
+	res := h.Get("/health", apitest.WithoutAuth()).AssertStatus(http.StatusServiceUnavailable)
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	res.Decode(&body)
+	if body.Status != "unavailable" || body.Checks["ping"] != "unavailable" {
+		t.Errorf("health = %+v", body)
+	}
+	// The cause is logged, not shown to unauthenticated callers
+	unhealthy := logs.FilterMessage("module unhealthy").AllUntimed()
+	if len(unhealthy) != 1 || unhealthy[0].ContextMap()["error"] != "upstream unreachable" {
+		t.Errorf("logged %v, want the cause of the unhealthy module", unhealthy)
+	}
+}
+
+// failModule serves /fail, which fails with a cause hidden from clients.
+type failModule struct{ modules.Base }
+
+func (failModule) Name() string { return "fail" }
+
+func (failModule) RegisterRoutes(api *echo.Group) {
+	api.POST("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed").
+			SetInternal(errors.New("disk on fire"))
+	})
+}
+
+func TestRequestLogging(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	h := apitest.New(t, apitest.WithLogger(zap.New(core)), apitest.WithModule(failModule{}))
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := apitest.WithHeader("Traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	res := h.Post("/api/v1/categories", map[string]string{"name": "Logged"}, traceparent).
+		AssertStatus(http.StatusCreated)
+
+	// Services log with the fields of the request
+	created := logs.FilterMessage("category created").AllUntimed()
+	if len(created) != 1 {
+		t.Fatalf("logged %d category creations, want 1", len(created))
+	}
+	fields := created[0].ContextMap()
+	want := map[string]any{
+		"request_id": res.Header.Get(echo.HeaderXRequestID),
+		"route":      "/api/v1/categories",
+		"trace_id":   traceID,
+		"principal": map[string]any{
+			"user_id":      h.User.ID.String(),
+			"workspace_id": h.Workspace.ID.String(),
+		},
+	}
+	for key, value := range want {
+		if !reflect.DeepEqual(fields[key], value) {
+			t.Errorf("%s = %v, want %v", key, fields[key], value)
+		}
+	}
+	if created[0].LoggerName != "category.service" {
+		t.Errorf("logger = %q, want category.service", created[0].LoggerName)
+	}
+
+	// The cause of a 5xx is logged but not returned, also past the
+	// idempotency middleware
+	res = h.Post("/api/v1/fail", nil, apitest.WithHeader("Idempotency-Key", "fail")).
+		AssertError(http.StatusInternalServerError, "failed")
+	failed := logs.FilterMessage("request failed").AllUntimed()
+	if len(failed) != 1 {
+		t.Fatalf("logged %d failed requests, want 1", len(failed))
+	}
+	fields = failed[0].ContextMap()
+	if fields["error"] != "disk on fire" || fields["request_id"] != res.Header.Get(echo.HeaderXRequestID) {
+		t.Errorf("failed request fields = %v", fields)
+	}
+	if strings.Contains(string(res.Body), "disk on fire") {
+		t.Errorf("response leaks the cause: %s", res.Body)
+	}
+
+	// Client errors are not logged as failures
+	h.Post("/api/v1/categories", map[string]string{"name": "Logged"}).AssertStatus(http.StatusConflict)
+	if n := logs.FilterMessage("request failed").Len(); n != 1 {
+		t.Errorf("logged %d failed requests, want 1", n)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	h := apitest.New(t)
+
+	tests := []struct {
+		name    string
+		opt     apitest.RequestOption
+		message string
+	}{
+		{"missing header", apitest.WithoutAuth(), "missing authorization header"},
+		{"wrong scheme", apitest.WithHeader("Authorization", "Basic "+h.Token), "invalid authorization header format"},
+		{"no credentials", apitest.WithHeader("Authorization", "Bearer"), "invalid authorization header format"},
+		{"invalid token", apitest.WithToken("invalid-token"), "invalid api key"},
+		{"unknown key", apitest.WithToken("mk_000000000000_secret"), "invalid api key"},
+	}
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/categories"},
+		{http.MethodPost, "/api/v1/categories"},
+		{http.MethodGet, "/api/v1/notes"},
+		{http.MethodDelete, "/api/v1/notes/00000000-0000-0000-0000-000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range routes {
+				h.For(t).Do(r.method, r.path, nil, tt.opt).
+					AssertError(http.StatusUnauthorized, tt.message)
+			}
+		})
+	}
+}
+
+func TestScopes(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Create()
+	n := h.Note().InCategory(c).Create()
+
+	reader := apitest.WithToken(h.Key(auth.ScopeNotesRead))
+	h.Get("/api/v1/notes/"+n.ID.String(), reader).AssertStatus(http.StatusOK)
+	h.Get("/api/v1/categories", reader).AssertStatus(http.StatusOK)
+	h.Delete("/api/v1/notes/"+n.ID.String(), reader).
+		AssertError(http.StatusForbidden, "missing scope notes:write")
+	h.Post("/api/v1/categories", map[string]string{"name": "New"}, reader).
+		AssertError(http.StatusForbidden, "missing scope categories:admin")
+
+	writer := apitest.WithToken(h.Key(auth.ScopeNotesWrite))
+	h.Get("/api/v1/notes", writer).
+		AssertError(http.StatusForbidden, "missing scope notes:read")
+	h.Put("/api/v1/notes/"+n.ID.String(), map[string]string{
+		"category_id": c.ID.String(),
+		"title":       "Renamed",
+	}, writer).AssertStatus(http.StatusOK)
+	h.Delete("/api/v1/categories/"+c.ID.String(), writer).
+		AssertError(http.StatusForbidden, "missing scope categories:admin")
+}
+
+func TestBodyLimit(t *testing.T) {
+	h := apitest.New(t, apitest.WithServerConfig(apphttp.ServerConfig{BodyLimit: "1K"}))
+
+	h.Post("/api/v1/categories", map[string]string{"name": strings.Repeat("x", 2048)}).
+		AssertError(http.StatusRequestEntityTooLarge, "Request Entity Too Large")
+	h.Post("/api/v1/categories", map[string]string{"name": "Small"}).
+		AssertStatus(http.StatusCreated)
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	h := apitest.New(t, apitest.WithServerConfig(apphttp.ServerConfig{SecurityHeaders: true}))
+
+	h.Get("/health", apitest.WithoutAuth()).
+		AssertStatus(http.StatusOK).
+		AssertHeader("X-Content-Type-Options", "nosniff").
+		AssertHeader("X-Frame-Options", "DENY").
+		AssertHeader("Referrer-Policy", "no-referrer").
+		AssertHeader("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'").
+		// HSTS is only sent over TLS
+		AssertHeader("Strict-Transport-Security", "")
+}
+
+func TestCORS(t *testing.T) {
+	h := apitest.New(t, apitest.WithServerConfig(apphttp.ServerConfig{
+		CORS: apphttp.CORSConfig{AllowOrigins: []string{"https://app.example.com"}, MaxAge: time.Minute},
+	}))
+
+	preflight := []apitest.RequestOption{
+		apitest.WithoutAuth(),
+		apitest.WithHeader("Access-Control-Request-Method", http.MethodPost),
+		apitest.WithHeader("Access-Control-Request-Headers", "Authorization, Content-Type"),
+	}
+
+	t.Run("allowed origin", func(t *testing.T) {
+		h.For(t).Do(http.MethodOptions, "/api/v1/notes", nil, append(preflight, apitest.WithHeader("Origin", "https://app.example.com"))...).
+			AssertStatus(http.StatusNoContent).
+			AssertHeader("Access-Control-Allow-Origin", "https://app.example.com").
+			AssertHeader("Access-Control-Max-Age", "60")
+
+		res := h.For(t).Get("/api/v1/notes", apitest.WithHeader("Origin", "https://app.example.com")).
+			AssertStatus(http.StatusOK).
+			AssertHeader("Access-Control-Allow-Origin", "https://app.example.com")
+		if exposed := res.Header.Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, "X-Next-Cursor") {
+			t.Errorf("Access-Control-Expose-Headers = %q, want X-Next-Cursor", exposed)
+		}
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		h.For(t).Do(http.MethodOptions, "/api/v1/notes", nil, append(preflight, apitest.WithHeader("Origin", "https://evil.example.com"))...).
+			AssertHeader("Access-Control-Allow-Origin", "")
+		h.For(t).Get("/api/v1/notes", apitest.WithHeader("Origin", "https://evil.example.com")).
+			AssertStatus(http.StatusOK).
+			AssertHeader("Access-Control-Allow-Origin", "")
+	})
+
+	t.Run("webdav options", func(t *testing.T) {
+		res := h.For(t).Do(http.MethodOptions, "/dav/", nil, apitest.WithHeader("Origin", "https://app.example.com")).
+			AssertStatus(http.StatusOK)
+		if res.Header.Get("DAV") == "" {
+			t.Error("OPTIONS /dav/ has no DAV header")
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Client authentication modes of TLSConfig.
+const (
+	// ClientAuthNone does not ask clients for certificates.
+	ClientAuthNone = "none"
+	// ClientAuthRequest verifies client certificates when clients send one.
+	ClientAuthRequest = "request"
+	// ClientAuthRequire refuses clients without a valid certificate.
+	ClientAuthRequire = "require"
+)
+
+// TLSConfig configures native TLS. The server speaks plain HTTP unless
+// CertFile and KeyFile are set.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds the PEM encoded CAs client certificates are
+	// verified against. It is required unless ClientAuth is none.
+	ClientCAFile string
+	// ClientAuth is one of ClientAuthNone, ClientAuthRequest or
+	// ClientAuthRequire. Empty means none.
+	ClientAuth string
+	// ReloadInterval is how often the files are checked for changes. Zero
+	// means DefaultTLSReloadInterval.
+	ReloadInterval time.Duration
+}
+
+// DefaultTLSReloadInterval is how often TLS files are checked for changes
+// unless configured.
+const DefaultTLSReloadInterval = time.Minute
+
+// Enabled reports whether the server serves TLS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+func (c TLSConfig) clientAuth() (tls.ClientAuthType, error) {
+	switch c.ClientAuth {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return 0, fmt.Errorf("unknown TLS client auth %q", c.ClientAuth)
+}
+
+// NewTLSConfig returns the TLS configuration of cfg. Until ctx is done, the
+// files are checked every ReloadInterval and the certificate and client
+// CAs reloaded when they changed, so they can be renewed without a
+// restart; if reloading fails the previous ones stay in use. Handshakes
+// only read the loaded configuration.
+func NewTLSConfig(ctx context.Context, cfg TLSConfig, logger *zap.Logger) (*tls.Config, error) {
+	clientAuth, err := cfg.clientAuth()
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, errors.New("TLS client auth requires a client CA file")
+	}
+
+	r := &tlsReloader{cfg: cfg, clientAuth: clientAuth, logger: logger.Named("http.tls")}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	interval := cfg.ReloadInterval
+	if interval <= 0 {
+		interval = DefaultTLSReloadInterval
+	}
+	go r.watch(ctx, interval)
+
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.configForClient,
+	}, nil
+}
+
+// tlsReloader holds the TLS configuration loaded from the files of a
+// TLSConfig.
+type tlsReloader struct {
+	cfg        TLSConfig
+	clientAuth tls.ClientAuthType
+	logger     *zap.Logger
+
+	current atomic.Pointer[tls.Config]
+	// modTimes are the modification times of the loaded files, only
+	// accessed by watch once it runs.
+	modTimes []time.Time
+}
+
+func (r *tlsReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+func (r *tlsReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return r.current.Load(), nil
+}
+
+// watch reloads the files every interval if they changed, until ctx is
+// done.
+func (r *tlsReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reload()
+		}
+	}
+}
+
+// reload loads the files if they changed since they were last loaded.
+func (r *tlsReloader) reload() {
+	// Files being replaced may be missing for a moment; keep the current
+	// configuration until they are back
+	modTimes, err := r.stat()
+	if err != nil || slices.EqualFunc(modTimes, r.modTimes, time.Time.Equal) {
+		return
+	}
+
+	// Each change is tried once, so broken files are not reread until
+	// they change again
+	r.modTimes = modTimes
+	if err := r.load(); err != nil {
+		r.logger.Error("failed to reload TLS certificates", zap.Error(err))
+		return
+	}
+	r.logger.Info("reloaded TLS certificates")
+}
+
+// stat returns the modification times of the files.
+func (r *tlsReloader) stat() ([]time.Time, error) {
+	files := r.files()
+	modTimes := make([]time.Time, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// load reads the files and replaces the current configuration.
+func (r *tlsReloader) load() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   r.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	if r.cfg.ClientCAFile != "" {
+		data, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates in TLS client CA file %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.current.Store(cfg)
+	r.modTimes = modTimes
+	return nil
+}
@@ -0,0 +1,266 @@
+package http_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	apphttp "github.com/piotmni/go-mini-templates/minimal/internal/http"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+// testCA issues certificates for TLS tests.
+type testCA struct {
+	t    *testing.T
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	ca := &testCA{t: t}
+	ca.cert, ca.key = ca.issue(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	ca.pem = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	return ca
+}
+
+// issue signs template with the CA, or self-signs it if the CA has no
+// certificate yet.
+func (ca *testCA) issue(template *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey) {
+	ca.t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		ca.t.Fatalf("generate key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		ca.t.Fatalf("generate serial: %v", err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent, signer := template, key
+	if ca.cert != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		ca.t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		ca.t.Fatalf("parse certificate: %v", err)
+	}
+	return cert, key
+}
+
+// serverFiles writes a server certificate for 127.0.0.1 named cn and its
+// key to dir.
+func (ca *testCA) serverFiles(dir, cn string) (certFile, keyFile string) {
+	ca.t.Helper()
+	cert, key := ca.issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: cn},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		ca.t.Fatalf("marshal key: %v", err)
+	}
+	certFile, keyFile = filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	ca.write(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	ca.write(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certFile, keyFile
+}
+
+// client returns a certificate for a client named cn.
+func (ca *testCA) client(cn string) tls.Certificate {
+	ca.t.Helper()
+	cert, key := ca.issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: cn},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	})
+	return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}
+}
+
+func (ca *testCA) write(path string, data []byte) {
+	ca.t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		ca.t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// httpClient returns a client trusting the CA that presents certs.
+func (ca *testCA) httpClient(certs ...tls.Certificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs},
+		DisableKeepAlives: true,
+		ForceAttemptHTTP2: true,
+	}}
+}
+
+// newTLSHarness starts a harness serving TLS that requires client
+// certificates issued by ca and maps CN=ci to the harness user.
+func newTLSHarness(t *testing.T, ca *testCA) (*apitest.Harness, string) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile, keyFile := ca.serverFiles(dir, "server")
+	caFile := filepath.Join(dir, "ca.crt")
+	ca.write(caFile, ca.pem)
+
+	h := apitest.New(t,
+		apitest.WithServerConfig(apphttp.ServerConfig{
+			SecurityHeaders: true,
+			HSTSMaxAge:      time.Hour,
+			TLS: apphttp.TLSConfig{
+				CertFile:     certFile,
+				KeyFile:      keyFile,
+				ClientCAFile: caFile,
+				ClientAuth:   apphttp.ClientAuthRequire,
+				// Short, so reloads are seen quickly
+				ReloadInterval: 10 * time.Millisecond,
+			},
+		}),
+		apitest.WithClientCertificate("CN=ci"),
+	)
+	return h, dir
+}
+
+func get(t *testing.T, client *http.Client, url, token string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read response body: %v", err)
+	}
+	return res, string(body)
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	h, _ := newTLSHarness(t, ca)
+	h.Category().Create()
+
+	t.Run("mapped certificate", func(t *testing.T) {
+		res, body := get(t, ca.httpClient(ca.client("ci")), h.URL()+"/api/v1/categories", "")
+		if res.StatusCode != http.StatusOK || !strings.Contains(body, `"name"`) {
+			t.Fatalf("status = %d, body: %s", res.StatusCode, body)
+		}
+		if res.ProtoMajor != 2 {
+			t.Errorf("protocol = %s, want HTTP/2", res.Proto)
+		}
+		if got := res.Header.Get("Strict-Transport-Security"); got != "max-age=3600; includeSubdomains" {
+			t.Errorf("Strict-Transport-Security = %q", got)
+		}
+	})
+
+	t.Run("token with certificate", func(t *testing.T) {
+		res, body := get(t, ca.httpClient(ca.client("stranger")), h.URL()+"/api/v1/categories", h.Token)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", res.StatusCode, body)
+		}
+	})
+
+	t.Run("unmapped certificate", func(t *testing.T) {
+		res, body := get(t, ca.httpClient(ca.client("stranger")), h.URL()+"/api/v1/categories", "")
+		if res.StatusCode != http.StatusUnauthorized || !strings.Contains(body, "unknown client certificate") {
+			t.Fatalf("status = %d, body: %s", res.StatusCode, body)
+		}
+	})
+
+	t.Run("no certificate", func(t *testing.T) {
+		if _, err := ca.httpClient().Get(h.URL() + "/health"); err == nil {
+			t.Fatal("request without client certificate succeeded")
+		}
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		other := newTestCA(t)
+		if _, err := ca.httpClient(other.client("ci")).Get(h.URL() + "/health"); err == nil {
+			t.Fatal("request with untrusted client certificate succeeded")
+		}
+	})
+}
+
+func TestTLSCertificateReload(t *testing.T) {
+	ca := newTestCA(t)
+	h, dir := newTLSHarness(t, ca)
+	client := ca.httpClient(ca.client("ci"))
+
+	serverName := func() string {
+		t.Helper()
+		res, body := get(t, client, h.URL()+"/health", "")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", res.StatusCode, body)
+		}
+		return res.TLS.PeerCertificates[0].Subject.CommonName
+	}
+
+	if got := serverName(); got != "server" {
+		t.Fatalf("server certificate = %q, want server", got)
+	}
+
+	// Move the renewed files into the future so the change is seen even
+	// within the file system's timestamp granularity
+	certFile, keyFile := ca.serverFiles(dir, "reloaded")
+	future := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, future, future); err != nil {
+			t.Fatalf("touch %s: %v", file, err)
+		}
+	}
+	// Reloads happen in the background; new connections see them soon
+	deadline := time.Now().Add(5 * time.Second)
+	for serverName() != "reloaded" {
+		if time.Now().After(deadline) {
+			t.Fatal("server certificate was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A broken renewal keeps the previous certificate
+	ca.write(certFile, []byte("not a certificate"))
+	future = future.Add(time.Minute)
+	if err := os.Chtimes(certFile, future, future); err != nil {
+		t.Fatalf("touch %s: %v", certFile, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := serverName(); got != "reloaded" {
+		t.Fatalf("server certificate = %q, want reloaded", got)
+	}
+}
@@ -0,0 +1,357 @@
+package http_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+)
+
+type categoryBody struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type noteBody struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+type importReportBody struct {
+	DryRun    bool `json:"dry_run"`
+	Created   int  `json:"created"`
+	Updated   int  `json:"updated"`
+	Unchanged int  `json:"unchanged"`
+	Failed    int  `json:"failed"`
+	Items     []struct {
+		Type   string `json:"type"`
+		Ref    string `json:"ref"`
+		ID     string `json:"id"`
+		Action string `json:"action"`
+		Error  string `json:"error"`
+	} `json:"items"`
+}
+
+func importArchive(t *testing.T, h *apitest.Harness, query string, archive []byte, status int) importReportBody {
+	t.Helper()
+	var report importReportBody
+	h.For(t).Post("/api/v1/import?"+query, archive).AssertStatus(status).Decode(&report)
+	return report
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		format      string
+		contentType string
+	}{
+		{"ndjson", "application/x-ndjson"},
+		{"markdown-zip", "application/zip"},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			h := apitest.New(t)
+			work := h.Category().Named("Work").Create()
+			h.Category().Named("Empty").Create()
+			h.Note().InCategory(work).Titled("Plan").WithContent("---\n# Q3\n\n- ship it\n").Create()
+			h.Note().InCategory(work).Titled("Plan").WithContent("second note, same title").Create()
+
+			res := h.For(t).Get("/api/v1/export?format=" + tc.format).AssertStatus(http.StatusOK)
+			if got := res.Header.Get("Content-Type"); got != tc.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tc.contentType)
+			}
+			if got := res.Header.Get("Content-Disposition"); !strings.HasPrefix(got, "attachment; filename=notes-") {
+				t.Errorf("Content-Disposition = %q, want an attachment", got)
+			}
+
+			other := h.InNewWorkspace("Copy")
+			report := importArchive(t, other, "format="+tc.format, res.Body, http.StatusOK)
+			if report.Created != 4 || report.Failed != 0 {
+				t.Fatalf("report = %+v, want 4 created", report)
+			}
+
+			var categories []categoryBody
+			other.For(t).Get("/api/v1/categories").AssertStatus(http.StatusOK).Decode(&categories)
+			if len(categories) != 2 {
+				t.Fatalf("got %d categories, want 2", len(categories))
+			}
+			var notes []noteBody
+			other.For(t).Get("/api/v1/notes").AssertStatus(http.StatusOK).Decode(&notes)
+			if len(notes) != 2 {
+				t.Fatalf("got %d notes, want 2", len(notes))
+			}
+			contents := map[string]bool{}
+			for _, n := range notes {
+				contents[n.Content] = true
+			}
+			if !contents["---\n# Q3\n\n- ship it\n"] || !contents["second note, same title"] {
+				t.Errorf("contents = %v, want both notes unchanged", contents)
+			}
+
+			// Importing an export of the same workspace changes nothing
+			report = importArchive(t, h, "format="+tc.format, res.Body, http.StatusOK)
+			if report.Unchanged != 4 || report.Created+report.Updated != 0 {
+				t.Errorf("re-import report = %+v, want 4 unchanged", report)
+			}
+		})
+	}
+}
+
+func TestImportFormatFromContentType(t *testing.T) {
+	h := apitest.New(t)
+
+	var report importReportBody
+	h.Post("/api/v1/import", `{"type":"note","title":"T"}`+"\n",
+		apitest.WithHeader("Content-Type", "application/x-ndjson")).
+		AssertStatus(http.StatusUnprocessableEntity).Decode(&report)
+	if report.Items[0].Error != "category is required" {
+		t.Errorf("error = %q, want category is required", report.Items[0].Error)
+	}
+
+	h.Post("/api/v1/import", "{}").AssertError(http.StatusBadRequest, "format must be ndjson or markdown-zip")
+	h.Post("/api/v1/import?format=csv", "").AssertError(http.StatusBadRequest, "format must be ndjson or markdown-zip")
+	h.Get("/api/v1/export?format=csv").AssertError(http.StatusBadRequest, "format must be ndjson or markdown-zip")
+	h.Post("/api/v1/import?format=markdown-zip", "not a zip").AssertError(http.StatusBadRequest, "invalid archive")
+}
+
+func TestImportUpsert(t *testing.T) {
+	h := apitest.New(t)
+	c := h.Category().Named("Work").Create()
+	n := h.Note().InCategory(c).Titled("Draft").Create()
+
+	archive := strings.Join([]string{
+		`{"type":"category","id":"` + c.ID.String() + `","name":"Office"}`,
+		`{"type":"note","id":"` + n.ID.String() + `","category_id":"` + c.ID.String() + `","title":"Final"}`,
+		`{"type":"note","id":"` + missingID + `","category_id":"` + c.ID.String() + `","title":"New"}`,
+	}, "\n")
+	report := importArchive(t, h, "format=ndjson", []byte(archive), http.StatusOK)
+	if report.Updated != 2 || report.Created != 1 {
+		t.Fatalf("report = %+v, want 2 updated and 1 created", report)
+	}
+	if id := report.Items[2].ID; id == "" || id == missingID {
+		t.Errorf("created note id = %q, want a new id", id)
+	}
+
+	var got noteBody
+	h.Get("/api/v1/notes/" + n.ID.String()).AssertStatus(http.StatusOK).Decode(&got)
+	if got.Title != "Final" {
+		t.Errorf("title = %q, want Final", got.Title)
+	}
+	var renamed categoryBody
+	h.Get("/api/v1/categories/" + c.ID.String()).AssertStatus(http.StatusOK).Decode(&renamed)
+	if renamed.Name != "Office" {
+		t.Errorf("name = %q, want Office", renamed.Name)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	h := apitest.New(t)
+
+	archive := []byte(`{"type":"category","id":"` + missingID + `","name":"Work"}
+{"type":"note","category_id":"` + missingID + `","title":"Plan"}
+`)
+	report := importArchive(t, h, "format=ndjson&dry_run=true", archive, http.StatusOK)
+	if !report.DryRun || report.Created != 2 {
+		t.Fatalf("report = %+v, want a dry run creating 2", report)
+	}
+
+	var categories []categoryBody
+	h.Get("/api/v1/categories").AssertStatus(http.StatusOK).Decode(&categories)
+	if len(categories) != 0 {
+		t.Errorf("dry run created %d categories", len(categories))
+	}
+}
+
+func TestImportInvalidItems(t *testing.T) {
+	h := apitest.New(t)
+
+	archive := []byte(`{"type":"category","name":"Work"}
+{"type":"note","category_id":"` + missingID + `","title":"Orphan"}
+{"type":"note","title":""}
+not json
+{"type":"tag"}
+`)
+	report := importArchive(t, h, "format=ndjson", archive, http.StatusUnprocessableEntity)
+	if report.Failed != 4 || report.Created != 1 {
+		t.Fatalf("report = %+v, want 4 failed and 1 valid", report)
+	}
+	for _, want := range []struct{ ref, err string }{
+		{"line 2", "category not found"},
+		{"line 3", "title is required"},
+		{"line 5", `unknown type "tag"`},
+	} {
+		found := false
+		for _, item := range report.Items {
+			if item.Ref == want.ref {
+				found = true
+				if item.Action != "error" || item.Error != want.err {
+					t.Errorf("%s = %s %q, want error %q", want.ref, item.Action, item.Error, want.err)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("no item for %s", want.ref)
+		}
+	}
+
+	// Nothing is imported when any record is invalid
+	var categories []categoryBody
+	h.Get("/api/v1/categories").AssertStatus(http.StatusOK).Decode(&categories)
+	if len(categories) != 0 {
+		t.Errorf("got %d categories, want none", len(categories))
+	}
+}
+
+func TestImportMarkdownWithoutFrontMatter(t *testing.T) {
+	h := apitest.New(t)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"Journal/monday.md":  "Went for a run.",
+		"Journal/tuesday.md": "Rained all day.",
+		"readme.txt":         "ignored",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := importArchive(t, h, "format=markdown-zip", buf.Bytes(), http.StatusOK)
+	if report.Created != 3 {
+		t.Fatalf("report = %+v, want a category and 2 notes created", report)
+	}
+
+	var categories []categoryBody
+	h.Get("/api/v1/categories").AssertStatus(http.StatusOK).Decode(&categories)
+	if len(categories) != 1 || categories[0].Name != "Journal" {
+		t.Errorf("categories = %+v, want Journal", categories)
+	}
+}
+
+func TestImportMarkdownFileTooLarge(t *testing.T) {
+	h := apitest.New(t)
+
+	// The header understates the size of the file, which compresses well
+	// enough to fit the request body limit
+	const maxFileSize = 4 << 20
+	content := bytes.Repeat([]byte("a"), maxFileSize+1)
+	var compressed bytes.Buffer
+	fw, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "Journal/long.md",
+		Method:             zip.Deflate,
+		CRC32:              crc32.ChecksumIEEE(content),
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: maxFileSize,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(compressed.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := importArchive(t, h, "format=markdown-zip", buf.Bytes(), http.StatusUnprocessableEntity)
+	if report.Failed != 1 || report.Created != 0 {
+		t.Fatalf("report = %+v, want the note failed", report)
+	}
+}
+
+func TestTransferScopes(t *testing.T) {
+	h := apitest.New(t)
+	reader := h.Key(auth.ScopeNotesRead)
+	writer := h.Key(auth.ScopeNotesRead, auth.ScopeNotesWrite)
+
+	h.Get("/api/v1/export", apitest.WithToken(reader)).AssertStatus(http.StatusOK)
+	h.Post("/api/v1/import?format=ndjson", "", apitest.WithToken(reader)).
+		AssertError(http.StatusForbidden, "missing scope notes:write")
+	h.Post("/api/v1/import?format=ndjson", "", apitest.WithToken(writer)).
+		AssertError(http.StatusForbidden, "missing scope categories:admin")
+}
+
+func TestExportImportMetadata(t *testing.T) {
+	for _, format := range []string{"ndjson", "markdown-zip"} {
+		t.Run(format, func(t *testing.T) {
+			h := apitest.New(t)
+			createSchemaCategory(h)
+			var tasks []categoryBody
+			h.For(t).Get("/api/v1/categories").AssertStatus(http.StatusOK).Decode(&tasks)
+			h.For(t).Post("/api/v1/notes", map[string]any{
+				"category_id": tasks[0].ID,
+				"title":       "Ship it",
+				"metadata":    map[string]any{"status": "open", "priority": 2},
+			}).AssertStatus(http.StatusCreated)
+
+			export := h.For(t).Get("/api/v1/export?format=" + format).AssertStatus(http.StatusOK).Body
+			other := h.InNewWorkspace("Copy")
+			if report := importArchive(t, other, "format="+format, export, http.StatusOK); report.Created != 2 {
+				t.Fatalf("report = %+v, want 2 created", report)
+			}
+
+			var notes []metadataNoteBody
+			other.For(t).Get("/api/v1/notes").AssertStatus(http.StatusOK).Decode(&notes)
+			if len(notes) != 1 || notes[0].Metadata["status"] != "open" || notes[0].Metadata["priority"] != float64(2) {
+				t.Fatalf("notes = %+v, want the metadata imported", notes)
+			}
+			// The schema is imported, so the copy still rejects invalid metadata
+			var copied []categoryBody
+			other.For(t).Get("/api/v1/categories").AssertStatus(http.StatusOK).Decode(&copied)
+			other.For(t).Post("/api/v1/notes", map[string]any{
+				"category_id": copied[0].ID,
+				"title":       "Unplanned",
+			}).AssertStatus(http.StatusBadRequest)
+
+			if report := importArchive(t, h, "format="+format, export, http.StatusOK); report.Unchanged != 2 {
+				t.Errorf("re-import report = %+v, want 2 unchanged", report)
+			}
+		})
+	}
+}
+
+func TestImportInvalidMetadata(t *testing.T) {
+	h := apitest.New(t)
+	categoryID := createSchemaCategory(h)
+
+	archive := []byte(`{"type":"category","id":"` + categoryID + `","name":"Tasks","metadata_schema":{"type":"nope"}}
+{"type":"note","category_id":"` + categoryID + `","title":"Unplanned","metadata":{"priority":1}}
+{"type":"note","category_id":"` + categoryID + `","title":"Planned","metadata":{"status":"open"}}
+`)
+	report := importArchive(t, h, "format=ndjson", archive, http.StatusUnprocessableEntity)
+	if report.Failed != 2 || report.Created != 1 {
+		t.Fatalf("report = %+v, want 2 failed and 1 valid", report)
+	}
+	for i, want := range []string{"invalid metadata schema", "invalid metadata"} {
+		if item := report.Items[i]; item.Action != "error" || !strings.HasPrefix(item.Error, want) {
+			t.Errorf("item %d = %s %q, want error %q", i, item.Action, item.Error, want)
+		}
+	}
+}
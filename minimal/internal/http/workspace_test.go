@@ -0,0 +1,114 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/workspace"
+)
+
+type memberBody struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+func TestWorkspaceIsolation(t *testing.T) {
+	h := apitest.New(t)
+	other := h.InNewWorkspace("Other")
+
+	n := h.Note().Titled("Private").Create()
+	h.Category().Named("Shared name").Create()
+
+	other.Category().Named("Shared name").Create()
+	other.Get("/api/v1/notes/"+n.ID.String()).AssertError(http.StatusNotFound, "note not found")
+	other.Get("/api/v1/categories/"+n.CategoryID.String()).AssertError(http.StatusNotFound, "category not found")
+
+	var notes []map[string]any
+	other.Get("/api/v1/notes").AssertStatus(http.StatusOK).Decode(&notes)
+	if len(notes) != 0 {
+		t.Fatalf("other workspace sees %d notes, want 0", len(notes))
+	}
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	h := apitest.New(t)
+
+	h.Post("/api/v1/workspaces", map[string]string{}).
+		AssertError(http.StatusBadRequest, "name is required")
+	h.Post("/api/v1/workspaces", map[string]string{"name": "Team"}).
+		AssertStatus(http.StatusCreated)
+
+	var workspaces []struct {
+		Name string `json:"name"`
+	}
+	h.Get("/api/v1/workspaces").AssertStatus(http.StatusOK).Decode(&workspaces)
+	if len(workspaces) != 2 {
+		t.Fatalf("got %d workspaces, want 2", len(workspaces))
+	}
+}
+
+func TestWorkspaceMembers(t *testing.T) {
+	h := apitest.New(t)
+	members := "/api/v1/workspaces/" + h.Workspace.ID.String() + "/members"
+
+	viewer := h.Member("viewer@example.com", workspace.RoleViewer)
+	if _, err := h.Users.Create(h.Context(), user.CreateInput{Email: "new@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("add", func(t *testing.T) {
+		h := h.For(t)
+		var m memberBody
+		h.Post(members, map[string]string{"email": "new@example.com", "role": "member"}).
+			AssertStatus(http.StatusCreated).Decode(&m)
+		if m.Role != "member" {
+			t.Fatalf("role = %q, want member", m.Role)
+		}
+
+		h.Post(members, map[string]string{"email": "new@example.com", "role": "member"}).
+			AssertError(http.StatusConflict, "user is already a member")
+		h.Post(members, map[string]string{"email": "nobody@example.com", "role": "member"}).
+			AssertError(http.StatusNotFound, "user not found")
+		h.Post(members, map[string]string{"email": "new@example.com", "role": "god"}).
+			AssertError(http.StatusBadRequest, `unknown role "god"`)
+	})
+
+	t.Run("viewer cannot manage", func(t *testing.T) {
+		viewer := viewer.For(t)
+		viewer.Get(members).AssertStatus(http.StatusOK)
+		viewer.Post(members, map[string]string{"email": "new@example.com", "role": "viewer"}).
+			AssertError(http.StatusForbidden, "insufficient workspace role")
+		viewer.Post("/api/v1/categories", map[string]string{"name": "Nope"}).
+			AssertError(http.StatusForbidden, "missing scope categories:admin")
+		viewer.Post("/api/v1/notes", map[string]string{"title": "Nope"}).
+			AssertError(http.StatusForbidden, "missing scope notes:write")
+	})
+
+	t.Run("last owner", func(t *testing.T) {
+		h := h.For(t)
+		h.Put(members+"/"+h.User.ID.String(), map[string]string{"role": "admin"}).
+			AssertError(http.StatusConflict, "workspace must keep an owner")
+		h.Delete(members+"/"+h.User.ID.String()).
+			AssertError(http.StatusConflict, "workspace must keep an owner")
+	})
+
+	t.Run("outsider", func(t *testing.T) {
+		h := h.For(t)
+		other := h.InNewWorkspace("Other")
+		h.Get("/api/v1/workspaces/"+missingID+"/members").
+			AssertError(http.StatusNotFound, "workspace not found")
+		viewer.For(t).Get("/api/v1/workspaces/"+other.Workspace.ID.String()+"/members").
+			AssertError(http.StatusNotFound, "workspace not found")
+	})
+
+	t.Run("removed member loses access", func(t *testing.T) {
+		h := h.For(t)
+		viewer := viewer.For(t)
+		viewer.Get("/api/v1/notes").AssertStatus(http.StatusOK)
+		h.Delete(members + "/" + viewer.User.ID.String()).AssertStatus(http.StatusNoContent)
+		viewer.Get("/api/v1/notes").
+			AssertError(http.StatusUnauthorized, "not a member of the workspace")
+	})
+}
@@ -0,0 +1,52 @@
+// Package jobs runs background work from a queue stored in the database.
+//
+// Handlers are registered by kind at startup. Workers claim due jobs with
+// FOR UPDATE SKIP LOCKED, so any number of instances can share the queue;
+// failed jobs are retried with backoff until they run out of attempts and
+// are left dead for inspection. Recurring jobs follow a cron schedule.
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// State is the lifecycle state of a job.
+type State string
+
+const (
+	// StatePending jobs wait for RunAt.
+	StatePending State = "pending"
+	// StateRunning jobs are held by a worker until their lock expires.
+	StateRunning State = "running"
+	// StateSucceeded jobs completed.
+	StateSucceeded State = "succeeded"
+	// StateDead jobs failed permanently or ran out of attempts. They are
+	// kept, with LastError, until removed by hand.
+	StateDead State = "dead"
+)
+
+// Job is a unit of background work.
+type Job struct {
+	ID   uuid.UUID
+	Kind string
+	// Payload is the JSON encoded argument of the handler.
+	Payload json.RawMessage
+	// WorkspaceID is the workspace the job runs in, or zero for jobs of
+	// the whole installation.
+	WorkspaceID uuid.UUID
+	State       State
+	// Attempts counts the runs started, including the current one.
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LastError   string
+	// Schedule is the cron expression of a recurring job.
+	Schedule string
+	// Key deduplicates jobs: at most one pending or running job has it.
+	Key       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]Job
+	// lockedUntil holds the lock expiry of running jobs.
+	lockedUntil map[uuid.UUID]time.Time
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		jobs:        make(map[uuid.UUID]Job),
+		lockedUntil: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, j Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if j.Key != "" {
+		for _, other := range r.jobs {
+			if other.Key == j.Key && (other.State == StatePending || other.State == StateRunning) {
+				return ErrAlreadyExists
+			}
+		}
+	}
+	r.jobs[j.ID] = j
+	return nil
+}
+
+func (r *MemoryRepository) Get(ctx context.Context, id uuid.UUID) (Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return j, nil
+}
+
+func (r *MemoryRepository) Claim(ctx context.Context, now, lockedUntil time.Time, limit int) ([]Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []Job
+	for _, j := range r.jobs {
+		switch {
+		case j.State == StatePending && !j.RunAt.After(now):
+		case j.State == StateRunning && !r.lockedUntil[j.ID].After(now):
+		default:
+			continue
+		}
+		due = append(due, j)
+	}
+	sort.Slice(due, func(i, k int) bool {
+		return due[i].RunAt.Before(due[k].RunAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i, j := range due {
+		j.State = StateRunning
+		j.Attempts++
+		j.UpdatedAt = now
+		r.jobs[j.ID] = j
+		r.lockedUntil[j.ID] = lockedUntil
+		due[i] = j
+	}
+	return due, nil
+}
+
+func (r *MemoryRepository) Finish(ctx context.Context, j Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.jobs[j.ID]
+	if !ok || existing.State != StateRunning || existing.Attempts != j.Attempts {
+		return ErrNotFound
+	}
+	existing.State = j.State
+	existing.RunAt = j.RunAt
+	existing.LastError = j.LastError
+	existing.UpdatedAt = j.UpdatedAt
+	r.jobs[j.ID] = existing
+	delete(r.lockedUntil, j.ID)
+	return nil
+}
+
+func (r *MemoryRepository) DeleteSucceeded(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, j := range r.jobs {
+		if j.State == StateSucceeded && j.UpdatedAt.Before(before) {
+			delete(r.jobs, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+)
+
+// PostgresRepository implements Repository using PostgreSQL.
+type PostgresRepository struct {
+	db *db.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository.
+func NewPostgresRepository(db *db.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+const jobColumns = `id, kind, payload, workspace_id, state, attempts, max_attempts, run_at, last_error, schedule, key, created_at, updated_at`
+
+// nullable returns nil for zero values, which are stored as NULL.
+func nullable[T comparable](v T) any {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return v
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, j Job) error {
+	var workspaceID any
+	if j.WorkspaceID != uuid.Nil {
+		workspaceID = j.WorkspaceID.String()
+	}
+	_, err := r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO jobs (`+jobColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		j.ID.String(), j.Kind, []byte(j.Payload), workspaceID, string(j.State), j.Attempts, j.MaxAttempts,
+		j.RunAt, j.LastError, j.Schedule, nullable(j.Key), j.CreatedAt, j.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepository) Get(ctx context.Context, id uuid.UUID) (Job, error) {
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+jobColumns+` FROM jobs WHERE id = $1`,
+		id.String(),
+	)
+	if err != nil {
+		return Job{}, err
+	}
+	jobs, err := scanJobs(rows)
+	if err != nil {
+		return Job{}, err
+	}
+	if len(jobs) == 0 {
+		return Job{}, ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+func (r *PostgresRepository) Claim(ctx context.Context, now, lockedUntil time.Time, limit int) ([]Job, error) {
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`UPDATE jobs SET state = 'running', attempts = attempts + 1, locked_until = $2, updated_at = $1
+		 WHERE id IN (
+		     SELECT id FROM jobs
+		     WHERE (state = 'pending' AND run_at <= $1) OR (state = 'running' AND locked_until <= $1)
+		     ORDER BY run_at
+		     LIMIT $3
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+jobColumns,
+		now, lockedUntil, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanJobs(rows)
+}
+
+func (r *PostgresRepository) Finish(ctx context.Context, j Job) error {
+	tag, err := r.db.Q(ctx).Exec(ctx,
+		`UPDATE jobs SET state = $3, run_at = $4, last_error = $5, locked_until = NULL, updated_at = $6
+		 WHERE id = $1 AND state = 'running' AND attempts = $2`,
+		j.ID.String(), j.Attempts, string(j.State), j.RunAt, j.LastError, j.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) DeleteSucceeded(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Q(ctx).Exec(ctx,
+		`DELETE FROM jobs WHERE state = 'succeeded' AND updated_at < $1`,
+		before,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanJobs(rows pgx.Rows) ([]Job, error) {
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var (
+			j                Job
+			id, state        string
+			workspaceID, key *string
+			payload          []byte
+		)
+		if err := rows.Scan(&id, &j.Kind, &payload, &workspaceID, &state, &j.Attempts, &j.MaxAttempts,
+			&j.RunAt, &j.LastError, &j.Schedule, &key, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			return nil, err
+		}
+		var err error
+		if j.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if workspaceID != nil {
+			if j.WorkspaceID, err = uuid.Parse(*workspaceID); err != nil {
+				return nil, err
+			}
+		}
+		if key != nil {
+			j.Key = *key
+		}
+		j.State = State(state)
+		j.Payload = payload
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
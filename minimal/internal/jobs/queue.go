@@ -0,0 +1,409 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// ErrUnknownKind is the error of jobs no handler is registered for. Such
+// jobs are not retried.
+var ErrUnknownKind = errors.New("no handler registered for job kind")
+
+// Handler runs a job. Returning an error fails the attempt; wrap it with
+// Permanent to give up without retrying.
+type Handler func(ctx context.Context, job Job) error
+
+// Handle returns a Handler decoding the payload of jobs into T.
+func Handle[T any](fn func(ctx context.Context, payload T) error) Handler {
+	return func(ctx context.Context, job Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("decode payload: %w", err))
+		}
+		return fn(ctx, payload)
+	}
+}
+
+// permanentError marks an error that retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails without further attempts.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Config tunes a Queue. Zero fields take the defaults.
+type Config struct {
+	// Workers is the number of jobs run concurrently by this instance.
+	Workers int
+	// PollInterval is how long an idle worker waits before looking for
+	// due jobs again.
+	PollInterval time.Duration
+	// Lease is how long a claimed job is held. A job still running after
+	// it may be claimed by another worker, so it should exceed the
+	// longest run.
+	Lease time.Duration
+	// MaxAttempts is the number of attempts jobs get unless enqueued
+	// with their own.
+	MaxAttempts int
+	// Backoff returns the delay before retrying a job that failed its
+	// attempt-th attempt.
+	Backoff func(attempt int) time.Duration
+}
+
+// Defaults of Config.
+const (
+	DefaultWorkers      = 4
+	DefaultPollInterval = time.Second
+	DefaultLease        = 15 * time.Minute
+	DefaultMaxAttempts  = 5
+)
+
+// ExponentialBackoff waits 10s after the first failure and doubles the
+// delay with every further one, up to an hour.
+func ExponentialBackoff(attempt int) time.Duration {
+	const base, limit = 10 * time.Second, time.Hour
+	d := base
+	for i := 1; i < attempt && d < limit; i++ {
+		d *= 2
+	}
+	return min(d, limit)
+}
+
+// Queue enqueues jobs and runs them on a pool of workers.
+type Queue struct {
+	repo   Repository
+	tx     db.Transactor
+	cfg    Config
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	// schedules holds the cron expressions of recurring kinds.
+	schedules map[string]string
+
+	// stop ends the workers; cancel aborts the jobs they run.
+	stop    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// NewQueue creates a new queue. Jobs run once Start is called.
+func NewQueue(repo Repository, tx db.Transactor, cfg Config, logger *zap.Logger) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = DefaultLease
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.Backoff == nil {
+		cfg.Backoff = ExponentialBackoff
+	}
+	return &Queue{
+		repo:      repo,
+		tx:        tx,
+		cfg:       cfg,
+		logger:    logger.Named("jobs.queue"),
+		handlers:  make(map[string]Handler),
+		schedules: make(map[string]string),
+	}
+}
+
+// Register sets the handler of jobs of kind. It is meant to be called at
+// startup, before Start.
+func (q *Queue) Register(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = h
+}
+
+// Options adjust an enqueued job.
+type Options struct {
+	// RunAt delays the job until the given time.
+	RunAt time.Time
+	// MaxAttempts overrides the attempts of the queue's Config.
+	MaxAttempts int
+	// Key makes Enqueue fail with ErrAlreadyExists while another pending
+	// or running job has the same key.
+	Key string
+}
+
+// Enqueue adds a job of kind with payload encoded as JSON. A job enqueued
+// in a workspace runs in it.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any, opts Options) (Job, error) {
+	j, err := q.newJob(ctx, kind, payload, opts)
+	if err != nil {
+		return Job{}, err
+	}
+	if err := q.repo.Create(ctx, j); err != nil {
+		if !errors.Is(err, ErrAlreadyExists) {
+			q.logger.Error("failed to enqueue job", zap.String("kind", kind), zap.Error(err))
+		}
+		return Job{}, err
+	}
+	return j, nil
+}
+
+func (q *Queue) newJob(ctx context.Context, kind string, payload any, opts Options) (Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("encode payload: %w", err)
+	}
+	now := time.Now().UTC()
+	j := Job{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     data,
+		State:       StatePending,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+		Key:         opts.Key,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if workspaceID, err := tenant.WorkspaceID(ctx); err == nil {
+		j.WorkspaceID = workspaceID
+	}
+	if j.MaxAttempts <= 0 {
+		j.MaxAttempts = q.cfg.MaxAttempts
+	}
+	if j.RunAt.IsZero() {
+		j.RunAt = now
+	}
+	return j, nil
+}
+
+// scheduleKey is the key of the pending run of the recurring job of kind.
+func scheduleKey(kind string) string {
+	return "schedule:" + kind
+}
+
+// Schedule runs jobs of kind with payload on the cron expression, see
+// ParseSchedule. Every instance may schedule the same kind at startup: a
+// single run is pending at a time, and the expression given last applies
+// from the next run on.
+func (q *Queue) Schedule(ctx context.Context, kind, expr string, payload any) error {
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	q.schedules[kind] = expr
+	q.mu.Unlock()
+
+	j, err := q.newJob(ctx, kind, payload, Options{
+		RunAt: schedule.Next(time.Now()),
+		Key:   scheduleKey(kind),
+	})
+	if err != nil {
+		return err
+	}
+	j.Schedule = expr
+	if err := q.repo.Create(ctx, j); err != nil && !errors.Is(err, ErrAlreadyExists) {
+		q.logger.Error("failed to schedule job", zap.String("kind", kind), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Start starts the workers.
+func (q *Queue) Start() {
+	q.stop = make(chan struct{})
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	for range q.cfg.Workers {
+		q.workers.Add(1)
+		go q.work()
+	}
+	q.logger.Info("job workers started", zap.Int("workers", q.cfg.Workers))
+}
+
+// Stop stops claiming jobs and waits for the running ones to finish. If
+// ctx is done first, their contexts are canceled and ctx's error is
+// returned; the jobs are claimed again once their lease expires.
+func (q *Queue) Stop(ctx context.Context) error {
+	if q.stop == nil {
+		return nil
+	}
+	close(q.stop)
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	defer q.cancel()
+
+	select {
+	case <-done:
+		q.logger.Info("job workers stopped")
+		return nil
+	case <-ctx.Done():
+		q.logger.Warn("job workers did not stop in time")
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.workers.Done()
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		if q.runNext() {
+			continue
+		}
+		select {
+		case <-q.stop:
+			return
+		case <-time.After(q.cfg.PollInterval):
+		}
+	}
+}
+
+// runNext claims and runs one due job. It reports whether there was one.
+func (q *Queue) runNext() bool {
+	now := time.Now().UTC()
+	claimed, err := q.repo.Claim(q.ctx, now, now.Add(q.cfg.Lease), 1)
+	if err != nil {
+		q.logger.Error("failed to claim jobs", zap.Error(err))
+		return false
+	}
+	if len(claimed) == 0 {
+		return false
+	}
+	q.run(claimed[0])
+	return true
+}
+
+func (q *Queue) run(j Job) {
+	logger := q.logger.With(
+		zap.String("id", j.ID.String()),
+		zap.String("kind", j.Kind),
+		zap.Int("attempt", j.Attempts),
+	)
+
+	started := time.Now()
+	err := q.call(j)
+	now := time.Now().UTC()
+
+	j.UpdatedAt = now
+	j.LastError = ""
+	var permanent permanentError
+	switch {
+	case err == nil:
+		j.State = StateSucceeded
+		logger.Info("job succeeded", zap.Duration("duration", now.Sub(started)))
+	case errors.As(err, &permanent) || errors.Is(err, ErrUnknownKind) || j.Attempts >= j.MaxAttempts:
+		j.State = StateDead
+		j.LastError = err.Error()
+		logger.Error("job failed permanently", zap.Error(err))
+	default:
+		j.State = StatePending
+		j.RunAt = now.Add(q.cfg.Backoff(j.Attempts))
+		j.LastError = err.Error()
+		logger.Warn("job failed, will retry", zap.Time("run_at", j.RunAt), zap.Error(err))
+	}
+
+	if err := q.finish(j); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			logger.Warn("job was claimed again while running; outcome discarded")
+			return
+		}
+		logger.Error("failed to store job outcome", zap.Error(err))
+	}
+}
+
+// call runs the handler of j, turning panics into errors.
+func (q *Queue) call(j Job) (err error) {
+	q.mu.RLock()
+	h, ok := q.handlers[j.Kind]
+	q.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, j.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx := q.ctx
+	if j.WorkspaceID != uuid.Nil {
+		ctx = tenant.WithWorkspace(ctx, j.WorkspaceID)
+	}
+	return h(ctx, j)
+}
+
+// finish stores the outcome of j and, once a recurring job is done,
+// schedules its next run.
+func (q *Queue) finish(j Job) error {
+	// Outcomes are stored even while stopping
+	ctx := context.WithoutCancel(q.ctx)
+	return q.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := q.repo.Finish(ctx, j); err != nil {
+			return err
+		}
+		if j.Schedule == "" || j.State == StatePending {
+			return nil
+		}
+
+		q.mu.RLock()
+		expr, ok := q.schedules[j.Kind]
+		q.mu.RUnlock()
+		if !ok {
+			expr = j.Schedule
+		}
+		schedule, err := ParseSchedule(expr)
+		if err != nil {
+			return err
+		}
+
+		next := j
+		next.ID = uuid.New()
+		next.Schedule = expr
+		next.State = StatePending
+		next.Attempts = 0
+		next.RunAt = schedule.Next(j.UpdatedAt)
+		next.LastError = ""
+		next.CreatedAt = j.UpdatedAt
+		if err := q.repo.Create(ctx, next); err != nil && !errors.Is(err, ErrAlreadyExists) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Purge deletes jobs that succeeded before the retention period.
+func (q *Queue) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	n, err := q.repo.DeleteSucceeded(ctx, time.Now().Add(-retention))
+	if err != nil {
+		q.logger.Error("failed to purge jobs", zap.Error(err))
+		return 0, err
+	}
+	return n, nil
+}
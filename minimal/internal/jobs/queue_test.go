@@ -0,0 +1,257 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+func newQueue(t *testing.T, repo jobs.Repository) *jobs.Queue {
+	t.Helper()
+	q := jobs.NewQueue(repo, db.NopTransactor{}, jobs.Config{
+		Workers:      2,
+		PollInterval: time.Millisecond,
+		MaxAttempts:  3,
+		Backoff:      func(int) time.Duration { return 0 },
+	}, zap.NewNop())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := q.Stop(ctx); err != nil {
+			t.Errorf("stop: %v", err)
+		}
+	})
+	return q
+}
+
+// waitFor polls the job until it reaches state.
+func waitFor(t *testing.T, repo jobs.Repository, id uuid.UUID, state jobs.State) jobs.Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		j, err := repo.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if j.State == state {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job is %s after %d attempts, want %s", j.State, j.Attempts, state)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func TestQueueRunsTypedHandlers(t *testing.T) {
+	repo := jobs.NewMemoryRepository()
+	q := newQueue(t, repo)
+
+	workspaceID := uuid.New()
+	got := make(chan string, 1)
+	q.Register("greet", jobs.Handle(func(ctx context.Context, g greeting) error {
+		if id, err := tenant.WorkspaceID(ctx); err != nil || id != workspaceID {
+			t.Errorf("workspace = %v, %v; want %v", id, err, workspaceID)
+		}
+		got <- g.Name
+		return nil
+	}))
+	q.Start()
+
+	ctx := tenant.WithWorkspace(context.Background(), workspaceID)
+	j, err := q.Enqueue(ctx, "greet", greeting{Name: "Ada"}, jobs.Options{})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	waitFor(t, repo, j.ID, jobs.StateSucceeded)
+	if name := <-got; name != "Ada" {
+		t.Errorf("payload name = %q, want Ada", name)
+	}
+}
+
+func TestQueueRetries(t *testing.T) {
+	repo := jobs.NewMemoryRepository()
+	q := newQueue(t, repo)
+
+	var calls atomic.Int32
+	q.Register("flaky", func(ctx context.Context, j jobs.Job) error {
+		if calls.Add(1) < 3 {
+			return errors.New("try again")
+		}
+		return nil
+	})
+	q.Register("broken", func(ctx context.Context, j jobs.Job) error {
+		return errors.New("always fails")
+	})
+	q.Register("hopeless", func(ctx context.Context, j jobs.Job) error {
+		return jobs.Permanent(errors.New("bad input"))
+	})
+	q.Register("panics", func(ctx context.Context, j jobs.Job) error {
+		panic("boom")
+	})
+	q.Start()
+
+	ctx := context.Background()
+	enqueue := func(kind string) uuid.UUID {
+		j, err := q.Enqueue(ctx, kind, nil, jobs.Options{})
+		if err != nil {
+			t.Fatalf("enqueue %s: %v", kind, err)
+		}
+		return j.ID
+	}
+
+	if j := waitFor(t, repo, enqueue("flaky"), jobs.StateSucceeded); j.Attempts != 3 {
+		t.Errorf("flaky job took %d attempts, want 3", j.Attempts)
+	}
+
+	tests := []struct {
+		kind     string
+		attempts int
+		lastErr  string
+	}{
+		{"broken", 3, "always fails"},
+		{"hopeless", 1, "bad input"},
+		{"panics", 3, "panic: boom"},
+		{"unknown", 1, "no handler registered for job kind: unknown"},
+	}
+	for _, tc := range tests {
+		j := waitFor(t, repo, enqueue(tc.kind), jobs.StateDead)
+		if j.Attempts != tc.attempts || j.LastError != tc.lastErr {
+			t.Errorf("%s job: %d attempts, error %q; want %d, %q", tc.kind, j.Attempts, j.LastError, tc.attempts, tc.lastErr)
+		}
+	}
+}
+
+func TestQueueDelaysAndDeduplicates(t *testing.T) {
+	repo := jobs.NewMemoryRepository()
+	q := newQueue(t, repo)
+	q.Register("later", func(ctx context.Context, j jobs.Job) error { return nil })
+	q.Start()
+
+	ctx := context.Background()
+	later, err := q.Enqueue(ctx, "later", nil, jobs.Options{RunAt: time.Now().Add(time.Hour), Key: "once"})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := q.Enqueue(ctx, "later", nil, jobs.Options{Key: "once"}); !errors.Is(err, jobs.ErrAlreadyExists) {
+		t.Errorf("second enqueue with key: %v, want ErrAlreadyExists", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if j, _ := repo.Get(ctx, later.ID); j.State != jobs.StatePending {
+		t.Errorf("delayed job is %s, want pending", j.State)
+	}
+}
+
+func TestQueueReschedulesRecurringJobs(t *testing.T) {
+	repo := jobs.NewMemoryRepository()
+	q := newQueue(t, repo)
+	q.Register("tick", func(ctx context.Context, j jobs.Job) error { return nil })
+
+	// A run scheduled earlier, by this or another instance, is due
+	ctx := context.Background()
+	now := time.Now().UTC()
+	first := jobs.Job{
+		ID:          uuid.New(),
+		Kind:        "tick",
+		Payload:     []byte("null"),
+		State:       jobs.StatePending,
+		MaxAttempts: 1,
+		RunAt:       now,
+		Schedule:    "@hourly",
+		Key:         "schedule:tick",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	// Scheduling the same kind again adds no run
+	if err := q.Schedule(ctx, "tick", "@hourly", nil); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	q.Start()
+	waitFor(t, repo, first.ID, jobs.StateSucceeded)
+
+	next, err := repo.Claim(ctx, time.Now().Add(2*time.Hour), time.Now().Add(2*time.Hour), 10)
+	if err != nil || len(next) != 1 {
+		t.Fatalf("got %d next runs, %v; want 1", len(next), err)
+	}
+	if next[0].Schedule != "@hourly" || next[0].RunAt.Minute() != 0 || !next[0].RunAt.After(time.Now()) {
+		t.Errorf("next run = %+v, want the next full hour", next[0])
+	}
+}
+
+func TestQueueStopWaitsForRunningJobs(t *testing.T) {
+	repo := jobs.NewMemoryRepository()
+	q := jobs.NewQueue(repo, db.NopTransactor{}, jobs.Config{Workers: 1, PollInterval: time.Millisecond}, zap.NewNop())
+
+	started, finished := make(chan struct{}), make(chan struct{})
+	q.Register("slow", func(ctx context.Context, j jobs.Job) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+		return nil
+	})
+	q.Start()
+	if _, err := q.Enqueue(context.Background(), "slow", nil, jobs.Options{}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	<-started
+
+	if err := q.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Error("Stop returned before the running job finished")
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2026, time.March, 14, 10, 7, 30, 0, time.UTC) // a Saturday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.March, 14, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.March, 14, 10, 15, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.March, 14, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, time.March, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		s, err := jobs.ParseSchedule(tc.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tc.expr, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: next = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "0 0 30 2 *"} {
+		if _, err := jobs.ParseSchedule(expr); !errors.Is(err, jobs.ErrInvalidSchedule) {
+			t.Errorf("ParseSchedule(%q) = %v, want ErrInvalidSchedule", expr, err)
+		}
+	}
+}
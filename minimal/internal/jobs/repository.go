@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound      = errors.New("job not found")
+	ErrAlreadyExists = errors.New("a job with this key is already pending")
+)
+
+// Repository defines the interface for job persistence. Jobs are not
+// scoped to the workspace of the context.
+type Repository interface {
+	// Create stores a job. It returns ErrAlreadyExists if a pending or
+	// running job has the same key.
+	Create(ctx context.Context, j Job) error
+	Get(ctx context.Context, id uuid.UUID) (Job, error)
+	// Claim marks up to limit due jobs running until lockedUntil, counting
+	// an attempt, and returns them. Due jobs are pending ones whose RunAt
+	// has passed and running ones whose lock expired.
+	Claim(ctx context.Context, now, lockedUntil time.Time, limit int) ([]Job, error)
+	// Finish stores the State, RunAt and LastError of a claimed job. It
+	// returns ErrNotFound if the job was claimed again since, which
+	// Attempts tells.
+	Finish(ctx context.Context, j Job) error
+	// DeleteSucceeded deletes succeeded jobs last updated before t.
+	DeleteSucceeded(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying fields in addition to those
+// already in it. Middleware adds the request ID, route and principal of a
+// request this way.
+//
+// The context carries fields rather than a logger, so every component keeps
+// its own named logger and adds the fields with FromContext.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FromContext returns l with the fields carried by ctx, or l itself if
+// ctx carries none.
+func FromContext(ctx context.Context, l *zap.Logger) *zap.Logger {
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
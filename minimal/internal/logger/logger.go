@@ -5,8 +5,9 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// New creates a new zap logger with the specified log level.
-func New(level string, development bool) (*zap.Logger, error) {
+// New creates a new zap logger with the specified log level. The returned
+// level changes the verbosity of the logger while it runs.
+func New(level string, development bool) (*zap.Logger, zap.AtomicLevel, error) {
 	var cfg zap.Config
 
 	if development {
@@ -24,8 +25,8 @@ func New(level string, development bool) (*zap.Logger, error) {
 
 	logger, err := cfg.Build()
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
 
-	return logger, nil
+	return logger, cfg.Level, nil
 }
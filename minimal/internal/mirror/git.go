@@ -0,0 +1,47 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// init creates the working tree and its repository unless they exist.
+func (m *Mirror) init(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(m.cfg.Dir, ".git")); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(m.cfg.Dir, 0o755); err != nil {
+		return err
+	}
+	_, err := m.git(ctx, nil, "init", "--quiet")
+	return err
+}
+
+// staged reports whether changes under dir are staged for commit.
+func (m *Mirror) staged(ctx context.Context, dir string) (bool, error) {
+	_, err := m.git(ctx, nil, "diff", "--cached", "--quiet", "--", dir)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
+// git runs a git command in the working tree with env added to the
+// environment.
+func (m *Mirror) git(ctx context.Context, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", m.cfg.Dir}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("git %v: %w: %s", args, err, bytes.TrimSpace(out))
+	}
+	return out, nil
+}
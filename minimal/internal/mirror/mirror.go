@@ -0,0 +1,222 @@
+// Package mirror keeps a plain-text copy of the notes of every workspace in
+// a local git repository, for disaster recovery and offline reading.
+//
+// Every audited change of a category or note enqueues a job, in the
+// transaction of the change, that exports the workspace to
+// <dir>/<workspace id> in the layout of a Markdown archive and commits the
+// result with the actor and time of the change. Each job exports the whole
+// workspace, so changes made in quick succession may be folded into one
+// commit and a resync repairs any drift. Jobs run on whichever instance
+// claims them, so every instance running jobs must share the working tree.
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/transfer"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/user"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// JobKind is the kind of the jobs that export a workspace and commit it.
+const JobKind = "mirror.sync"
+
+// ErrResyncPending is returned by Resync while an earlier resync of the
+// workspace has not run yet.
+var ErrResyncPending = errors.New("resync already pending")
+
+// Config configures a Mirror.
+type Config struct {
+	// Dir is the git working tree. It is created and initialised if it is
+	// not a repository yet.
+	Dir string
+	// AuthorName and AuthorEmail commit every change, and author those
+	// without an actor, such as the ones made from the CLI.
+	AuthorName  string
+	AuthorEmail string
+}
+
+// Defaults of Config.
+const (
+	DefaultAuthorName  = "Notes"
+	DefaultAuthorEmail = "notes@localhost"
+)
+
+// Change is the payload of a sync job: the commit that records it.
+type Change struct {
+	// ActorID is the user who authored the change, or zero if nobody did.
+	ActorID uuid.UUID `json:"actor_id"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+// Mirror exports workspaces to a git working tree.
+type Mirror struct {
+	cfg      Config
+	transfer *transfer.Service
+	users    *user.Service
+	queue    *jobs.Queue
+	logger   *zap.Logger
+
+	// mu serialises the syncs of this instance, which share the index.
+	mu sync.Mutex
+}
+
+// New creates a new Mirror, initialising the repository in cfg.Dir if
+// needed. Call Register to start mirroring changes.
+func New(ctx context.Context, cfg Config, transfer *transfer.Service, users *user.Service, queue *jobs.Queue, logger *zap.Logger) (*Mirror, error) {
+	if cfg.AuthorName == "" {
+		cfg.AuthorName = DefaultAuthorName
+	}
+	if cfg.AuthorEmail == "" {
+		cfg.AuthorEmail = DefaultAuthorEmail
+	}
+	m := &Mirror{
+		cfg:      cfg,
+		transfer: transfer,
+		users:    users,
+		queue:    queue,
+		logger:   logger.Named("mirror"),
+	}
+	if err := m.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialise git mirror: %w", err)
+	}
+	return m, nil
+}
+
+// Register registers the sync job with the queue and enqueues a job for
+// every change of a category or note audited by auditor.
+func (m *Mirror) Register(auditor *audit.Service) {
+	m.queue.Register(JobKind, jobs.Handle(m.sync))
+	auditor.Listen(m.record)
+}
+
+// record enqueues the sync of a change.
+func (m *Mirror) record(ctx context.Context, e audit.Event) error {
+	if e.EntityType != note.EntityType && e.EntityType != category.EntityType {
+		return nil
+	}
+	_, err := m.queue.Enqueue(ctx, JobKind, Change{
+		ActorID: e.ActorID,
+		Message: message(e),
+		At:      e.CreatedAt,
+	}, jobs.Options{})
+	return err
+}
+
+// message describes an event in a commit subject, e.g. `Update note "Plan"`.
+func message(e audit.Event) string {
+	verb := map[audit.Action]string{
+		audit.ActionCreate: "Create",
+		audit.ActionUpdate: "Update",
+		audit.ActionDelete: "Delete",
+	}[e.Action]
+	field := "title"
+	if e.EntityType == category.EntityType {
+		field = "name"
+	}
+	if c, ok := e.Changes[field]; ok {
+		for _, v := range []any{c.After, c.Before} {
+			if s, ok := v.(string); ok && s != "" {
+				return fmt.Sprintf("%s %s %q", verb, e.EntityType, s)
+			}
+		}
+	}
+	return fmt.Sprintf("%s %s %s", verb, e.EntityType, e.EntityID)
+}
+
+// Resync enqueues a full export of the workspace in ctx, authored by the
+// principal in ctx.
+func (m *Mirror) Resync(ctx context.Context) (jobs.Job, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	c := Change{Message: "Resync", At: time.Now().UTC()}
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		c.ActorID = p.UserID
+	}
+
+	j, err := m.queue.Enqueue(ctx, JobKind, c, jobs.Options{Key: "mirror.resync:" + workspaceID.String()})
+	if errors.Is(err, jobs.ErrAlreadyExists) {
+		return jobs.Job{}, ErrResyncPending
+	}
+	return j, err
+}
+
+// sync exports the workspace in ctx over its directory and commits the
+// result, if anything changed.
+func (m *Mirror) sync(ctx context.Context, c Change) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	dir := workspaceID.String()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Export from scratch so deleted and renamed files disappear
+	if err := os.RemoveAll(filepath.Join(m.cfg.Dir, dir)); err != nil {
+		return err
+	}
+	if err := m.transfer.ExportDir(ctx, filepath.Join(m.cfg.Dir, dir)); err != nil {
+		return err
+	}
+	if _, err := m.git(ctx, nil, "add", "--all", "--", dir); err != nil {
+		return err
+	}
+	changed, err := m.staged(ctx, dir)
+	if err != nil || !changed {
+		return err
+	}
+
+	name, email := m.author(ctx, c.ActorID)
+	date := c.At.Format(time.RFC3339)
+	env := []string{
+		"GIT_AUTHOR_NAME=" + name,
+		"GIT_AUTHOR_EMAIL=" + email,
+		"GIT_AUTHOR_DATE=" + date,
+		"GIT_COMMITTER_NAME=" + m.cfg.AuthorName,
+		"GIT_COMMITTER_EMAIL=" + m.cfg.AuthorEmail,
+		"GIT_COMMITTER_DATE=" + date,
+	}
+	if _, err := m.git(ctx, env, "-c", "commit.gpgSign=false", "commit", "--quiet", "--no-verify", "--message", c.Message, "--", dir); err != nil {
+		return err
+	}
+	m.logger.Debug("mirrored workspace",
+		zap.String("workspace_id", dir),
+		zap.String("message", c.Message),
+	)
+	return nil
+}
+
+// author returns the name and email of the commit author of a change by
+// actorID, falling back to the configured author.
+func (m *Mirror) author(ctx context.Context, actorID uuid.UUID) (name, email string) {
+	if actorID == uuid.Nil {
+		return m.cfg.AuthorName, m.cfg.AuthorEmail
+	}
+	u, err := m.users.GetByID(ctx, actorID)
+	if err != nil {
+		m.logger.Warn("failed to look up commit author", zap.String("user_id", actorID.String()), zap.Error(err))
+		return m.cfg.AuthorName, m.cfg.AuthorEmail
+	}
+	if u.Name == "" {
+		return u.Email, u.Email
+	}
+	return u.Name, u.Email
+}
@@ -0,0 +1,45 @@
+package mirror
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+)
+
+// Module serves /mirror and syncs the changes audited by auditor to the
+// git mirror.
+type Module struct {
+	modules.Base
+	mirror  *Mirror
+	routes  modules.Routes
+	auditor *audit.Service
+}
+
+// NewModule creates the mirror module. mirror is nil when the git mirror
+// is disabled, in which case routes still serve /mirror but nothing is
+// synced.
+func NewModule(mirror *Mirror, routes modules.Routes, auditor *audit.Service) *Module {
+	return &Module{mirror: mirror, routes: routes, auditor: auditor}
+}
+
+func (m *Module) Name() string { return "mirror" }
+
+// The mirror exports categories and notes when the audit log records a
+// change.
+func (m *Module) Dependencies() []string { return []string{"audit", "transfer"} }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/mirror"))
+}
+
+// RegisterJobs registers the sync job with the queue the mirror was
+// created with, which is the application's.
+func (m *Module) RegisterJobs(context.Context, *jobs.Queue) error {
+	if m.mirror != nil {
+		m.mirror.Register(m.auditor)
+	}
+	return nil
+}
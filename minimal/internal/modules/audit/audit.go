@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ID represents an audit event identifier.
+type ID = uuid.UUID
+
+// Action is the kind of mutation an event records.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Event records a single mutation of an entity in a workspace.
+type Event struct {
+	ID          ID
+	WorkspaceID uuid.UUID
+	// ActorID is the user who made the change; it is zero for changes made
+	// outside a request, e.g. from the CLI.
+	ActorID    uuid.UUID
+	RequestID  string
+	ClientIP   string
+	EntityType string
+	EntityID   uuid.UUID
+	Action     Action
+	Changes    Changes
+	CreatedAt  time.Time
+}
+
+// Snapshot is the audited state of an entity, keyed by field name.
+type Snapshot map[string]any
+
+// Change is the value of a field before and after a mutation. Before is nil
+// on create and After is nil on delete.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Changes maps field names to the changes made to them.
+type Changes map[string]Change
+
+// Diff returns the fields whose values differ between before and after.
+// Either snapshot may be nil.
+func Diff(before, after Snapshot) Changes {
+	changes := make(Changes)
+	for field, b := range before {
+		if a, ok := after[field]; !ok || !equal(a, b) {
+			changes[field] = Change{Before: b, After: after[field]}
+		}
+	}
+	for field, a := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = Change{After: a}
+		}
+	}
+	return changes
+}
+
+// equal compares values by their JSON form so that e.g. a UUID and its
+// string are treated alike.
+func equal(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(ja) == string(jb)
+}
+
+// Origin describes the request a mutation came from.
+type Origin struct {
+	RequestID string
+	ClientIP  string
+}
+
+type originKey struct{}
+
+// WithOrigin returns a copy of ctx carrying origin.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFrom returns the origin stored in ctx, if any.
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
+
+// NewID generates a new audit event ID.
+func NewID() ID {
+	return uuid.New()
+}
+
+// ParseID parses a string into an audit event ID.
+func ParseID(s string) (ID, error) {
+	return uuid.Parse(s)
+}
@@ -0,0 +1,58 @@
+package audit_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+)
+
+func TestDiff(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name          string
+		before, after audit.Snapshot
+		want          audit.Changes
+	}{
+		{
+			name:  "create",
+			after: audit.Snapshot{"name": "Work", "schema": nil},
+			want:  audit.Changes{"name": {After: "Work"}, "schema": {}},
+		},
+		{
+			name:   "delete",
+			before: audit.Snapshot{"name": "Work"},
+			want:   audit.Changes{"name": {Before: "Work"}},
+		},
+		{
+			name:   "update",
+			before: audit.Snapshot{"name": "Work", "content": "same"},
+			after:  audit.Snapshot{"name": "Office", "content": "same"},
+			want:   audit.Changes{"name": {Before: "Work", After: "Office"}},
+		},
+		{
+			name:   "added and removed fields",
+			before: audit.Snapshot{"old": 1},
+			after:  audit.Snapshot{"new": 2},
+			want:   audit.Changes{"old": {Before: 1}, "new": {After: 2}},
+		},
+		{
+			name:   "values compared as JSON",
+			before: audit.Snapshot{"category_id": id, "priority": 1, "tags": []string{"a"}},
+			after:  audit.Snapshot{"category_id": id.String(), "priority": float64(1), "tags": []any{"a"}},
+			want:   audit.Changes{},
+		},
+		{
+			name: "nothing",
+			want: audit.Changes{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := audit.Diff(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
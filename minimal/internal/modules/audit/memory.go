@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	events []Event
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, e Event) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.WorkspaceID = workspaceID
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *MemoryRepository) List(ctx context.Context, f Filter) ([]Event, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []Event
+	for _, e := range r.events {
+		if e.WorkspaceID == workspaceID && matches(e, f) {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	if f.Limit > 0 && len(events) > f.Limit {
+		events = events[:f.Limit]
+	}
+	return events, nil
+}
+
+func matches(e Event, f Filter) bool {
+	switch {
+	case f.EntityType != "" && e.EntityType != f.EntityType:
+		return false
+	case f.EntityID != (ID{}) && e.EntityID != f.EntityID:
+		return false
+	case f.ActorID != (ID{}) && e.ActorID != f.ActorID:
+		return false
+	case !f.Since.IsZero() && e.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.CreatedAt.Before(f.Until):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migrations holds the migrations of the audit log.
+var Migrations, _ = fs.Sub(migrationsFS, "migrations")
+
+// Module serves the audit log under /audit.
+type Module struct {
+	modules.Base
+	routes modules.Routes
+	db     modules.Pinger
+}
+
+// NewModule creates the audit module. routes serves /audit and db is the
+// database events are stored in, or nil if they are kept in memory.
+func NewModule(routes modules.Routes, db modules.Pinger) *Module {
+	return &Module{routes: routes, db: db}
+}
+
+func (m *Module) Name() string { return "audit" }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/audit"))
+}
+
+func (m *Module) Migrations() fs.FS { return Migrations }
+
+// Health pings the database events are stored in.
+func (m *Module) Health(ctx context.Context) error {
+	return modules.Ping(ctx, m.db)
+}
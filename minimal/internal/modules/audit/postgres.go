@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// eventRow is the database representation of an audit event.
+type eventRow struct {
+	ID          string    `db:"id"`
+	WorkspaceID string    `db:"workspace_id"`
+	ActorID     *string   `db:"actor_id"`
+	RequestID   string    `db:"request_id"`
+	ClientIP    string    `db:"client_ip"`
+	EntityType  string    `db:"entity_type"`
+	EntityID    string    `db:"entity_id"`
+	Action      string    `db:"action"`
+	Changes     []byte    `db:"changes"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (r eventRow) toDomain() (Event, error) {
+	id, err := ParseID(r.ID)
+	if err != nil {
+		return Event{}, err
+	}
+	workspaceID, err := uuid.Parse(r.WorkspaceID)
+	if err != nil {
+		return Event{}, err
+	}
+	entityID, err := uuid.Parse(r.EntityID)
+	if err != nil {
+		return Event{}, err
+	}
+	var actorID uuid.UUID
+	if r.ActorID != nil {
+		if actorID, err = uuid.Parse(*r.ActorID); err != nil {
+			return Event{}, err
+		}
+	}
+	var changes Changes
+	if err := json.Unmarshal(r.Changes, &changes); err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:          id,
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		RequestID:   r.RequestID,
+		ClientIP:    r.ClientIP,
+		EntityType:  r.EntityType,
+		EntityID:    entityID,
+		Action:      Action(r.Action),
+		Changes:     changes,
+		CreatedAt:   r.CreatedAt,
+	}, nil
+}
+
+func toRow(e Event) (eventRow, error) {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return eventRow{}, err
+	}
+	row := eventRow{
+		ID:          e.ID.String(),
+		WorkspaceID: e.WorkspaceID.String(),
+		RequestID:   e.RequestID,
+		ClientIP:    e.ClientIP,
+		EntityType:  e.EntityType,
+		EntityID:    e.EntityID.String(),
+		Action:      string(e.Action),
+		Changes:     changes,
+		CreatedAt:   e.CreatedAt,
+	}
+	if e.ActorID != (uuid.UUID{}) {
+		actorID := e.ActorID.String()
+		row.ActorID = &actorID
+	}
+	return row, nil
+}
+
+const eventColumns = `id, workspace_id, actor_id, request_id, client_ip, entity_type, entity_id, action, changes, created_at`
+
+// PostgresRepository implements Repository using PostgreSQL.
+type PostgresRepository struct {
+	db *db.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository.
+func NewPostgresRepository(db *db.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, e Event) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	e.WorkspaceID = workspaceID
+	row, err := toRow(e)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO audit_events (`+eventColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		row.ID, row.WorkspaceID, row.ActorID, row.RequestID, row.ClientIP,
+		row.EntityType, row.EntityID, row.Action, row.Changes, row.CreatedAt,
+	)
+	return err
+}
+
+func (r *PostgresRepository) List(ctx context.Context, f Filter) ([]Event, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{"workspace_id = $1"}
+	args := []any{workspaceID.String()}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != (uuid.UUID{}) {
+		add("entity_id = $%d", f.EntityID.String())
+	}
+	if f.ActorID != (uuid.UUID{}) {
+		add("actor_id = $%d", f.ActorID.String())
+	}
+	if !f.Since.IsZero() {
+		add("created_at >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created_at < $%d", f.Until)
+	}
+	args = append(args, f.Limit)
+
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+eventColumns+` FROM audit_events
+		 WHERE `+strings.Join(where, " AND ")+`
+		 ORDER BY created_at DESC
+		 LIMIT $`+fmt.Sprint(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var row eventRow
+		if err := rows.Scan(&row.ID, &row.WorkspaceID, &row.ActorID, &row.RequestID, &row.ClientIP,
+			&row.EntityType, &row.EntityID, &row.Action, &row.Changes, &row.CreatedAt); err != nil {
+			return nil, err
+		}
+		e, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Filter narrows the events returned by List. Zero fields match anything.
+type Filter struct {
+	EntityType string
+	EntityID   uuid.UUID
+	ActorID    uuid.UUID
+	Since      time.Time
+	Until      time.Time
+	// Limit caps the number of events returned, newest first.
+	Limit int
+}
+
+// Repository defines the interface for audit event persistence.
+// Events are scoped to the workspace in the context.
+type Repository interface {
+	Create(ctx context.Context, e Event) error
+	List(ctx context.Context, f Filter) ([]Event, error)
+}
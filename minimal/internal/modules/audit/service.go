@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// DefaultLimit and MaxLimit bound the number of events List returns.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 500
+)
+
+// Listener is called with every recorded event, in the transaction that
+// records it. Returning an error fails the mutation.
+type Listener func(ctx context.Context, e Event) error
+
+// Service records and queries audit events.
+type Service struct {
+	repo   Repository
+	logger *zap.Logger
+
+	mu        sync.RWMutex
+	listeners []Listener
+}
+
+// NewService creates a new audit service.
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger.Named("audit.service"),
+	}
+}
+
+// Record stores an event for a mutation of an entity. before is nil for
+// creates and after is nil for deletes. The actor and origin are taken from
+// ctx. Call it in the transaction that makes the change so that the event
+// is stored if and only if the change is.
+func (s *Service) Record(ctx context.Context, entityType string, entityID uuid.UUID, before, after Snapshot) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	action := ActionUpdate
+	switch {
+	case before == nil:
+		action = ActionCreate
+	case after == nil:
+		action = ActionDelete
+	}
+
+	origin := OriginFrom(ctx)
+	e := Event{
+		ID:          NewID(),
+		WorkspaceID: workspaceID,
+		RequestID:   origin.RequestID,
+		ClientIP:    origin.ClientIP,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Action:      action,
+		Changes:     Diff(before, after),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		e.ActorID = p.UserID
+	}
+
+	if err := s.repo.Create(ctx, e); err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to record audit event",
+			zap.String("entity_type", entityType),
+			zap.String("entity_id", entityID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, l := range listeners {
+		if err := l(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen adds l to the listeners of recorded events. It is meant to be
+// called at startup.
+func (s *Service) Listen(l Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// List retrieves the events matching f, newest first.
+func (s *Service) List(ctx context.Context, f Filter) ([]Event, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultLimit
+	}
+	f.Limit = min(f.Limit, MaxLimit)
+
+	events, err := s.repo.List(ctx, f)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to list audit events", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,83 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+func TestRecord(t *testing.T) {
+	s := audit.NewService(audit.NewMemoryRepository(), zap.NewNop())
+	workspaceID, actorID, entityID := uuid.New(), uuid.New(), uuid.New()
+	ctx := tenant.WithWorkspace(context.Background(), workspaceID)
+	ctx = auth.WithPrincipal(ctx, auth.Principal{UserID: actorID})
+	ctx = audit.WithOrigin(ctx, audit.Origin{RequestID: "req-1", ClientIP: "192.0.2.1"})
+
+	var heard []audit.Event
+	s.Listen(func(ctx context.Context, e audit.Event) error {
+		heard = append(heard, e)
+		return nil
+	})
+
+	for _, change := range []struct{ before, after audit.Snapshot }{
+		{nil, audit.Snapshot{"name": "Work"}},
+		{audit.Snapshot{"name": "Work"}, audit.Snapshot{"name": "Office"}},
+		{audit.Snapshot{"name": "Office"}, nil},
+	} {
+		if err := s.Record(ctx, "category", entityID, change.before, change.after); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	events, err := s.List(ctx, audit.Filter{EntityID: entityID})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(events) != 3 || len(heard) != 3 {
+		t.Fatalf("got %d events and heard %d, want 3", len(events), len(heard))
+	}
+	for i, want := range []audit.Action{audit.ActionCreate, audit.ActionUpdate, audit.ActionDelete} {
+		e := heard[i]
+		if e.Action != want {
+			t.Errorf("event %d action = %s, want %s", i, e.Action, want)
+		}
+		if e.WorkspaceID != workspaceID || e.ActorID != actorID || e.EntityType != "category" || e.EntityID != entityID {
+			t.Errorf("event %d = %+v, want the workspace, actor and entity of the change", i, e)
+		}
+		if e.RequestID != "req-1" || e.ClientIP != "192.0.2.1" {
+			t.Errorf("event %d origin = %q from %q, want req-1 from 192.0.2.1", i, e.RequestID, e.ClientIP)
+		}
+	}
+	if c := heard[1].Changes["name"]; c.Before != "Work" || c.After != "Office" {
+		t.Errorf("update changes = %+v, want name Work -> Office", heard[1].Changes)
+	}
+
+	// Events outside a request have no actor
+	if err := s.Record(tenant.WithWorkspace(context.Background(), workspaceID), "category", uuid.New(), nil, audit.Snapshot{}); err != nil {
+		t.Fatalf("Record without principal: %v", err)
+	}
+	if e := heard[3]; e.ActorID != uuid.Nil || e.RequestID != "" {
+		t.Errorf("event without request = %+v, want no actor or origin", e)
+	}
+
+	if err := s.Record(context.Background(), "category", entityID, nil, audit.Snapshot{}); !errors.Is(err, tenant.ErrNoWorkspace) {
+		t.Errorf("Record without workspace = %v, want ErrNoWorkspace", err)
+	}
+}
+
+func TestRecordListenerError(t *testing.T) {
+	s := audit.NewService(audit.NewMemoryRepository(), zap.NewNop())
+	failed := errors.New("mirror unavailable")
+	s.Listen(func(ctx context.Context, e audit.Event) error { return failed })
+
+	ctx := tenant.WithWorkspace(context.Background(), uuid.New())
+	if err := s.Record(ctx, "note", uuid.New(), nil, audit.Snapshot{"title": "Plan"}); !errors.Is(err, failed) {
+		t.Errorf("Record = %v, want the listener's error", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package batch runs ordered lists of category and note mutations in a
+// single transaction.
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+)
+
+// Action is the mutation an operation performs.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Entity types operations apply to.
+const (
+	TypeCategory = category.EntityType
+	TypeNote     = note.EntityType
+)
+
+// refPrefix marks an ID field that refers to the entity created by an
+// earlier operation of the batch, e.g. "$work" for the operation with ref
+// "work".
+const refPrefix = "$"
+
+// DefaultMaxOperations is the default limit on the size of a batch.
+const DefaultMaxOperations = 100
+
+var (
+	ErrEmpty             = errors.New("operations are required")
+	ErrTooManyOperations = errors.New("too many operations")
+	ErrInvalidOperation  = errors.New("invalid operation")
+)
+
+// Operation is one mutation of a batch. ID and CategoryID take either an
+// ID or a reference to a create earlier in the batch.
+type Operation struct {
+	// Ref names the entity a create makes, for later operations to refer to.
+	Ref    string
+	Type   string
+	Action Action
+	// ID is the entity to update or delete.
+	ID string
+	// Name and MetadataSchema are the fields of a category.
+	Name           string
+	MetadataSchema json.RawMessage
+	// CategoryID, Title, Content and Metadata are the fields of a note.
+	CategoryID string
+	Title      string
+	Content    string
+	Metadata   map[string]any
+}
+
+// Result is the outcome of an operation. Category or Note holds the entity
+// after a create or update.
+type Result struct {
+	Ref      string
+	Type     string
+	Action   Action
+	ID       uuid.UUID
+	Category *category.Category
+	Note     *note.Note
+}
+
+// OpError is the failure of an operation. The batch stops at the first one
+// and nothing of it is stored.
+type OpError struct {
+	Index int
+	Err   error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// invalidError describes why an operation is invalid. It matches
+// ErrInvalidOperation.
+type invalidError string
+
+func (e invalidError) Error() string {
+	return string(e)
+}
+
+func (e invalidError) Unwrap() error {
+	return ErrInvalidOperation
+}
+
+func invalid(format string, args ...any) error {
+	return invalidError(fmt.Sprintf(format, args...))
+}
+
+// refName returns the reference in an ID field, if it holds one.
+func refName(s string) (string, bool) {
+	return strings.CutPrefix(s, refPrefix)
+}
@@ -0,0 +1,26 @@
+package batch
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+// Module serves batches of category and note operations under /batch.
+type Module struct {
+	modules.Base
+	routes modules.Routes
+}
+
+// NewModule creates the batch module. routes serves /batch.
+func NewModule(routes modules.Routes) *Module {
+	return &Module{routes: routes}
+}
+
+func (m *Module) Name() string { return "batch" }
+
+// Batches operate on categories and notes.
+func (m *Module) Dependencies() []string { return []string{"category", "note"} }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/batch"))
+}
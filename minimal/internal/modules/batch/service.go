@@ -0,0 +1,211 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+)
+
+// Service executes batches through the category and note services, so
+// every operation is validated and audited as if made on its own.
+type Service struct {
+	categories    *category.Service
+	notes         *note.Service
+	tx            db.Transactor
+	maxOperations int
+	logger        *zap.Logger
+}
+
+// NewService creates a new batch service accepting up to maxOperations
+// operations per batch.
+func NewService(categories *category.Service, notes *note.Service, tx db.Transactor, maxOperations int, logger *zap.Logger) *Service {
+	return &Service{
+		categories:    categories,
+		notes:         notes,
+		tx:            tx,
+		maxOperations: maxOperations,
+		logger:        logger.Named("batch.service"),
+	}
+}
+
+// Execute runs ops in order in one transaction. If an operation fails the
+// transaction is rolled back and an *OpError is returned; operations are
+// validated before any is run, so malformed batches fail without writes.
+func (s *Service) Execute(ctx context.Context, ops []Operation) ([]Result, error) {
+	switch {
+	case len(ops) == 0:
+		return nil, ErrEmpty
+	case len(ops) > s.maxOperations:
+		return nil, fmt.Errorf("%w: at most %d are allowed", ErrTooManyOperations, s.maxOperations)
+	}
+	if err := validate(ops); err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		results = make([]Result, 0, len(ops))
+		refs := make(map[string]uuid.UUID)
+		for i, op := range ops {
+			res, err := s.execute(ctx, op, refs)
+			if err != nil {
+				return &OpError{Index: i, Err: err}
+			}
+			if op.Ref != "" {
+				refs[op.Ref] = res.ID
+			}
+			results = append(results, res)
+		}
+		return nil
+	})
+	if err != nil {
+		var opErr *OpError
+		if !errors.As(err, &opErr) {
+			logger.FromContext(ctx, s.logger).Error("failed to execute batch", zap.Error(err))
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *Service) execute(ctx context.Context, op Operation, refs map[string]uuid.UUID) (Result, error) {
+	res := Result{Ref: op.Ref, Type: op.Type, Action: op.Action}
+
+	var id uuid.UUID
+	if op.Action != ActionCreate {
+		id = resolve(op.ID, refs)
+	}
+	res.ID = id
+
+	switch op.Type {
+	case TypeCategory:
+		switch op.Action {
+		case ActionCreate:
+			c, err := s.categories.Create(ctx, category.CreateInput{Name: op.Name, MetadataSchema: op.MetadataSchema})
+			if err != nil {
+				return res, err
+			}
+			res.ID, res.Category = c.ID, &c
+		case ActionUpdate:
+			c, err := s.categories.Update(ctx, category.UpdateInput{ID: id, Name: op.Name, MetadataSchema: op.MetadataSchema})
+			if err != nil {
+				return res, err
+			}
+			res.Category = &c
+		case ActionDelete:
+			return res, s.categories.Delete(ctx, id)
+		}
+	case TypeNote:
+		var categoryID category.ID
+		if op.Action != ActionDelete {
+			categoryID = resolve(op.CategoryID, refs)
+		}
+		switch op.Action {
+		case ActionCreate:
+			n, err := s.notes.Create(ctx, note.CreateInput{CategoryID: categoryID, Title: op.Title, Content: op.Content, Metadata: op.Metadata})
+			if err != nil {
+				return res, err
+			}
+			res.ID, res.Note = n.ID, &n
+		case ActionUpdate:
+			n, err := s.notes.Update(ctx, note.UpdateInput{ID: id, CategoryID: categoryID, Title: op.Title, Content: op.Content, Metadata: op.Metadata})
+			if err != nil {
+				return res, err
+			}
+			res.Note = &n
+		case ActionDelete:
+			return res, s.notes.Delete(ctx, id)
+		}
+	}
+	return res, nil
+}
+
+// resolve returns the ID in an ID field, looking up references. validate
+// has checked that the field parses and references are defined.
+func resolve(s string, refs map[string]uuid.UUID) uuid.UUID {
+	if name, ok := refName(s); ok {
+		return refs[name]
+	}
+	return uuid.MustParse(s)
+}
+
+// validate checks the fields of every operation and that references point
+// to creates of the right type earlier in the batch.
+func validate(ops []Operation) error {
+	defined := make(map[string]string)
+	checkID := func(field, value, typ string) error {
+		if value == "" {
+			return invalid("%s is required", field)
+		}
+		if name, ok := refName(value); ok {
+			refType, found := defined[name]
+			switch {
+			case !found:
+				return invalid("%s refers to unknown ref %q", field, name)
+			case refType != typ:
+				return invalid("%s refers to a %s", field, refType)
+			}
+			return nil
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			return invalid("invalid %s", field)
+		}
+		return nil
+	}
+
+	for i, op := range ops {
+		err := func() error {
+			if op.Type != TypeCategory && op.Type != TypeNote {
+				return invalid("type must be %s or %s", TypeCategory, TypeNote)
+			}
+
+			switch op.Action {
+			case ActionCreate:
+			case ActionUpdate, ActionDelete:
+				if err := checkID("id", op.ID, op.Type); err != nil {
+					return err
+				}
+			default:
+				return invalid("action must be create, update or delete")
+			}
+
+			switch op.Type {
+			case TypeCategory:
+				if op.Action != ActionDelete && op.Name == "" {
+					return invalid("name is required")
+				}
+			case TypeNote:
+				if op.Action != ActionDelete {
+					if op.Title == "" {
+						return invalid("title is required")
+					}
+					if err := checkID("category_id", op.CategoryID, TypeCategory); err != nil {
+						return err
+					}
+				}
+			}
+
+			if op.Ref != "" {
+				switch {
+				case op.Action != ActionCreate:
+					return invalid("ref is only allowed on create")
+				case defined[op.Ref] != "":
+					return invalid("ref %q is already defined", op.Ref)
+				}
+				defined[op.Ref] = op.Type
+			}
+			return nil
+		}()
+		if err != nil {
+			return &OpError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
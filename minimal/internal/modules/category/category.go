@@ -1,6 +1,7 @@
 package category
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,10 +12,14 @@ type ID = uuid.UUID
 
 // Category represents a note category domain model.
 type Category struct {
-	ID        ID
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          ID
+	WorkspaceID uuid.UUID
+	Name        string
+	// MetadataSchema is the JSON Schema the metadata of notes in the
+	// category must match. It is nil when metadata is unconstrained.
+	MetadataSchema json.RawMessage
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 // NewID generates a new category ID.
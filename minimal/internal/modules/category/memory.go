@@ -0,0 +1,147 @@
+package category
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu         sync.RWMutex
+	categories map[ID]Category
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{categories: make(map[ID]Category)}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, c Category) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.WorkspaceID = workspaceID
+	if _, ok := r.categories[c.ID]; ok {
+		return ErrAlreadyExists
+	}
+	if r.nameTaken(c) {
+		return ErrAlreadyExists
+	}
+	r.categories[c.ID] = c
+	return nil
+}
+
+func (r *MemoryRepository) GetByID(ctx context.Context, id ID) (Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Category{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.categories[id]
+	if !ok || c.WorkspaceID != workspaceID {
+		return Category{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *MemoryRepository) GetByIDs(ctx context.Context, ids []ID) ([]Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var categories []Category
+	for _, id := range ids {
+		if c, ok := r.categories[id]; ok && c.WorkspaceID == workspaceID {
+			categories = append(categories, c)
+		}
+	}
+	return categories, nil
+}
+
+func (r *MemoryRepository) GetAll(ctx context.Context) ([]Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var categories []Category
+	for _, c := range r.categories {
+		if c.WorkspaceID == workspaceID {
+			categories = append(categories, c)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].CreatedAt.After(categories[j].CreatedAt)
+	})
+	return categories, nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, c Category) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.categories[c.ID]
+	if !ok || existing.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	existing.Name = c.Name
+	existing.MetadataSchema = c.MetadataSchema
+	existing.UpdatedAt = c.UpdatedAt
+	if r.nameTaken(existing) {
+		return ErrAlreadyExists
+	}
+	r.categories[c.ID] = existing
+	return nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.categories[id]
+	if !ok || c.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	delete(r.categories, id)
+	return nil
+}
+
+// nameTaken reports whether another category in the workspace of c already
+// uses its name. The caller must hold r.mu.
+func (r *MemoryRepository) nameTaken(c Category) bool {
+	for _, other := range r.categories {
+		if other.ID != c.ID && other.WorkspaceID == c.WorkspaceID && other.Name == c.Name {
+			return true
+		}
+	}
+	return false
+}
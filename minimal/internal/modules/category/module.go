@@ -0,0 +1,34 @@
+package category
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+// Module serves categories under /categories. Their tables are part of
+// the core schema.
+type Module struct {
+	modules.Base
+	routes modules.Routes
+	db     modules.Pinger
+}
+
+// NewModule creates the category module. routes serves /categories and db
+// is the database categories are stored in, or nil if they are kept in
+// memory.
+func NewModule(routes modules.Routes, db modules.Pinger) *Module {
+	return &Module{routes: routes, db: db}
+}
+
+func (m *Module) Name() string { return "category" }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/categories"))
+}
+
+// Health pings the database categories are stored in.
+func (m *Module) Health(ctx context.Context) error {
+	return modules.Ping(ctx, m.db)
+}
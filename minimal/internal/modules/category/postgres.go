@@ -1,20 +1,26 @@
 package category
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
 )
 
 // categoryRow is the database representation of a category.
 type categoryRow struct {
-	ID        string    `db:"id"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID             string    `db:"id"`
+	WorkspaceID    string    `db:"workspace_id"`
+	Name           string    `db:"name"`
+	MetadataSchema []byte    `db:"metadata_schema"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
 }
 
 func (r categoryRow) toDomain() (Category, error) {
@@ -22,38 +28,62 @@ func (r categoryRow) toDomain() (Category, error) {
 	if err != nil {
 		return Category{}, err
 	}
+	workspaceID, err := ParseID(r.WorkspaceID)
+	if err != nil {
+		return Category{}, err
+	}
+	// jsonb adds whitespace to the schema, which is returned compact as
+	// by the other repositories
+	var schema json.RawMessage
+	if r.MetadataSchema != nil {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, r.MetadataSchema); err != nil {
+			return Category{}, err
+		}
+		schema = buf.Bytes()
+	}
 	return Category{
-		ID:        id,
-		Name:      r.Name,
-		CreatedAt: r.CreatedAt,
-		UpdatedAt: r.UpdatedAt,
+		ID:             id,
+		WorkspaceID:    workspaceID,
+		Name:           r.Name,
+		MetadataSchema: schema,
+		CreatedAt:      r.CreatedAt.UTC(),
+		UpdatedAt:      r.UpdatedAt.UTC(),
 	}, nil
 }
 
 func toRow(c Category) categoryRow {
 	return categoryRow{
-		ID:        c.ID.String(),
-		Name:      c.Name,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: c.UpdatedAt,
+		ID:             c.ID.String(),
+		WorkspaceID:    c.WorkspaceID.String(),
+		Name:           c.Name,
+		MetadataSchema: c.MetadataSchema,
+		CreatedAt:      c.CreatedAt,
+		UpdatedAt:      c.UpdatedAt,
 	}
 }
 
 // PostgresRepository implements Repository using PostgreSQL.
+// Every statement is scoped to the workspace of the context.
 type PostgresRepository struct {
-	db *pgx.Conn
+	db *db.DB
 }
 
 // NewPostgresRepository creates a new PostgresRepository.
-func NewPostgresRepository(db *pgx.Conn) *PostgresRepository {
+func NewPostgresRepository(db *db.DB) *PostgresRepository {
 	return &PostgresRepository{db: db}
 }
 
 func (r *PostgresRepository) Create(ctx context.Context, c Category) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	c.WorkspaceID = workspaceID
 	row := toRow(c)
-	_, err := r.db.Exec(ctx,
-		`INSERT INTO categories (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4)`,
-		row.ID, row.Name, row.CreatedAt, row.UpdatedAt,
+	_, err = r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO categories (id, workspace_id, name, metadata_schema, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		row.ID, row.WorkspaceID, row.Name, row.MetadataSchema, row.CreatedAt, row.UpdatedAt,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -66,11 +96,15 @@ func (r *PostgresRepository) Create(ctx context.Context, c Category) error {
 }
 
 func (r *PostgresRepository) GetByID(ctx context.Context, id ID) (Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Category{}, err
+	}
 	var row categoryRow
-	err := r.db.QueryRow(ctx,
-		`SELECT id, name, created_at, updated_at FROM categories WHERE id = $1`,
-		id.String(),
-	).Scan(&row.ID, &row.Name, &row.CreatedAt, &row.UpdatedAt)
+	err = r.db.Q(ctx).QueryRow(ctx,
+		`SELECT id, workspace_id, name, metadata_schema, created_at, updated_at FROM categories WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), id.String(),
+	).Scan(&row.ID, &row.WorkspaceID, &row.Name, &row.MetadataSchema, &row.CreatedAt, &row.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return Category{}, ErrNotFound
@@ -80,19 +114,47 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id ID) (Category, erro
 	return row.toDomain()
 }
 
+func (r *PostgresRepository) GetByIDs(ctx context.Context, ids []ID) ([]Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	strIDs := make([]string, len(ids))
+	for i, id := range ids {
+		strIDs[i] = id.String()
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT id, workspace_id, name, metadata_schema, created_at, updated_at FROM categories WHERE workspace_id = $1 AND id = ANY($2)`,
+		workspaceID.String(), strIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanCategories(rows)
+}
+
 func (r *PostgresRepository) GetAll(ctx context.Context) ([]Category, error) {
-	rows, err := r.db.Query(ctx,
-		`SELECT id, name, created_at, updated_at FROM categories ORDER BY created_at DESC`,
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT id, workspace_id, name, metadata_schema, created_at, updated_at FROM categories WHERE workspace_id = $1 ORDER BY created_at DESC`,
+		workspaceID.String(),
 	)
 	if err != nil {
 		return nil, err
 	}
+	return scanCategories(rows)
+}
+
+func scanCategories(rows pgx.Rows) ([]Category, error) {
 	defer rows.Close()
 
 	var categories []Category
 	for rows.Next() {
 		var row categoryRow
-		if err := rows.Scan(&row.ID, &row.Name, &row.CreatedAt, &row.UpdatedAt); err != nil {
+		if err := rows.Scan(&row.ID, &row.WorkspaceID, &row.Name, &row.MetadataSchema, &row.CreatedAt, &row.UpdatedAt); err != nil {
 			return nil, err
 		}
 		c, err := row.toDomain()
@@ -105,12 +167,20 @@ func (r *PostgresRepository) GetAll(ctx context.Context) ([]Category, error) {
 }
 
 func (r *PostgresRepository) Update(ctx context.Context, c Category) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
 	row := toRow(c)
-	result, err := r.db.Exec(ctx,
-		`UPDATE categories SET name = $2, updated_at = $3 WHERE id = $1`,
-		row.ID, row.Name, row.UpdatedAt,
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`UPDATE categories SET name = $3, metadata_schema = $4, updated_at = $5 WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), row.ID, row.Name, row.MetadataSchema, row.UpdatedAt,
 	)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
 		return err
 	}
 	if result.RowsAffected() == 0 {
@@ -120,7 +190,14 @@ func (r *PostgresRepository) Update(ctx context.Context, c Category) error {
 }
 
 func (r *PostgresRepository) Delete(ctx context.Context, id ID) error {
-	result, err := r.db.Exec(ctx, `DELETE FROM categories WHERE id = $1`, id.String())
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`DELETE FROM categories WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), id.String(),
+	)
 	if err != nil {
 		return err
 	}
@@ -14,6 +14,9 @@ var (
 type Repository interface {
 	Create(ctx context.Context, c Category) error
 	GetByID(ctx context.Context, id ID) (Category, error)
+	// GetByIDs returns the categories with the given IDs in no particular
+	// order, omitting IDs that do not exist.
+	GetByIDs(ctx context.Context, ids []ID) ([]Category, error)
 	GetAll(ctx context.Context) ([]Category, error)
 	Update(ctx context.Context, c Category) error
 	Delete(ctx context.Context, id ID) error
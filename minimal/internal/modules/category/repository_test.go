@@ -0,0 +1,193 @@
+package category_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db/dbtest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) category.Repository {
+		return category.NewMemoryRepository()
+	})
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) category.Repository {
+		return category.NewSQLiteRepository(dbtest.NewSQLite(t))
+	})
+}
+
+func TestPostgresRepository(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		rowLevelSecurity bool
+	}{
+		{"owner", false},
+		{"row-level security", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepository(t, func(t *testing.T) category.Repository {
+				database := dbtest.NewPostgres(t, tt.rowLevelSecurity)
+				dbtest.AddWorkspaces(t, database, workspace, otherWorkspace)
+				return category.NewPostgresRepository(database)
+			})
+		})
+	}
+}
+
+// base is the creation time of the first category of a test, in
+// microseconds as Postgres stores times.
+var base = time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC)
+
+// workspace and otherWorkspace are the workspaces of the contract, which
+// Postgres repositories must create.
+var workspace, otherWorkspace = uuid.New(), uuid.New()
+
+func newCategory(name string, at time.Time) category.Category {
+	return category.Category{ID: category.NewID(), Name: name, CreatedAt: at, UpdatedAt: at}
+}
+
+// testRepository runs the contract every Repository implementation
+// fulfils against repositories created by newRepo.
+func testRepository(t *testing.T, newRepo func(t *testing.T) category.Repository) {
+	ctx := tenant.WithWorkspace(context.Background(), workspace)
+	other := tenant.WithWorkspace(context.Background(), otherWorkspace)
+
+	create := func(t *testing.T, repo category.Repository, ctx context.Context, c category.Category) category.Category {
+		t.Helper()
+		if err := repo.Create(ctx, c); err != nil {
+			t.Fatalf("create %s: %v", c.Name, err)
+		}
+		c.WorkspaceID, _ = tenant.WorkspaceID(ctx)
+		return c
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repo := newRepo(t)
+		want := newCategory("Work", base)
+		want.MetadataSchema = json.RawMessage(`{"type":"object"}`)
+		want = create(t, repo, ctx, want)
+
+		got, err := repo.GetByID(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GetByID = %+v, want %+v", got, want)
+		}
+		if _, err := repo.GetByID(other, want.ID); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("GetByID in other workspace = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetByID(ctx, category.NewID()); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("GetByID unknown = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("unique names", func(t *testing.T) {
+		repo := newRepo(t)
+		work := create(t, repo, ctx, newCategory("Work", base))
+
+		if err := repo.Create(ctx, newCategory("Work", base)); !errors.Is(err, category.ErrAlreadyExists) {
+			t.Errorf("Create duplicate name = %v, want ErrAlreadyExists", err)
+		}
+		duplicateID := newCategory("Other", base)
+		duplicateID.ID = work.ID
+		if err := repo.Create(ctx, duplicateID); !errors.Is(err, category.ErrAlreadyExists) {
+			t.Errorf("Create duplicate ID = %v, want ErrAlreadyExists", err)
+		}
+		create(t, repo, other, newCategory("Work", base))
+
+		home := create(t, repo, ctx, newCategory("Home", base))
+		home.Name = "Work"
+		if err := repo.Update(ctx, home); !errors.Is(err, category.ErrAlreadyExists) {
+			t.Errorf("Update to duplicate name = %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		repo := newRepo(t)
+		first := create(t, repo, ctx, newCategory("First", base))
+		second := create(t, repo, ctx, newCategory("Second", base.Add(time.Second)))
+		third := create(t, repo, ctx, newCategory("Third", base.Add(time.Minute)))
+		create(t, repo, other, newCategory("Elsewhere", base))
+
+		all, err := repo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if want := []category.Category{third, second, first}; !reflect.DeepEqual(all, want) {
+			t.Errorf("GetAll = %+v, want newest first %+v", all, want)
+		}
+
+		byIDs, err := repo.GetByIDs(ctx, []category.ID{first.ID, third.ID, category.NewID()})
+		if err != nil {
+			t.Fatalf("GetByIDs: %v", err)
+		}
+		if len(byIDs) != 2 {
+			t.Errorf("GetByIDs = %+v, want First and Third", byIDs)
+		}
+		if empty, err := repo.GetByIDs(ctx, nil); err != nil || len(empty) != 0 {
+			t.Errorf("GetByIDs(nil) = %+v, %v", empty, err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		c := create(t, repo, ctx, newCategory("Work", base))
+
+		c.Name = "Job"
+		c.MetadataSchema = json.RawMessage(`{"type":"object"}`)
+		c.UpdatedAt = base.Add(time.Hour)
+		if err := repo.Update(ctx, c); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repo.GetByID(ctx, c.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("GetByID after update = %+v, want %+v", got, c)
+		}
+
+		if err := repo.Update(other, c); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("Update in other workspace = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo(t)
+		c := create(t, repo, ctx, newCategory("Work", base))
+
+		if err := repo.Delete(other, c.ID); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("Delete in other workspace = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, c.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, c.ID); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("GetByID after delete = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, c.ID); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("Delete twice = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("requires workspace", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Create(context.Background(), newCategory("Work", base)); !errors.Is(err, tenant.ErrNoWorkspace) {
+			t.Errorf("Create without workspace = %v, want ErrNoWorkspace", err)
+		}
+		if _, err := repo.GetAll(context.Background()); !errors.Is(err, tenant.ErrNoWorkspace) {
+			t.Errorf("GetAll without workspace = %v, want ErrNoWorkspace", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package category
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// ErrInvalidSchema is returned for metadata schemas that are not valid
+// JSON Schema documents.
+var ErrInvalidSchema = errors.New("invalid metadata schema")
+
+// schemaURL is the location schemas are compiled at. Nothing is ever
+// loaded from it.
+const schemaURL = "metadata-schema.json"
+
+// noLoader refuses to load schemas referenced with $ref, so a schema cannot
+// read files or make requests.
+type noLoader struct{}
+
+func (noLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("cannot load %s: schemas must be self-contained", url)
+}
+
+// compileSchema compiles a metadata schema.
+func compileSchema(raw json.RawMessage) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	c := jsonschema.NewCompiler()
+	c.UseLoader(noLoader{})
+	if err := c.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	schema, err := c.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return schema, nil
+}
+
+// ValidateMetadata checks metadata against the metadata schema of c. The
+// error describes the first violation.
+func (c Category) ValidateMetadata(metadata map[string]any) error {
+	if c.MetadataSchema == nil {
+		return nil
+	}
+	schema, err := compileSchema(c.MetadataSchema)
+	if err != nil {
+		return err
+	}
+
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	// Validate the JSON form, which the validator expects numbers in
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	err = schema.Validate(doc)
+	var ve *jsonschema.ValidationError
+	if errors.As(err, &ve) {
+		for len(ve.Causes) > 0 {
+			ve = ve.Causes[0]
+		}
+		return errors.New(ve.Error())
+	}
+	return err
+}
@@ -2,46 +2,107 @@ package category
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"sync"
 	"time"
 
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
 	"go.uber.org/zap"
 )
 
 // Service provides category business logic.
 type Service struct {
 	repo   Repository
+	tx     db.Transactor
+	audit  *audit.Service
 	logger *zap.Logger
+
+	mu       sync.RWMutex
+	onDelete []DeleteHook
 }
 
-// NewService creates a new category service.
-func NewService(repo Repository, logger *zap.Logger) *Service {
+// DeleteHook is called in the transaction deleting the category with id,
+// before it is removed.
+type DeleteHook func(ctx context.Context, id ID) error
+
+// NewService creates a new category service. Mutations are recorded with
+// auditor in the same transaction.
+func NewService(repo Repository, tx db.Transactor, auditor *audit.Service, logger *zap.Logger) *Service {
 	return &Service{
 		repo:   repo,
+		tx:     tx,
+		audit:  auditor,
 		logger: logger.Named("category.service"),
 	}
 }
 
+// EntityType identifies categories in audit events.
+const EntityType = "category"
+
+// snapshot returns the audited fields of c.
+func snapshot(c Category) audit.Snapshot {
+	return audit.Snapshot{"name": c.Name, "metadata_schema": c.MetadataSchema}
+}
+
+// CheckSchema returns the metadata schema to store for raw, which is
+// either empty, JSON null or a schema document. It returns nil for no
+// schema, and an error wrapping ErrInvalidSchema for invalid documents.
+func CheckSchema(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if _, err := compileSchema(raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
 // CreateInput contains data for creating a category.
 type CreateInput struct {
 	Name string
+	// MetadataSchema optionally constrains the metadata of notes in the
+	// category.
+	MetadataSchema json.RawMessage
 }
 
 // Create creates a new category.
 func (s *Service) Create(ctx context.Context, input CreateInput) (Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Category{}, err
+	}
+
+	schema, err := CheckSchema(input.MetadataSchema)
+	if err != nil {
+		return Category{}, err
+	}
+
 	now := time.Now().UTC()
 	c := Category{
-		ID:        NewID(),
-		Name:      input.Name,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:             NewID(),
+		WorkspaceID:    workspaceID,
+		Name:           input.Name,
+		MetadataSchema: schema,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
-	if err := s.repo.Create(ctx, c); err != nil {
-		s.logger.Error("failed to create category", zap.Error(err))
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, c); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, EntityType, c.ID, nil, snapshot(c))
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to create category", zap.Error(err))
 		return Category{}, err
 	}
 
-	s.logger.Info("category created", zap.String("id", c.ID.String()))
+	logger.FromContext(ctx, s.logger).Info("category created", zap.String("id", c.ID.String()))
 	return c, nil
 }
 
@@ -49,17 +110,28 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (Category, erro
 func (s *Service) GetByID(ctx context.Context, id ID) (Category, error) {
 	c, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("failed to get category", zap.String("id", id.String()), zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("failed to get category", zap.String("id", id.String()), zap.Error(err))
 		return Category{}, err
 	}
 	return c, nil
 }
 
+// GetByIDs retrieves the categories with the given IDs in one query.
+// Missing IDs are omitted.
+func (s *Service) GetByIDs(ctx context.Context, ids []ID) ([]Category, error) {
+	categories, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to get categories by ids", zap.Int("count", len(ids)), zap.Error(err))
+		return nil, err
+	}
+	return categories, nil
+}
+
 // GetAll retrieves all categories.
 func (s *Service) GetAll(ctx context.Context) ([]Category, error) {
 	categories, err := s.repo.GetAll(ctx)
 	if err != nil {
-		s.logger.Error("failed to get all categories", zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("failed to get all categories", zap.Error(err))
 		return nil, err
 	}
 	return categories, nil
@@ -69,33 +141,84 @@ func (s *Service) GetAll(ctx context.Context) ([]Category, error) {
 type UpdateInput struct {
 	ID   ID
 	Name string
+	// MetadataSchema replaces the metadata schema unless it is nil; JSON
+	// null removes it. Notes already in the category are not revalidated.
+	MetadataSchema json.RawMessage
 }
 
 // Update updates an existing category.
 func (s *Service) Update(ctx context.Context, input UpdateInput) (Category, error) {
-	c, err := s.repo.GetByID(ctx, input.ID)
+	schema, err := CheckSchema(input.MetadataSchema)
 	if err != nil {
 		return Category{}, err
 	}
 
-	c.Name = input.Name
-	c.UpdatedAt = time.Now().UTC()
-
-	if err := s.repo.Update(ctx, c); err != nil {
-		s.logger.Error("failed to update category", zap.String("id", input.ID.String()), zap.Error(err))
+	var c Category
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		c, err = s.repo.GetByID(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		before := snapshot(c)
+
+		c.Name = input.Name
+		if input.MetadataSchema != nil {
+			c.MetadataSchema = schema
+		}
+		c.UpdatedAt = time.Now().UTC()
+
+		if err := s.repo.Update(ctx, c); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, EntityType, c.ID, before, snapshot(c))
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Category{}, err
+		}
+		logger.FromContext(ctx, s.logger).Error("failed to update category", zap.String("id", input.ID.String()), zap.Error(err))
 		return Category{}, err
 	}
 
-	s.logger.Info("category updated", zap.String("id", c.ID.String()))
+	logger.FromContext(ctx, s.logger).Info("category updated", zap.String("id", c.ID.String()))
 	return c, nil
 }
 
-// Delete removes a category.
+// OnDelete adds h to the hooks of Delete. Services of entities in a
+// category use it to delete them first, recording each delete, rather than
+// leaving them to the database's cascade. It is meant to be called at
+// startup.
+func (s *Service) OnDelete(h DeleteHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDelete = append(s.onDelete, h)
+}
+
+// Delete removes a category, after its hooks removed what is in it.
 func (s *Service) Delete(ctx context.Context, id ID) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("failed to delete category", zap.String("id", id.String()), zap.Error(err))
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		c, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		s.mu.RLock()
+		hooks := s.onDelete
+		s.mu.RUnlock()
+		for _, h := range hooks {
+			if err := h(ctx, id); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, EntityType, id, snapshot(c), nil)
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to delete category", zap.String("id", id.String()), zap.Error(err))
 		return err
 	}
-	s.logger.Info("category deleted", zap.String("id", id.String()))
+	logger.FromContext(ctx, s.logger).Info("category deleted", zap.String("id", id.String()))
 	return nil
 }
@@ -0,0 +1,175 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+const sqliteColumns = `id, workspace_id, name, metadata_schema, created_at, updated_at`
+
+// SQLiteRepository implements Repository using SQLite.
+// Every statement is scoped to the workspace of the context.
+type SQLiteRepository struct {
+	db *db.SQLite
+}
+
+// NewSQLiteRepository creates a new SQLiteRepository.
+func NewSQLiteRepository(db *db.SQLite) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// scanSQLite scans a category selected with sqliteColumns.
+func scanSQLite(scan func(dest ...any) error) (Category, error) {
+	var (
+		row                  categoryRow
+		createdAt, updatedAt string
+		metadataSchema       sql.NullString
+	)
+	if err := scan(&row.ID, &row.WorkspaceID, &row.Name, &metadataSchema, &createdAt, &updatedAt); err != nil {
+		return Category{}, err
+	}
+	if metadataSchema.Valid {
+		row.MetadataSchema = []byte(metadataSchema.String)
+	}
+	var err error
+	if row.CreatedAt, err = db.ParseSQLiteTime(createdAt); err != nil {
+		return Category{}, err
+	}
+	if row.UpdatedAt, err = db.ParseSQLiteTime(updatedAt); err != nil {
+		return Category{}, err
+	}
+	return row.toDomain()
+}
+
+// sqliteSchema returns the metadata schema as stored, NULL if there is
+// none.
+func sqliteSchema(c Category) any {
+	if c.MetadataSchema == nil {
+		return nil
+	}
+	return string(c.MetadataSchema)
+}
+
+func (r *SQLiteRepository) Create(ctx context.Context, c Category) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).ExecContext(ctx,
+		`INSERT INTO categories (`+sqliteColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		c.ID.String(), workspaceID.String(), c.Name, sqliteSchema(c), db.SQLiteTime(c.CreatedAt), db.SQLiteTime(c.UpdatedAt),
+	)
+	if errors.Is(db.SQLiteError(err), db.ErrUniqueViolation) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *SQLiteRepository) GetByID(ctx context.Context, id ID) (Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Category{}, err
+	}
+	c, err := scanSQLite(r.db.Q(ctx).QueryRowContext(ctx,
+		`SELECT `+sqliteColumns+` FROM categories WHERE workspace_id = ? AND id = ?`,
+		workspaceID.String(), id.String(),
+	).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Category{}, ErrNotFound
+	}
+	return c, err
+}
+
+func (r *SQLiteRepository) GetByIDs(ctx context.Context, ids []ID) ([]Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	strIDs := make([]string, len(ids))
+	for i, id := range ids {
+		strIDs[i] = id.String()
+	}
+	// json_each expands the array the IDs are passed as
+	return r.query(ctx,
+		`SELECT `+sqliteColumns+` FROM categories WHERE workspace_id = ? AND id IN (SELECT value FROM json_each(?))`,
+		workspaceID.String(), db.SQLiteArray(strIDs),
+	)
+}
+
+func (r *SQLiteRepository) GetAll(ctx context.Context) ([]Category, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx,
+		`SELECT `+sqliteColumns+` FROM categories WHERE workspace_id = ? ORDER BY created_at DESC`,
+		workspaceID.String(),
+	)
+}
+
+func (r *SQLiteRepository) query(ctx context.Context, query string, args ...any) ([]Category, error) {
+	rows, err := r.db.Q(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []Category
+	for rows.Next() {
+		c, err := scanSQLite(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *SQLiteRepository) Update(ctx context.Context, c Category) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).ExecContext(ctx,
+		`UPDATE categories SET name = ?, metadata_schema = ?, updated_at = ? WHERE workspace_id = ? AND id = ?`,
+		c.Name, sqliteSchema(c), db.SQLiteTime(c.UpdatedAt), workspaceID.String(), c.ID.String(),
+	)
+	if err != nil {
+		if errors.Is(db.SQLiteError(err), db.ErrUniqueViolation) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return sqliteAffected(result)
+}
+
+func (r *SQLiteRepository) Delete(ctx context.Context, id ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).ExecContext(ctx,
+		`DELETE FROM categories WHERE workspace_id = ? AND id = ?`,
+		workspaceID.String(), id.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return sqliteAffected(result)
+}
+
+// sqliteAffected returns ErrNotFound if result affected no rows.
+func sqliteAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
+)
+
+// TestEncryption seals and opens response bodies without a database.
+func TestEncryption(t *testing.T) {
+	keyring, err := encryption.ParseKeyring("a:" + base64.StdEncoding.EncodeToString(make([]byte, encryption.KeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewPostgresRepository(nil, keyring)
+	rec := Record{WorkspaceID: uuid.New(), UserID: uuid.New(), Key: "create-note"}
+	body := []byte(`{"title":"Passwords"}`)
+
+	keyID, wrapped, sealed, err := r.seal(rec, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *keyID != "a" || bytes.Contains(sealed, body) {
+		t.Fatalf("sealed %q under %q", sealed, *keyID)
+	}
+
+	got, err := r.open(rec, *keyID, wrapped, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Fatalf("got %q, want %q", got, body)
+	}
+
+	other := rec
+	other.Key = "other"
+	if _, err := r.open(other, *keyID, wrapped, sealed); !errors.Is(err, encryption.ErrDecrypt) {
+		t.Fatalf("other key: got %v, want ErrDecrypt", err)
+	}
+	if _, err := NewPostgresRepository(nil, nil).open(rec, *keyID, wrapped, sealed); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Fatalf("no keyring: got %v, want ErrKeyNotFound", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Package idempotency stores the responses to requests sent with an
+// Idempotency-Key header, so that retries of a request are answered with
+// the first response instead of repeating its effects.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Record is an idempotency key of a user in a workspace.
+type Record struct {
+	WorkspaceID uuid.UUID
+	UserID      uuid.UUID
+	Key         string
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+	// Token identifies the claim of the key, telling it apart from later
+	// claims of the same key, which may be for the same request once the
+	// claim expired.
+	Token string
+	// Response is nil while the first request is in flight.
+	Response  *Response
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Response is a stored HTTP response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Fingerprint returns the fingerprint of a request. Reusing a key for a
+// different method, path or body is an error.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
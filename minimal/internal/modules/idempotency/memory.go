@@ -0,0 +1,113 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// recordKey identifies a record in MemoryRepository.
+type recordKey struct {
+	workspaceID uuid.UUID
+	userID      uuid.UUID
+	key         string
+}
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu      sync.Mutex
+	records map[recordKey]Record
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{records: make(map[recordKey]Record)}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, rec Record) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec.WorkspaceID = workspaceID
+	k := recordKey{workspaceID, rec.UserID, rec.Key}
+	if existing, ok := r.records[k]; ok && existing.ExpiresAt.After(rec.CreatedAt) {
+		return ErrAlreadyExists
+	}
+	r.records[k] = rec
+	return nil
+}
+
+func (r *MemoryRepository) Get(ctx context.Context, userID uuid.UUID, key string) (Record, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.records[recordKey{workspaceID, userID, key}]
+	if !ok || !rec.ExpiresAt.After(time.Now()) {
+		return Record{}, ErrNotFound
+	}
+	return rec, nil
+}
+
+func (r *MemoryRepository) Complete(ctx context.Context, rec Record) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := recordKey{workspaceID, rec.UserID, rec.Key}
+	existing, ok := r.records[k]
+	if !ok || existing.Token != rec.Token || existing.Response != nil {
+		return ErrClaimLost
+	}
+	existing.Response = rec.Response
+	existing.ExpiresAt = rec.ExpiresAt
+	r.records[k] = existing
+	return nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, userID uuid.UUID, key, token string) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := recordKey{workspaceID, userID, key}
+	if existing, ok := r.records[k]; ok && existing.Token == token {
+		delete(r.records, k)
+	}
+	return nil
+}
+
+func (r *MemoryRepository) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for k, rec := range r.records {
+		if !rec.ExpiresAt.After(now) {
+			delete(r.records, k)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,20 @@
+-- Create idempotency_keys table; a row holds the response to replay for a
+-- key, or no response while the first request is in flight
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER,
+    response_header JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (workspace_id, user_id, key)
+);
+
+-- Create index for purging expired keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- No row-level security: expired keys are purged across workspaces, and
+-- every other statement filters by workspace_id
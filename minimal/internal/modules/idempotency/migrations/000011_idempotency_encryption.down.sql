@@ -0,0 +1,5 @@
+-- Bodies still encrypted become unreadable once their key columns are
+-- dropped, so drop them with their responses
+DELETE FROM idempotency_keys WHERE key_id IS NOT NULL;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS data_key;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS key_id;
@@ -0,0 +1,5 @@
+-- Envelope encryption of response bodies, as of notes. key_id names the
+-- key that wrapped data_key, the data key sealing response_body, and is
+-- NULL for bodies stored in the clear.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS key_id TEXT;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS data_key BYTEA;
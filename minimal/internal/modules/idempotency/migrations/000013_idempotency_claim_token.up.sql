@@ -0,0 +1,4 @@
+-- A random token per claim, so that a request whose claim expired and was
+-- taken over can neither complete nor release the new claim. Keys claimed
+-- before have no token and can no longer be completed, so they expire.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS token TEXT;
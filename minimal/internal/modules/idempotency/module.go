@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migrations holds the migrations of idempotency keys.
+var Migrations, _ = fs.Sub(migrationsFS, "migrations")
+
+// PurgeJob is the kind of the job purging expired keys.
+const PurgeJob = "idempotency.purge"
+
+// Module purges expired idempotency keys. It has no routes: keys are
+// claimed by the idempotency middleware of the API.
+type Module struct {
+	modules.Base
+	service *Service
+	db      modules.Pinger
+}
+
+// NewModule creates the idempotency module. db is the database keys are
+// stored in, or nil if they are kept in memory.
+func NewModule(service *Service, db modules.Pinger) *Module {
+	return &Module{service: service, db: db}
+}
+
+func (m *Module) Name() string { return "idempotency" }
+
+func (m *Module) RegisterRoutes(*echo.Group) {}
+
+func (m *Module) Migrations() fs.FS { return Migrations }
+
+// RegisterJobs purges expired keys every hour.
+func (m *Module) RegisterJobs(ctx context.Context, queue *jobs.Queue) error {
+	queue.Register(PurgeJob, jobs.Handle(func(ctx context.Context, _ struct{}) error {
+		_, err := m.service.Purge(ctx)
+		return err
+	}))
+	return queue.Schedule(ctx, PurgeJob, "@hourly", struct{}{})
+}
+
+// Health pings the database keys are stored in.
+func (m *Module) Health(ctx context.Context) error {
+	return modules.Ping(ctx, m.db)
+}
@@ -0,0 +1,196 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// PostgresRepository implements Repository using PostgreSQL.
+//
+// With a keyring, response bodies are encrypted at rest like notes are, as
+// they may hold note titles and contents: each is sealed with a new data
+// key, stored wrapped by the current key in data_key, naming that key in
+// key_id. Bodies are not re-encrypted after a rotation, as they expire
+// with their keys; keep the rotated key configured for IDEMPOTENCY_TTL.
+type PostgresRepository struct {
+	db *db.DB
+	// keyring is nil if response bodies are stored in the clear.
+	keyring *encryption.Keyring
+}
+
+// NewPostgresRepository creates a new PostgresRepository. keyring encrypts
+// the response bodies stored from now on, or is nil to store them in the
+// clear.
+func NewPostgresRepository(db *db.DB, keyring *encryption.Keyring) *PostgresRepository {
+	return &PostgresRepository{db: db, keyring: keyring}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, rec Record) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	// Take over the key only if its record has expired
+	tag, err := r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO idempotency_keys (workspace_id, user_id, key, fingerprint, token, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (workspace_id, user_id, key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			token = EXCLUDED.token,
+			status_code = NULL,
+			response_header = NULL,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`,
+		workspaceID.String(), rec.UserID.String(), rec.Key, rec.Fingerprint, rec.Token, rec.CreatedAt, rec.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+func (r *PostgresRepository) Get(ctx context.Context, userID uuid.UUID, key string) (Record, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	rec := Record{WorkspaceID: workspaceID, UserID: userID, Key: key}
+	var (
+		statusCode *int
+		header     []byte
+		body       []byte
+		keyID      *string
+		dataKey    []byte
+	)
+	err = r.db.Q(ctx).QueryRow(ctx,
+		`SELECT fingerprint, status_code, response_header, response_body, key_id, data_key, created_at, expires_at
+		FROM idempotency_keys WHERE workspace_id = $1 AND user_id = $2 AND key = $3 AND expires_at > now()`,
+		workspaceID.String(), userID.String(), key,
+	).Scan(&rec.Fingerprint, &statusCode, &header, &body, &keyID, &dataKey, &rec.CreatedAt, &rec.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Record{}, ErrNotFound
+		}
+		return Record{}, err
+	}
+	if keyID != nil {
+		if body, err = r.open(rec, *keyID, dataKey, body); err != nil {
+			return Record{}, err
+		}
+	}
+	if statusCode != nil {
+		rec.Response = &Response{StatusCode: *statusCode, Header: http.Header{}, Body: body}
+		if err := json.Unmarshal(header, &rec.Response.Header); err != nil {
+			return Record{}, err
+		}
+	}
+	return rec, nil
+}
+
+func (r *PostgresRepository) Complete(ctx context.Context, rec Record) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	rec.WorkspaceID = workspaceID
+	header, err := json.Marshal(rec.Response.Header)
+	if err != nil {
+		return err
+	}
+	body := rec.Response.Body
+	var (
+		keyID   *string
+		dataKey []byte
+	)
+	if r.keyring != nil {
+		if keyID, dataKey, body, err = r.seal(rec, body); err != nil {
+			return err
+		}
+	}
+	tag, err := r.db.Q(ctx).Exec(ctx,
+		`UPDATE idempotency_keys SET status_code = $4, response_header = $5, response_body = $6, key_id = $7, data_key = $8, expires_at = $9
+		WHERE workspace_id = $1 AND user_id = $2 AND key = $3 AND token = $10 AND status_code IS NULL`,
+		workspaceID.String(), rec.UserID.String(), rec.Key,
+		rec.Response.StatusCode, header, body, keyID, dataKey, rec.ExpiresAt, rec.Token,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// seal encrypts the response body of rec under a new data key wrapped by
+// the current key, returning that key's ID, the wrapped data key and the
+// ciphertext.
+func (r *PostgresRepository) seal(rec Record, body []byte) (*string, []byte, []byte, error) {
+	dk, wrapped, err := r.keyring.NewDataKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sealed, err := dk.Seal(body, bodyAAD(rec))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyID := r.keyring.Current()
+	return &keyID, wrapped, sealed, nil
+}
+
+// open decrypts the response body of rec. It fails with
+// encryption.ErrKeyNotFound if the key with keyID is not configured.
+func (r *PostgresRepository) open(rec Record, keyID string, wrapped, ciphertext []byte) ([]byte, error) {
+	if r.keyring == nil {
+		return nil, fmt.Errorf("decrypt idempotency key %q: %w: %q", rec.Key, encryption.ErrKeyNotFound, keyID)
+	}
+	dk, err := r.keyring.Unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt idempotency key %q: %w", rec.Key, err)
+	}
+	body, err := dk.Open(ciphertext, bodyAAD(rec))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt idempotency key %q: %w", rec.Key, err)
+	}
+	return body, nil
+}
+
+// bodyAAD binds the ciphertext of a response body to its key.
+func bodyAAD(rec Record) []byte {
+	return []byte(rec.WorkspaceID.String() + "/" + rec.UserID.String() + "/" + rec.Key)
+}
+
+func (r *PostgresRepository) Delete(ctx context.Context, userID uuid.UUID, key, token string) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE workspace_id = $1 AND user_id = $2 AND key = $3 AND token = $4`,
+		workspaceID.String(), userID.String(), key, token,
+	)
+	return err
+}
+
+func (r *PostgresRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Q(ctx).Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,35 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound      = errors.New("idempotency key not found")
+	ErrAlreadyExists = errors.New("idempotency key already exists")
+	ErrClaimLost     = errors.New("idempotency key was claimed by another request")
+)
+
+// Repository defines the interface for idempotency key persistence.
+// Keys are scoped to the workspace in the context, except in DeleteExpired.
+// Expired records are treated as absent.
+type Repository interface {
+	// Create stores r, replacing an expired record of the same key. It
+	// returns ErrAlreadyExists if the key is held by a live record.
+	Create(ctx context.Context, r Record) error
+	Get(ctx context.Context, userID uuid.UUID, key string) (Record, error)
+	// Complete stores the response and expiry of r. It returns
+	// ErrClaimLost unless the key is held without a response by the
+	// claim with the token of r, e.g. because it expired and was taken
+	// over.
+	Complete(ctx context.Context, r Record) error
+	// Delete removes the key if it is held by the claim with token.
+	Delete(ctx context.Context, userID uuid.UUID, key, token string) error
+	// DeleteExpired removes the records of every workspace that expired
+	// before now and returns how many there were.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
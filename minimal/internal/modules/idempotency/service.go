@@ -0,0 +1,139 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInFlight  = errors.New("a request with this idempotency key is in progress")
+	ErrKeyReused = errors.New("idempotency key was used with a different request")
+)
+
+// DefaultTTL is how long responses are kept for replay by default.
+const DefaultTTL = 24 * time.Hour
+
+// lockTimeout is how long a key is held for a request that never
+// completes, e.g. because the server stopped while handling it.
+const lockTimeout = 5 * time.Minute
+
+// Service tracks idempotency keys and the responses stored for them.
+type Service struct {
+	repo   Repository
+	ttl    time.Duration
+	logger *zap.Logger
+}
+
+// NewService creates a new idempotency service keeping responses for ttl.
+func NewService(repo Repository, ttl time.Duration, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		ttl:    ttl,
+		logger: logger.Named("idempotency.service"),
+	}
+}
+
+// Request identifies a request sent with an idempotency key.
+type Request struct {
+	UserID      uuid.UUID
+	Key         string
+	Fingerprint string
+}
+
+// Claim is a key claimed by Begin. Only the request holding it may
+// complete or abandon the key.
+type Claim struct {
+	Request
+	// Token identifies the claim, see Record.
+	Token string
+}
+
+// Begin claims the key of req for a new request. It returns the stored
+// response if the key was already used for the same request and has been
+// answered, ErrInFlight if that request is still being handled and
+// ErrKeyReused if the key was used for a different request. A nil response
+// and error mean the caller should handle the request and then call
+// Complete or Abandon with the claim.
+func (s *Service) Begin(ctx context.Context, req Request) (Claim, *Response, error) {
+	now := time.Now()
+	claim := Claim{Request: req, Token: uuid.NewString()}
+	err := s.repo.Create(ctx, Record{
+		UserID:      req.UserID,
+		Key:         req.Key,
+		Fingerprint: req.Fingerprint,
+		Token:       claim.Token,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lockTimeout),
+	})
+	if err == nil {
+		return claim, nil, nil
+	}
+	if !errors.Is(err, ErrAlreadyExists) {
+		logger.FromContext(ctx, s.logger).Error("failed to claim idempotency key", zap.Error(err))
+		return Claim{}, nil, err
+	}
+
+	rec, err := s.repo.Get(ctx, req.UserID, req.Key)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		// Expired since the claim failed; the client may retry
+		return Claim{}, nil, ErrInFlight
+	case err != nil:
+		logger.FromContext(ctx, s.logger).Error("failed to get idempotency key", zap.Error(err))
+		return Claim{}, nil, err
+	case rec.Fingerprint != req.Fingerprint:
+		return Claim{}, nil, ErrKeyReused
+	case rec.Response == nil:
+		return Claim{}, nil, ErrInFlight
+	}
+	return Claim{}, rec.Response, nil
+}
+
+// Complete stores res as the response to replay for the key of claim. It
+// returns ErrClaimLost if the key expired while the request was handled
+// and another request claimed it, whose record is left alone.
+func (s *Service) Complete(ctx context.Context, claim Claim, res Response) error {
+	err := s.repo.Complete(ctx, Record{
+		UserID:      claim.UserID,
+		Key:         claim.Key,
+		Fingerprint: claim.Fingerprint,
+		Token:       claim.Token,
+		Response:    &res,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	})
+	if errors.Is(err, ErrClaimLost) {
+		logger.FromContext(ctx, s.logger).Warn("idempotency key claimed by another request, response not stored")
+		return err
+	}
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to store idempotent response", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Abandon releases the key of claim without storing a response, so that a
+// retry is handled anew. A key claimed by another request since is left
+// alone.
+func (s *Service) Abandon(ctx context.Context, claim Claim) error {
+	if err := s.repo.Delete(ctx, claim.UserID, claim.Key, claim.Token); err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to release idempotency key", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Purge deletes expired keys of every workspace.
+func (s *Service) Purge(ctx context.Context) (int64, error) {
+	n, err := s.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to purge idempotency keys", zap.Error(err))
+		return 0, err
+	}
+	return n, nil
+}
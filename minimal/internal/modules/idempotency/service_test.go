@@ -0,0 +1,83 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/idempotency"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+func TestComplete(t *testing.T) {
+	repo := idempotency.NewMemoryRepository()
+	s := idempotency.NewService(repo, idempotency.DefaultTTL, zap.NewNop())
+	ctx := tenant.WithWorkspace(context.Background(), uuid.New())
+	req := idempotency.Request{UserID: uuid.New(), Key: "create-note", Fingerprint: "a"}
+	res := idempotency.Response{StatusCode: http.StatusCreated, Header: http.Header{}, Body: []byte(`{}`)}
+
+	claim, stored, err := s.Begin(ctx, req)
+	if stored != nil || err != nil {
+		t.Fatalf("Begin = %v, %v, want a claim", stored, err)
+	}
+	if err := s.Complete(ctx, claim, res); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if err := s.Complete(ctx, claim, res); !errors.Is(err, idempotency.ErrClaimLost) {
+		t.Errorf("Complete twice = %v, want ErrClaimLost", err)
+	}
+	if _, stored, err := s.Begin(ctx, req); err != nil || stored == nil || stored.StatusCode != http.StatusCreated {
+		t.Errorf("Begin after Complete = %+v, %v, want the stored response", stored, err)
+	}
+}
+
+func TestCompleteAfterTakeover(t *testing.T) {
+	for name, fingerprint := range map[string]string{"other request": "b", "same request": "a"} {
+		t.Run(name, func(t *testing.T) {
+			repo := idempotency.NewMemoryRepository()
+			s := idempotency.NewService(repo, idempotency.DefaultTTL, zap.NewNop())
+			ctx := tenant.WithWorkspace(context.Background(), uuid.New())
+			slow, _, err := s.Begin(ctx, idempotency.Request{UserID: uuid.New(), Key: "create-note", Fingerprint: "a"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := idempotency.Request{UserID: slow.UserID, Key: slow.Key, Fingerprint: fingerprint}
+
+			// The claim of slow expired, and next took the key over
+			expire(t, repo, ctx, slow)
+			if _, stored, err := s.Begin(ctx, next); stored != nil || err != nil {
+				t.Fatalf("Begin of next request = %v, %v, want a claim", stored, err)
+			}
+
+			res := idempotency.Response{StatusCode: http.StatusCreated, Header: http.Header{}}
+			if err := s.Complete(ctx, slow, res); !errors.Is(err, idempotency.ErrClaimLost) {
+				t.Fatalf("Complete of slow request = %v, want ErrClaimLost", err)
+			}
+			if err := s.Abandon(ctx, slow); err != nil {
+				t.Fatalf("Abandon of slow request: %v", err)
+			}
+			if _, _, err := s.Begin(ctx, next); !errors.Is(err, idempotency.ErrInFlight) {
+				t.Errorf("Begin after lost claim = %v, want the next request in flight", err)
+			}
+		})
+	}
+}
+
+// expire ends the claim of c by replacing it with an expired record of the
+// same token.
+func expire(t *testing.T, repo *idempotency.MemoryRepository, ctx context.Context, c idempotency.Claim) {
+	t.Helper()
+	if err := repo.Delete(ctx, c.UserID, c.Key, c.Token); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := repo.Create(ctx, idempotency.Record{
+		UserID: c.UserID, Key: c.Key, Fingerprint: c.Fingerprint, Token: c.Token, CreatedAt: past, ExpiresAt: past,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package modules defines how features plug into the application. A
+// feature implements Module and is added to the Registry the application
+// is built from, which serves its routes, applies its migrations, runs its
+// jobs and reports its health.
+package modules
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+)
+
+// Module is a feature of the application.
+type Module interface {
+	// Name identifies the module, e.g. "note".
+	Name() string
+	// Dependencies are the names of the modules this one needs. They are
+	// set up before it.
+	Dependencies() []string
+	// RegisterRoutes adds the module's routes to the authenticated API
+	// group, /api/v1.
+	RegisterRoutes(api *echo.Group)
+	// Migrations holds the module's numbered *.up.sql and *.down.sql
+	// files, or is nil. Versions share one sequence with the migrations
+	// of every other module.
+	Migrations() fs.FS
+	// RegisterJobs registers the module's job handlers with queue and
+	// schedules its recurring jobs.
+	RegisterJobs(ctx context.Context, queue *jobs.Queue) error
+	// Health returns an error if the module cannot serve requests.
+	Health(ctx context.Context) error
+}
+
+// RootRouter is implemented by modules that also serve routes outside of
+// /api/v1, which authenticate requests themselves.
+type RootRouter interface {
+	RegisterRootRoutes(e *echo.Echo)
+}
+
+// Routes registers HTTP routes on a group. Modules are given their
+// handlers as Routes, as the handlers depend on the modules' packages.
+type Routes interface {
+	RegisterRoutes(g *echo.Group)
+}
+
+// Pinger checks the connection to a database.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping is the Health of a module storing its data in database, which is
+// nil if the data is kept in memory.
+func Ping(ctx context.Context, database Pinger) error {
+	if database == nil {
+		return nil
+	}
+	return database.Ping(ctx)
+}
+
+// Base implements the optional parts of Module as no-ops. Embed it and
+// define Name and RegisterRoutes.
+type Base struct{}
+
+func (Base) Dependencies() []string { return nil }
+
+func (Base) Migrations() fs.FS { return nil }
+
+func (Base) RegisterJobs(context.Context, *jobs.Queue) error { return nil }
+
+func (Base) Health(context.Context) error { return nil }
@@ -0,0 +1,367 @@
+package note
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// Notes are encrypted at rest by PostgresRepository when it has a keyring.
+// Every write seals the title and content with a new data key, stores them
+// base64 encoded in their columns and the data key wrapped by the current
+// key in data_key, naming that key in key_id. Each ciphertext is bound to
+// the note and field it belongs to. Lookups by title use title_index, a
+// hash of the workspace and title by the same key. Notes written before
+// encryption was enabled keep a NULL key_id until they are re-encrypted.
+//
+// The references of links are sealed alike, each with its own data key and
+// bound to the position of the link in its source note. ref_index hashes
+// them as title_index hashes titles, so title links resolve to encrypted
+// notes by comparing the two.
+//
+// Rotating keys rewraps data keys and recomputes the hashes; ciphertexts
+// are left as they are. Filters on titles or contents are evaluated after
+// decrypting notes, see List. The service leaves titles and contents out
+// of audit events, and the git mirror, which writes notes in the clear,
+// cannot be enabled with encryption.
+
+// toRow returns the row of n, encrypted if r has a keyring.
+func (r *PostgresRepository) toRow(n Note) (noteRow, error) {
+	row := toRow(n)
+	if r.keyring == nil {
+		return row, nil
+	}
+	return row, r.seal(&row)
+}
+
+// toDomain returns the note of row, decrypting it if it is encrypted.
+func (r *PostgresRepository) toDomain(row noteRow) (Note, error) {
+	if err := r.open(&row); err != nil {
+		return Note{}, err
+	}
+	return row.toDomain()
+}
+
+// seal encrypts the title and content of row under a new data key wrapped
+// by the current key.
+func (r *PostgresRepository) seal(row *noteRow) error {
+	dk, wrapped, err := r.keyring.NewDataKey()
+	if err != nil {
+		return err
+	}
+	keyID := r.keyring.Current()
+	index, err := r.keyring.IndexWith(keyID, titleIndexValue(row.WorkspaceID, row.Title))
+	if err != nil {
+		return err
+	}
+	if row.Title, err = sealString(dk, row.Title, fieldAAD(row.ID, FieldTitle)); err != nil {
+		return err
+	}
+	if row.Content, err = sealString(dk, row.Content, fieldAAD(row.ID, FieldContent)); err != nil {
+		return err
+	}
+	row.KeyID = &keyID
+	row.DataKey = wrapped
+	row.TitleIndex = index
+	return nil
+}
+
+// open decrypts the title and content of row in place unless it is stored
+// in the clear. It fails with encryption.ErrKeyNotFound if the key that
+// wrapped the data key of row is not configured.
+func (r *PostgresRepository) open(row *noteRow) error {
+	if row.KeyID == nil {
+		return nil
+	}
+	dk, err := r.unwrap(*row.KeyID, row.DataKey)
+	if err != nil {
+		return fmt.Errorf("decrypt note %s: %w", row.ID, err)
+	}
+	for _, field := range []struct {
+		name  FilterField
+		value *string
+	}{
+		{FieldTitle, &row.Title},
+		{FieldContent, &row.Content},
+	} {
+		if *field.value, err = openString(dk, *field.value, fieldAAD(row.ID, field.name)); err != nil {
+			return fmt.Errorf("decrypt note %s: %s: %w", row.ID, field.name, err)
+		}
+	}
+	row.KeyID, row.DataKey, row.TitleIndex = nil, nil, nil
+	return nil
+}
+
+// rewrap moves row under the current key: it seals a row stored in the
+// clear, and otherwise rewraps its data key and rehashes its title,
+// keeping the ciphertexts.
+func (r *PostgresRepository) rewrap(row *noteRow) error {
+	if row.KeyID == nil {
+		return r.seal(row)
+	}
+	plain := *row
+	if err := r.open(&plain); err != nil {
+		return err
+	}
+	wrapped, err := r.keyring.Rewrap(*row.KeyID, row.DataKey)
+	if err != nil {
+		return fmt.Errorf("rewrap note %s: %w", row.ID, err)
+	}
+	keyID := r.keyring.Current()
+	index, err := r.keyring.IndexWith(keyID, titleIndexValue(row.WorkspaceID, plain.Title))
+	if err != nil {
+		return err
+	}
+	row.KeyID = &keyID
+	row.DataKey = wrapped
+	row.TitleIndex = index
+	return nil
+}
+
+// sealLink encrypts the reference of row under a new data key wrapped by
+// the current key.
+func (r *PostgresRepository) sealLink(row *linkRow) error {
+	dk, wrapped, err := r.keyring.NewDataKey()
+	if err != nil {
+		return err
+	}
+	keyID := r.keyring.Current()
+	index, err := r.keyring.IndexWith(keyID, titleIndexValue(row.WorkspaceID, row.Ref))
+	if err != nil {
+		return err
+	}
+	if row.Ref, err = sealString(dk, row.Ref, linkAAD(row.SourceID, row.Position)); err != nil {
+		return err
+	}
+	row.KeyID = &keyID
+	row.DataKey = wrapped
+	row.RefIndex = index
+	return nil
+}
+
+// openLink decrypts the reference of row in place unless it is stored in
+// the clear, failing like open.
+func (r *PostgresRepository) openLink(row *linkRow) error {
+	if row.KeyID == nil {
+		return nil
+	}
+	dk, err := r.unwrap(*row.KeyID, row.DataKey)
+	if err != nil {
+		return fmt.Errorf("decrypt link %s/%d: %w", row.SourceID, row.Position, err)
+	}
+	if row.Ref, err = openString(dk, row.Ref, linkAAD(row.SourceID, row.Position)); err != nil {
+		return fmt.Errorf("decrypt link %s/%d: %w", row.SourceID, row.Position, err)
+	}
+	row.KeyID, row.DataKey, row.RefIndex = nil, nil, nil
+	return nil
+}
+
+// rewrapLink moves row under the current key like rewrap does.
+func (r *PostgresRepository) rewrapLink(row *linkRow) error {
+	if row.KeyID == nil {
+		return r.sealLink(row)
+	}
+	plain := *row
+	if err := r.openLink(&plain); err != nil {
+		return err
+	}
+	wrapped, err := r.keyring.Rewrap(*row.KeyID, row.DataKey)
+	if err != nil {
+		return fmt.Errorf("rewrap link %s/%d: %w", row.SourceID, row.Position, err)
+	}
+	keyID := r.keyring.Current()
+	index, err := r.keyring.IndexWith(keyID, titleIndexValue(row.WorkspaceID, plain.Ref))
+	if err != nil {
+		return err
+	}
+	row.KeyID = &keyID
+	row.DataKey = wrapped
+	row.RefIndex = index
+	return nil
+}
+
+// unwrap returns the data key wrapped by the key with keyID.
+func (r *PostgresRepository) unwrap(keyID string, wrapped []byte) (*encryption.DataKey, error) {
+	if r.keyring == nil {
+		return nil, fmt.Errorf("%w: %q", encryption.ErrKeyNotFound, keyID)
+	}
+	return r.keyring.Unwrap(keyID, wrapped)
+}
+
+// sealString encrypts s, base64 encoding the ciphertext for a text column.
+func sealString(dk *encryption.DataKey, s string, aad []byte) (string, error) {
+	ciphertext, err := dk.Seal([]byte(s), aad)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// openString decrypts a string sealed by sealString.
+func openString(dk *encryption.DataKey, s string, aad []byte) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := dk.Open(ciphertext, aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// fieldAAD binds the ciphertext of a field to its note.
+func fieldAAD(id string, field FilterField) []byte {
+	return []byte(id + "/" + string(field))
+}
+
+// linkAAD binds the ciphertext of a reference to its link.
+func linkAAD(sourceID string, position int) []byte {
+	return []byte(sourceID + "/links/" + strconv.Itoa(position))
+}
+
+// titleIndexValue scopes title to its workspace, so equal titles of
+// different workspaces have different hashes.
+func titleIndexValue(workspaceID, title string) []byte {
+	return []byte(workspaceID + "/" + title)
+}
+
+// titleIndexes returns the hashes of titles by every key, which may be
+// compared with title_index and ref_index.
+func (r *PostgresRepository) titleIndexes(workspaceID uuid.UUID, titles []string) [][]byte {
+	indexes := [][]byte{}
+	if r.keyring == nil {
+		return indexes
+	}
+	for _, title := range titles {
+		indexes = append(indexes, r.keyring.Index(titleIndexValue(workspaceID.String(), title))...)
+	}
+	return indexes
+}
+
+// Encrypted reports whether r encrypts notes.
+func (r *PostgresRepository) Encrypted() bool {
+	return r.keyring != nil
+}
+
+// CheckKeys returns an error wrapping encryption.ErrKeyNotFound if notes
+// or links are encrypted with a key r lacks, as they could not be read.
+// Under row-level security it sees none outside of a workspace, leaving
+// the error to reads.
+func (r *PostgresRepository) CheckKeys(ctx context.Context) error {
+	ids := []string{}
+	if r.keyring != nil {
+		ids = r.keyring.IDs()
+	}
+	var keyID string
+	err := r.db.Q(ctx).QueryRow(ctx,
+		`(SELECT key_id FROM notes WHERE key_id IS NOT NULL AND NOT key_id = ANY($1) LIMIT 1)
+		 UNION ALL
+		 (SELECT key_id FROM note_links WHERE key_id IS NOT NULL AND NOT key_id = ANY($1) LIMIT 1)
+		 LIMIT 1`,
+		ids,
+	).Scan(&keyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("notes are encrypted with key %q: %w", keyID, encryption.ErrKeyNotFound)
+}
+
+// Workspaces returns the IDs of every workspace. Workspaces are not
+// subject to row-level security, so the IDs are visible outside of one.
+func (r *PostgresRepository) Workspaces(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.Q(ctx).Query(ctx, `SELECT id FROM workspaces ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Reencrypt moves up to limit notes and up to limit links of the workspace
+// in ctx that are stored in the clear or under another key to the current
+// key, see rewrap. It returns the larger of the numbers of notes and links
+// it moved; fewer than limit means it is done, except for rows being written concurrently, which are skipped
+// and encrypted by their write. It must run in a transaction, which locks
+// the rows until it ends.
+func (r *PostgresRepository) Reencrypt(ctx context.Context, limit int) (int, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if r.keyring == nil {
+		return 0, nil
+	}
+	q := r.db.Q(ctx)
+	rows, err := q.Query(ctx,
+		`SELECT `+postgresColumns+` FROM notes WHERE workspace_id = $1 AND key_id IS DISTINCT FROM $2
+		 ORDER BY id LIMIT $3 FOR UPDATE SKIP LOCKED`,
+		workspaceID.String(), r.keyring.Current(), limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	noteRows, err := scanRows(rows)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range noteRows {
+		if err := r.rewrap(&row); err != nil {
+			return 0, err
+		}
+		if _, err := q.Exec(ctx,
+			`UPDATE notes SET title = $3, content = $4, key_id = $5, data_key = $6, title_index = $7 WHERE workspace_id = $1 AND id = $2`,
+			workspaceID.String(), row.ID, row.Title, row.Content, row.KeyID, row.DataKey, row.TitleIndex,
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	rows, err = q.Query(ctx,
+		`SELECT `+linkColumns+` FROM note_links WHERE workspace_id = $1 AND key_id IS DISTINCT FROM $2
+		 ORDER BY source_id, position LIMIT $3 FOR UPDATE SKIP LOCKED`,
+		workspaceID.String(), r.keyring.Current(), limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	linkRows, err := scanLinkRows(rows)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range linkRows {
+		if err := r.rewrapLink(&row); err != nil {
+			return 0, err
+		}
+		if _, err := q.Exec(ctx,
+			`UPDATE note_links SET ref = $4, key_id = $5, data_key = $6, ref_index = $7 WHERE workspace_id = $1 AND source_id = $2 AND position = $3`,
+			workspaceID.String(), row.SourceID, row.Position, row.Ref, row.KeyID, row.DataKey, row.RefIndex,
+		); err != nil {
+			return 0, err
+		}
+	}
+	return max(len(noteRows), len(linkRows)), nil
+}
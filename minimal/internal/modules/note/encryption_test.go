@@ -0,0 +1,274 @@
+package note
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// The tests seal and open rows without a database, which the repository
+// only needs for its statements.
+
+// testKeyring returns a keyring of keys named by ids, the first current.
+func testKeyring(t *testing.T, ids ...string) *encryption.Keyring {
+	t.Helper()
+	entries := make([]string, len(ids))
+	for i, id := range ids {
+		// Keys differ by ID, and stay the same across keyrings
+		secret := []byte(strings.Repeat(id, encryption.KeySize)[:encryption.KeySize])
+		entries[i] = id + ":" + base64.StdEncoding.EncodeToString(secret)
+	}
+	keyring, err := encryption.ParseKeyring(strings.Join(entries, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func testNote() Note {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	return Note{
+		ID:          NewID(),
+		WorkspaceID: uuid.New(),
+		CategoryID:  category.NewID(),
+		Title:       "Passwords",
+		Content:     "hunter2",
+		Metadata:    map[string]any{"tag": "secret"},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	r := NewPostgresRepository(nil, testKeyring(t, "a"))
+	n := testNote()
+
+	row, err := r.toRow(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.KeyID == nil || *row.KeyID != "a" {
+		t.Fatalf("key_id = %v, want a", row.KeyID)
+	}
+	if strings.Contains(row.Title, n.Title) || strings.Contains(row.Content, n.Content) {
+		t.Fatalf("row stored in the clear: %+v", row)
+	}
+	if len(row.DataKey) == 0 || len(row.TitleIndex) == 0 {
+		t.Fatalf("row lacks its data key or title index: %+v", row)
+	}
+
+	got, err := r.toDomain(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, n) {
+		t.Fatalf("got %+v, want %+v", got, n)
+	}
+}
+
+func TestEncryptionBindsFields(t *testing.T) {
+	r := NewPostgresRepository(nil, testKeyring(t, "a"))
+	row, err := r.toRow(testNote())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tamper := range map[string]func(row *noteRow){
+		"title as content": func(row *noteRow) { row.Content = row.Title },
+		"content as title": func(row *noteRow) { row.Title = row.Content },
+		// The data key and ciphertexts copied together to another note
+		"other note": func(row *noteRow) { row.ID = NewID().String() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tampered := row
+			tamper(&tampered)
+			if _, err := r.toDomain(tampered); !errors.Is(err, encryption.ErrDecrypt) {
+				t.Fatalf("got %v, want ErrDecrypt", err)
+			}
+		})
+	}
+}
+
+func TestEncryptionMissingKey(t *testing.T) {
+	row, err := NewPostgresRepository(nil, testKeyring(t, "a")).toRow(testNote())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, keyring := range map[string]*encryption.Keyring{
+		"other keys": testKeyring(t, "b"),
+		"no keyring": nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewPostgresRepository(nil, keyring).toDomain(row)
+			if !errors.Is(err, encryption.ErrKeyNotFound) {
+				t.Fatalf("got %v, want ErrKeyNotFound", err)
+			}
+		})
+	}
+}
+
+func TestEncryptionRotation(t *testing.T) {
+	n := testNote()
+	row, err := NewPostgresRepository(nil, testKeyring(t, "old")).toRow(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := row
+
+	// Rotation rewraps the data key and rehashes the title, as Reencrypt
+	// does, keeping the ciphertexts
+	rotated := NewPostgresRepository(nil, testKeyring(t, "new", "old"))
+	if err := rotated.rewrap(&row); err != nil {
+		t.Fatal(err)
+	}
+	if row.KeyID == nil || *row.KeyID != "new" {
+		t.Fatalf("key_id = %v, want new", row.KeyID)
+	}
+	if row.Title != before.Title || row.Content != before.Content {
+		t.Fatal("rotation sealed the note again")
+	}
+	if bytes.Equal(row.DataKey, before.DataKey) || bytes.Equal(row.TitleIndex, before.TitleIndex) {
+		t.Fatal("rotation kept the wrapped data key or title index")
+	}
+	index, err := rotated.keyring.IndexWith("new", titleIndexValue(row.WorkspaceID, n.Title))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(row.TitleIndex, index) {
+		t.Fatal("title index is not the hash of the title by the current key")
+	}
+
+	// The old key may now be removed
+	got, err := NewPostgresRepository(nil, testKeyring(t, "new")).toDomain(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, n) {
+		t.Fatalf("got %+v, want %+v", got, n)
+	}
+	if _, err := NewPostgresRepository(nil, testKeyring(t, "old")).toDomain(row); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Fatalf("old key: got %v, want ErrKeyNotFound", err)
+	}
+
+	// Notes stored in the clear are sealed
+	plain := toRow(n)
+	if err := rotated.rewrap(&plain); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := rotated.toDomain(plain); err != nil || !reflect.DeepEqual(got, n) {
+		t.Fatalf("got %+v, %v, want %+v", got, err, n)
+	}
+}
+
+func TestLinkEncryption(t *testing.T) {
+	r := NewPostgresRepository(nil, testKeyring(t, "a"))
+	n := testNote()
+	plain := linkRow{WorkspaceID: n.WorkspaceID.String(), SourceID: NewID().String(), Position: 1, Kind: string(LinkByTitle), Ref: n.Title}
+
+	row := plain
+	if err := r.sealLink(&row); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(row.Ref, n.Title) {
+		t.Fatalf("reference stored in the clear: %q", row.Ref)
+	}
+	// Title links resolve by comparing hashes
+	note, err := r.toRow(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(row.RefIndex, note.TitleIndex) {
+		t.Fatal("ref_index differs from the title_index of the linked note")
+	}
+
+	opened := row
+	if err := r.openLink(&opened); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opened, plain) {
+		t.Fatalf("got %+v, want %+v", opened, plain)
+	}
+
+	moved := row
+	moved.Position = 2
+	if err := r.openLink(&moved); !errors.Is(err, encryption.ErrDecrypt) {
+		t.Fatalf("other position: got %v, want ErrDecrypt", err)
+	}
+	missing := row
+	if err := NewPostgresRepository(nil, testKeyring(t, "b")).openLink(&missing); !errors.Is(err, encryption.ErrKeyNotFound) {
+		t.Fatalf("other keys: got %v, want ErrKeyNotFound", err)
+	}
+
+	rotated := NewPostgresRepository(nil, testKeyring(t, "new", "a"))
+	rewrapped := row
+	if err := rotated.rewrapLink(&rewrapped); err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped.Ref != row.Ref || *rewrapped.KeyID != "new" {
+		t.Fatalf("rotation sealed the reference again or kept its key: %+v", rewrapped)
+	}
+	if err := NewPostgresRepository(nil, testKeyring(t, "new")).openLink(&rewrapped); err != nil || rewrapped.Ref != n.Title {
+		t.Fatalf("open rewrapped = %q, %v", rewrapped.Ref, err)
+	}
+}
+
+// encryptedRepository is a memory repository reporting that it encrypts
+// notes.
+type encryptedRepository struct {
+	*MemoryRepository
+}
+
+func (encryptedRepository) Encrypted() bool { return true }
+
+func (encryptedRepository) Workspaces(context.Context) ([]uuid.UUID, error) { return nil, nil }
+
+func (encryptedRepository) Reencrypt(context.Context, int) (int, error) { return 0, nil }
+
+func TestEncryptionLeavesAuditOut(t *testing.T) {
+	logger := zap.NewNop()
+	auditor := audit.NewService(audit.NewMemoryRepository(), logger)
+	categories := category.NewService(category.NewMemoryRepository(), db.NopTransactor{}, auditor, logger)
+	s := NewService(encryptedRepository{NewMemoryRepository()}, categories, db.NopTransactor{}, auditor, logger)
+	ctx := tenant.WithWorkspace(context.Background(), uuid.New())
+
+	c, err := categories.Create(ctx, category.CreateInput{Name: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := s.Create(ctx, CreateInput{CategoryID: c.ID, Title: "Passwords", Content: "hunter2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update(ctx, UpdateInput{ID: n.ID, CategoryID: c.ID, Title: "Secrets", Content: "hunter3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := auditor.List(ctx, audit.Filter{EntityID: n.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	for _, e := range events {
+		for _, field := range []string{"title", "content"} {
+			if _, ok := e.Changes[field]; ok {
+				t.Errorf("%s event records the %s", e.Action, field)
+			}
+		}
+	}
+}
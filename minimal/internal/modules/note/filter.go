@@ -0,0 +1,534 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+)
+
+// ErrInvalidFilter is returned by ParseFilter for malformed expressions.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// TagsKey is the metadata field holding the tags of a note, an array of
+// strings.
+const TagsKey = "tags"
+
+// FilterField is a property of notes compared by filter expressions.
+type FilterField string
+
+const (
+	FieldTitle    FilterField = "title"
+	FieldContent  FilterField = "content"
+	FieldCategory FilterField = "category"
+	FieldTag      FilterField = "tag"
+	FieldCreated  FilterField = "created"
+	FieldUpdated  FilterField = "updated"
+)
+
+// FilterOp is a comparison operator of filter expressions.
+type FilterOp string
+
+const (
+	FilterEq          FilterOp = "="
+	FilterNe          FilterOp = "!="
+	FilterContains    FilterOp = "~"
+	FilterNotContains FilterOp = "!~"
+	FilterGt          FilterOp = ">"
+	FilterGe          FilterOp = ">="
+	FilterLt          FilterOp = "<"
+	FilterLe          FilterOp = "<="
+)
+
+var dateOps = []FilterOp{FilterEq, FilterNe, FilterGt, FilterGe, FilterLt, FilterLe}
+
+// filterOps lists the operators each field accepts.
+var filterOps = map[FilterField][]FilterOp{
+	FieldTitle:    {FilterEq, FilterNe, FilterContains, FilterNotContains},
+	FieldContent:  {FilterContains, FilterNotContains},
+	FieldCategory: {FilterEq, FilterNe},
+	FieldTag:      {FilterEq, FilterNe},
+	FieldCreated:  dateOps,
+	FieldUpdated:  dateOps,
+}
+
+// fieldAliases are alternative spellings of fields.
+var fieldAliases = map[string]FilterField{
+	"tags": FieldTag,
+}
+
+// maxFilterDepth bounds the nesting of parentheses and NOT.
+const maxFilterDepth = 32
+
+// Filter is a parsed filter expression, see ParseFilter. Its category
+// names and dates are resolved by Service.List before the repository
+// evaluates it.
+type Filter struct {
+	source string
+	root   filterExpr
+}
+
+// String returns the expression the filter was parsed from.
+func (f *Filter) String() string {
+	return f.source
+}
+
+// filterExpr is a node of a parsed filter: filterAnd, filterOr, filterNot
+// or *filterTerm.
+type filterExpr interface {
+	match(n Note) bool
+}
+
+type filterAnd []filterExpr
+
+func (e filterAnd) match(n Note) bool {
+	for _, x := range e {
+		if !x.match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+type filterOr []filterExpr
+
+func (e filterOr) match(n Note) bool {
+	for _, x := range e {
+		if x.match(n) {
+			return true
+		}
+	}
+	return false
+}
+
+type filterNot struct {
+	expr filterExpr
+}
+
+func (e filterNot) match(n Note) bool {
+	return !e.expr.match(n)
+}
+
+// filterTerm compares a field of notes with a value.
+type filterTerm struct {
+	Field FilterField
+	Op    FilterOp
+	Value string
+
+	// date is the parsed value of created and updated terms.
+	date dateValue
+	// categories holds the IDs of the categories Value refers to, once
+	// resolved.
+	categories []category.ID
+	// from and to bound the day of date terms, once resolved.
+	from, to time.Time
+}
+
+// dateValue is a day, either a date or a number of days before today.
+type dateValue struct {
+	day     time.Time
+	daysAgo int
+}
+
+// maxDaysAgo bounds relative dates.
+const maxDaysAgo = 100 * 366
+
+// parseDate parses a YYYY-MM-DD date, "today" or "-<n>d", n days before
+// today.
+func parseDate(s string) (dateValue, bool) {
+	if s == "today" {
+		return dateValue{daysAgo: 0}, true
+	}
+	if n, ok := strings.CutSuffix(s, "d"); ok && strings.HasPrefix(n, "-") {
+		days, err := strconv.Atoi(n[1:])
+		if err != nil || days < 0 || days > maxDaysAgo {
+			return dateValue{}, false
+		}
+		return dateValue{daysAgo: days}, true
+	}
+	day, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return dateValue{}, false
+	}
+	return dateValue{day: day}, true
+}
+
+// resolve returns the start of the day in UTC.
+func (d dateValue) resolve(now time.Time) time.Time {
+	if !d.day.IsZero() {
+		return d.day
+	}
+	today := now.UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, -d.daysAgo)
+}
+
+func (t *filterTerm) match(n Note) bool {
+	switch t.Field {
+	case FieldTitle:
+		return t.matchText(n.Title)
+	case FieldContent:
+		return t.matchText(n.Content)
+	case FieldCategory:
+		return slices.Contains(t.categories, n.CategoryID) == (t.Op == FilterEq)
+	case FieldTag:
+		return hasTag(n.Metadata, t.Value) == (t.Op == FilterEq)
+	case FieldCreated:
+		return t.matchTime(n.CreatedAt)
+	case FieldUpdated:
+		return t.matchTime(n.UpdatedAt)
+	}
+	return false
+}
+
+func (t *filterTerm) matchText(s string) bool {
+	s, v := strings.ToLower(s), strings.ToLower(t.Value)
+	switch t.Op {
+	case FilterEq:
+		return s == v
+	case FilterNe:
+		return s != v
+	case FilterContains:
+		return strings.Contains(s, v)
+	case FilterNotContains:
+		return !strings.Contains(s, v)
+	}
+	return false
+}
+
+// matchTime compares ts with the day of the term: = is any time on it, >
+// is after it and < before it.
+func (t *filterTerm) matchTime(ts time.Time) bool {
+	switch t.Op {
+	case FilterEq:
+		return !ts.Before(t.from) && ts.Before(t.to)
+	case FilterNe:
+		return ts.Before(t.from) || !ts.Before(t.to)
+	case FilterGt:
+		return !ts.Before(t.to)
+	case FilterGe:
+		return !ts.Before(t.from)
+	case FilterLt:
+		return ts.Before(t.from)
+	case FilterLe:
+		return ts.Before(t.to)
+	}
+	return false
+}
+
+// hasTag reports whether the tags of metadata include tag.
+func hasTag(metadata map[string]any, tag string) bool {
+	tags, _ := metadata[TagsKey].([]any)
+	return slices.Contains(tags, any(tag))
+}
+
+// terms returns the terms of the filter.
+func (f *Filter) terms() []*filterTerm {
+	var terms []*filterTerm
+	var walk func(e filterExpr)
+	walk = func(e filterExpr) {
+		switch e := e.(type) {
+		case filterAnd:
+			for _, x := range e {
+				walk(x)
+			}
+		case filterOr:
+			for _, x := range e {
+				walk(x)
+			}
+		case filterNot:
+			walk(e.expr)
+		case *filterTerm:
+			terms = append(terms, e)
+		}
+	}
+	walk(f.root)
+	return terms
+}
+
+// refersTo reports whether the filter compares field.
+func (f *Filter) refersTo(field FilterField) bool {
+	return slices.ContainsFunc(f.terms(), func(t *filterTerm) bool {
+		return t.Field == field
+	})
+}
+
+// resolve sets the days of date terms relative to now and the categories
+// of category terms, which refer to a category by name or ID.
+func (f *Filter) resolve(now time.Time, categories []category.Category) {
+	for _, t := range f.terms() {
+		switch t.Field {
+		case FieldCreated, FieldUpdated:
+			t.from = t.date.resolve(now)
+			t.to = t.from.AddDate(0, 0, 1)
+		case FieldCategory:
+			t.categories = nil
+			for _, c := range categories {
+				if c.Name == t.Value || c.ID.String() == strings.ToLower(t.Value) {
+					t.categories = append(t.categories, c.ID)
+				}
+			}
+		}
+	}
+}
+
+// ParseFilter parses a filter expression. Terms compare a field with a
+// value, e.g. title ~ "meeting" or created >= -7d, and combine with AND,
+// OR, NOT and parentheses; adjacent terms are ANDed.
+//
+// Fields and their operators:
+//
+//	title      = != ~ !~   the title; ~ is contains
+//	content    ~ !~        the content
+//	category   = !=        the category, by name or ID
+//	tag        = !=        an entry of the "tags" array in the metadata
+//	created    = != > >= < <=   the day of creation
+//	updated    = != > >= < <=   the day of the last update
+//
+// Titles and content compare case-insensitively. Values are bare words or
+// double-quoted strings with \" and \\ escapes.
+// Dates are YYYY-MM-DD, today or -<n>d for n days ago, in UTC.
+func ParseFilter(s string) (*Filter, error) {
+	tokens, err := lexFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, fmt.Errorf("%w: empty expression", ErrInvalidFilter)
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	return &Filter{source: s, root: root}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// keyword reports whether t is the bare word kw, in any case.
+func (t token) keyword(kw string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, kw)
+}
+
+// filterDelimiters end bare words. They are all ASCII, so the expression
+// is scanned byte by byte.
+const filterDelimiters = " \t\r\n()\"=!~<>"
+
+func lexFilter(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		r := rune(s[i])
+		switch {
+		case strings.ContainsRune(" \t\r\n", r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case strings.ContainsRune("=!~<>", r):
+			op := s[i : i+1]
+			if i+1 < len(s) && (r == '!' || r == '<' || r == '>') && (s[i+1] == '=' || (r == '!' && s[i+1] == '~')) {
+				op = s[i : i+2]
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("%w: unexpected \"!\" at offset %d", ErrInvalidFilter, i)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		case r == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) && (s[j+1] == '"' || s[j+1] == '\\') {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j == len(s) {
+				return nil, fmt.Errorf("%w: unterminated string at offset %d", ErrInvalidFilter, i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: i})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(filterDelimiters, rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: s[i:j], pos: i})
+			i = j
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+type filterParser struct {
+	tokens []token
+	depth  int
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[0]
+}
+
+func (p *filterParser) next() token {
+	tok := p.tokens[0]
+	if tok.kind != tokenEOF {
+		p.tokens = p.tokens[1:]
+	}
+	return tok
+}
+
+func (p *filterParser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidFilter, fmt.Sprintf(format, args...), tok.pos)
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := filterOr{left}
+	for p.peek().keyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, right)
+	}
+	if len(or) == 1 {
+		return left, nil
+	}
+	return or, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	and := filterAnd{left}
+	for {
+		tok := p.peek()
+		if tok.keyword("AND") {
+			p.next()
+		} else if tok.kind == tokenRParen || tok.kind == tokenEOF || tok.keyword("OR") {
+			break
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, right)
+	}
+	if len(and) == 1 {
+		return left, nil
+	}
+	return and, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	tok := p.peek()
+	if tok.keyword("NOT") || tok.kind == tokenLParen {
+		if p.depth++; p.depth > maxFilterDepth {
+			return nil, p.errorf(tok, "expression nested too deeply")
+		}
+		defer func() { p.depth-- }()
+	}
+
+	switch {
+	case tok.keyword("NOT"):
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{expr: expr}, nil
+	case tok.kind == tokenLParen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, "expected \")\", got %s", closing)
+		}
+		return expr, nil
+	case tok.kind == tokenWord:
+		return p.parseTerm()
+	}
+	return nil, p.errorf(tok, "expected a field, got %s", tok)
+}
+
+func (p *filterParser) parseTerm() (filterExpr, error) {
+	name := p.next()
+	field := FilterField(strings.ToLower(name.text))
+	if alias, ok := fieldAliases[string(field)]; ok {
+		field = alias
+	}
+	ops, ok := filterOps[field]
+	if !ok {
+		return nil, p.errorf(name, "unknown field %q", name.text)
+	}
+
+	opTok := p.next()
+	if opTok.kind != tokenOp {
+		return nil, p.errorf(opTok, "expected an operator after %s, got %s", field, opTok)
+	}
+	op := FilterOp(opTok.text)
+	if !slices.Contains(ops, op) {
+		return nil, p.errorf(opTok, "operator %s is not supported by %s", op, field)
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, p.errorf(value, "expected a value after %s %s, got %s", field, op, value)
+	}
+
+	t := &filterTerm{Field: field, Op: op, Value: value.text}
+	switch field {
+	case FieldCreated, FieldUpdated:
+		if t.date, ok = parseDate(value.text); !ok {
+			return nil, p.errorf(value, "invalid date %s, want YYYY-MM-DD, today or -<n>d", value)
+		}
+	case FieldCategory, FieldTag:
+		if value.text == "" {
+			return nil, p.errorf(value, "%s must not be empty", field)
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,82 @@
+package note
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LinkKind tells how a link names its target.
+type LinkKind string
+
+const (
+	// LinkByTitle links are written [[Note Title]].
+	LinkByTitle LinkKind = "title"
+	// LinkByID links are written [[note:<id>]].
+	LinkByID LinkKind = "id"
+)
+
+// linkIDPrefix starts the reference of an ID link.
+const linkIDPrefix = "note:"
+
+// Link is a reference from the content of one note to another.
+type Link struct {
+	SourceID ID
+	Kind     LinkKind
+	// Ref is the title of a title link or the ID of an ID link.
+	Ref string
+	// TargetID is the linked note. It is zero while the link is dangling,
+	// i.e. no note has the title or ID.
+	TargetID ID
+}
+
+// Dangling reports whether the link points to no note.
+func (l Link) Dangling() bool {
+	return l.TargetID == ID{}
+}
+
+// linkPattern matches [[...]] references within a line.
+var linkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+
+// ParseLinks returns the distinct links in content in order of first
+// appearance. References starting with "note:" followed by a valid ID are
+// ID links, anything else names a title.
+func ParseLinks(content string) []Link {
+	var links []Link
+	seen := make(map[Link]bool)
+	for _, m := range linkPattern.FindAllStringSubmatch(content, -1) {
+		l := parseLink(m[1])
+		if l.Ref == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		links = append(links, l)
+	}
+	return links
+}
+
+func parseLink(ref string) Link {
+	ref = strings.TrimSpace(ref)
+	if s, ok := strings.CutPrefix(ref, linkIDPrefix); ok {
+		if id, err := ParseID(strings.TrimSpace(s)); err == nil {
+			return Link{Kind: LinkByID, Ref: id.String()}
+		}
+	}
+	return Link{Kind: LinkByTitle, Ref: ref}
+}
+
+// Linkable reports whether notes titled title can be linked by title.
+func Linkable(title string) bool {
+	return strings.TrimSpace(title) == title && title != "" && !strings.ContainsAny(title, "[]\n")
+}
+
+// rewriteTitleLinks replaces the title links to from in content with links
+// to to, keeping other references as written.
+func rewriteTitleLinks(content, from, to string) string {
+	return linkPattern.ReplaceAllStringFunc(content, func(m string) string {
+		l := parseLink(m[2 : len(m)-2])
+		if l.Kind != LinkByTitle || l.Ref != from {
+			return m
+		}
+		return "[[" + to + "]]"
+	})
+}
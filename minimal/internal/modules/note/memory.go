@@ -0,0 +1,292 @@
+package note
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	notes map[ID]Note
+	// links holds the links of each note by source.
+	links map[ID][]Link
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{notes: make(map[ID]Note), links: make(map[ID][]Link)}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, n Note) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.notes[n.ID]; ok {
+		return ErrAlreadyExists
+	}
+	n.WorkspaceID = workspaceID
+	r.notes[n.ID] = n
+	return nil
+}
+
+func (r *MemoryRepository) GetByID(ctx context.Context, id ID) (Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Note{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, ok := r.notes[id]
+	if !ok || n.WorkspaceID != workspaceID {
+		return Note{}, ErrNotFound
+	}
+	return n, nil
+}
+
+func (r *MemoryRepository) GetAll(ctx context.Context) ([]Note, error) {
+	return r.filter(ctx, func(Note) bool { return true })
+}
+
+func (r *MemoryRepository) GetByCategory(ctx context.Context, categoryID category.ID) ([]Note, error) {
+	return r.filter(ctx, func(n Note) bool { return n.CategoryID == categoryID })
+}
+
+func (r *MemoryRepository) List(ctx context.Context, f ListFilter) ([]Note, error) {
+	notes, err := r.filter(ctx, func(n Note) bool {
+		if f.CategoryID != (category.ID{}) && n.CategoryID != f.CategoryID {
+			return false
+		}
+		for _, c := range f.Metadata {
+			if !c.Match(n.Metadata) {
+				return false
+			}
+		}
+		if f.Filter != nil && !f.Filter.root.match(n) {
+			return false
+		}
+		return f.After == nil || f.After.After(n)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f.Limit > 0 && len(notes) > f.Limit {
+		notes = notes[:f.Limit]
+	}
+	return notes, nil
+}
+
+func (r *MemoryRepository) ListByCategories(ctx context.Context, categoryIDs []category.ID, limit int) ([]Note, error) {
+	notes, err := r.filter(ctx, func(n Note) bool { return slices.Contains(categoryIDs, n.CategoryID) })
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[category.ID]int, len(categoryIDs))
+	return slices.DeleteFunc(notes, func(n Note) bool {
+		counts[n.CategoryID]++
+		return counts[n.CategoryID] > limit
+	}), nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, n Note) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.notes[n.ID]
+	if !ok || existing.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	existing.CategoryID = n.CategoryID
+	existing.Title = n.Title
+	existing.Content = n.Content
+	existing.Metadata = n.Metadata
+	existing.UpdatedAt = n.UpdatedAt
+	r.notes[n.ID] = existing
+	return nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, ok := r.notes[id]
+	if !ok || n.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	delete(r.notes, id)
+	delete(r.links, id)
+	for source, links := range r.links {
+		for i := range links {
+			if links[i].TargetID == id {
+				r.links[source][i].TargetID = ID{}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) GetByTitles(ctx context.Context, titles []string) ([]Note, error) {
+	want := make(map[string]bool, len(titles))
+	for _, t := range titles {
+		want[t] = true
+	}
+	notes, err := r.filter(ctx, func(n Note) bool { return want[n.Title] })
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(notes)
+	return notes, nil
+}
+
+func (r *MemoryRepository) GetByIDs(ctx context.Context, ids []ID) ([]Note, error) {
+	return r.filter(ctx, func(n Note) bool { return slices.Contains(ids, n.ID) })
+}
+
+func (r *MemoryRepository) SetLinks(ctx context.Context, source ID, links []Link) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, ok := r.notes[source]
+	if !ok || n.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	stored := make([]Link, len(links))
+	for i, l := range links {
+		l.SourceID = source
+		stored[i] = l
+	}
+	r.links[source] = stored
+	return nil
+}
+
+func (r *MemoryRepository) GetLinks(ctx context.Context, source ID) ([]Link, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if n, ok := r.notes[source]; !ok || n.WorkspaceID != workspaceID {
+		return nil, nil
+	}
+	return slices.Clone(r.links[source]), nil
+}
+
+func (r *MemoryRepository) GetLinkSources(ctx context.Context, target ID) ([]Note, error) {
+	r.mu.RLock()
+	sources := make(map[ID]bool)
+	for source, links := range r.links {
+		for _, l := range links {
+			if l.TargetID == target {
+				sources[source] = true
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	return r.filter(ctx, func(n Note) bool { return sources[n.ID] })
+}
+
+func (r *MemoryRepository) GetDanglingLinks(ctx context.Context) ([]Link, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var dangling []Link
+	for source, links := range r.links {
+		if r.notes[source].WorkspaceID != workspaceID {
+			continue
+		}
+		for _, l := range links {
+			if l.Dangling() {
+				dangling = append(dangling, l)
+			}
+		}
+	}
+	sort.Slice(dangling, func(i, j int) bool {
+		if dangling[i].Ref != dangling[j].Ref {
+			return dangling[i].Ref < dangling[j].Ref
+		}
+		return dangling[i].SourceID.String() < dangling[j].SourceID.String()
+	})
+	return dangling, nil
+}
+
+func (r *MemoryRepository) ResolveLinks(ctx context.Context, title string, target ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for source, links := range r.links {
+		if r.notes[source].WorkspaceID != workspaceID {
+			continue
+		}
+		for i, l := range links {
+			if l.Dangling() && l.Kind == LinkByTitle && l.Ref == title {
+				links[i].TargetID = target
+			}
+		}
+	}
+	return nil
+}
+
+// filter returns the notes of the context's workspace matching keep,
+// newest first.
+func (r *MemoryRepository) filter(ctx context.Context, keep func(Note) bool) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var notes []Note
+	for _, n := range r.notes {
+		if n.WorkspaceID == workspaceID && keep(n) {
+			notes = append(notes, n)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return CursorOf(notes[j]).Before(notes[i])
+	})
+	return notes, nil
+}
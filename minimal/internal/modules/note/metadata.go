@@ -0,0 +1,158 @@
+package note
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidMetadata is returned for metadata that does not match the
+	// metadata schema of the note's category.
+	ErrInvalidMetadata = errors.New("invalid metadata")
+	// ErrInvalidMetadataFilter is returned by ParseMetadataCondition.
+	ErrInvalidMetadataFilter = errors.New("invalid metadata filter")
+)
+
+// MetadataOp compares a metadata field with a value.
+type MetadataOp string
+
+const (
+	OpEq MetadataOp = "="
+	OpNe MetadataOp = "!="
+	OpGt MetadataOp = ">"
+	OpGe MetadataOp = ">="
+	OpLt MetadataOp = "<"
+	OpLe MetadataOp = "<="
+)
+
+// MetadataCondition selects notes by a top-level metadata field.
+//
+// = and != compare with the value as a number or boolean, when it reads as
+// one, and as a string. The other operators compare numbers if the value
+// is a number and strings otherwise, so ISO dates order as expected. Notes
+// without the field only match !=.
+type MetadataCondition struct {
+	Key   string
+	Op    MetadataOp
+	Value string
+}
+
+// metadataKeyPattern restricts the keys conditions can refer to.
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ParseMetadataCondition parses a condition from a query parameter, split
+// at its first "=" as URL queries are: "status=open" is name "status" and
+// value "open", "priority>=2" is name "priority>" and value "2", and
+// "priority>2" is name "priority>2" with an empty value.
+func ParseMetadataCondition(name, value string) (MetadataCondition, error) {
+	i := strings.IndexAny(name, "!<>")
+	if i < 0 {
+		return newMetadataCondition(name, OpEq, value)
+	}
+	key, op := name[:i], name[i:]
+	switch op {
+	case "!", "<", ">":
+		return newMetadataCondition(key, MetadataOp(op+"="), value)
+	}
+	if value == "" && (op[0] == '<' || op[0] == '>') {
+		return newMetadataCondition(key, MetadataOp(op[:1]), op[1:])
+	}
+	return MetadataCondition{}, fmt.Errorf("%w: %s", ErrInvalidMetadataFilter, name)
+}
+
+func newMetadataCondition(key string, op MetadataOp, value string) (MetadataCondition, error) {
+	if !metadataKeyPattern.MatchString(key) {
+		return MetadataCondition{}, fmt.Errorf("%w: invalid key %q", ErrInvalidMetadataFilter, key)
+	}
+	return MetadataCondition{Key: key, Op: op, Value: value}, nil
+}
+
+// Number returns the value as a number, if it reads as one.
+func (c MetadataCondition) Number() (float64, bool) {
+	n, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, false
+	}
+	return n, true
+}
+
+// Candidates returns the JSON values a field equals to satisfy an = or !=
+// condition.
+func (c MetadataCondition) Candidates() []any {
+	if n, ok := c.Number(); ok {
+		return []any{n, c.Value}
+	}
+	if c.Value == "true" || c.Value == "false" {
+		return []any{c.Value == "true", c.Value}
+	}
+	return []any{c.Value}
+}
+
+// Match reports whether metadata satisfies the condition.
+func (c MetadataCondition) Match(metadata map[string]any) bool {
+	v, ok := metadata[c.Key]
+	switch c.Op {
+	case OpEq:
+		return ok && c.equals(v)
+	case OpNe:
+		return !ok || !c.equals(v)
+	}
+
+	var order int
+	if n, isNumber := c.Number(); isNumber {
+		f, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		order = cmp.Compare(f, n)
+	} else {
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		order = strings.Compare(s, c.Value)
+	}
+	switch c.Op {
+	case OpGt:
+		return order > 0
+	case OpGe:
+		return order >= 0
+	case OpLt:
+		return order < 0
+	case OpLe:
+		return order <= 0
+	}
+	return false
+}
+
+func (c MetadataCondition) equals(v any) bool {
+	for _, candidate := range c.Candidates() {
+		if v == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeMetadata returns metadata as it reads back from JSON, so it
+// compares the same whether stored in memory or in the database.
+func normalizeMetadata(metadata map[string]any) (map[string]any, error) {
+	if metadata == nil {
+		return map[string]any{}, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	var normalized map[string]any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	return normalized, nil
+}
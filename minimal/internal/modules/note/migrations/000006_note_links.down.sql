@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notes_workspace_title;
+DROP TABLE IF EXISTS note_links;
@@ -0,0 +1,25 @@
+-- Create note_links table; a row is a [[...]] reference in the content of
+-- a source note, and target_id is NULL while the link is dangling
+CREATE TABLE IF NOT EXISTS note_links (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('title', 'id')),
+    ref TEXT NOT NULL,
+    target_id UUID REFERENCES notes(id) ON DELETE SET NULL,
+    PRIMARY KEY (source_id, position)
+);
+
+-- Create indexes for backlinks and resolving dangling links
+CREATE INDEX IF NOT EXISTS idx_note_links_target_id ON note_links(target_id);
+CREATE INDEX IF NOT EXISTS idx_note_links_dangling ON note_links(workspace_id, ref) WHERE target_id IS NULL;
+
+-- Create index for resolving links by title
+CREATE INDEX IF NOT EXISTS idx_notes_workspace_title ON notes(workspace_id, title);
+
+-- Row-level security, as for notes
+ALTER TABLE note_links ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY note_links_workspace_isolation ON note_links
+    USING (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid)
+    WITH CHECK (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::uuid);
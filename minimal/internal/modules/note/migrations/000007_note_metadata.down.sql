@@ -0,0 +1,3 @@
+ALTER TABLE categories DROP COLUMN IF EXISTS metadata_schema;
+DROP INDEX IF EXISTS idx_notes_metadata;
+ALTER TABLE notes DROP COLUMN IF EXISTS metadata;
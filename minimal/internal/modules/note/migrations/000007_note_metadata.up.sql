@@ -0,0 +1,8 @@
+-- Add custom metadata fields to notes
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+-- Create index for filtering notes by metadata
+CREATE INDEX IF NOT EXISTS idx_notes_metadata ON notes USING GIN (metadata jsonb_path_ops);
+
+-- Add the optional JSON Schema the metadata of notes in a category must match
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS metadata_schema JSONB;
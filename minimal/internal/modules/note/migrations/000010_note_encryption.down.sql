@@ -0,0 +1,6 @@
+-- Notes still encrypted become unreadable once their key columns are dropped
+DROP INDEX IF EXISTS idx_notes_key_id;
+DROP INDEX IF EXISTS idx_notes_workspace_title_index;
+ALTER TABLE notes DROP COLUMN IF EXISTS title_index;
+ALTER TABLE notes DROP COLUMN IF EXISTS data_key;
+ALTER TABLE notes DROP COLUMN IF EXISTS key_id;
@@ -0,0 +1,14 @@
+-- Envelope encryption of note titles and contents. key_id names the key
+-- that wrapped data_key, the data key sealing title and content, and is
+-- NULL for notes stored in the clear. title_index is a keyed hash of the
+-- title for lookups by title.
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS key_id TEXT;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS data_key BYTEA;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS title_index BYTEA;
+
+-- Create index for resolving links to encrypted notes by title
+CREATE INDEX IF NOT EXISTS idx_notes_workspace_title_index ON notes(workspace_id, title_index)
+    WHERE title_index IS NOT NULL;
+
+-- Create index for finding notes encrypted with keys that are not configured
+CREATE INDEX IF NOT EXISTS idx_notes_key_id ON notes(key_id) WHERE key_id IS NOT NULL;
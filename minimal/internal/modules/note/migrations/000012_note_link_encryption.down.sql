@@ -0,0 +1,7 @@
+-- Links still encrypted become unreadable once their key columns are
+-- dropped
+DROP INDEX IF EXISTS idx_note_links_key_id;
+DROP INDEX IF EXISTS idx_note_links_dangling_ref_index;
+ALTER TABLE note_links DROP COLUMN IF EXISTS ref_index;
+ALTER TABLE note_links DROP COLUMN IF EXISTS data_key;
+ALTER TABLE note_links DROP COLUMN IF EXISTS key_id;
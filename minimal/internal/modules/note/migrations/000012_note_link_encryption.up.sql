@@ -0,0 +1,14 @@
+-- Envelope encryption of link references, as of note titles. key_id names
+-- the key that wrapped data_key, the data key sealing ref, and is NULL for
+-- links stored in the clear. ref_index is a keyed hash of ref, comparable
+-- with the title_index of notes.
+ALTER TABLE note_links ADD COLUMN IF NOT EXISTS key_id TEXT;
+ALTER TABLE note_links ADD COLUMN IF NOT EXISTS data_key BYTEA;
+ALTER TABLE note_links ADD COLUMN IF NOT EXISTS ref_index BYTEA;
+
+-- Create index for resolving dangling links to encrypted titles
+CREATE INDEX IF NOT EXISTS idx_note_links_dangling_ref_index ON note_links(workspace_id, ref_index)
+    WHERE target_id IS NULL AND ref_index IS NOT NULL;
+
+-- Create index for finding links encrypted with keys that are not configured
+CREATE INDEX IF NOT EXISTS idx_note_links_key_id ON note_links(key_id) WHERE key_id IS NOT NULL;
@@ -0,0 +1,52 @@
+package note
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migrations holds the migrations of links, metadata and encryption.
+var Migrations, _ = fs.Sub(migrationsFS, "migrations")
+
+// Module serves notes under /notes and re-encrypts them in the background.
+type Module struct {
+	modules.Base
+	service *Service
+	routes  modules.Routes
+	db      modules.Pinger
+}
+
+// NewModule creates the note module. routes serves /notes and db is the
+// database notes are stored in, or nil if they are kept in memory.
+func NewModule(service *Service, routes modules.Routes, db modules.Pinger) *Module {
+	return &Module{service: service, routes: routes, db: db}
+}
+
+func (m *Module) Name() string { return "note" }
+
+// Notes belong to categories.
+func (m *Module) Dependencies() []string { return []string{"category"} }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/notes"))
+}
+
+func (m *Module) Migrations() fs.FS { return Migrations }
+
+// RegisterJobs registers the re-encryption of notes encrypted at rest.
+func (m *Module) RegisterJobs(ctx context.Context, queue *jobs.Queue) error {
+	return m.service.RegisterJobs(ctx, queue)
+}
+
+// Health pings the database notes are stored in.
+func (m *Module) Health(ctx context.Context) error {
+	return modules.Ping(ctx, m.db)
+}
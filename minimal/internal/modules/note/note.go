@@ -1,6 +1,10 @@
 package note
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,12 +16,16 @@ type ID = uuid.UUID
 
 // Note represents a note domain model.
 type Note struct {
-	ID         ID
-	CategoryID category.ID
-	Title      string
-	Content    string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID          ID
+	WorkspaceID uuid.UUID
+	CategoryID  category.ID
+	Title       string
+	Content     string
+	// Metadata holds custom fields, constrained by the metadata schema of
+	// the category if it has one.
+	Metadata  map[string]any
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 // NewID generates a new note ID.
@@ -29,3 +37,57 @@ func NewID() ID {
 func ParseID(s string) (ID, error) {
 	return uuid.Parse(s)
 }
+
+// Cursor is the position of a note in the newest-first order of listings.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        ID
+}
+
+// CursorOf returns the cursor positioned at n.
+func CursorOf(n Note) Cursor {
+	return Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
+
+// Before reports whether n comes before c, i.e. is newer.
+func (c Cursor) Before(n Note) bool {
+	if !n.CreatedAt.Equal(c.CreatedAt) {
+		return n.CreatedAt.After(c.CreatedAt)
+	}
+	return bytes.Compare(n.ID[:], c.ID[:]) > 0
+}
+
+// After reports whether n comes after c, i.e. is older.
+func (c Cursor) After(n Note) bool {
+	return !c.Before(n) && !(n.CreatedAt.Equal(c.CreatedAt) && n.ID == c.ID)
+}
+
+// String encodes the cursor as an opaque token.
+func (c Cursor) String() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ErrInvalidCursor is returned by ParseCursor for malformed tokens.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ParseCursor decodes a token produced by Cursor.String.
+func ParseCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	parsed, err := ParseID(id)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{CreatedAt: t, ID: parsed}, nil
+}
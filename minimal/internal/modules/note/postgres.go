@@ -2,21 +2,42 @@ package note
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
 	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
 )
 
 // noteRow is the database representation of a note.
 type noteRow struct {
-	ID         string    `db:"id"`
-	CategoryID string    `db:"category_id"`
-	Title      string    `db:"title"`
-	Content    string    `db:"content"`
-	CreatedAt  time.Time `db:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at"`
+	ID          string         `db:"id"`
+	WorkspaceID string         `db:"workspace_id"`
+	CategoryID  string         `db:"category_id"`
+	Title       string         `db:"title"`
+	Content     string         `db:"content"`
+	Metadata    map[string]any `db:"metadata"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+	// KeyID names the key wrapping DataKey, which sealed Title and
+	// Content, and is nil for notes stored in the clear. TitleIndex is
+	// the hash of the title by that key.
+	KeyID      *string `db:"key_id"`
+	DataKey    []byte  `db:"data_key"`
+	TitleIndex []byte  `db:"title_index"`
+}
+
+// fields returns the destinations of postgresColumns.
+func (r *noteRow) fields() []any {
+	return []any{&r.ID, &r.WorkspaceID, &r.CategoryID, &r.Title, &r.Content, &r.Metadata, &r.CreatedAt, &r.UpdatedAt, &r.KeyID, &r.DataKey, &r.TitleIndex}
 }
 
 func (r noteRow) toDomain() (Note, error) {
@@ -24,124 +45,302 @@ func (r noteRow) toDomain() (Note, error) {
 	if err != nil {
 		return Note{}, err
 	}
+	workspaceID, err := ParseID(r.WorkspaceID)
+	if err != nil {
+		return Note{}, err
+	}
 	catID, err := category.ParseID(r.CategoryID)
 	if err != nil {
 		return Note{}, err
 	}
 	return Note{
-		ID:         id,
-		CategoryID: catID,
-		Title:      r.Title,
-		Content:    r.Content,
-		CreatedAt:  r.CreatedAt,
-		UpdatedAt:  r.UpdatedAt,
+		ID:          id,
+		WorkspaceID: workspaceID,
+		CategoryID:  catID,
+		Title:       r.Title,
+		Content:     r.Content,
+		Metadata:    r.Metadata,
+		CreatedAt:   r.CreatedAt.UTC(),
+		UpdatedAt:   r.UpdatedAt.UTC(),
 	}, nil
 }
 
 func toRow(n Note) noteRow {
 	return noteRow{
-		ID:         n.ID.String(),
-		CategoryID: n.CategoryID.String(),
-		Title:      n.Title,
-		Content:    n.Content,
-		CreatedAt:  n.CreatedAt,
-		UpdatedAt:  n.UpdatedAt,
+		ID:          n.ID.String(),
+		WorkspaceID: n.WorkspaceID.String(),
+		CategoryID:  n.CategoryID.String(),
+		Title:       n.Title,
+		Content:     n.Content,
+		Metadata:    n.Metadata,
+		CreatedAt:   n.CreatedAt,
+		UpdatedAt:   n.UpdatedAt,
+	}
+}
+
+const noteColumns = `id, workspace_id, category_id, title, content, metadata, created_at, updated_at`
+
+// postgresColumns adds the encryption columns, see encryption.go.
+const postgresColumns = noteColumns + `, key_id, data_key, title_index`
+
+func scanRows(rows pgx.Rows) ([]noteRow, error) {
+	defer rows.Close()
+
+	var noteRows []noteRow
+	for rows.Next() {
+		var row noteRow
+		if err := rows.Scan(row.fields()...); err != nil {
+			return nil, err
+		}
+		noteRows = append(noteRows, row)
+	}
+	return noteRows, rows.Err()
+}
+
+func (r *PostgresRepository) scanNotes(rows pgx.Rows) ([]Note, error) {
+	noteRows, err := scanRows(rows)
+	if err != nil {
+		return nil, err
 	}
+	notes := make([]Note, 0, len(noteRows))
+	for _, row := range noteRows {
+		n, err := r.toDomain(row)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
 }
 
 // PostgresRepository implements Repository using PostgreSQL.
+// Every statement is scoped to the workspace of the context.
+//
+// With a keyring, titles and contents are encrypted at rest, see
+// encryption.go, as are the references of links. Metadata is not.
 type PostgresRepository struct {
-	db *pgx.Conn
+	db *db.DB
+	// keyring is nil if notes are stored in the clear.
+	keyring *encryption.Keyring
 }
 
-// NewPostgresRepository creates a new PostgresRepository.
-func NewPostgresRepository(db *pgx.Conn) *PostgresRepository {
-	return &PostgresRepository{db: db}
+// NewPostgresRepository creates a new PostgresRepository. keyring encrypts
+// the titles and contents of notes written from now on, or is nil to store
+// them in the clear; notes encrypted before cannot be read without it.
+func NewPostgresRepository(db *db.DB, keyring *encryption.Keyring) *PostgresRepository {
+	return &PostgresRepository{db: db, keyring: keyring}
 }
 
 func (r *PostgresRepository) Create(ctx context.Context, n Note) error {
-	row := toRow(n)
-	_, err := r.db.Exec(ctx,
-		`INSERT INTO notes (id, category_id, title, content, created_at, updated_at) 
-		 VALUES ($1, $2, $3, $4, $5, $6)`,
-		row.ID, row.CategoryID, row.Title, row.Content, row.CreatedAt, row.UpdatedAt,
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	n.WorkspaceID = workspaceID
+	row, err := r.toRow(n)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO notes (`+postgresColumns+`) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		row.ID, row.WorkspaceID, row.CategoryID, row.Title, row.Content, row.Metadata, row.CreatedAt, row.UpdatedAt, row.KeyID, row.DataKey, row.TitleIndex,
 	)
+	return postgresError(err)
+}
+
+// postgresError translates constraint violations of notes to the errors
+// of Repository.
+func postgresError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrAlreadyExists
+		case "23503":
+			if pgErr.ConstraintName == "notes_workspace_id_category_id_fkey" {
+				return category.ErrNotFound
+			}
+		}
+	}
 	return err
 }
 
 func (r *PostgresRepository) GetByID(ctx context.Context, id ID) (Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Note{}, err
+	}
 	var row noteRow
-	err := r.db.QueryRow(ctx,
-		`SELECT id, category_id, title, content, created_at, updated_at FROM notes WHERE id = $1`,
-		id.String(),
-	).Scan(&row.ID, &row.CategoryID, &row.Title, &row.Content, &row.CreatedAt, &row.UpdatedAt)
+	err = r.db.Q(ctx).QueryRow(ctx,
+		`SELECT `+postgresColumns+` FROM notes WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), id.String(),
+	).Scan(row.fields()...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return Note{}, ErrNotFound
 		}
 		return Note{}, err
 	}
-	return row.toDomain()
+	return r.toDomain(row)
 }
 
 func (r *PostgresRepository) GetAll(ctx context.Context) ([]Note, error) {
-	rows, err := r.db.Query(ctx,
-		`SELECT id, category_id, title, content, created_at, updated_at FROM notes ORDER BY created_at DESC`,
-	)
+	workspaceID, err := tenant.WorkspaceID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var notes []Note
-	for rows.Next() {
-		var row noteRow
-		if err := rows.Scan(&row.ID, &row.CategoryID, &row.Title, &row.Content, &row.CreatedAt, &row.UpdatedAt); err != nil {
-			return nil, err
-		}
-		n, err := row.toDomain()
-		if err != nil {
-			return nil, err
-		}
-		notes = append(notes, n)
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+postgresColumns+` FROM notes WHERE workspace_id = $1 ORDER BY created_at DESC`,
+		workspaceID.String(),
+	)
+	if err != nil {
+		return nil, err
 	}
-	return notes, rows.Err()
+	return r.scanNotes(rows)
 }
 
 func (r *PostgresRepository) GetByCategory(ctx context.Context, categoryID category.ID) ([]Note, error) {
-	rows, err := r.db.Query(ctx,
-		`SELECT id, category_id, title, content, created_at, updated_at FROM notes WHERE category_id = $1 ORDER BY created_at DESC`,
-		categoryID.String(),
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+postgresColumns+` FROM notes WHERE workspace_id = $1 AND category_id = $2 ORDER BY created_at DESC`,
+		workspaceID.String(), categoryID.String(),
 	)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return r.scanNotes(rows)
+}
 
-	var notes []Note
-	for rows.Next() {
-		var row noteRow
-		if err := rows.Scan(&row.ID, &row.CategoryID, &row.Title, &row.Content, &row.CreatedAt, &row.UpdatedAt); err != nil {
-			return nil, err
-		}
-		n, err := row.toDomain()
+// List selects notes in SQL, except that filter expressions comparing
+// titles or contents are evaluated on the selected notes when those are
+// encrypted, see matchNotes.
+func (r *PostgresRepository) List(ctx context.Context, f ListFilter) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + postgresColumns + ` FROM notes WHERE workspace_id = $1`
+	args := []any{workspaceID.String()}
+	if f.CategoryID != (category.ID{}) {
+		args = append(args, f.CategoryID.String())
+		query += fmt.Sprintf(` AND category_id = $%d`, len(args))
+	}
+	for _, c := range f.Metadata {
+		var clause string
+		clause, args = metadataClause(c, args)
+		query += ` AND ` + clause
+	}
+	if r.keyring != nil && f.Filter != nil &&
+		(f.Filter.refersTo(FieldTitle) || f.Filter.refersTo(FieldContent)) {
+		return r.matchNotes(ctx, query, args, f)
+	}
+	if f.Filter != nil {
+		var clause string
+		clause, args = filterClause(f.Filter.root, args)
+		query += ` AND ` + clause
+	}
+	return r.listPage(ctx, query, args, f.After, f.Limit)
+}
+
+// listPage runs the notes query with args, selecting the notes behind after
+// unless nil, newest first and up to limit unless zero.
+func (r *PostgresRepository) listPage(ctx context.Context, query string, args []any, after *Cursor, limit int) ([]Note, error) {
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID.String())
+		query += fmt.Sprintf(` AND (created_at, id) < ($%d, $%d)`, len(args)-1, len(args))
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	rows, err := r.db.Q(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanNotes(rows)
+}
+
+// matchBatchSize is the number of notes decrypted at a time to evaluate a
+// filter on encrypted titles or contents.
+const matchBatchSize = 100
+
+// matchNotes evaluates the filter of f on the notes selected by the notes
+// query with args, decrypting them a batch at a time until the page is
+// full. Pages of rare matches, and lists without a limit, decrypt every
+// selected note of the workspace.
+func (r *PostgresRepository) matchNotes(ctx context.Context, query string, args []any, f ListFilter) ([]Note, error) {
+	var matched []Note
+	after := f.After
+	for {
+		notes, err := r.listPage(ctx, query, args, after, matchBatchSize)
 		if err != nil {
 			return nil, err
 		}
-		notes = append(notes, n)
+		for _, n := range notes {
+			if !f.Filter.root.match(n) {
+				continue
+			}
+			matched = append(matched, n)
+			if f.Limit > 0 && len(matched) == f.Limit {
+				return matched, nil
+			}
+		}
+		if len(notes) < matchBatchSize {
+			return matched, nil
+		}
+		cursor := CursorOf(notes[len(notes)-1])
+		after = &cursor
 	}
-	return notes, rows.Err()
 }
 
-func (r *PostgresRepository) Update(ctx context.Context, n Note) error {
-	row := toRow(n)
-	result, err := r.db.Exec(ctx,
-		`UPDATE notes SET category_id = $2, title = $3, content = $4, updated_at = $5 WHERE id = $1`,
-		row.ID, row.CategoryID, row.Title, row.Content, row.UpdatedAt,
+func (r *PostgresRepository) ListByCategories(ctx context.Context, categoryIDs []category.ID, limit int) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(categoryIDs))
+	for i, id := range categoryIDs {
+		ids[i] = id.String()
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+postgresColumns+` FROM (
+		     SELECT `+postgresColumns+`,
+		            row_number() OVER (PARTITION BY category_id ORDER BY created_at DESC, id DESC) AS rank
+		     FROM notes WHERE workspace_id = $1 AND category_id = ANY($2::uuid[])
+		 ) AS ranked WHERE rank <= $3 ORDER BY created_at DESC, id DESC`,
+		workspaceID.String(), ids, limit,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanNotes(rows)
+}
+
+func (r *PostgresRepository) Update(ctx context.Context, n Note) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	n.WorkspaceID = workspaceID
+	row, err := r.toRow(n)
 	if err != nil {
 		return err
 	}
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`UPDATE notes SET category_id = $3, title = $4, content = $5, metadata = $6, updated_at = $7, key_id = $8, data_key = $9, title_index = $10
+		 WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), row.ID, row.CategoryID, row.Title, row.Content, row.Metadata, row.UpdatedAt, row.KeyID, row.DataKey, row.TitleIndex,
+	)
+	if err != nil {
+		return postgresError(err)
+	}
 	if result.RowsAffected() == 0 {
 		return ErrNotFound
 	}
@@ -149,7 +348,14 @@ func (r *PostgresRepository) Update(ctx context.Context, n Note) error {
 }
 
 func (r *PostgresRepository) Delete(ctx context.Context, id ID) error {
-	result, err := r.db.Exec(ctx, `DELETE FROM notes WHERE id = $1`, id.String())
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`DELETE FROM notes WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), id.String(),
+	)
 	if err != nil {
 		return err
 	}
@@ -158,3 +364,336 @@ func (r *PostgresRepository) Delete(ctx context.Context, id ID) error {
 	}
 	return nil
 }
+
+// GetByTitles compares titles in the clear and, for encrypted notes, by
+// their hash.
+func (r *PostgresRepository) GetByTitles(ctx context.Context, titles []string) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+postgresColumns+` FROM notes WHERE workspace_id = $1
+		   AND ((key_id IS NULL AND title = ANY($2)) OR title_index = ANY($3))
+		 ORDER BY created_at, id`,
+		workspaceID.String(), titles, r.titleIndexes(workspaceID, titles),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanNotes(rows)
+}
+
+func (r *PostgresRepository) GetByIDs(ctx context.Context, ids []ID) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	strIDs := make([]string, len(ids))
+	for i, id := range ids {
+		strIDs[i] = id.String()
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+postgresColumns+` FROM notes WHERE workspace_id = $1 AND id = ANY($2)`,
+		workspaceID.String(), strIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanNotes(rows)
+}
+
+func (r *PostgresRepository) SetLinks(ctx context.Context, source ID, links []Link) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	q := r.db.Q(ctx)
+	if _, err := q.Exec(ctx,
+		`DELETE FROM note_links WHERE workspace_id = $1 AND source_id = $2`,
+		workspaceID.String(), source.String(),
+	); err != nil {
+		return err
+	}
+	for i, l := range links {
+		row := linkRow{WorkspaceID: workspaceID.String(), SourceID: source.String(), Position: i, Kind: string(l.Kind), Ref: l.Ref}
+		if !l.Dangling() {
+			s := l.TargetID.String()
+			row.TargetID = &s
+		}
+		if r.keyring != nil {
+			if err := r.sealLink(&row); err != nil {
+				return err
+			}
+		}
+		if _, err := q.Exec(ctx,
+			`INSERT INTO note_links (`+linkColumns+`)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			row.WorkspaceID, row.SourceID, row.Position, row.Kind, row.Ref, row.TargetID, row.KeyID, row.DataKey, row.RefIndex,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgresRepository) GetLinks(ctx context.Context, source ID) ([]Link, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+linkColumns+` FROM note_links WHERE workspace_id = $1 AND source_id = $2 ORDER BY position`,
+		workspaceID.String(), source.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanLinks(rows)
+}
+
+func (r *PostgresRepository) GetLinkSources(ctx context.Context, target ID) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+postgresColumns+` FROM notes WHERE workspace_id = $1 AND id IN (
+		     SELECT source_id FROM note_links WHERE workspace_id = $1 AND target_id = $2
+		 ) ORDER BY created_at DESC, id DESC`,
+		workspaceID.String(), target.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanNotes(rows)
+}
+
+// GetDanglingLinks orders encrypted references after decrypting them.
+func (r *PostgresRepository) GetDanglingLinks(ctx context.Context) ([]Link, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+linkColumns+` FROM note_links WHERE workspace_id = $1 AND target_id IS NULL ORDER BY ref, source_id`,
+		workspaceID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	links, err := r.scanLinks(rows)
+	if err != nil || r.keyring == nil {
+		return links, err
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Ref != links[j].Ref {
+			return links[i].Ref < links[j].Ref
+		}
+		return links[i].SourceID.String() < links[j].SourceID.String()
+	})
+	return links, nil
+}
+
+// ResolveLinks compares references in the clear and, for encrypted links,
+// by their hash.
+func (r *PostgresRepository) ResolveLinks(ctx context.Context, title string, target ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).Exec(ctx,
+		`UPDATE note_links SET target_id = $3 WHERE workspace_id = $1 AND kind = $4 AND target_id IS NULL
+		   AND ((key_id IS NULL AND ref = $2) OR ref_index = ANY($5))`,
+		workspaceID.String(), title, target.String(), string(LinkByTitle), r.titleIndexes(workspaceID, []string{title}),
+	)
+	return err
+}
+
+// metadataClause returns the SQL condition for c, appending its arguments
+// to args. Equality uses containment, which the GIN index on metadata
+// serves; ordering guards the casts with CASE, as AND does not guarantee
+// the order of evaluation.
+func metadataClause(c MetadataCondition, args []any) (string, []any) {
+	switch c.Op {
+	case OpEq, OpNe:
+		var alternatives []string
+		for _, v := range c.Candidates() {
+			doc, _ := json.Marshal(map[string]any{c.Key: v})
+			args = append(args, string(doc))
+			alternatives = append(alternatives, fmt.Sprintf(`metadata @> $%d::jsonb`, len(args)))
+		}
+		clause := `(` + strings.Join(alternatives, ` OR `) + `)`
+		if c.Op == OpNe {
+			clause = `NOT ` + clause
+		}
+		return clause, args
+	case OpGt, OpGe, OpLt, OpLe:
+		args = append(args, c.Key)
+		key := len(args)
+		if n, ok := c.Number(); ok {
+			args = append(args, n)
+			return fmt.Sprintf(`CASE WHEN jsonb_typeof(metadata->$%d::text) = 'number' THEN (metadata->>$%d::text)::numeric %s $%d::numeric END`,
+				key, key, c.Op, len(args)), args
+		}
+		args = append(args, c.Value)
+		return fmt.Sprintf(`CASE WHEN jsonb_typeof(metadata->$%d::text) = 'string' THEN (metadata->>$%d::text) COLLATE "C" %s $%d::text END`,
+			key, key, c.Op, len(args)), args
+	}
+	return `FALSE`, args
+}
+
+// filterClause compiles a filter expression to an SQL condition, appending
+// its arguments to args.
+func filterClause(e filterExpr, args []any) (string, []any) {
+	switch e := e.(type) {
+	case filterAnd:
+		return joinClauses(e, ` AND `, args)
+	case filterOr:
+		return joinClauses(e, ` OR `, args)
+	case filterNot:
+		clause, args := filterClause(e.expr, args)
+		return `NOT ` + clause, args
+	case *filterTerm:
+		return termClause(e, args)
+	}
+	return `FALSE`, args
+}
+
+func joinClauses(exprs []filterExpr, sep string, args []any) (string, []any) {
+	clauses := make([]string, len(exprs))
+	for i, x := range exprs {
+		clauses[i], args = filterClause(x, args)
+	}
+	return `(` + strings.Join(clauses, sep) + `)`, args
+}
+
+// termClause returns the SQL condition for t. Dates compare with the
+// bounds of their day as filterTerm.matchTime does.
+func termClause(t *filterTerm, args []any) (string, []any) {
+	var clause string
+	switch t.Field {
+	case FieldTitle, FieldContent:
+		args = append(args, t.Value)
+		column := string(t.Field)
+		switch t.Op {
+		case FilterEq, FilterNe:
+			clause = fmt.Sprintf(`lower(%s) = lower($%d)`, column, len(args))
+		case FilterContains, FilterNotContains:
+			clause = fmt.Sprintf(`strpos(lower(%s), lower($%d)) > 0`, column, len(args))
+		}
+		if t.Op == FilterNe || t.Op == FilterNotContains {
+			clause = `NOT ` + clause
+		}
+		return `(` + clause + `)`, args
+	case FieldCategory:
+		ids := make([]string, len(t.categories))
+		for i, id := range t.categories {
+			ids[i] = id.String()
+		}
+		args = append(args, ids)
+		clause = fmt.Sprintf(`category_id = ANY($%d::uuid[])`, len(args))
+	case FieldTag:
+		doc, _ := json.Marshal(map[string]any{TagsKey: []string{t.Value}})
+		args = append(args, string(doc))
+		clause = fmt.Sprintf(`metadata @> $%d::jsonb`, len(args))
+	case FieldCreated, FieldUpdated:
+		column := string(t.Field) + `_at`
+		bound := func(op string, ts time.Time) string {
+			args = append(args, ts)
+			return fmt.Sprintf(`%s %s $%d`, column, op, len(args))
+		}
+		switch t.Op {
+		case FilterEq, FilterNe:
+			clause = bound(`>=`, t.from) + ` AND ` + bound(`<`, t.to)
+		case FilterGt:
+			clause = bound(`>=`, t.to)
+		case FilterGe:
+			clause = bound(`>=`, t.from)
+		case FilterLt:
+			clause = bound(`<`, t.from)
+		case FilterLe:
+			clause = bound(`<`, t.to)
+		}
+		if t.Op == FilterNe {
+			return `NOT (` + clause + `)`, args
+		}
+		return `(` + clause + `)`, args
+	}
+	if t.Op == FilterNe {
+		clause = `NOT ` + clause
+	}
+	return `(` + clause + `)`, args
+}
+
+// linkRow is the database representation of a link.
+type linkRow struct {
+	WorkspaceID string
+	SourceID    string
+	Position    int
+	Kind        string
+	Ref         string
+	TargetID    *string
+	// KeyID names the key wrapping DataKey, which sealed Ref, and is nil
+	// for links stored in the clear. RefIndex is the hash of Ref by that
+	// key.
+	KeyID    *string
+	DataKey  []byte
+	RefIndex []byte
+}
+
+const linkColumns = `workspace_id, source_id, position, kind, ref, target_id, key_id, data_key, ref_index`
+
+// fields returns the destinations of linkColumns.
+func (r *linkRow) fields() []any {
+	return []any{&r.WorkspaceID, &r.SourceID, &r.Position, &r.Kind, &r.Ref, &r.TargetID, &r.KeyID, &r.DataKey, &r.RefIndex}
+}
+
+func (r linkRow) toDomain() (Link, error) {
+	l := Link{Kind: LinkKind(r.Kind), Ref: r.Ref}
+	var err error
+	if l.SourceID, err = ParseID(r.SourceID); err != nil {
+		return Link{}, err
+	}
+	if r.TargetID != nil {
+		if l.TargetID, err = ParseID(*r.TargetID); err != nil {
+			return Link{}, err
+		}
+	}
+	return l, nil
+}
+
+func scanLinkRows(rows pgx.Rows) ([]linkRow, error) {
+	defer rows.Close()
+
+	var linkRows []linkRow
+	for rows.Next() {
+		var row linkRow
+		if err := rows.Scan(row.fields()...); err != nil {
+			return nil, err
+		}
+		linkRows = append(linkRows, row)
+	}
+	return linkRows, rows.Err()
+}
+
+// scanLinks returns the links of rows, decrypting those that are
+// encrypted.
+func (r *PostgresRepository) scanLinks(rows pgx.Rows) ([]Link, error) {
+	linkRows, err := scanLinkRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	var links []Link
+	for _, row := range linkRows {
+		if err := r.openLink(&row); err != nil {
+			return nil, err
+		}
+		l, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, nil
+}
@@ -0,0 +1,101 @@
+package note
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// ReencryptJobKind is the kind of the jobs encrypting notes under the
+// current key.
+const ReencryptJobKind = "notes.reencrypt"
+
+// reencryptBatchSize is the number of notes encrypted per transaction.
+const reencryptBatchSize = 100
+
+// Reencrypter is implemented by repositories encrypting notes at rest,
+// such as *PostgresRepository.
+type Reencrypter interface {
+	// Encrypted reports whether notes are encrypted.
+	Encrypted() bool
+	// Workspaces returns the IDs of every workspace.
+	Workspaces(ctx context.Context) ([]uuid.UUID, error)
+	// Reencrypt moves up to limit notes and links of the workspace in ctx
+	// that are not encrypted under the current key to it, returning fewer
+	// than limit once none are left.
+	Reencrypt(ctx context.Context, limit int) (int, error)
+}
+
+// RegisterJobs registers the re-encryption job with queue if the
+// repository encrypts notes, and enqueues a run. Notes are encrypted under
+// the current key when written, so a run at startup moves the remaining
+// ones after the key was rotated or encryption enabled.
+func (s *Service) RegisterJobs(ctx context.Context, queue *jobs.Queue) error {
+	r, ok := s.repo.(Reencrypter)
+	if !ok || !r.Encrypted() {
+		return nil
+	}
+	queue.Register(ReencryptJobKind, jobs.Handle(func(ctx context.Context, _ struct{}) error {
+		if _, err := tenant.WorkspaceID(ctx); err != nil {
+			return s.fanOutReencrypt(ctx, r, queue)
+		}
+		return s.reencrypt(ctx, r)
+	}))
+
+	_, err := queue.Enqueue(ctx, ReencryptJobKind, struct{}{}, jobs.Options{Key: ReencryptJobKind})
+	if errors.Is(err, jobs.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// fanOutReencrypt enqueues a re-encryption job in every workspace, as
+// row-level security confines statements on notes to one.
+func (s *Service) fanOutReencrypt(ctx context.Context, r Reencrypter, queue *jobs.Queue) error {
+	ids, err := r.Workspaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		ctx := tenant.WithWorkspace(ctx, id)
+		_, err := queue.Enqueue(ctx, ReencryptJobKind, struct{}{}, jobs.Options{Key: ReencryptJobKind + ":" + id.String()})
+		if err != nil && !errors.Is(err, jobs.ErrAlreadyExists) {
+			return err
+		}
+	}
+	return nil
+}
+
+// reencrypt encrypts the notes of the workspace in ctx under the current
+// key, a batch per transaction. It fails on notes encrypted with a key
+// that is not configured.
+func (s *Service) reencrypt(ctx context.Context, r Reencrypter) error {
+	log := logger.FromContext(ctx, s.logger)
+	total := 0
+	for {
+		var n int
+		err := s.tx.InTx(ctx, func(ctx context.Context) error {
+			var err error
+			n, err = r.Reencrypt(ctx, reencryptBatchSize)
+			return err
+		})
+		if err != nil {
+			log.Error("failed to re-encrypt notes", zap.Int("reencrypted", total), zap.Error(err))
+			return err
+		}
+		total += n
+		if n < reencryptBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		workspaceID, _ := tenant.WorkspaceID(ctx)
+		log.Info("notes re-encrypted", zap.String("workspace_id", workspaceID.String()), zap.Int("count", total))
+	}
+	return nil
+}
@@ -8,15 +8,54 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("note not found")
+	ErrNotFound      = errors.New("note not found")
+	ErrAlreadyExists = errors.New("note already exists")
 )
 
+// ListFilter selects a page of notes.
+type ListFilter struct {
+	// CategoryID limits the page to one category unless zero.
+	CategoryID category.ID
+	// After starts the page behind the given note unless nil.
+	After *Cursor
+	// Metadata holds conditions all notes of the page satisfy.
+	Metadata []MetadataCondition
+	// Filter is an expression all notes of the page satisfy unless nil.
+	// It must be resolved, see Service.List.
+	Filter *Filter
+	// Limit bounds the page size unless zero.
+	Limit int
+}
+
 // Repository defines the interface for note persistence.
+// Create and Update return category.ErrNotFound if the category of the note
+// does not exist, where the storage enforces it.
 type Repository interface {
 	Create(ctx context.Context, n Note) error
 	GetByID(ctx context.Context, id ID) (Note, error)
 	GetAll(ctx context.Context) ([]Note, error)
 	GetByCategory(ctx context.Context, categoryID category.ID) ([]Note, error)
+	// List returns a page of notes, newest first.
+	List(ctx context.Context, f ListFilter) ([]Note, error)
+	// ListByCategories returns up to limit notes of each of categoryIDs,
+	// newest first.
+	ListByCategories(ctx context.Context, categoryIDs []category.ID, limit int) ([]Note, error)
+	// GetByTitles returns the notes with any of titles, oldest first.
+	GetByTitles(ctx context.Context, titles []string) ([]Note, error)
+	// GetByIDs returns the notes with any of ids, in no particular order.
+	GetByIDs(ctx context.Context, ids []ID) ([]Note, error)
 	Update(ctx context.Context, n Note) error
+	// Delete removes a note and its links. Links to it become dangling.
 	Delete(ctx context.Context, id ID) error
+
+	// SetLinks replaces the links of the source note.
+	SetLinks(ctx context.Context, source ID, links []Link) error
+	// GetLinks returns the links of the source note in order.
+	GetLinks(ctx context.Context, source ID) ([]Link, error)
+	// GetLinkSources returns the notes linking to target, newest first.
+	GetLinkSources(ctx context.Context, target ID) ([]Note, error)
+	// GetDanglingLinks returns the dangling links of the workspace.
+	GetDanglingLinks(ctx context.Context) ([]Link, error)
+	// ResolveLinks points the dangling title links to title at target.
+	ResolveLinks(ctx context.Context, title string, target ID) error
 }
@@ -0,0 +1,418 @@
+package note_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db/dbtest"
+	"github.com/piotmni/go-mini-templates/minimal/internal/encryption"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// storage is a note repository with the category repository its notes
+// refer to.
+type storage struct {
+	notes      note.Repository
+	categories category.Repository
+	// foreignKeys is set if notes must refer to existing categories.
+	foreignKeys bool
+}
+
+func TestMemoryRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) storage {
+		return storage{notes: note.NewMemoryRepository(), categories: category.NewMemoryRepository()}
+	})
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) storage {
+		database := dbtest.NewSQLite(t)
+		return storage{
+			notes:       note.NewSQLiteRepository(database),
+			categories:  category.NewSQLiteRepository(database),
+			foreignKeys: true,
+		}
+	})
+}
+
+func TestPostgresRepository(t *testing.T) {
+	keyring, err := encryption.ParseKeyring("test:" + base64.StdEncoding.EncodeToString(make([]byte, encryption.KeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name             string
+		rowLevelSecurity bool
+		keyring          *encryption.Keyring
+	}{
+		{"owner", false, nil},
+		{"row-level security", true, nil},
+		{"encrypted", true, keyring},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepository(t, func(t *testing.T) storage {
+				database := dbtest.NewPostgres(t, tt.rowLevelSecurity)
+				dbtest.AddWorkspaces(t, database, workspace, otherWorkspace)
+				return storage{
+					notes:       note.NewPostgresRepository(database, tt.keyring),
+					categories:  category.NewPostgresRepository(database),
+					foreignKeys: true,
+				}
+			})
+		})
+	}
+}
+
+// TestPostgresRowLevelSecurity checks that the policies confine statements
+// to the workspace of their context even when they do not filter by it.
+func TestPostgresRowLevelSecurity(t *testing.T) {
+	database := dbtest.NewPostgres(t, true)
+	dbtest.AddWorkspaces(t, database, workspace, otherWorkspace)
+	ctx := tenant.WithWorkspace(t.Context(), workspace)
+	other := tenant.WithWorkspace(t.Context(), otherWorkspace)
+
+	cat := category.Category{ID: category.NewID(), Name: "Notes", CreatedAt: base, UpdatedAt: base}
+	if err := category.NewPostgresRepository(database).Create(ctx, cat); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	n := note.Note{ID: note.NewID(), CategoryID: cat.ID, Title: "Plan", Metadata: map[string]any{}, CreatedAt: base, UpdatedAt: base}
+	if err := note.NewPostgresRepository(database, nil).Create(ctx, n); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+
+	count := func(ctx context.Context) int {
+		t.Helper()
+		var count int
+		if err := database.Q(ctx).QueryRow(ctx, `SELECT count(*) FROM notes`).Scan(&count); err != nil {
+			t.Fatalf("count notes: %v", err)
+		}
+		return count
+	}
+	if got := count(ctx); got != 1 {
+		t.Errorf("notes in workspace = %d, want 1", got)
+	}
+	if got := count(other); got != 0 {
+		t.Errorf("notes in other workspace = %d, want 0", got)
+	}
+	if got := count(t.Context()); got != 0 {
+		t.Errorf("notes outside of a workspace = %d, want 0", got)
+	}
+
+	if _, err := database.Q(ctx).Exec(ctx,
+		`INSERT INTO categories (id, workspace_id, name) VALUES ($1, $2, $3)`,
+		category.NewID().String(), otherWorkspace.String(), "Foreign",
+	); err == nil {
+		t.Error("inserting a category into another workspace succeeded")
+	}
+	if tag, err := database.Q(other).Exec(other, `DELETE FROM notes`); err != nil || tag.RowsAffected() != 0 {
+		t.Errorf("delete from other workspace = %v, %v, want no rows", tag, err)
+	}
+}
+
+// base is the creation time of the first note of a test, in microseconds
+// as Postgres stores times.
+var base = time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC)
+
+// workspace and otherWorkspace are the workspaces of the contract, which
+// Postgres repositories must create.
+var workspace, otherWorkspace = uuid.New(), uuid.New()
+
+// testRepository runs the contract every Repository implementation
+// fulfils against repositories created by newStorage.
+func testRepository(t *testing.T, newStorage func(t *testing.T) storage) {
+	ctx := tenant.WithWorkspace(context.Background(), workspace)
+	other := tenant.WithWorkspace(context.Background(), otherWorkspace)
+
+	// setup creates a category in the workspace of ctx and of other
+	setup := func(t *testing.T) (storage, category.ID, category.ID) {
+		t.Helper()
+		s := newStorage(t)
+		var ids []category.ID
+		for _, ctx := range []context.Context{ctx, other} {
+			c := category.Category{ID: category.NewID(), Name: "Notes", CreatedAt: base, UpdatedAt: base}
+			if err := s.categories.Create(ctx, c); err != nil {
+				t.Fatalf("create category: %v", err)
+			}
+			ids = append(ids, c.ID)
+		}
+		return s, ids[0], ids[1]
+	}
+
+	create := func(t *testing.T, repo note.Repository, ctx context.Context, n note.Note) note.Note {
+		t.Helper()
+		if err := repo.Create(ctx, n); err != nil {
+			t.Fatalf("create %s: %v", n.Title, err)
+		}
+		n.WorkspaceID, _ = tenant.WorkspaceID(ctx)
+		return n
+	}
+
+	// newNote has empty metadata like the notes of the service
+	newNote := func(categoryID category.ID, title string, at time.Time) note.Note {
+		return note.Note{
+			ID:         note.NewID(),
+			CategoryID: categoryID,
+			Title:      title,
+			Content:    "Content of " + title,
+			Metadata:   map[string]any{},
+			CreatedAt:  at,
+			UpdatedAt:  at,
+		}
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		s, cat, otherCat := setup(t)
+		n := newNote(cat, "Plan", base)
+		n.Metadata = map[string]any{"priority": float64(2), "tags": []any{"work"}}
+		want := create(t, s.notes, ctx, n)
+
+		got, err := s.notes.GetByID(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GetByID = %+v, want %+v", got, want)
+		}
+		if _, err := s.notes.GetByID(other, want.ID); !errors.Is(err, note.ErrNotFound) {
+			t.Errorf("GetByID in other workspace = %v, want ErrNotFound", err)
+		}
+		if err := s.notes.Create(ctx, n); !errors.Is(err, note.ErrAlreadyExists) {
+			t.Errorf("Create duplicate = %v, want ErrAlreadyExists", err)
+		}
+
+		if !s.foreignKeys {
+			return
+		}
+		if err := s.notes.Create(ctx, newNote(category.NewID(), "Orphan", base)); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("Create in unknown category = %v, want category.ErrNotFound", err)
+		}
+		if err := s.notes.Create(ctx, newNote(otherCat, "Foreign", base)); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("Create in category of other workspace = %v, want category.ErrNotFound", err)
+		}
+		want.CategoryID = category.NewID()
+		if err := s.notes.Update(ctx, want); !errors.Is(err, category.ErrNotFound) {
+			t.Errorf("Update to unknown category = %v, want category.ErrNotFound", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		s, cat, otherCat := setup(t)
+		second := category.Category{ID: category.NewID(), Name: "Second", CreatedAt: base, UpdatedAt: base}
+		if err := s.categories.Create(ctx, second); err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+
+		a := newNote(cat, "Alpha", base)
+		a.Metadata = map[string]any{"tags": []any{"work"}, "priority": float64(1)}
+		a = create(t, s.notes, ctx, a)
+		b := create(t, s.notes, ctx, newNote(second.ID, "Beta", base.Add(time.Second)))
+		c := newNote(cat, "Gamma plan", base.Add(time.Second))
+		c.Metadata = map[string]any{"tags": []any{"home"}, "priority": float64(3)}
+		c = create(t, s.notes, ctx, c)
+		d := create(t, s.notes, ctx, newNote(cat, "Delta plan", base.Add(time.Minute)))
+		create(t, s.notes, other, newNote(otherCat, "Elsewhere", base))
+
+		// b and c share their creation time, so IDs order them
+		newest := []note.Note{d, b, c, a}
+		if note.CursorOf(b).Before(c) {
+			newest[1], newest[2] = c, b
+		}
+
+		list := func(t *testing.T, f note.ListFilter) []note.Note {
+			t.Helper()
+			notes, err := s.notes.List(ctx, f)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			return notes
+		}
+		assertNotes := func(t *testing.T, name string, got []note.Note, want ...note.Note) {
+			t.Helper()
+			if len(got) == 0 && len(want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s = %v, want %v", name, titles(got), titles(want))
+			}
+		}
+
+		all, err := s.notes.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		assertNotes(t, "GetAll", all, newest...)
+		byCategory, err := s.notes.GetByCategory(ctx, second.ID)
+		if err != nil {
+			t.Fatalf("GetByCategory: %v", err)
+		}
+		assertNotes(t, "GetByCategory", byCategory, b)
+
+		assertNotes(t, "List", list(t, note.ListFilter{}), newest...)
+		assertNotes(t, "List by category", list(t, note.ListFilter{CategoryID: second.ID}), b)
+		page := list(t, note.ListFilter{Limit: 2})
+		assertNotes(t, "first page", page, newest[:2]...)
+		cursor := note.CursorOf(page[1])
+		assertNotes(t, "second page", list(t, note.ListFilter{After: &cursor, Limit: 2}), newest[2:]...)
+
+		priority, err := note.ParseMetadataCondition("priority>2", "")
+		if err != nil {
+			t.Fatalf("parse condition: %v", err)
+		}
+		assertNotes(t, "List by metadata", list(t, note.ListFilter{Metadata: []note.MetadataCondition{priority}}), c)
+		filter, err := note.ParseFilter(`title ~ plan AND NOT tag = home`)
+		if err != nil {
+			t.Fatalf("parse filter: %v", err)
+		}
+		assertNotes(t, "List by filter", list(t, note.ListFilter{Filter: filter}), d)
+		plans, err := note.ParseFilter(`title ~ plan`)
+		if err != nil {
+			t.Fatalf("parse filter: %v", err)
+		}
+		assertNotes(t, "List by filter with limit", list(t, note.ListFilter{Filter: plans, Limit: 1}), d)
+
+		// a is the third note of cat, and otherCat is of another workspace
+		firstPages, err := s.notes.ListByCategories(ctx, []category.ID{cat, second.ID, otherCat}, 2)
+		if err != nil {
+			t.Fatalf("ListByCategories: %v", err)
+		}
+		assertNotes(t, "ListByCategories", firstPages, newest[:3]...)
+
+		byTitles, err := s.notes.GetByTitles(ctx, []string{"Delta plan", "Alpha", "Elsewhere"})
+		if err != nil {
+			t.Fatalf("GetByTitles: %v", err)
+		}
+		assertNotes(t, "GetByTitles", byTitles, a, d)
+		byIDs, err := s.notes.GetByIDs(ctx, []note.ID{a.ID, note.NewID()})
+		if err != nil {
+			t.Fatalf("GetByIDs: %v", err)
+		}
+		assertNotes(t, "GetByIDs", byIDs, a)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		s, cat, _ := setup(t)
+		n := create(t, s.notes, ctx, newNote(cat, "Plan", base))
+
+		n.Title = "Roadmap"
+		n.Content = "Updated"
+		n.Metadata = map[string]any{"status": "open"}
+		n.UpdatedAt = base.Add(time.Hour)
+		if err := s.notes.Update(ctx, n); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := s.notes.GetByID(ctx, n.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !reflect.DeepEqual(got, n) {
+			t.Errorf("GetByID after update = %+v, want %+v", got, n)
+		}
+		if err := s.notes.Update(other, n); !errors.Is(err, note.ErrNotFound) {
+			t.Errorf("Update in other workspace = %v, want ErrNotFound", err)
+		}
+
+		if err := s.notes.Delete(other, n.ID); !errors.Is(err, note.ErrNotFound) {
+			t.Errorf("Delete in other workspace = %v, want ErrNotFound", err)
+		}
+		if err := s.notes.Delete(ctx, n.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := s.notes.GetByID(ctx, n.ID); !errors.Is(err, note.ErrNotFound) {
+			t.Errorf("GetByID after delete = %v, want ErrNotFound", err)
+		}
+		if err := s.notes.Delete(ctx, n.ID); !errors.Is(err, note.ErrNotFound) {
+			t.Errorf("Delete twice = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("links", func(t *testing.T) {
+		s, cat, _ := setup(t)
+		source := create(t, s.notes, ctx, newNote(cat, "Source", base))
+		older := create(t, s.notes, ctx, newNote(cat, "Older", base.Add(time.Second)))
+		target := create(t, s.notes, ctx, newNote(cat, "Target", base.Add(time.Minute)))
+
+		links := []note.Link{
+			{Kind: note.LinkByTitle, Ref: "Target", TargetID: target.ID},
+			{Kind: note.LinkByTitle, Ref: "Missing"},
+			{Kind: note.LinkByID, Ref: target.ID.String(), TargetID: target.ID},
+		}
+		if err := s.notes.SetLinks(ctx, source.ID, links); err != nil {
+			t.Fatalf("SetLinks: %v", err)
+		}
+		if err := s.notes.SetLinks(ctx, older.ID, []note.Link{{Kind: note.LinkByTitle, Ref: "Target", TargetID: target.ID}}); err != nil {
+			t.Fatalf("SetLinks: %v", err)
+		}
+		for i := range links {
+			links[i].SourceID = source.ID
+		}
+
+		got, err := s.notes.GetLinks(ctx, source.ID)
+		if err != nil {
+			t.Fatalf("GetLinks: %v", err)
+		}
+		if !reflect.DeepEqual(got, links) {
+			t.Errorf("GetLinks = %+v, want %+v", got, links)
+		}
+		sources, err := s.notes.GetLinkSources(ctx, target.ID)
+		if err != nil {
+			t.Fatalf("GetLinkSources: %v", err)
+		}
+		if want := []note.Note{older, source}; !reflect.DeepEqual(sources, want) {
+			t.Errorf("GetLinkSources = %v, want %v", titles(sources), titles(want))
+		}
+		dangling, err := s.notes.GetDanglingLinks(ctx)
+		if err != nil {
+			t.Fatalf("GetDanglingLinks: %v", err)
+		}
+		if want := []note.Link{links[1]}; !reflect.DeepEqual(dangling, want) {
+			t.Errorf("GetDanglingLinks = %+v, want %+v", dangling, want)
+		}
+
+		missing := create(t, s.notes, ctx, newNote(cat, "Missing", base.Add(time.Hour)))
+		if err := s.notes.ResolveLinks(other, "Missing", missing.ID); err != nil {
+			t.Fatalf("ResolveLinks in other workspace: %v", err)
+		}
+		if dangling, _ := s.notes.GetDanglingLinks(ctx); len(dangling) != 1 {
+			t.Errorf("ResolveLinks in other workspace resolved %+v", dangling)
+		}
+		if err := s.notes.ResolveLinks(ctx, "Missing", missing.ID); err != nil {
+			t.Fatalf("ResolveLinks: %v", err)
+		}
+		if dangling, _ := s.notes.GetDanglingLinks(ctx); len(dangling) != 0 {
+			t.Errorf("GetDanglingLinks after resolving = %+v", dangling)
+		}
+
+		// Deleting the target leaves the links to it dangling, deleting
+		// the source removes its links
+		if err := s.notes.Delete(ctx, target.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if dangling, _ := s.notes.GetDanglingLinks(ctx); len(dangling) != 3 {
+			t.Errorf("GetDanglingLinks after deleting target = %+v, want 3", dangling)
+		}
+		if err := s.notes.Delete(ctx, source.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if got, _ := s.notes.GetLinks(ctx, source.ID); len(got) != 0 {
+			t.Errorf("GetLinks of deleted source = %+v", got)
+		}
+		if dangling, _ := s.notes.GetDanglingLinks(ctx); len(dangling) != 1 {
+			t.Errorf("GetDanglingLinks after deleting source = %+v, want 1", dangling)
+		}
+	})
+}
+
+func titles(notes []note.Note) []string {
+	titles := make([]string, len(notes))
+	for i, n := range notes {
+		titles[i] = n.Title
+	}
+	return titles
+}
@@ -2,24 +2,80 @@ package note
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
 	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
 	"go.uber.org/zap"
 )
 
+// Categories looks up the categories of notes. *category.Service
+// implements it.
+type Categories interface {
+	GetByID(ctx context.Context, id category.ID) (category.Category, error)
+	GetAll(ctx context.Context) ([]category.Category, error)
+	OnDelete(h category.DeleteHook)
+}
+
 // Service provides note business logic.
 type Service struct {
-	repo   Repository
-	logger *zap.Logger
+	repo       Repository
+	categories Categories
+	tx         db.Transactor
+	audit      *audit.Service
+	logger     *zap.Logger
+}
+
+// NewService creates a new note service. Metadata is validated against the
+// schemas of categories, and deleting a category deletes its notes.
+// Mutations are recorded with auditor in the same transaction.
+func NewService(repo Repository, categories Categories, tx db.Transactor, auditor *audit.Service, logger *zap.Logger) *Service {
+	s := &Service{
+		repo:       repo,
+		categories: categories,
+		tx:         tx,
+		audit:      auditor,
+		logger:     logger.Named("note.service"),
+	}
+	categories.OnDelete(s.deleteCategory)
+	return s
+}
+
+// EntityType identifies notes in audit events.
+const EntityType = "note"
+
+// snapshot returns the audited fields of n. Titles and contents are left
+// out when the repository encrypts them, so audit events do not store them
+// in the clear.
+func (s *Service) snapshot(n Note) audit.Snapshot {
+	snapshot := audit.Snapshot{
+		"category_id": n.CategoryID,
+		"metadata":    n.Metadata,
+	}
+	if r, ok := s.repo.(Reencrypter); !ok || !r.Encrypted() {
+		snapshot["title"] = n.Title
+		snapshot["content"] = n.Content
+	}
+	return snapshot
 }
 
-// NewService creates a new note service.
-func NewService(repo Repository, logger *zap.Logger) *Service {
-	return &Service{
-		repo:   repo,
-		logger: logger.Named("note.service"),
+// checkMetadata validates the metadata of n against the schema of its
+// category.
+func (s *Service) checkMetadata(ctx context.Context, n Note) error {
+	c, err := s.categories.GetByID(ctx, n.CategoryID)
+	if err != nil {
+		return err
 	}
+	if err := c.ValidateMetadata(n.Metadata); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	return nil
 }
 
 // CreateInput contains data for creating a note.
@@ -27,26 +83,57 @@ type CreateInput struct {
 	CategoryID category.ID
 	Title      string
 	Content    string
+	Metadata   map[string]any
 }
 
 // Create creates a new note.
 func (s *Service) Create(ctx context.Context, input CreateInput) (Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Note{}, err
+	}
+
+	metadata, err := normalizeMetadata(input.Metadata)
+	if err != nil {
+		return Note{}, err
+	}
+
 	now := time.Now().UTC()
 	n := Note{
-		ID:         NewID(),
-		CategoryID: input.CategoryID,
-		Title:      input.Title,
-		Content:    input.Content,
-		CreatedAt:  now,
-		UpdatedAt:  now,
+		ID:          NewID(),
+		WorkspaceID: workspaceID,
+		CategoryID:  input.CategoryID,
+		Title:       input.Title,
+		Content:     input.Content,
+		Metadata:    metadata,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
-	if err := s.repo.Create(ctx, n); err != nil {
-		s.logger.Error("failed to create note", zap.Error(err))
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.checkMetadata(ctx, n); err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, n); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, EntityType, n.ID, nil, s.snapshot(n)); err != nil {
+			return err
+		}
+		if err := s.syncLinks(ctx, n); err != nil {
+			return err
+		}
+		return s.repo.ResolveLinks(ctx, n.Title, n.ID)
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidMetadata) || errors.Is(err, category.ErrNotFound) {
+			return Note{}, err
+		}
+		logger.FromContext(ctx, s.logger).Error("failed to create note", zap.Error(err))
 		return Note{}, err
 	}
 
-	s.logger.Info("note created", zap.String("id", n.ID.String()))
+	logger.FromContext(ctx, s.logger).Info("note created", zap.String("id", n.ID.String()))
 	return n, nil
 }
 
@@ -54,7 +141,7 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (Note, error) {
 func (s *Service) GetByID(ctx context.Context, id ID) (Note, error) {
 	n, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("failed to get note", zap.String("id", id.String()), zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("failed to get note", zap.String("id", id.String()), zap.Error(err))
 		return Note{}, err
 	}
 	return n, nil
@@ -64,7 +151,7 @@ func (s *Service) GetByID(ctx context.Context, id ID) (Note, error) {
 func (s *Service) GetAll(ctx context.Context) ([]Note, error) {
 	notes, err := s.repo.GetAll(ctx)
 	if err != nil {
-		s.logger.Error("failed to get all notes", zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("failed to get all notes", zap.Error(err))
 		return nil, err
 	}
 	return notes, nil
@@ -74,7 +161,41 @@ func (s *Service) GetAll(ctx context.Context) ([]Note, error) {
 func (s *Service) GetByCategory(ctx context.Context, categoryID category.ID) ([]Note, error) {
 	notes, err := s.repo.GetByCategory(ctx, categoryID)
 	if err != nil {
-		s.logger.Error("failed to get notes by category", zap.String("category_id", categoryID.String()), zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("failed to get notes by category", zap.String("category_id", categoryID.String()), zap.Error(err))
+		return nil, err
+	}
+	return notes, nil
+}
+
+// List retrieves a page of notes, newest first. The filter expression, if
+// any, is resolved against the current categories and date.
+func (s *Service) List(ctx context.Context, f ListFilter) ([]Note, error) {
+	if f.Filter != nil {
+		var categories []category.Category
+		if f.Filter.refersTo(FieldCategory) {
+			var err error
+			if categories, err = s.categories.GetAll(ctx); err != nil {
+				logger.FromContext(ctx, s.logger).Error("failed to resolve filter categories", zap.Error(err))
+				return nil, err
+			}
+		}
+		f.Filter.resolve(time.Now(), categories)
+	}
+
+	notes, err := s.repo.List(ctx, f)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to list notes", zap.Error(err))
+		return nil, err
+	}
+	return notes, nil
+}
+
+// ListByCategories returns up to limit notes of each of categoryIDs, newest
+// first, with one query for all categories.
+func (s *Service) ListByCategories(ctx context.Context, categoryIDs []category.ID, limit int) ([]Note, error) {
+	notes, err := s.repo.ListByCategories(ctx, categoryIDs, limit)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to list notes", zap.Error(err))
 		return nil, err
 	}
 	return notes, nil
@@ -86,35 +207,241 @@ type UpdateInput struct {
 	CategoryID category.ID
 	Title      string
 	Content    string
+	// Metadata replaces the metadata unless nil.
+	Metadata map[string]any
 }
 
 // Update updates an existing note.
 func (s *Service) Update(ctx context.Context, input UpdateInput) (Note, error) {
-	n, err := s.repo.GetByID(ctx, input.ID)
-	if err != nil {
-		return Note{}, err
+	var metadata map[string]any
+	if input.Metadata != nil {
+		var err error
+		if metadata, err = normalizeMetadata(input.Metadata); err != nil {
+			return Note{}, err
+		}
 	}
 
-	n.CategoryID = input.CategoryID
-	n.Title = input.Title
-	n.Content = input.Content
-	n.UpdatedAt = time.Now().UTC()
+	var n Note
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		n, err = s.repo.GetByID(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		before, oldTitle := s.snapshot(n), n.Title
 
-	if err := s.repo.Update(ctx, n); err != nil {
-		s.logger.Error("failed to update note", zap.String("id", input.ID.String()), zap.Error(err))
+		n.CategoryID = input.CategoryID
+		n.Title = input.Title
+		n.Content = input.Content
+		if metadata != nil {
+			n.Metadata = metadata
+		}
+		n.UpdatedAt = time.Now().UTC()
+
+		if err := s.checkMetadata(ctx, n); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, n); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, EntityType, n.ID, before, s.snapshot(n)); err != nil {
+			return err
+		}
+		if err := s.syncLinks(ctx, n); err != nil {
+			return err
+		}
+		if n.Title == oldTitle {
+			return nil
+		}
+		if err := s.retitleLinks(ctx, n, oldTitle); err != nil {
+			return err
+		}
+		return s.repo.ResolveLinks(ctx, n.Title, n.ID)
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidMetadata) || errors.Is(err, category.ErrNotFound) {
+			return Note{}, err
+		}
+		logger.FromContext(ctx, s.logger).Error("failed to update note", zap.String("id", input.ID.String()), zap.Error(err))
 		return Note{}, err
 	}
 
-	s.logger.Info("note updated", zap.String("id", n.ID.String()))
+	logger.FromContext(ctx, s.logger).Info("note updated", zap.String("id", n.ID.String()))
 	return n, nil
 }
 
 // Delete removes a note.
 func (s *Service) Delete(ctx context.Context, id ID) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("failed to delete note", zap.String("id", id.String()), zap.Error(err))
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		n, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.delete(ctx, n)
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to delete note", zap.String("id", id.String()), zap.Error(err))
+		return err
+	}
+	logger.FromContext(ctx, s.logger).Info("note deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// delete removes n and records it. It must run in a transaction.
+func (s *Service) delete(ctx context.Context, n Note) error {
+	if err := s.repo.Delete(ctx, n.ID); err != nil {
 		return err
 	}
-	s.logger.Info("note deleted", zap.String("id", id.String()))
+	if err := s.audit.Record(ctx, EntityType, n.ID, s.snapshot(n), nil); err != nil {
+		return err
+	}
+	// Links to the title fall back to the next note having it
+	namesakes, err := s.repo.GetByTitles(ctx, []string{n.Title})
+	if err != nil || len(namesakes) == 0 {
+		return err
+	}
+	return s.repo.ResolveLinks(ctx, n.Title, namesakes[0].ID)
+}
+
+// deleteCategory deletes the notes of a category being deleted, so each
+// delete is recorded as if the notes were deleted one by one.
+func (s *Service) deleteCategory(ctx context.Context, id category.ID) error {
+	notes, err := s.repo.GetByCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		if err := s.delete(ctx, n); err != nil {
+			return err
+		}
+	}
+	if len(notes) > 0 {
+		logger.FromContext(ctx, s.logger).Info("notes of category deleted", zap.String("category_id", id.String()), zap.Int("count", len(notes)))
+	}
+	return nil
+}
+
+// Links returns the links in the content of a note, in order.
+func (s *Service) Links(ctx context.Context, id ID) ([]Link, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	links, err := s.repo.GetLinks(ctx, id)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to get note links", zap.String("id", id.String()), zap.Error(err))
+		return nil, err
+	}
+	return links, nil
+}
+
+// Backlinks returns the notes linking to a note, newest first.
+func (s *Service) Backlinks(ctx context.Context, id ID) ([]Note, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	notes, err := s.repo.GetLinkSources(ctx, id)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to get note backlinks", zap.String("id", id.String()), zap.Error(err))
+		return nil, err
+	}
+	return notes, nil
+}
+
+// DanglingLinks returns the links of the workspace that point to no note.
+func (s *Service) DanglingLinks(ctx context.Context) ([]Link, error) {
+	links, err := s.repo.GetDanglingLinks(ctx)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to get dangling links", zap.Error(err))
+		return nil, err
+	}
+	return links, nil
+}
+
+// syncLinks stores the links in the content of n. A title link points to
+// the oldest note having the title.
+func (s *Service) syncLinks(ctx context.Context, n Note) error {
+	links := ParseLinks(n.Content)
+
+	var (
+		titles []string
+		ids    []ID
+	)
+	for _, l := range links {
+		switch l.Kind {
+		case LinkByTitle:
+			titles = append(titles, l.Ref)
+		case LinkByID:
+			if id, err := ParseID(l.Ref); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	targets := make(map[Link]ID)
+	if len(titles) > 0 {
+		notes, err := s.repo.GetByTitles(ctx, titles)
+		if err != nil {
+			return err
+		}
+		for _, t := range notes {
+			key := Link{Kind: LinkByTitle, Ref: t.Title}
+			if _, ok := targets[key]; !ok {
+				targets[key] = t.ID
+			}
+		}
+	}
+	if len(ids) > 0 {
+		notes, err := s.repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, t := range notes {
+			targets[Link{Kind: LinkByID, Ref: t.ID.String()}] = t.ID
+		}
+	}
+
+	for i, l := range links {
+		links[i].TargetID = targets[l]
+	}
+	return s.repo.SetLinks(ctx, n.ID, links)
+}
+
+// retitleLinks rewrites the title links to n in other notes after n was
+// renamed from oldTitle, so they keep pointing to it. The rewrites are
+// audited as changes of those notes.
+func (s *Service) retitleLinks(ctx context.Context, n Note, oldTitle string) error {
+	sources, err := s.repo.GetLinkSources(ctx, n.ID)
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		if source.ID == n.ID {
+			continue
+		}
+		links, err := s.repo.GetLinks(ctx, source.ID)
+		if err != nil {
+			return err
+		}
+		byTitle := slices.Contains(links, Link{SourceID: source.ID, Kind: LinkByTitle, Ref: oldTitle, TargetID: n.ID})
+		if byTitle && Linkable(n.Title) {
+			content := rewriteTitleLinks(source.Content, oldTitle, n.Title)
+			if content != source.Content {
+				before := s.snapshot(source)
+				source.Content = content
+				source.UpdatedAt = time.Now().UTC()
+				if err := s.repo.Update(ctx, source); err != nil {
+					return err
+				}
+				if err := s.audit.Record(ctx, EntityType, source.ID, before, s.snapshot(source)); err != nil {
+					return err
+				}
+			}
+		}
+		// Links that could not be rewritten resolve to another note
+		// having the old title, or dangle
+		if err := s.syncLinks(ctx, source); err != nil {
+			return err
+		}
+	}
 	return nil
 }
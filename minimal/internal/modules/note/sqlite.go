@@ -0,0 +1,368 @@
+package note
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// SQLiteRepository implements Repository using SQLite.
+// Every statement is scoped to the workspace of the context.
+type SQLiteRepository struct {
+	db *db.SQLite
+}
+
+// NewSQLiteRepository creates a new SQLiteRepository.
+func NewSQLiteRepository(db *db.SQLite) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// scanSQLite scans a note selected with noteColumns.
+func scanSQLite(scan func(dest ...any) error) (Note, error) {
+	var (
+		row                  noteRow
+		metadata             string
+		createdAt, updatedAt string
+	)
+	if err := scan(&row.ID, &row.WorkspaceID, &row.CategoryID, &row.Title, &row.Content, &metadata, &createdAt, &updatedAt); err != nil {
+		return Note{}, err
+	}
+	if err := json.Unmarshal([]byte(metadata), &row.Metadata); err != nil {
+		return Note{}, err
+	}
+	var err error
+	if row.CreatedAt, err = db.ParseSQLiteTime(createdAt); err != nil {
+		return Note{}, err
+	}
+	if row.UpdatedAt, err = db.ParseSQLiteTime(updatedAt); err != nil {
+		return Note{}, err
+	}
+	return row.toDomain()
+}
+
+// sqliteError translates constraint violations of notes to the errors of
+// Repository.
+func sqliteError(err error) error {
+	switch db.SQLiteError(err) {
+	case db.ErrUniqueViolation:
+		return ErrAlreadyExists
+	case db.ErrForeignKeyViolation:
+		return category.ErrNotFound
+	}
+	return err
+}
+
+func (r *SQLiteRepository) Create(ctx context.Context, n Note) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(n.Metadata)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).ExecContext(ctx,
+		`INSERT INTO notes (`+noteColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		n.ID.String(), workspaceID.String(), n.CategoryID.String(), n.Title, n.Content, string(metadata),
+		db.SQLiteTime(n.CreatedAt), db.SQLiteTime(n.UpdatedAt),
+	)
+	return sqliteError(err)
+}
+
+func (r *SQLiteRepository) GetByID(ctx context.Context, id ID) (Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return Note{}, err
+	}
+	n, err := scanSQLite(r.db.Q(ctx).QueryRowContext(ctx,
+		`SELECT `+noteColumns+` FROM notes WHERE workspace_id = ? AND id = ?`,
+		workspaceID.String(), id.String(),
+	).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Note{}, ErrNotFound
+	}
+	return n, err
+}
+
+func (r *SQLiteRepository) GetAll(ctx context.Context) ([]Note, error) {
+	return r.List(ctx, ListFilter{})
+}
+
+func (r *SQLiteRepository) GetByCategory(ctx context.Context, categoryID category.ID) ([]Note, error) {
+	return r.List(ctx, ListFilter{CategoryID: categoryID})
+}
+
+// List selects notes by category and cursor in SQL. Metadata conditions
+// and filter expressions are evaluated on the selected notes as the memory
+// repository does, so the page is cut after them.
+func (r *SQLiteRepository) List(ctx context.Context, f ListFilter) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + noteColumns + ` FROM notes WHERE workspace_id = ?`
+	args := []any{workspaceID.String()}
+	if f.CategoryID != (category.ID{}) {
+		query += ` AND category_id = ?`
+		args = append(args, f.CategoryID.String())
+	}
+	if f.After != nil {
+		query += ` AND (created_at, id) < (?, ?)`
+		args = append(args, db.SQLiteTime(f.After.CreatedAt), f.After.ID.String())
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	matchInGo := len(f.Metadata) > 0 || f.Filter != nil
+	if f.Limit > 0 && !matchInGo {
+		query += ` LIMIT ?`
+		args = append(args, f.Limit)
+	}
+
+	notes, err := r.query(ctx, query, args...)
+	if err != nil || !matchInGo {
+		return notes, err
+	}
+
+	var matched []Note
+	for _, n := range notes {
+		if f.Limit > 0 && len(matched) == f.Limit {
+			break
+		}
+		if matchMetadata(f.Metadata, n) && (f.Filter == nil || f.Filter.root.match(n)) {
+			matched = append(matched, n)
+		}
+	}
+	return matched, nil
+}
+
+func matchMetadata(conditions []MetadataCondition, n Note) bool {
+	for _, c := range conditions {
+		if !c.Match(n.Metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *SQLiteRepository) query(ctx context.Context, query string, args ...any) ([]Note, error) {
+	rows, err := r.db.Q(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		n, err := scanSQLite(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+func (r *SQLiteRepository) ListByCategories(ctx context.Context, categoryIDs []category.ID, limit int) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(categoryIDs))
+	for i, id := range categoryIDs {
+		ids[i] = id.String()
+	}
+	return r.query(ctx,
+		`SELECT `+noteColumns+` FROM (
+		     SELECT `+noteColumns+`,
+		            row_number() OVER (PARTITION BY category_id ORDER BY created_at DESC, id DESC) AS rank
+		     FROM notes WHERE workspace_id = ? AND category_id IN (SELECT value FROM json_each(?))
+		 ) WHERE rank <= ? ORDER BY created_at DESC, id DESC`,
+		workspaceID.String(), db.SQLiteArray(ids), limit,
+	)
+}
+
+func (r *SQLiteRepository) Update(ctx context.Context, n Note) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(n.Metadata)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).ExecContext(ctx,
+		`UPDATE notes SET category_id = ?, title = ?, content = ?, metadata = ?, updated_at = ? WHERE workspace_id = ? AND id = ?`,
+		n.CategoryID.String(), n.Title, n.Content, string(metadata), db.SQLiteTime(n.UpdatedAt), workspaceID.String(), n.ID.String(),
+	)
+	if err != nil {
+		return sqliteError(err)
+	}
+	return sqliteAffected(result)
+}
+
+func (r *SQLiteRepository) Delete(ctx context.Context, id ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).ExecContext(ctx,
+		`DELETE FROM notes WHERE workspace_id = ? AND id = ?`,
+		workspaceID.String(), id.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return sqliteAffected(result)
+}
+
+// sqliteAffected returns ErrNotFound if result affected no rows.
+func sqliteAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) GetByTitles(ctx context.Context, titles []string) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx,
+		`SELECT `+noteColumns+` FROM notes WHERE workspace_id = ? AND title IN (SELECT value FROM json_each(?)) ORDER BY created_at, id`,
+		workspaceID.String(), db.SQLiteArray(titles),
+	)
+}
+
+func (r *SQLiteRepository) GetByIDs(ctx context.Context, ids []ID) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	strIDs := make([]string, len(ids))
+	for i, id := range ids {
+		strIDs[i] = id.String()
+	}
+	return r.query(ctx,
+		`SELECT `+noteColumns+` FROM notes WHERE workspace_id = ? AND id IN (SELECT value FROM json_each(?))`,
+		workspaceID.String(), db.SQLiteArray(strIDs),
+	)
+}
+
+func (r *SQLiteRepository) SetLinks(ctx context.Context, source ID, links []Link) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	q := r.db.Q(ctx)
+	if _, err := q.ExecContext(ctx,
+		`DELETE FROM note_links WHERE workspace_id = ? AND source_id = ?`,
+		workspaceID.String(), source.String(),
+	); err != nil {
+		return err
+	}
+	for i, l := range links {
+		var target *string
+		if !l.Dangling() {
+			s := l.TargetID.String()
+			target = &s
+		}
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO note_links (workspace_id, source_id, position, kind, ref, target_id) VALUES (?, ?, ?, ?, ?, ?)`,
+			workspaceID.String(), source.String(), i, string(l.Kind), l.Ref, target,
+		); err != nil {
+			if errors.Is(db.SQLiteError(err), db.ErrForeignKeyViolation) {
+				return ErrNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) GetLinks(ctx context.Context, source ID) ([]Link, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryLinks(ctx,
+		`SELECT source_id, kind, ref, target_id FROM note_links WHERE workspace_id = ? AND source_id = ? ORDER BY position`,
+		workspaceID.String(), source.String(),
+	)
+}
+
+func (r *SQLiteRepository) GetLinkSources(ctx context.Context, target ID) ([]Note, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx,
+		`SELECT `+noteColumns+` FROM notes WHERE workspace_id = ?1 AND id IN (
+		     SELECT source_id FROM note_links WHERE workspace_id = ?1 AND target_id = ?2
+		 ) ORDER BY created_at DESC, id DESC`,
+		workspaceID.String(), target.String(),
+	)
+}
+
+func (r *SQLiteRepository) GetDanglingLinks(ctx context.Context) ([]Link, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryLinks(ctx,
+		`SELECT source_id, kind, ref, target_id FROM note_links WHERE workspace_id = ? AND target_id IS NULL ORDER BY ref, source_id`,
+		workspaceID.String(),
+	)
+}
+
+func (r *SQLiteRepository) ResolveLinks(ctx context.Context, title string, target ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).ExecContext(ctx,
+		`UPDATE note_links SET target_id = ? WHERE workspace_id = ? AND kind = ? AND ref = ? AND target_id IS NULL`,
+		target.String(), workspaceID.String(), string(LinkByTitle), title,
+	)
+	return err
+}
+
+func (r *SQLiteRepository) queryLinks(ctx context.Context, query string, args ...any) ([]Link, error) {
+	rows, err := r.db.Q(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []Link
+	for rows.Next() {
+		var (
+			source, kind, ref string
+			target            sql.NullString
+		)
+		if err := rows.Scan(&source, &kind, &ref, &target); err != nil {
+			return nil, err
+		}
+		l := Link{Kind: LinkKind(kind), Ref: ref}
+		var err error
+		if l.SourceID, err = ParseID(source); err != nil {
+			return nil, err
+		}
+		if target.Valid {
+			if l.TargetID, err = ParseID(target.String); err != nil {
+				return nil, err
+			}
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
@@ -0,0 +1,124 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/jobs"
+)
+
+// Registry holds the modules of the application in dependency order.
+type Registry struct {
+	modules []Module
+}
+
+// NewRegistry creates a Registry of mods. It fails if two modules share a
+// name, a dependency is not among mods or dependencies form a cycle.
+func NewRegistry(mods ...Module) (*Registry, error) {
+	byName := make(map[string]Module, len(mods))
+	for _, m := range mods {
+		if _, ok := byName[m.Name()]; ok {
+			return nil, fmt.Errorf("module %q is registered twice", m.Name())
+		}
+		byName[m.Name()] = m
+	}
+
+	// Depth-first, so every module follows its dependencies; mods keeps
+	// its order where dependencies allow
+	r := &Registry{modules: make([]Module, 0, len(mods))}
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make(map[string]int, len(mods))
+	var path []string
+	var visit func(m Module) error
+	visit = func(m Module) error {
+		switch state[m.Name()] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("module dependency cycle: %s -> %s", strings.Join(path, " -> "), m.Name())
+		}
+		state[m.Name()] = visiting
+		path = append(path, m.Name())
+		for _, name := range m.Dependencies() {
+			dep, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("module %q depends on unknown module %q", m.Name(), name)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[m.Name()] = done
+		r.modules = append(r.modules, m)
+		return nil
+	}
+	for _, m := range mods {
+		if err := visit(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Modules returns the modules, each after its dependencies.
+func (r *Registry) Modules() []Module {
+	return r.modules
+}
+
+// RegisterRoutes adds the routes of every module to the API group.
+func (r *Registry) RegisterRoutes(api *echo.Group) {
+	for _, m := range r.modules {
+		m.RegisterRoutes(api)
+	}
+}
+
+// RegisterRootRoutes adds the routes of the modules implementing
+// RootRouter to e.
+func (r *Registry) RegisterRootRoutes(e *echo.Echo) {
+	for _, m := range r.modules {
+		if root, ok := m.(RootRouter); ok {
+			root.RegisterRootRoutes(e)
+		}
+	}
+}
+
+// Migrations returns the migrations of the modules that have any.
+func (r *Registry) Migrations() []fs.FS {
+	var sources []fs.FS
+	for _, m := range r.modules {
+		if fsys := m.Migrations(); fsys != nil {
+			sources = append(sources, fsys)
+		}
+	}
+	return sources
+}
+
+// RegisterJobs registers the jobs of every module with queue.
+func (r *Registry) RegisterJobs(ctx context.Context, queue *jobs.Queue) error {
+	for _, m := range r.modules {
+		if err := m.RegisterJobs(ctx, queue); err != nil {
+			return fmt.Errorf("module %q: %w", m.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Health checks every module and returns the errors of the unhealthy
+// ones by module name.
+func (r *Registry) Health(ctx context.Context) map[string]error {
+	failed := make(map[string]error)
+	for _, m := range r.modules {
+		if err := m.Health(ctx); err != nil {
+			failed[m.Name()] = err
+		}
+	}
+	return failed
+}
@@ -0,0 +1,147 @@
+package modules_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+type fakeModule struct {
+	modules.Base
+	name       string
+	deps       []string
+	migrations fs.FS
+	health     error
+}
+
+func (m fakeModule) Name() string                 { return m.name }
+func (m fakeModule) Dependencies() []string       { return m.deps }
+func (m fakeModule) RegisterRoutes(*echo.Group)   {}
+func (m fakeModule) Migrations() fs.FS            { return m.migrations }
+func (m fakeModule) Health(context.Context) error { return m.health }
+
+func names(r *modules.Registry) string {
+	var names []string
+	for _, m := range r.Modules() {
+		names = append(names, m.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func TestRegistryOrder(t *testing.T) {
+	r, err := modules.NewRegistry(
+		fakeModule{name: "search", deps: []string{"note", "tag"}},
+		fakeModule{name: "note", deps: []string{"category"}},
+		fakeModule{name: "tag"},
+		fakeModule{name: "category"},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	if got, want := names(r), "category,note,tag,search"; got != want {
+		t.Errorf("modules = %s, want %s", got, want)
+	}
+}
+
+func TestRegistryInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []modules.Module
+		err     string
+	}{
+		{
+			"duplicate",
+			[]modules.Module{fakeModule{name: "note"}, fakeModule{name: "note"}},
+			`module "note" is registered twice`,
+		},
+		{
+			"unknown dependency",
+			[]modules.Module{fakeModule{name: "note", deps: []string{"category"}}},
+			`module "note" depends on unknown module "category"`,
+		},
+		{
+			"cycle",
+			[]modules.Module{
+				fakeModule{name: "a", deps: []string{"b"}},
+				fakeModule{name: "b", deps: []string{"c"}},
+				fakeModule{name: "c", deps: []string{"a"}},
+			},
+			"module dependency cycle: a -> b -> c -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := modules.NewRegistry(tt.modules...)
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("err = %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRegistryMigrationsAndHealth(t *testing.T) {
+	migrations := fstest.MapFS{"000010_tags.up.sql": {}, "000010_tags.down.sql": {}}
+	down := errors.New("index unavailable")
+	r, err := modules.NewRegistry(
+		fakeModule{name: "note"},
+		fakeModule{name: "tag", migrations: migrations},
+		fakeModule{name: "search", health: down},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	if sources := r.Migrations(); len(sources) != 1 {
+		t.Errorf("migrations = %d sources, want 1", len(sources))
+	}
+	failed := r.Health(t.Context())
+	if len(failed) != 1 || !errors.Is(failed["search"], down) {
+		t.Errorf("health = %v, want search failing", failed)
+	}
+}
+
+// rootModule serves /root outside of the API group.
+type rootModule struct{ fakeModule }
+
+func (rootModule) RegisterRootRoutes(e *echo.Echo) {
+	e.GET("/root", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+}
+
+func TestRegistryRootRoutes(t *testing.T) {
+	r, err := modules.NewRegistry(fakeModule{name: "note"}, rootModule{fakeModule{name: "rpc"}})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	e := echo.New()
+	r.RegisterRootRoutes(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/root", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("GET /root = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
+
+// pinger fails with err.
+type pinger struct{ err error }
+
+func (p pinger) Ping(context.Context) error { return p.err }
+
+func TestPing(t *testing.T) {
+	down := errors.New("connection refused")
+	if err := modules.Ping(t.Context(), nil); err != nil {
+		t.Errorf("Ping(nil) = %v, want nil for data kept in memory", err)
+	}
+	if err := modules.Ping(t.Context(), pinger{err: down}); !errors.Is(err, down) {
+		t.Errorf("Ping = %v, want %v", err, down)
+	}
+}
@@ -0,0 +1,129 @@
+package savedsearch
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu       sync.RWMutex
+	searches map[ID]SavedSearch
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{searches: make(map[ID]SavedSearch)}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, s SavedSearch) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s.WorkspaceID = workspaceID
+	if _, ok := r.searches[s.ID]; ok {
+		return ErrAlreadyExists
+	}
+	if r.nameTaken(s) {
+		return ErrAlreadyExists
+	}
+	r.searches[s.ID] = s
+	return nil
+}
+
+func (r *MemoryRepository) GetByID(ctx context.Context, id ID) (SavedSearch, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.searches[id]
+	if !ok || s.WorkspaceID != workspaceID {
+		return SavedSearch{}, ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *MemoryRepository) GetAll(ctx context.Context) ([]SavedSearch, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var searches []SavedSearch
+	for _, s := range r.searches {
+		if s.WorkspaceID == workspaceID {
+			searches = append(searches, s)
+		}
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		return searches[i].Name < searches[j].Name
+	})
+	return searches, nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, s SavedSearch) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.searches[s.ID]
+	if !ok || existing.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	existing.Name = s.Name
+	existing.Filter = s.Filter
+	existing.UpdatedAt = s.UpdatedAt
+	if r.nameTaken(existing) {
+		return ErrAlreadyExists
+	}
+	r.searches[s.ID] = existing
+	return nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.searches[id]
+	if !ok || s.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	delete(r.searches, id)
+	return nil
+}
+
+// nameTaken reports whether another saved search in the workspace of s
+// already uses its name. The caller must hold r.mu.
+func (r *MemoryRepository) nameTaken(s SavedSearch) bool {
+	for _, other := range r.searches {
+		if other.ID != s.ID && other.WorkspaceID == s.WorkspaceID && other.Name == s.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package savedsearch
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Migrations holds the migrations of saved searches.
+var Migrations, _ = fs.Sub(migrationsFS, "migrations")
+
+// Module serves saved searches under /saved-searches.
+type Module struct {
+	modules.Base
+	routes modules.Routes
+	db     modules.Pinger
+}
+
+// NewModule creates the saved search module. routes serves
+// /saved-searches and db is the database searches are stored in, or nil
+// if they are kept in memory.
+func NewModule(routes modules.Routes, db modules.Pinger) *Module {
+	return &Module{routes: routes, db: db}
+}
+
+func (m *Module) Name() string { return "savedsearch" }
+
+// Saved searches run note searches.
+func (m *Module) Dependencies() []string { return []string{"note"} }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/saved-searches"))
+}
+
+func (m *Module) Migrations() fs.FS { return Migrations }
+
+// Health pings the database searches are stored in.
+func (m *Module) Health(ctx context.Context) error {
+	return modules.Ping(ctx, m.db)
+}
@@ -0,0 +1,168 @@
+package savedsearch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+)
+
+// savedSearchRow is the database representation of a saved search.
+type savedSearchRow struct {
+	ID          string    `db:"id"`
+	WorkspaceID string    `db:"workspace_id"`
+	Name        string    `db:"name"`
+	Filter      string    `db:"filter"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func (r savedSearchRow) toDomain() (SavedSearch, error) {
+	id, err := ParseID(r.ID)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	workspaceID, err := ParseID(r.WorkspaceID)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	return SavedSearch{
+		ID:          id,
+		WorkspaceID: workspaceID,
+		Name:        r.Name,
+		Filter:      r.Filter,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}, nil
+}
+
+const savedSearchColumns = `id, workspace_id, name, filter, created_at, updated_at`
+
+func scanSavedSearches(rows pgx.Rows) ([]SavedSearch, error) {
+	defer rows.Close()
+
+	var searches []SavedSearch
+	for rows.Next() {
+		var row savedSearchRow
+		if err := rows.Scan(&row.ID, &row.WorkspaceID, &row.Name, &row.Filter, &row.CreatedAt, &row.UpdatedAt); err != nil {
+			return nil, err
+		}
+		s, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, s)
+	}
+	return searches, rows.Err()
+}
+
+// PostgresRepository implements Repository using PostgreSQL.
+// Every statement is scoped to the workspace of the context.
+type PostgresRepository struct {
+	db *db.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository.
+func NewPostgresRepository(db *db.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, s SavedSearch) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO saved_searches (`+savedSearchColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		s.ID.String(), workspaceID.String(), s.Name, s.Filter, s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepository) GetByID(ctx context.Context, id ID) (SavedSearch, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+savedSearchColumns+` FROM saved_searches WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), id.String(),
+	)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	searches, err := scanSavedSearches(rows)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	if len(searches) == 0 {
+		return SavedSearch{}, ErrNotFound
+	}
+	return searches[0], nil
+}
+
+func (r *PostgresRepository) GetAll(ctx context.Context) ([]SavedSearch, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+savedSearchColumns+` FROM saved_searches WHERE workspace_id = $1 ORDER BY name COLLATE "C"`,
+		workspaceID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSavedSearches(rows)
+}
+
+func (r *PostgresRepository) Update(ctx context.Context, s SavedSearch) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`UPDATE saved_searches SET name = $3, filter = $4, updated_at = $5 WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), s.ID.String(), s.Name, s.Filter, s.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) Delete(ctx context.Context, id ID) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`DELETE FROM saved_searches WHERE workspace_id = $1 AND id = $2`,
+		workspaceID.String(), id.String(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package savedsearch
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound      = errors.New("saved search not found")
+	ErrAlreadyExists = errors.New("saved search already exists")
+)
+
+// Repository defines the interface for saved search persistence.
+type Repository interface {
+	Create(ctx context.Context, s SavedSearch) error
+	GetByID(ctx context.Context, id ID) (SavedSearch, error)
+	// GetAll returns the saved searches of the workspace by name.
+	GetAll(ctx context.Context) ([]SavedSearch, error)
+	Update(ctx context.Context, s SavedSearch) error
+	Delete(ctx context.Context, id ID) error
+}
@@ -0,0 +1,32 @@
+package savedsearch
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ID represents a saved search identifier.
+type ID = uuid.UUID
+
+// SavedSearch is a named filter expression over the notes of a workspace,
+// see note.ParseFilter. It is evaluated anew on every run, so it acts as a
+// smart category that follows the notes matching it.
+type SavedSearch struct {
+	ID          ID
+	WorkspaceID uuid.UUID
+	Name        string
+	Filter      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewID generates a new saved search ID.
+func NewID() ID {
+	return uuid.New()
+}
+
+// ParseID parses a string into a saved search ID.
+func ParseID(s string) (ID, error) {
+	return uuid.Parse(s)
+}
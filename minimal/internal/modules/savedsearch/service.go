@@ -0,0 +1,206 @@
+package savedsearch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/audit"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"github.com/piotmni/go-mini-templates/minimal/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// Notes lists the notes matching saved searches. *note.Service implements
+// it.
+type Notes interface {
+	List(ctx context.Context, f note.ListFilter) ([]note.Note, error)
+}
+
+// Service provides saved search business logic.
+type Service struct {
+	repo   Repository
+	notes  Notes
+	tx     db.Transactor
+	audit  *audit.Service
+	logger *zap.Logger
+}
+
+// NewService creates a new saved search service. Mutations are recorded
+// with auditor in the same transaction.
+func NewService(repo Repository, notes Notes, tx db.Transactor, auditor *audit.Service, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		notes:  notes,
+		tx:     tx,
+		audit:  auditor,
+		logger: logger.Named("savedsearch.service"),
+	}
+}
+
+// EntityType identifies saved searches in audit events.
+const EntityType = "saved_search"
+
+// snapshot returns the audited fields of s.
+func snapshot(s SavedSearch) audit.Snapshot {
+	return audit.Snapshot{"name": s.Name, "filter": s.Filter}
+}
+
+// CreateInput contains data for creating a saved search.
+type CreateInput struct {
+	Name string
+	// Filter is a filter expression, see note.ParseFilter.
+	Filter string
+}
+
+// Create creates a new saved search. It fails with note.ErrInvalidFilter
+// if the filter does not parse.
+func (s *Service) Create(ctx context.Context, input CreateInput) (SavedSearch, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+
+	if _, err := note.ParseFilter(input.Filter); err != nil {
+		return SavedSearch{}, err
+	}
+
+	now := time.Now().UTC()
+	search := SavedSearch{
+		ID:          NewID(),
+		WorkspaceID: workspaceID,
+		Name:        input.Name,
+		Filter:      input.Filter,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, search); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, EntityType, search.ID, nil, snapshot(search))
+	})
+	if err != nil {
+		if errors.Is(err, ErrAlreadyExists) {
+			return SavedSearch{}, err
+		}
+		logger.FromContext(ctx, s.logger).Error("failed to create saved search", zap.Error(err))
+		return SavedSearch{}, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("saved search created", zap.String("id", search.ID.String()))
+	return search, nil
+}
+
+// GetByID retrieves a saved search by ID.
+func (s *Service) GetByID(ctx context.Context, id ID) (SavedSearch, error) {
+	search, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to get saved search", zap.String("id", id.String()), zap.Error(err))
+		return SavedSearch{}, err
+	}
+	return search, nil
+}
+
+// GetAll retrieves all saved searches by name.
+func (s *Service) GetAll(ctx context.Context) ([]SavedSearch, error) {
+	searches, err := s.repo.GetAll(ctx)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to get all saved searches", zap.Error(err))
+		return nil, err
+	}
+	return searches, nil
+}
+
+// UpdateInput contains data for updating a saved search.
+type UpdateInput struct {
+	ID     ID
+	Name   string
+	Filter string
+}
+
+// Update updates an existing saved search.
+func (s *Service) Update(ctx context.Context, input UpdateInput) (SavedSearch, error) {
+	if _, err := note.ParseFilter(input.Filter); err != nil {
+		return SavedSearch{}, err
+	}
+
+	var search SavedSearch
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		search, err = s.repo.GetByID(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		before := snapshot(search)
+
+		search.Name = input.Name
+		search.Filter = input.Filter
+		search.UpdatedAt = time.Now().UTC()
+
+		if err := s.repo.Update(ctx, search); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, EntityType, search.ID, before, snapshot(search))
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrAlreadyExists) {
+			return SavedSearch{}, err
+		}
+		logger.FromContext(ctx, s.logger).Error("failed to update saved search", zap.String("id", input.ID.String()), zap.Error(err))
+		return SavedSearch{}, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("saved search updated", zap.String("id", search.ID.String()))
+	return search, nil
+}
+
+// Delete removes a saved search.
+func (s *Service) Delete(ctx context.Context, id ID) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		search, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, EntityType, id, snapshot(search), nil)
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to delete saved search", zap.String("id", id.String()), zap.Error(err))
+		return err
+	}
+	logger.FromContext(ctx, s.logger).Info("saved search deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// NotesInput selects a page of the notes matching a saved search.
+type NotesInput struct {
+	ID ID
+	// After starts the page behind the given note unless nil.
+	After *note.Cursor
+	// Limit bounds the page size unless zero.
+	Limit int
+}
+
+// Notes runs a saved search, returning the matching notes newest first.
+// Filters on encrypted titles or contents decrypt notes until the page is
+// full, see note.PostgresRepository.List.
+func (s *Service) Notes(ctx context.Context, input NotesInput) ([]note.Note, error) {
+	search, err := s.GetByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := note.ParseFilter(search.Filter)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("stored saved search filter is invalid", zap.String("id", search.ID.String()), zap.Error(err))
+		return nil, err
+	}
+
+	return s.notes.List(ctx, note.ListFilter{Filter: filter, After: input.After, Limit: input.Limit})
+}
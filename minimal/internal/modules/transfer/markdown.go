@@ -0,0 +1,315 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"gopkg.in/yaml.v3"
+)
+
+// categoriesFile is the index of categories in a Markdown archive. It keeps
+// category IDs and categories without notes.
+const categoriesFile = "categories.yaml"
+
+// frontMatterDelimiter opens and closes the front matter of a note file.
+const frontMatterDelimiter = "---\n"
+
+// maxFileSize bounds a single file in a Markdown archive.
+const maxFileSize = 4 << 20
+
+// frontMatter holds the fields of a note file.
+type frontMatter struct {
+	ID         string         `yaml:"id,omitempty"`
+	CategoryID string         `yaml:"category_id,omitempty"`
+	Category   string         `yaml:"category,omitempty"`
+	Title      string         `yaml:"title,omitempty"`
+	Metadata   map[string]any `yaml:"metadata,omitempty"`
+	CreatedAt  *time.Time     `yaml:"created_at,omitempty"`
+	UpdatedAt  *time.Time     `yaml:"updated_at,omitempty"`
+}
+
+// categoryEntry is a category in categoriesFile. The metadata schema is
+// written as YAML rather than as a JSON string, to be readable.
+type categoryEntry struct {
+	ID             string `yaml:"id,omitempty"`
+	Name           string `yaml:"name"`
+	MetadataSchema any    `yaml:"metadata_schema,omitempty"`
+}
+
+// fileWriter stores the files of a Markdown archive.
+type fileWriter interface {
+	create(name string, modified time.Time) (io.Writer, error)
+	close() error
+}
+
+// zipWriter stores files in a zip archive.
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w zipWriter) create(name string, modified time.Time) (io.Writer, error) {
+	return w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+}
+
+func (w zipWriter) close() error {
+	return w.zw.Close()
+}
+
+// dirWriter stores files under a directory, closing each file when the
+// next one is created.
+type dirWriter struct {
+	dir      string
+	f        *os.File
+	modified time.Time
+}
+
+func (w *dirWriter) create(name string, modified time.Time) (io.Writer, error) {
+	if err := w.close(); err != nil {
+		return nil, err
+	}
+	file := filepath.Join(w.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	w.f, w.modified = f, modified
+	return f, nil
+}
+
+func (w *dirWriter) close() error {
+	if w.f == nil {
+		return nil
+	}
+	f := w.f
+	w.f = nil
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if w.modified.IsZero() {
+		return nil
+	}
+	return os.Chtimes(f.Name(), w.modified, w.modified)
+}
+
+// markdownEncoder writes a Markdown archive. Notes are stored as
+// <category>/<title>-<id prefix>.md, using slugs of the names.
+type markdownEncoder struct {
+	files fileWriter
+	names map[category.ID]string
+}
+
+func newMarkdownEncoder(w io.Writer) *markdownEncoder {
+	return &markdownEncoder{files: zipWriter{zw: zip.NewWriter(w)}, names: map[category.ID]string{}}
+}
+
+// newMarkdownDirEncoder writes the files of a Markdown archive under dir.
+func newMarkdownDirEncoder(dir string) *markdownEncoder {
+	return &markdownEncoder{files: &dirWriter{dir: dir}, names: map[category.ID]string{}}
+}
+
+func (e *markdownEncoder) categories(categories []category.Category) error {
+	entries := make([]categoryEntry, len(categories))
+	for i, c := range categories {
+		entries[i] = categoryEntry{ID: c.ID.String(), Name: c.Name}
+		if c.MetadataSchema != nil {
+			if err := json.Unmarshal(c.MetadataSchema, &entries[i].MetadataSchema); err != nil {
+				return err
+			}
+		}
+		e.names[c.ID] = c.Name
+	}
+
+	f, err := e.files.create(categoriesFile, time.Time{})
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(f)
+	enc.SetIndent(2)
+	if err := enc.Encode(entries); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (e *markdownEncoder) notes(notes []note.Note) error {
+	for _, n := range notes {
+		name := e.names[n.CategoryID]
+		file := path.Join(slug(name, "uncategorized"), slug(n.Title, "untitled")+"-"+n.ID.String()[:8]+".md")
+
+		f, err := e.files.create(file, n.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		fm, err := yaml.Marshal(frontMatter{
+			ID:         n.ID.String(),
+			CategoryID: n.CategoryID.String(),
+			Category:   name,
+			Title:      n.Title,
+			Metadata:   n.Metadata,
+			CreatedAt:  &n.CreatedAt,
+			UpdatedAt:  &n.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		for _, part := range [][]byte{[]byte(frontMatterDelimiter), fm, []byte(frontMatterDelimiter), []byte(n.Content)} {
+			if _, err := f.Write(part); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *markdownEncoder) close() error {
+	return e.files.close()
+}
+
+// decodeMarkdownZip reads a Markdown archive. Files without front matter
+// are taken as notes titled after the file name, in the category named
+// after their directory.
+func decodeMarkdownZip(data []byte) (Archive, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Archive{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	var a Archive
+	for _, f := range zr.File {
+		switch {
+		case f.FileInfo().IsDir():
+		case f.Name == categoriesFile:
+			a.Categories = append(a.Categories, decodeCategoriesFile(f)...)
+		case strings.EqualFold(path.Ext(f.Name), ".md"):
+			a.Notes = append(a.Notes, decodeNoteFile(f))
+		}
+	}
+	return a, nil
+}
+
+func decodeCategoriesFile(f *zip.File) []CategoryRecord {
+	data, err := readZipFile(f)
+	if err == nil {
+		var entries []categoryEntry
+		if err = yaml.Unmarshal(data, &entries); err == nil {
+			records := make([]CategoryRecord, len(entries))
+			for i, e := range entries {
+				records[i] = CategoryRecord{Ref: fmt.Sprintf("%s[%d]", f.Name, i), ID: e.ID, Name: e.Name}
+				if e.MetadataSchema != nil {
+					schema, err := json.Marshal(e.MetadataSchema)
+					if err != nil {
+						records[i].Err = fmt.Errorf("%w: %v", category.ErrInvalidSchema, err)
+					}
+					records[i].MetadataSchema = schema
+				}
+			}
+			return records
+		}
+	}
+	return []CategoryRecord{{Ref: f.Name, Err: err}}
+}
+
+func decodeNoteFile(f *zip.File) NoteRecord {
+	r := NoteRecord{Ref: f.Name}
+
+	data, err := readZipFile(f)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	content := string(data)
+	var fm frontMatter
+	if rest, ok := strings.CutPrefix(content, frontMatterDelimiter); ok {
+		header, body, found := strings.Cut("\n"+rest, "\n"+frontMatterDelimiter)
+		if !found {
+			r.Err = errors.New("unterminated front matter")
+			return r
+		}
+		if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+			r.Err = fmt.Errorf("invalid front matter: %w", err)
+			return r
+		}
+		content = body
+	}
+
+	r.ID = fm.ID
+	r.CategoryID = fm.CategoryID
+	r.CategoryName = fm.Category
+	r.Title = fm.Title
+	r.Metadata = fm.Metadata
+	r.Content = content
+	if r.Title == "" {
+		r.Title = strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+	}
+	if r.CategoryID == "" && r.CategoryName == "" {
+		if dir := path.Dir(f.Name); dir != "." {
+			r.CategoryName = path.Base(dir)
+		}
+	}
+	return r
+}
+
+// readZipFile reads f, failing on files larger than maxFileSize rather than
+// truncating them, whatever size their header declares.
+func readZipFile(f *zip.File) ([]byte, error) {
+	tooLarge := fmt.Errorf("file larger than %d bytes", maxFileSize)
+	if f.UncompressedSize64 > maxFileSize {
+		return nil, tooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFileSize {
+		return nil, tooLarge
+	}
+	return data, nil
+}
+
+// maxSlugLength bounds the length of file names in runes.
+const maxSlugLength = 60
+
+// slug turns s into a lower case file name of letters, digits and dashes,
+// or returns fallback if nothing is left.
+func slug(s, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := []rune(strings.TrimSuffix(b.String(), "-"))
+	if len(out) == 0 {
+		return fallback
+	}
+	if len(out) > maxSlugLength {
+		out = out[:maxSlugLength]
+	}
+	return strings.TrimSuffix(string(out), "-")
+}
@@ -0,0 +1,27 @@
+package transfer
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+// Module serves /export and /import.
+type Module struct {
+	modules.Base
+	routes modules.Routes
+}
+
+// NewModule creates the transfer module. routes serves /export and
+// /import.
+func NewModule(routes modules.Routes) *Module {
+	return &Module{routes: routes}
+}
+
+func (m *Module) Name() string { return "transfer" }
+
+// Archives hold categories and notes.
+func (m *Module) Dependencies() []string { return []string{"category", "note"} }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api)
+}
@@ -0,0 +1,124 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+)
+
+// Record types of NDJSON lines.
+const (
+	typeCategory = "category"
+	typeNote     = "note"
+)
+
+// ndjsonLine is one line of an NDJSON archive.
+type ndjsonLine struct {
+	Type           string          `json:"type"`
+	ID             string          `json:"id,omitempty"`
+	Name           string          `json:"name,omitempty"`
+	MetadataSchema json.RawMessage `json:"metadata_schema,omitempty"`
+	CategoryID     string          `json:"category_id,omitempty"`
+	Title          string          `json:"title,omitempty"`
+	Content        string          `json:"content,omitempty"`
+	Metadata       map[string]any  `json:"metadata,omitempty"`
+	CreatedAt      *time.Time      `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time      `json:"updated_at,omitempty"`
+}
+
+// maxLineSize bounds a single NDJSON line, and so the size of a note.
+const maxLineSize = 4 << 20
+
+// decodeNDJSON reads an NDJSON archive. Blank lines are skipped.
+func decodeNDJSON(data []byte) (Archive, error) {
+	var a Archive
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for n := 1; scanner.Scan(); n++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		ref := fmt.Sprintf("line %d", n)
+
+		var line ndjsonLine
+		if err := json.Unmarshal(raw, &line); err != nil {
+			a.Notes = append(a.Notes, NoteRecord{Ref: ref, Err: fmt.Errorf("invalid JSON: %w", err)})
+			continue
+		}
+
+		switch line.Type {
+		case typeCategory:
+			a.Categories = append(a.Categories, CategoryRecord{Ref: ref, ID: line.ID, Name: line.Name, MetadataSchema: line.MetadataSchema})
+		case typeNote:
+			a.Notes = append(a.Notes, NoteRecord{
+				Ref:        ref,
+				ID:         line.ID,
+				CategoryID: line.CategoryID,
+				Title:      line.Title,
+				Content:    line.Content,
+				Metadata:   line.Metadata,
+			})
+		default:
+			a.Notes = append(a.Notes, NoteRecord{Ref: ref, Err: fmt.Errorf("unknown type %q", line.Type)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Archive{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	return a, nil
+}
+
+// ndjsonEncoder writes an NDJSON archive.
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func newNDJSONEncoder(w io.Writer) *ndjsonEncoder {
+	return &ndjsonEncoder{enc: json.NewEncoder(w)}
+}
+
+func (e *ndjsonEncoder) categories(categories []category.Category) error {
+	for _, c := range categories {
+		if err := e.enc.Encode(ndjsonLine{
+			Type:           typeCategory,
+			ID:             c.ID.String(),
+			Name:           c.Name,
+			MetadataSchema: c.MetadataSchema,
+			CreatedAt:      &c.CreatedAt,
+			UpdatedAt:      &c.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ndjsonEncoder) notes(notes []note.Note) error {
+	for _, n := range notes {
+		if err := e.enc.Encode(ndjsonLine{
+			Type:       typeNote,
+			ID:         n.ID.String(),
+			CategoryID: n.CategoryID.String(),
+			Title:      n.Title,
+			Content:    n.Content,
+			Metadata:   n.Metadata,
+			CreatedAt:  &n.CreatedAt,
+			UpdatedAt:  &n.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ndjsonEncoder) close() error {
+	return nil
+}
@@ -0,0 +1,470 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+)
+
+// exportPageSize is how many notes Export reads per query.
+const exportPageSize = 500
+
+// Service exports and imports workspaces through the category and note
+// services, so imports are validated and audited like other mutations.
+type Service struct {
+	categories *category.Service
+	notes      *note.Service
+	tx         db.Transactor
+	logger     *zap.Logger
+}
+
+// NewService creates a new transfer service.
+func NewService(categories *category.Service, notes *note.Service, tx db.Transactor, logger *zap.Logger) *Service {
+	return &Service{
+		categories: categories,
+		notes:      notes,
+		tx:         tx,
+		logger:     logger.Named("transfer.service"),
+	}
+}
+
+// encoder writes an archive incrementally.
+type encoder interface {
+	categories([]category.Category) error
+	notes([]note.Note) error
+	close() error
+}
+
+// flusher is implemented by writers that buffer, such as HTTP responses.
+type flusher interface {
+	Flush()
+}
+
+// Export writes all categories and notes of the workspace to w, reading
+// notes a page at a time.
+func (s *Service) Export(ctx context.Context, format Format, w io.Writer) error {
+	var enc encoder
+	switch format {
+	case FormatNDJSON:
+		enc = newNDJSONEncoder(w)
+	case FormatMarkdownZip:
+		enc = newMarkdownEncoder(w)
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+
+	err := s.export(ctx, enc, w)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to export", zap.String("format", string(format)), zap.Error(err))
+	}
+	return err
+}
+
+// ExportDir writes all categories and notes of the workspace to dir in the
+// layout of a Markdown archive. Files already in dir are left alone.
+func (s *Service) ExportDir(ctx context.Context, dir string) error {
+	enc := newMarkdownDirEncoder(dir)
+	err := s.export(ctx, enc, io.Discard)
+	if err != nil {
+		// Release the file being written
+		_ = enc.close()
+		logger.FromContext(ctx, s.logger).Error("failed to export to directory", zap.String("dir", dir), zap.Error(err))
+	}
+	return err
+}
+
+func (s *Service) export(ctx context.Context, enc encoder, w io.Writer) error {
+	categories, err := s.categories.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if err := enc.categories(categories); err != nil {
+		return err
+	}
+
+	filter := note.ListFilter{Limit: exportPageSize}
+	for {
+		notes, err := s.notes.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if err := enc.notes(notes); err != nil {
+			return err
+		}
+		if f, ok := w.(flusher); ok {
+			f.Flush()
+		}
+		if len(notes) < exportPageSize {
+			break
+		}
+		cursor := note.CursorOf(notes[len(notes)-1])
+		filter.After = &cursor
+	}
+
+	return enc.close()
+}
+
+// Decode reads an archive in the given format.
+func Decode(format Format, data []byte) (Archive, error) {
+	switch format {
+	case FormatNDJSON:
+		return decodeNDJSON(data)
+	case FormatMarkdownZip:
+		return decodeMarkdownZip(data)
+	}
+	return Archive{}, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// Import stores the records of a, updating entities whose ID exists in the
+// workspace and creating the others with new IDs. Categories without a
+// known ID are matched by name. Records without a metadata schema or
+// metadata leave those of existing entities alone.
+//
+// Every record is validated before anything is written. If any is invalid
+// the report lists the errors and nothing is imported; otherwise all
+// records are written in one transaction. A dry run only validates.
+func (s *Service) Import(ctx context.Context, a Archive, dryRun bool) (Report, error) {
+	p, err := s.plan(ctx, a)
+	if err != nil {
+		return Report{}, err
+	}
+	p.report.DryRun = dryRun
+	if dryRun || !p.report.OK() {
+		return p.report, nil
+	}
+
+	if err := s.tx.InTx(ctx, p.apply); err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to import", zap.Error(err))
+		return Report{}, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("import completed",
+		zap.Int("created", p.report.Created),
+		zap.Int("updated", p.report.Updated),
+		zap.Int("unchanged", p.report.Unchanged),
+	)
+	return p.report, nil
+}
+
+// categoryTarget is the category an imported note goes to. The ID of a
+// category the import creates is known once it is applied. schema is its
+// metadata schema once the import is applied, which notes are validated
+// against.
+type categoryTarget struct {
+	id     category.ID
+	schema json.RawMessage
+}
+
+type categoryOp struct {
+	item   int
+	action Action
+	target *categoryTarget
+	name   string
+	// schema replaces the metadata schema unless nil.
+	schema json.RawMessage
+}
+
+type noteOp struct {
+	item     int
+	action   Action
+	id       note.ID
+	category *categoryTarget
+	title    string
+	content  string
+	metadata map[string]any
+}
+
+// plan is a validated import.
+type plan struct {
+	s          *Service
+	report     Report
+	categories []*categoryOp
+	notes      []*noteOp
+
+	// byID maps existing category IDs and the source IDs of imported
+	// categories to their targets; byName does the same for names.
+	byID   map[string]*categoryTarget
+	byName map[string]*categoryTarget
+}
+
+func (s *Service) plan(ctx context.Context, a Archive) (*plan, error) {
+	existing, err := s.categories.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &plan{
+		s:      s,
+		byID:   make(map[string]*categoryTarget, len(existing)),
+		byName: make(map[string]*categoryTarget, len(existing)),
+	}
+	names := make(map[category.ID]string, len(existing))
+	for _, c := range existing {
+		t := &categoryTarget{id: c.ID, schema: c.MetadataSchema}
+		p.byID[c.ID.String()] = t
+		p.byName[c.Name] = t
+		names[c.ID] = c.Name
+	}
+
+	for _, r := range a.Categories {
+		p.planCategory(r, names)
+	}
+	for _, r := range a.Notes {
+		if err := p.planNote(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *plan) fail(typ, ref, sourceID string, err error) {
+	p.report.add(Item{Type: typ, Ref: ref, SourceID: sourceID, Action: ActionError, Error: err.Error()})
+}
+
+func (p *plan) planCategory(r CategoryRecord, names map[category.ID]string) {
+	item := Item{Type: category.EntityType, Ref: r.Ref, SourceID: r.ID}
+	switch {
+	case r.Err != nil:
+		p.fail(item.Type, r.Ref, r.ID, r.Err)
+		return
+	case r.Name == "":
+		p.fail(item.Type, r.Ref, r.ID, errors.New("name is required"))
+		return
+	}
+	schema, err := category.CheckSchema(r.MetadataSchema)
+	if err != nil {
+		p.fail(item.Type, r.Ref, r.ID, err)
+		return
+	}
+
+	if r.ID != "" {
+		if _, err := category.ParseID(r.ID); err != nil {
+			p.fail(item.Type, r.Ref, r.ID, errors.New("invalid id"))
+			return
+		}
+		if t, ok := p.byID[r.ID]; ok {
+			// An existing category, or one listed twice
+			current, stored := names[t.id]
+			if other, taken := p.byName[r.Name]; taken && other != t {
+				p.fail(item.Type, r.Ref, r.ID, category.ErrAlreadyExists)
+				return
+			}
+			op := &categoryOp{action: ActionUnchanged, target: t, name: r.Name}
+			if stored && current != r.Name {
+				op.action = ActionUpdate
+				delete(p.byName, current)
+				names[t.id] = r.Name
+			}
+			if stored && p.setSchema(op, schema) {
+				op.action = ActionUpdate
+			}
+			p.byName[r.Name] = t
+			item.ID = idString(t.id)
+			item.Action = op.action
+			op.item = p.report.add(item)
+			p.categories = append(p.categories, op)
+			return
+		}
+	}
+
+	if t, ok := p.byName[r.Name]; ok {
+		// Merge into the category of the same name
+		if r.ID != "" {
+			p.byID[r.ID] = t
+		}
+		item.ID = idString(t.id)
+		item.Action = ActionUnchanged
+		op := &categoryOp{action: ActionUnchanged, target: t, name: r.Name}
+		if t.id != (category.ID{}) && p.setSchema(op, schema) {
+			item.Action = ActionUpdate
+			op.action = ActionUpdate
+		}
+		op.item = p.report.add(item)
+		if op.action == ActionUpdate {
+			p.categories = append(p.categories, op)
+		}
+		return
+	}
+
+	t := &categoryTarget{schema: schema}
+	if r.ID != "" {
+		p.byID[r.ID] = t
+	}
+	p.byName[r.Name] = t
+	item.Action = ActionCreate
+	op := &categoryOp{action: ActionCreate, target: t, name: r.Name, schema: schema}
+	op.item = p.report.add(item)
+	p.categories = append(p.categories, op)
+}
+
+// setSchema makes op replace the metadata schema of an existing category
+// with schema, unless schema is nil or the same. It reports whether the
+// schema changes.
+func (p *plan) setSchema(op *categoryOp, schema json.RawMessage) bool {
+	if schema == nil || sameJSON(schema, op.target.schema) {
+		return false
+	}
+	op.schema = schema
+	op.target.schema = schema
+	return true
+}
+
+func (p *plan) planNote(ctx context.Context, r NoteRecord) error {
+	typ := note.EntityType
+	switch {
+	case r.Err != nil:
+		p.fail(typ, r.Ref, r.ID, r.Err)
+		return nil
+	case r.Title == "":
+		p.fail(typ, r.Ref, r.ID, errors.New("title is required"))
+		return nil
+	}
+
+	var target *categoryTarget
+	switch {
+	case r.CategoryID != "":
+		target = p.byID[r.CategoryID]
+		if target == nil && r.CategoryName == "" {
+			p.fail(typ, r.Ref, r.ID, category.ErrNotFound)
+			return nil
+		}
+		if target != nil {
+			break
+		}
+		fallthrough
+	case r.CategoryName != "":
+		target = p.byName[r.CategoryName]
+		if target == nil {
+			// Create the category the note names
+			p.planCategory(CategoryRecord{Ref: r.Ref, ID: r.CategoryID, Name: r.CategoryName}, nil)
+			target = p.byName[r.CategoryName]
+		}
+		if target == nil {
+			p.fail(typ, r.Ref, r.ID, errors.New("invalid category"))
+			return nil
+		}
+	default:
+		p.fail(typ, r.Ref, r.ID, errors.New("category is required"))
+		return nil
+	}
+
+	// Validate the metadata like the note service, so an invalid note is
+	// reported rather than failing the import when it is applied
+	if r.Metadata != nil {
+		if err := (category.Category{MetadataSchema: target.schema}).ValidateMetadata(r.Metadata); err != nil {
+			p.fail(typ, r.Ref, r.ID, fmt.Errorf("%w: %v", note.ErrInvalidMetadata, err))
+			return nil
+		}
+	}
+
+	op := &noteOp{action: ActionCreate, category: target, title: r.Title, content: r.Content, metadata: r.Metadata}
+	item := Item{Type: typ, Ref: r.Ref, SourceID: r.ID}
+	if r.ID != "" {
+		id, err := note.ParseID(r.ID)
+		if err != nil {
+			p.fail(typ, r.Ref, r.ID, errors.New("invalid id"))
+			return nil
+		}
+		n, err := p.s.notes.GetByID(ctx, id)
+		switch {
+		case err == nil:
+			op.id = n.ID
+			op.action = ActionUpdate
+			if n.CategoryID == target.id && n.Title == r.Title && n.Content == r.Content &&
+				(r.Metadata == nil || sameJSON(n.Metadata, r.Metadata)) {
+				op.action = ActionUnchanged
+			}
+			item.ID = n.ID.String()
+		case !errors.Is(err, note.ErrNotFound):
+			return err
+		}
+	}
+
+	item.Action = op.action
+	op.item = p.report.add(item)
+	p.notes = append(p.notes, op)
+	return nil
+}
+
+// apply writes the plan. It runs in the import transaction.
+func (p *plan) apply(ctx context.Context) error {
+	for _, op := range p.categories {
+		switch op.action {
+		case ActionCreate:
+			c, err := p.s.categories.Create(ctx, category.CreateInput{Name: op.name, MetadataSchema: op.schema})
+			if err != nil {
+				return err
+			}
+			op.target.id = c.ID
+		case ActionUpdate:
+			if _, err := p.s.categories.Update(ctx, category.UpdateInput{ID: op.target.id, Name: op.name, MetadataSchema: op.schema}); err != nil {
+				return err
+			}
+		}
+		p.report.Items[op.item].ID = op.target.id.String()
+	}
+
+	for _, op := range p.notes {
+		switch op.action {
+		case ActionCreate:
+			n, err := p.s.notes.Create(ctx, note.CreateInput{
+				CategoryID: op.category.id,
+				Title:      op.title,
+				Content:    op.content,
+				Metadata:   op.metadata,
+			})
+			if err != nil {
+				return err
+			}
+			op.id = n.ID
+		case ActionUpdate:
+			if _, err := p.s.notes.Update(ctx, note.UpdateInput{
+				ID:         op.id,
+				CategoryID: op.category.id,
+				Title:      op.title,
+				Content:    op.content,
+				Metadata:   op.metadata,
+			}); err != nil {
+				return err
+			}
+		}
+		p.report.Items[op.item].ID = op.id.String()
+	}
+	return nil
+}
+
+// sameJSON reports whether a and b, JSON documents or values, have the
+// same JSON form regardless of formatting and key order.
+func sameJSON[T json.RawMessage | map[string]any](a, b T) bool {
+	normalize := func(v T) (string, bool) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return "", false
+		}
+		data, err = json.Marshal(doc)
+		return string(data), err == nil
+	}
+	ja, okA := normalize(a)
+	jb, okB := normalize(b)
+	return okA && okB && ja == jb
+}
+
+// idString returns id as a string, or "" for categories not created yet.
+func idString(id category.ID) string {
+	if id == (category.ID{}) {
+		return ""
+	}
+	return id.String()
+}
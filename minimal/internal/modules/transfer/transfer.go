@@ -0,0 +1,122 @@
+// Package transfer exports the categories and notes of a workspace to
+// portable archives and imports them back.
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Format is an archive format.
+type Format string
+
+const (
+	// FormatNDJSON is newline delimited JSON with one category or note per
+	// line, categories first.
+	FormatNDJSON Format = "ndjson"
+	// FormatMarkdownZip is a zip archive with one Markdown file per note,
+	// carrying its fields as YAML front matter, and a categories.yaml index.
+	FormatMarkdownZip Format = "markdown-zip"
+)
+
+// ErrUnknownFormat is returned for formats other than the ones above.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// ParseFormat parses a format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatNDJSON, FormatMarkdownZip:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+// ErrInvalidArchive is returned when an archive cannot be read at all.
+// Problems with single entries are reported per item instead.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// Archive is the decoded content of an import.
+type Archive struct {
+	Categories []CategoryRecord
+	Notes      []NoteRecord
+}
+
+// CategoryRecord is a category read from an archive.
+type CategoryRecord struct {
+	// Ref locates the record in the archive, e.g. "line 3".
+	Ref  string
+	ID   string
+	Name string
+	// MetadataSchema replaces the schema of the category unless nil.
+	MetadataSchema json.RawMessage
+	// Err is set if the record could not be decoded.
+	Err error
+}
+
+// NoteRecord is a note read from an archive. The category is given by ID
+// or, failing that, by name.
+type NoteRecord struct {
+	Ref          string
+	ID           string
+	CategoryID   string
+	CategoryName string
+	Title        string
+	Content      string
+	// Metadata replaces the metadata of the note unless nil.
+	Metadata map[string]any
+	Err      error
+}
+
+// Action is what an import does with an item.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+	ActionError     Action = "error"
+)
+
+// Item is the result of importing one record.
+type Item struct {
+	Type string
+	Ref  string
+	// SourceID is the ID the record carried, if any.
+	SourceID string
+	// ID is the ID of the stored entity. It is empty for entities a dry
+	// run would create.
+	ID     string
+	Action Action
+	Error  string
+}
+
+// Report summarises an import.
+type Report struct {
+	DryRun    bool
+	Created   int
+	Updated   int
+	Unchanged int
+	Failed    int
+	Items     []Item
+}
+
+// OK reports whether every item can be imported.
+func (r *Report) OK() bool {
+	return r.Failed == 0
+}
+
+func (r *Report) add(item Item) int {
+	switch item.Action {
+	case ActionCreate:
+		r.Created++
+	case ActionUpdate:
+		r.Updated++
+	case ActionUnchanged:
+		r.Unchanged++
+	case ActionError:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+	return len(r.Items) - 1
+}
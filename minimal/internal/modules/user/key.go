@@ -0,0 +1,48 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// keyType is the leading marker of every API key, e.g. mk_1a2b3c4d5e6f_<secret>.
+const keyType = "mk"
+
+// generateKey returns a new random key and its lookup prefix.
+func generateKey() (raw, prefix string, err error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = keyType + "_" + hex.EncodeToString(id)
+	raw = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return raw, prefix, nil
+}
+
+// keyPrefix extracts the lookup prefix from a raw key.
+func keyPrefix(raw string) (string, bool) {
+	parts := strings.SplitN(raw, "_", 3)
+	if len(parts) != 3 || parts[0] != keyType || len(parts[1]) != 12 || parts[2] == "" {
+		return "", false
+	}
+	return parts[0] + "_" + parts[1], true
+}
+
+// IsKey reports whether raw looks like an API key issued by this package.
+func IsKey(raw string) bool {
+	_, ok := keyPrefix(raw)
+	return ok
+}
+
+func hashKey(raw string) []byte {
+	sum := sha256.Sum256([]byte(raw))
+	return sum[:]
+}
@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	users map[ID]User
+	keys  map[KeyID]APIKey
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		users: make(map[ID]User),
+		keys:  make(map[KeyID]APIKey),
+	}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, u User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.ID == u.ID || existing.Email == u.Email {
+			return ErrAlreadyExists
+		}
+	}
+	r.users[u.ID] = u
+	return nil
+}
+
+func (r *MemoryRepository) GetByID(ctx context.Context, id ID) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *MemoryRepository) GetByEmail(ctx context.Context, email string) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (r *MemoryRepository) CreateKey(ctx context.Context, k APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k.Scopes = slices.Clone(k.Scopes)
+	r.keys[k.ID] = k
+	return nil
+}
+
+func (r *MemoryRepository) GetKeyByID(ctx context.Context, id KeyID) (APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return APIKey{}, ErrKeyNotFound
+	}
+	return k, nil
+}
+
+func (r *MemoryRepository) GetKeyByPrefix(ctx context.Context, prefix string) (APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return k, nil
+		}
+	}
+	return APIKey{}, ErrKeyNotFound
+}
+
+func (r *MemoryRepository) GetKeysByUser(ctx context.Context, userID ID) ([]APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []APIKey
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+func (r *MemoryRepository) RevokeKey(ctx context.Context, id KeyID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok || k.Revoked() {
+		return ErrKeyNotFound
+	}
+	k.RevokedAt = &at
+	r.keys[id] = k
+	return nil
+}
+
+func (r *MemoryRepository) TouchKey(ctx context.Context, id KeyID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if k, ok := r.keys[id]; ok {
+		k.LastUsedAt = &at
+		r.keys[id] = k
+	}
+	return nil
+}
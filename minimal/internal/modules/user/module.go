@@ -0,0 +1,33 @@
+package user
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+// Module serves the caller's API keys under /keys. Users and API keys are
+// part of the core schema.
+type Module struct {
+	modules.Base
+	routes modules.Routes
+	db     modules.Pinger
+}
+
+// NewModule creates the user module. routes serves /keys and db is the
+// database users are stored in, or nil if they are kept in memory.
+func NewModule(routes modules.Routes, db modules.Pinger) *Module {
+	return &Module{routes: routes, db: db}
+}
+
+func (m *Module) Name() string { return "user" }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/keys"))
+}
+
+// Health pings the database users are stored in.
+func (m *Module) Health(ctx context.Context) error {
+	return modules.Ping(ctx, m.db)
+}
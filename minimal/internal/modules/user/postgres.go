@@ -0,0 +1,221 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+)
+
+// userRow is the database representation of a user.
+type userRow struct {
+	ID        string    `db:"id"`
+	Email     string    `db:"email"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r userRow) toDomain() (User, error) {
+	id, err := ParseID(r.ID)
+	if err != nil {
+		return User{}, err
+	}
+	return User{
+		ID:        id,
+		Email:     r.Email,
+		Name:      r.Name,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}, nil
+}
+
+// keyRow is the database representation of an API key.
+type keyRow struct {
+	ID          string     `db:"id"`
+	UserID      string     `db:"user_id"`
+	WorkspaceID string     `db:"workspace_id"`
+	Name        string     `db:"name"`
+	Prefix      string     `db:"prefix"`
+	Hash        []byte     `db:"hash"`
+	Scopes      []string   `db:"scopes"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+	LastUsedAt  *time.Time `db:"last_used_at"`
+	RevokedAt   *time.Time `db:"revoked_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+func (r keyRow) toDomain() (APIKey, error) {
+	id, err := ParseID(r.ID)
+	if err != nil {
+		return APIKey{}, err
+	}
+	userID, err := ParseID(r.UserID)
+	if err != nil {
+		return APIKey{}, err
+	}
+	workspaceID, err := ParseID(r.WorkspaceID)
+	if err != nil {
+		return APIKey{}, err
+	}
+	scopes := make([]auth.Scope, len(r.Scopes))
+	for i, s := range r.Scopes {
+		scopes[i] = auth.Scope(s)
+	}
+	return APIKey{
+		ID:          id,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Name:        r.Name,
+		Prefix:      r.Prefix,
+		Hash:        r.Hash,
+		Scopes:      scopes,
+		ExpiresAt:   r.ExpiresAt,
+		LastUsedAt:  r.LastUsedAt,
+		RevokedAt:   r.RevokedAt,
+		CreatedAt:   r.CreatedAt,
+	}, nil
+}
+
+func toKeyRow(k APIKey) keyRow {
+	scopes := make([]string, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = string(s)
+	}
+	return keyRow{
+		ID:          k.ID.String(),
+		UserID:      k.UserID.String(),
+		WorkspaceID: k.WorkspaceID.String(),
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		Hash:        k.Hash,
+		Scopes:      scopes,
+		ExpiresAt:   k.ExpiresAt,
+		LastUsedAt:  k.LastUsedAt,
+		RevokedAt:   k.RevokedAt,
+		CreatedAt:   k.CreatedAt,
+	}
+}
+
+const keyColumns = `id, user_id, workspace_id, name, prefix, hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanKey(row pgx.Row) (APIKey, error) {
+	var r keyRow
+	err := row.Scan(&r.ID, &r.UserID, &r.WorkspaceID, &r.Name, &r.Prefix, &r.Hash, &r.Scopes,
+		&r.ExpiresAt, &r.LastUsedAt, &r.RevokedAt, &r.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return APIKey{}, ErrKeyNotFound
+		}
+		return APIKey{}, err
+	}
+	return r.toDomain()
+}
+
+// PostgresRepository implements Repository using PostgreSQL.
+type PostgresRepository struct {
+	db *db.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository.
+func NewPostgresRepository(db *db.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, u User) error {
+	_, err := r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO users (id, email, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`,
+		u.ID.String(), u.Email, u.Name, u.CreatedAt, u.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepository) GetByID(ctx context.Context, id ID) (User, error) {
+	return r.getUser(ctx, `SELECT id, email, name, created_at, updated_at FROM users WHERE id = $1`, id.String())
+}
+
+func (r *PostgresRepository) GetByEmail(ctx context.Context, email string) (User, error) {
+	return r.getUser(ctx, `SELECT id, email, name, created_at, updated_at FROM users WHERE email = $1`, email)
+}
+
+func (r *PostgresRepository) getUser(ctx context.Context, query string, arg any) (User, error) {
+	var row userRow
+	err := r.db.Q(ctx).QueryRow(ctx, query, arg).
+		Scan(&row.ID, &row.Email, &row.Name, &row.CreatedAt, &row.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrNotFound
+		}
+		return User{}, err
+	}
+	return row.toDomain()
+}
+
+func (r *PostgresRepository) CreateKey(ctx context.Context, k APIKey) error {
+	row := toKeyRow(k)
+	_, err := r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO api_keys (`+keyColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		row.ID, row.UserID, row.WorkspaceID, row.Name, row.Prefix, row.Hash, row.Scopes,
+		row.ExpiresAt, row.LastUsedAt, row.RevokedAt, row.CreatedAt,
+	)
+	return err
+}
+
+func (r *PostgresRepository) GetKeyByID(ctx context.Context, id KeyID) (APIKey, error) {
+	return scanKey(r.db.Q(ctx).QueryRow(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE id = $1`, id.String()))
+}
+
+func (r *PostgresRepository) GetKeyByPrefix(ctx context.Context, prefix string) (APIKey, error) {
+	return scanKey(r.db.Q(ctx).QueryRow(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+}
+
+func (r *PostgresRepository) GetKeysByUser(ctx context.Context, userID ID) ([]APIKey, error) {
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT `+keyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`,
+		userID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *PostgresRepository) RevokeKey(ctx context.Context, id KeyID, at time.Time) error {
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`,
+		id.String(), at,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) TouchKey(ctx context.Context, id KeyID, at time.Time) error {
+	_, err := r.db.Q(ctx).Exec(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id.String(), at)
+	return err
+}
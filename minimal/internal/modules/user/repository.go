@@ -0,0 +1,31 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("user not found")
+	ErrAlreadyExists = errors.New("user already exists")
+	ErrKeyNotFound   = errors.New("api key not found")
+	ErrInvalidKey    = errors.New("invalid api key")
+	ErrKeyExpired    = errors.New("api key expired")
+	ErrKeyRevoked    = errors.New("api key revoked")
+	ErrScopeNotHeld  = errors.New("api key has a scope the caller lacks")
+)
+
+// Repository defines the interface for user and API key persistence.
+type Repository interface {
+	Create(ctx context.Context, u User) error
+	GetByID(ctx context.Context, id ID) (User, error)
+	GetByEmail(ctx context.Context, email string) (User, error)
+
+	CreateKey(ctx context.Context, k APIKey) error
+	GetKeyByID(ctx context.Context, id KeyID) (APIKey, error)
+	GetKeyByPrefix(ctx context.Context, prefix string) (APIKey, error)
+	GetKeysByUser(ctx context.Context, userID ID) ([]APIKey, error)
+	RevokeKey(ctx context.Context, id KeyID, at time.Time) error
+	TouchKey(ctx context.Context, id KeyID, at time.Time) error
+}
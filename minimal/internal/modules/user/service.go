@@ -0,0 +1,235 @@
+package user
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"go.uber.org/zap"
+)
+
+// touchInterval limits how often a key's last-used timestamp is written.
+const touchInterval = time.Minute
+
+// Service provides user and API key business logic.
+type Service struct {
+	repo   Repository
+	tx     db.Transactor
+	logger *zap.Logger
+}
+
+// NewService creates a new user service.
+func NewService(repo Repository, tx db.Transactor, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		tx:     tx,
+		logger: logger.Named("user.service"),
+	}
+}
+
+// CreateInput contains data for creating a user.
+type CreateInput struct {
+	Email string
+	Name  string
+}
+
+// Create creates a new user.
+func (s *Service) Create(ctx context.Context, input CreateInput) (User, error) {
+	now := time.Now().UTC()
+	u := User{
+		ID:        NewID(),
+		Email:     strings.ToLower(strings.TrimSpace(input.Email)),
+		Name:      input.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Create(ctx, u); err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to create user", zap.Error(err))
+		return User{}, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("user created", zap.String("id", u.ID.String()))
+	return u, nil
+}
+
+// GetByID retrieves a user by ID.
+func (s *Service) GetByID(ctx context.Context, id ID) (User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetByEmail retrieves a user by email address.
+func (s *Service) GetByEmail(ctx context.Context, email string) (User, error) {
+	return s.repo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+}
+
+// IssueKeyInput contains data for issuing an API key.
+type IssueKeyInput struct {
+	UserID      ID
+	WorkspaceID uuid.UUID
+	Name        string
+	Scopes      []auth.Scope
+	ExpiresAt   *time.Time
+}
+
+// IssueKey creates a new API key. The raw key is returned only here; it
+// cannot be recovered later.
+func (s *Service) IssueKey(ctx context.Context, input IssueKeyInput) (APIKey, string, error) {
+	if _, err := s.repo.GetByID(ctx, input.UserID); err != nil {
+		return APIKey{}, "", err
+	}
+
+	raw, prefix, err := generateKey()
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	k := APIKey{
+		ID:          NewID(),
+		UserID:      input.UserID,
+		WorkspaceID: input.WorkspaceID,
+		Name:        input.Name,
+		Prefix:      prefix,
+		Hash:        hashKey(raw),
+		Scopes:      input.Scopes,
+		ExpiresAt:   input.ExpiresAt,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if err := s.repo.CreateKey(ctx, k); err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to create api key", zap.Error(err))
+		return APIKey{}, "", err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("api key issued", zap.String("id", k.ID.String()), zap.String("user_id", k.UserID.String()))
+	return k, raw, nil
+}
+
+// ListKeys retrieves all API keys of a user, including revoked ones.
+func (s *Service) ListKeys(ctx context.Context, userID ID) ([]APIKey, error) {
+	keys, err := s.repo.GetKeysByUser(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to list api keys", zap.String("user_id", userID.String()), zap.Error(err))
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeKey revokes an API key of caller. It returns ErrScopeNotHeld if the
+// key has a scope caller lacks, so a narrow key cannot revoke a broader one.
+func (s *Service) RevokeKey(ctx context.Context, caller auth.Principal, id KeyID) error {
+	if _, err := s.managedKey(ctx, caller, id); err != nil {
+		return err
+	}
+	return s.revokeKey(ctx, id)
+}
+
+func (s *Service) revokeKey(ctx context.Context, id KeyID) error {
+	if err := s.repo.RevokeKey(ctx, id, time.Now().UTC()); err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to revoke api key", zap.String("id", id.String()), zap.Error(err))
+		return err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("api key revoked", zap.String("id", id.String()))
+	return nil
+}
+
+// RotateKey replaces an API key of caller with a new one carrying the same
+// name, scopes and expiry, and revokes the old key, in one transaction.
+// Like RevokeKey, caller must hold every scope of the key: rotating hands
+// them out with the new key.
+func (s *Service) RotateKey(ctx context.Context, caller auth.Principal, id KeyID) (APIKey, string, error) {
+	var k APIKey
+	var raw string
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		old, err := s.managedKey(ctx, caller, id)
+		if err != nil {
+			return err
+		}
+
+		// Revoking first locks the old key, so of concurrent rotations
+		// only one issues a new key
+		if err := s.revokeKey(ctx, id); err != nil {
+			return err
+		}
+
+		k, raw, err = s.IssueKey(ctx, IssueKeyInput{
+			UserID:      old.UserID,
+			WorkspaceID: old.WorkspaceID,
+			Name:        old.Name,
+			Scopes:      old.Scopes,
+			ExpiresAt:   old.ExpiresAt,
+		})
+		return err
+	})
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	return k, raw, nil
+}
+
+// managedKey retrieves an active key of caller, and checks that caller
+// holds every scope of it.
+func (s *Service) managedKey(ctx context.Context, caller auth.Principal, id KeyID) (APIKey, error) {
+	k, err := s.repo.GetKeyByID(ctx, id)
+	if err != nil {
+		return APIKey{}, err
+	}
+	if k.UserID != caller.UserID || k.Revoked() {
+		return APIKey{}, ErrKeyNotFound
+	}
+	for _, scope := range k.Scopes {
+		if !caller.HasScope(scope) {
+			return APIKey{}, fmt.Errorf("%w: %s", ErrScopeNotHeld, scope)
+		}
+	}
+	return k, nil
+}
+
+// Authenticate resolves a raw API key into the principal it represents.
+func (s *Service) Authenticate(ctx context.Context, raw string) (auth.Principal, error) {
+	prefix, ok := keyPrefix(raw)
+	if !ok {
+		return auth.Principal{}, ErrInvalidKey
+	}
+
+	k, err := s.repo.GetKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return auth.Principal{}, ErrInvalidKey
+		}
+		return auth.Principal{}, err
+	}
+
+	if subtle.ConstantTimeCompare(k.Hash, hashKey(raw)) != 1 {
+		return auth.Principal{}, ErrInvalidKey
+	}
+
+	now := time.Now().UTC()
+	if k.Revoked() {
+		return auth.Principal{}, ErrKeyRevoked
+	}
+	if k.Expired(now) {
+		return auth.Principal{}, ErrKeyExpired
+	}
+
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= touchInterval {
+		if err := s.repo.TouchKey(ctx, k.ID, now); err != nil {
+			logger.FromContext(ctx, s.logger).Warn("failed to record api key use", zap.String("id", k.ID.String()), zap.Error(err))
+		}
+	}
+
+	return auth.Principal{
+		UserID:      k.UserID,
+		KeyID:       k.ID,
+		WorkspaceID: k.WorkspaceID,
+		Scopes:      k.Scopes,
+	}, nil
+}
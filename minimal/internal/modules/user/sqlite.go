@@ -0,0 +1,158 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+)
+
+// SQLiteRepository implements Repository using SQLite.
+type SQLiteRepository struct {
+	db *db.SQLite
+}
+
+// NewSQLiteRepository creates a new SQLiteRepository.
+func NewSQLiteRepository(db *db.SQLite) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// scanSQLiteKey scans an API key selected with keyColumns.
+func scanSQLiteKey(scan func(dest ...any) error) (APIKey, error) {
+	var (
+		row                              keyRow
+		scopes, createdAt                string
+		expiresAt, lastUsedAt, revokedAt sql.NullString
+	)
+	err := scan(&row.ID, &row.UserID, &row.WorkspaceID, &row.Name, &row.Prefix, &row.Hash, &scopes,
+		&expiresAt, &lastUsedAt, &revokedAt, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return APIKey{}, ErrKeyNotFound
+		}
+		return APIKey{}, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &row.Scopes); err != nil {
+		return APIKey{}, err
+	}
+	if row.ExpiresAt, err = db.ParseSQLiteNullTime(expiresAt); err != nil {
+		return APIKey{}, err
+	}
+	if row.LastUsedAt, err = db.ParseSQLiteNullTime(lastUsedAt); err != nil {
+		return APIKey{}, err
+	}
+	if row.RevokedAt, err = db.ParseSQLiteNullTime(revokedAt); err != nil {
+		return APIKey{}, err
+	}
+	if row.CreatedAt, err = db.ParseSQLiteTime(createdAt); err != nil {
+		return APIKey{}, err
+	}
+	return row.toDomain()
+}
+
+func (r *SQLiteRepository) Create(ctx context.Context, u User) error {
+	_, err := r.db.Q(ctx).ExecContext(ctx,
+		`INSERT INTO users (id, email, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		u.ID.String(), u.Email, u.Name, db.SQLiteTime(u.CreatedAt), db.SQLiteTime(u.UpdatedAt),
+	)
+	if errors.Is(db.SQLiteError(err), db.ErrUniqueViolation) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *SQLiteRepository) GetByID(ctx context.Context, id ID) (User, error) {
+	return r.getUser(ctx, `SELECT id, email, name, created_at, updated_at FROM users WHERE id = ?`, id.String())
+}
+
+func (r *SQLiteRepository) GetByEmail(ctx context.Context, email string) (User, error) {
+	return r.getUser(ctx, `SELECT id, email, name, created_at, updated_at FROM users WHERE email = ?`, email)
+}
+
+func (r *SQLiteRepository) getUser(ctx context.Context, query string, arg any) (User, error) {
+	var (
+		row                  userRow
+		createdAt, updatedAt string
+	)
+	err := r.db.Q(ctx).QueryRowContext(ctx, query, arg).
+		Scan(&row.ID, &row.Email, &row.Name, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, ErrNotFound
+		}
+		return User{}, err
+	}
+	if row.CreatedAt, err = db.ParseSQLiteTime(createdAt); err != nil {
+		return User{}, err
+	}
+	if row.UpdatedAt, err = db.ParseSQLiteTime(updatedAt); err != nil {
+		return User{}, err
+	}
+	return row.toDomain()
+}
+
+func (r *SQLiteRepository) CreateKey(ctx context.Context, k APIKey) error {
+	row := toKeyRow(k)
+	_, err := r.db.Q(ctx).ExecContext(ctx,
+		`INSERT INTO api_keys (`+keyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		row.ID, row.UserID, row.WorkspaceID, row.Name, row.Prefix, row.Hash, db.SQLiteArray(row.Scopes),
+		db.SQLiteNullTime(row.ExpiresAt), db.SQLiteNullTime(row.LastUsedAt), db.SQLiteNullTime(row.RevokedAt),
+		db.SQLiteTime(row.CreatedAt),
+	)
+	return err
+}
+
+func (r *SQLiteRepository) GetKeyByID(ctx context.Context, id KeyID) (APIKey, error) {
+	return scanSQLiteKey(r.db.Q(ctx).QueryRowContext(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE id = ?`, id.String()).Scan)
+}
+
+func (r *SQLiteRepository) GetKeyByPrefix(ctx context.Context, prefix string) (APIKey, error) {
+	return scanSQLiteKey(r.db.Q(ctx).QueryRowContext(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE prefix = ?`, prefix).Scan)
+}
+
+func (r *SQLiteRepository) GetKeysByUser(ctx context.Context, userID ID) ([]APIKey, error) {
+	rows, err := r.db.Q(ctx).QueryContext(ctx,
+		`SELECT `+keyColumns+` FROM api_keys WHERE user_id = ? ORDER BY created_at DESC`,
+		userID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		k, err := scanSQLiteKey(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *SQLiteRepository) RevokeKey(ctx context.Context, id KeyID, at time.Time) error {
+	result, err := r.db.Q(ctx).ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`,
+		db.SQLiteTime(at), id.String(),
+	)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) TouchKey(ctx context.Context, id KeyID, at time.Time) error {
+	_, err := r.db.Q(ctx).ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, db.SQLiteTime(at), id.String())
+	return err
+}
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+)
+
+// ID represents a user identifier.
+type ID = uuid.UUID
+
+// KeyID represents an API key identifier.
+type KeyID = uuid.UUID
+
+// User represents a person or integration that owns API keys.
+type User struct {
+	ID        ID
+	Email     string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// APIKey represents a credential issued to a user for acting in one
+// workspace. Only a hash of the key is stored; Prefix identifies the key
+// without revealing it.
+type APIKey struct {
+	ID          KeyID
+	UserID      ID
+	WorkspaceID uuid.UUID
+	Name        string
+	Prefix      string
+	Hash        []byte
+	Scopes      []auth.Scope
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// Expired reports whether the key is past its expiry at now.
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Revoked reports whether the key has been revoked.
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// NewID generates a new user or key ID.
+func NewID() ID {
+	return uuid.New()
+}
+
+// ParseID parses a string into a user or key ID.
+func ParseID(s string) (ID, error) {
+	return uuid.Parse(s)
+}
@@ -0,0 +1,46 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+)
+
+// CredentialAuthenticator resolves a bearer credential into a principal
+// acting in a workspace.
+type CredentialAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+}
+
+// Authenticator checks that principals resolved by a credential
+// authenticator are still members of their workspace, and narrows their
+// scopes to the ones their role permits.
+type Authenticator struct {
+	next    CredentialAuthenticator
+	service *Service
+}
+
+// NewAuthenticator creates an Authenticator wrapping next.
+func NewAuthenticator(next CredentialAuthenticator, service *Service) *Authenticator {
+	return &Authenticator{next: next, service: service}
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	p, err := a.next.Authenticate(ctx, token)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	m, err := a.service.GetMember(ctx, p.WorkspaceID, p.UserID)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return auth.Principal{}, ErrNotMember
+		}
+		return auth.Principal{}, err
+	}
+
+	p.Role = string(m.Role)
+	p.Scopes = restrict(p.Scopes, m.Role.Scopes())
+	return p, nil
+}
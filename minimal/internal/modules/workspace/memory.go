@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type memberKey struct {
+	workspaceID ID
+	userID      UserID
+}
+
+// MemoryRepository implements Repository in memory.
+// It is intended for tests and local experiments.
+type MemoryRepository struct {
+	mu         sync.RWMutex
+	workspaces map[ID]Workspace
+	members    map[memberKey]Member
+}
+
+// NewMemoryRepository creates a new MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		workspaces: make(map[ID]Workspace),
+		members:    make(map[memberKey]Member),
+	}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, w Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.workspaces[w.ID] = w
+	return nil
+}
+
+func (r *MemoryRepository) GetByID(ctx context.Context, id ID) (Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.workspaces[id]
+	if !ok {
+		return Workspace{}, ErrNotFound
+	}
+	return w, nil
+}
+
+func (r *MemoryRepository) GetByUser(ctx context.Context, userID UserID) ([]Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var workspaces []Workspace
+	for key := range r.members {
+		if key.userID == userID {
+			workspaces = append(workspaces, r.workspaces[key.workspaceID])
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt)
+	})
+	return workspaces, nil
+}
+
+func (r *MemoryRepository) AddMember(ctx context.Context, m Member) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{m.WorkspaceID, m.UserID}
+	if _, ok := r.members[key]; ok {
+		return ErrMemberExists
+	}
+	r.members[key] = m
+	return nil
+}
+
+func (r *MemoryRepository) GetMember(ctx context.Context, id ID, userID UserID) (Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.members[memberKey{id, userID}]
+	if !ok {
+		return Member{}, ErrMemberNotFound
+	}
+	return m, nil
+}
+
+func (r *MemoryRepository) GetMembers(ctx context.Context, id ID) ([]Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var members []Member
+	for key, m := range r.members {
+		if key.workspaceID == id {
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	return members, nil
+}
+
+func (r *MemoryRepository) UpdateMember(ctx context.Context, m Member) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{m.WorkspaceID, m.UserID}
+	existing, ok := r.members[key]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	existing.Role = m.Role
+	r.members[key] = existing
+	return nil
+}
+
+func (r *MemoryRepository) RemoveMember(ctx context.Context, id ID, userID UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{id, userID}
+	if _, ok := r.members[key]; !ok {
+		return ErrMemberNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
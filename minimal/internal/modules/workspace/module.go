@@ -0,0 +1,37 @@
+package workspace
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+)
+
+// Module serves workspaces and their members under /workspaces.
+// Workspaces are part of the core schema.
+type Module struct {
+	modules.Base
+	routes modules.Routes
+	db     modules.Pinger
+}
+
+// NewModule creates the workspace module. routes serves /workspaces and
+// db is the database workspaces are stored in, or nil if they are kept in
+// memory.
+func NewModule(routes modules.Routes, db modules.Pinger) *Module {
+	return &Module{routes: routes, db: db}
+}
+
+func (m *Module) Name() string { return "workspace" }
+
+// Members are users.
+func (m *Module) Dependencies() []string { return []string{"user"} }
+
+func (m *Module) RegisterRoutes(api *echo.Group) {
+	m.routes.RegisterRoutes(api.Group("/workspaces"))
+}
+
+// Health pings the database workspaces are stored in.
+func (m *Module) Health(ctx context.Context) error {
+	return modules.Ping(ctx, m.db)
+}
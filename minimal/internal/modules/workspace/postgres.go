@@ -0,0 +1,202 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+)
+
+// workspaceRow is the database representation of a workspace.
+type workspaceRow struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r workspaceRow) toDomain() (Workspace, error) {
+	id, err := ParseID(r.ID)
+	if err != nil {
+		return Workspace{}, err
+	}
+	return Workspace{
+		ID:        id,
+		Name:      r.Name,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}, nil
+}
+
+// memberRow is the database representation of a membership.
+type memberRow struct {
+	WorkspaceID string    `db:"workspace_id"`
+	UserID      string    `db:"user_id"`
+	Role        string    `db:"role"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (r memberRow) toDomain() (Member, error) {
+	workspaceID, err := ParseID(r.WorkspaceID)
+	if err != nil {
+		return Member{}, err
+	}
+	userID, err := ParseID(r.UserID)
+	if err != nil {
+		return Member{}, err
+	}
+	return Member{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        Role(r.Role),
+		CreatedAt:   r.CreatedAt,
+	}, nil
+}
+
+// PostgresRepository implements Repository using PostgreSQL.
+type PostgresRepository struct {
+	db *db.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository.
+func NewPostgresRepository(db *db.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, w Workspace) error {
+	_, err := r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO workspaces (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4)`,
+		w.ID.String(), w.Name, w.CreatedAt, w.UpdatedAt,
+	)
+	return err
+}
+
+func (r *PostgresRepository) GetByID(ctx context.Context, id ID) (Workspace, error) {
+	var row workspaceRow
+	err := r.db.Q(ctx).QueryRow(ctx,
+		`SELECT id, name, created_at, updated_at FROM workspaces WHERE id = $1`,
+		id.String(),
+	).Scan(&row.ID, &row.Name, &row.CreatedAt, &row.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Workspace{}, ErrNotFound
+		}
+		return Workspace{}, err
+	}
+	return row.toDomain()
+}
+
+func (r *PostgresRepository) GetByUser(ctx context.Context, userID UserID) ([]Workspace, error) {
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT w.id, w.name, w.created_at, w.updated_at
+		 FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id
+		 WHERE m.user_id = $1 ORDER BY w.created_at`,
+		userID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []Workspace
+	for rows.Next() {
+		var row workspaceRow
+		if err := rows.Scan(&row.ID, &row.Name, &row.CreatedAt, &row.UpdatedAt); err != nil {
+			return nil, err
+		}
+		w, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, rows.Err()
+}
+
+func (r *PostgresRepository) AddMember(ctx context.Context, m Member) error {
+	_, err := r.db.Q(ctx).Exec(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`,
+		m.WorkspaceID.String(), m.UserID.String(), string(m.Role), m.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrMemberExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepository) GetMember(ctx context.Context, id ID, userID UserID) (Member, error) {
+	var row memberRow
+	err := r.db.Q(ctx).QueryRow(ctx,
+		`SELECT workspace_id, user_id, role, created_at FROM workspace_members
+		 WHERE workspace_id = $1 AND user_id = $2`,
+		id.String(), userID.String(),
+	).Scan(&row.WorkspaceID, &row.UserID, &row.Role, &row.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Member{}, ErrMemberNotFound
+		}
+		return Member{}, err
+	}
+	return row.toDomain()
+}
+
+func (r *PostgresRepository) GetMembers(ctx context.Context, id ID) ([]Member, error) {
+	rows, err := r.db.Q(ctx).Query(ctx,
+		`SELECT workspace_id, user_id, role, created_at FROM workspace_members
+		 WHERE workspace_id = $1 ORDER BY created_at`,
+		id.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []Member
+	for rows.Next() {
+		var row memberRow
+		if err := rows.Scan(&row.WorkspaceID, &row.UserID, &row.Role, &row.CreatedAt); err != nil {
+			return nil, err
+		}
+		m, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (r *PostgresRepository) UpdateMember(ctx context.Context, m Member) error {
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2`,
+		m.WorkspaceID.String(), m.UserID.String(), string(m.Role),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) RemoveMember(ctx context.Context, id ID, userID UserID) error {
+	result, err := r.db.Q(ctx).Exec(ctx,
+		`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`,
+		id.String(), userID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
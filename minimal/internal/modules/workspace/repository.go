@@ -0,0 +1,28 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound       = errors.New("workspace not found")
+	ErrMemberNotFound = errors.New("member not found")
+	ErrMemberExists   = errors.New("user is already a member")
+	ErrNotMember      = errors.New("not a member of the workspace")
+	ErrForbidden      = errors.New("insufficient workspace role")
+	ErrLastOwner      = errors.New("workspace must keep an owner")
+)
+
+// Repository defines the interface for workspace and membership persistence.
+type Repository interface {
+	Create(ctx context.Context, w Workspace) error
+	GetByID(ctx context.Context, id ID) (Workspace, error)
+	GetByUser(ctx context.Context, userID UserID) ([]Workspace, error)
+
+	AddMember(ctx context.Context, m Member) error
+	GetMember(ctx context.Context, id ID, userID UserID) (Member, error)
+	GetMembers(ctx context.Context, id ID) ([]Member, error)
+	UpdateMember(ctx context.Context, m Member) error
+	RemoveMember(ctx context.Context, id ID, userID UserID) error
+}
@@ -0,0 +1,240 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Service provides workspace and membership business logic.
+type Service struct {
+	repo   Repository
+	tx     db.Transactor
+	logger *zap.Logger
+}
+
+// NewService creates a new workspace service.
+func NewService(repo Repository, tx db.Transactor, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		tx:     tx,
+		logger: logger.Named("workspace.service"),
+	}
+}
+
+// CreateInput contains data for creating a workspace.
+type CreateInput struct {
+	Name    string
+	OwnerID UserID
+}
+
+// Create creates a new workspace owned by input.OwnerID.
+func (s *Service) Create(ctx context.Context, input CreateInput) (Workspace, error) {
+	now := time.Now().UTC()
+	w := Workspace{
+		ID:        NewID(),
+		Name:      input.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, w); err != nil {
+			return err
+		}
+		return s.repo.AddMember(ctx, Member{
+			WorkspaceID: w.ID,
+			UserID:      input.OwnerID,
+			Role:        RoleOwner,
+			CreatedAt:   now,
+		})
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to create workspace", zap.Error(err))
+		return Workspace{}, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("workspace created", zap.String("id", w.ID.String()))
+	return w, nil
+}
+
+// GetByID retrieves a workspace by ID.
+func (s *Service) GetByID(ctx context.Context, id ID) (Workspace, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListForUser retrieves the workspaces userID is a member of.
+func (s *Service) ListForUser(ctx context.Context, userID UserID) ([]Workspace, error) {
+	workspaces, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to list workspaces", zap.String("user_id", userID.String()), zap.Error(err))
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// GetMember retrieves the membership of userID in a workspace.
+func (s *Service) GetMember(ctx context.Context, id ID, userID UserID) (Member, error) {
+	return s.repo.GetMember(ctx, id, userID)
+}
+
+// Members lists the members of a workspace. The actor must be a member.
+func (s *Service) Members(ctx context.Context, actor UserID, id ID) ([]Member, error) {
+	if _, err := s.membership(ctx, id, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(ctx, id)
+}
+
+// MemberInput contains data for adding a member or changing their role.
+type MemberInput struct {
+	WorkspaceID ID
+	UserID      UserID
+	Role        Role
+}
+
+// AddMember adds a user to a workspace. The actor must be an owner or admin,
+// and only owners may add owners.
+func (s *Service) AddMember(ctx context.Context, actor UserID, input MemberInput) (Member, error) {
+	if err := s.authorize(ctx, input.WorkspaceID, actor, input.Role); err != nil {
+		return Member{}, err
+	}
+	return s.Join(ctx, input)
+}
+
+// Join adds a user to a workspace without checking anyone's role. It backs
+// AddMember and operator tooling.
+func (s *Service) Join(ctx context.Context, input MemberInput) (Member, error) {
+	m := Member{
+		WorkspaceID: input.WorkspaceID,
+		UserID:      input.UserID,
+		Role:        input.Role,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.AddMember(ctx, m); err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to add member", zap.String("workspace_id", m.WorkspaceID.String()), zap.Error(err))
+		return Member{}, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("member added",
+		zap.String("workspace_id", m.WorkspaceID.String()),
+		zap.String("user_id", m.UserID.String()),
+	)
+	return m, nil
+}
+
+// UpdateMember changes a member's role under the rules of AddMember. The
+// last owner cannot be demoted.
+func (s *Service) UpdateMember(ctx context.Context, actor UserID, input MemberInput) (Member, error) {
+	var m Member
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		m, err = s.repo.GetMember(ctx, input.WorkspaceID, input.UserID)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, input.WorkspaceID, actor, m.Role, input.Role); err != nil {
+			return err
+		}
+		if m.Role == RoleOwner && input.Role != RoleOwner {
+			if err := s.keepOwner(ctx, input.WorkspaceID); err != nil {
+				return err
+			}
+		}
+
+		m.Role = input.Role
+		return s.repo.UpdateMember(ctx, m)
+	})
+	if err != nil {
+		return Member{}, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("member updated",
+		zap.String("workspace_id", m.WorkspaceID.String()),
+		zap.String("user_id", m.UserID.String()),
+	)
+	return m, nil
+}
+
+// RemoveMember removes a user from a workspace. Members may remove
+// themselves; removing others requires the rules of AddMember. The last
+// owner cannot be removed.
+func (s *Service) RemoveMember(ctx context.Context, actor UserID, id ID, userID UserID) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		m, err := s.repo.GetMember(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+		if actor != userID {
+			if err := s.authorize(ctx, id, actor, m.Role); err != nil {
+				return err
+			}
+		}
+		if m.Role == RoleOwner {
+			if err := s.keepOwner(ctx, id); err != nil {
+				return err
+			}
+		}
+		return s.repo.RemoveMember(ctx, id, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("member removed",
+		zap.String("workspace_id", id.String()),
+		zap.String("user_id", userID.String()),
+	)
+	return nil
+}
+
+// membership returns the actor's membership, or ErrNotMember.
+func (s *Service) membership(ctx context.Context, id ID, actor UserID) (Member, error) {
+	m, err := s.repo.GetMember(ctx, id, actor)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return Member{}, ErrNotMember
+		}
+		return Member{}, err
+	}
+	return m, nil
+}
+
+// authorize checks that actor may manage members holding the given roles.
+func (s *Service) authorize(ctx context.Context, id ID, actor UserID, roles ...Role) error {
+	m, err := s.membership(ctx, id, actor)
+	if err != nil {
+		return err
+	}
+	if !m.Role.CanManageMembers() {
+		return ErrForbidden
+	}
+	for _, r := range roles {
+		if r == RoleOwner && m.Role != RoleOwner {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// keepOwner returns ErrLastOwner unless the workspace has several owners.
+func (s *Service) keepOwner(ctx context.Context, id ID) error {
+	members, err := s.repo.GetMembers(ctx, id)
+	if err != nil {
+		return err
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == RoleOwner {
+			owners++
+		}
+	}
+	if owners < 2 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package workspace
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/db"
+)
+
+// SQLiteRepository implements Repository using SQLite.
+type SQLiteRepository struct {
+	db *db.SQLite
+}
+
+// NewSQLiteRepository creates a new SQLiteRepository.
+func NewSQLiteRepository(db *db.SQLite) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// scanSQLiteWorkspace scans the id, name, created_at and updated_at of a
+// workspace.
+func scanSQLiteWorkspace(scan func(dest ...any) error) (Workspace, error) {
+	var (
+		row                  workspaceRow
+		createdAt, updatedAt string
+	)
+	if err := scan(&row.ID, &row.Name, &createdAt, &updatedAt); err != nil {
+		return Workspace{}, err
+	}
+	var err error
+	if row.CreatedAt, err = db.ParseSQLiteTime(createdAt); err != nil {
+		return Workspace{}, err
+	}
+	if row.UpdatedAt, err = db.ParseSQLiteTime(updatedAt); err != nil {
+		return Workspace{}, err
+	}
+	return row.toDomain()
+}
+
+// scanSQLiteMember scans the workspace_id, user_id, role and created_at of
+// a membership.
+func scanSQLiteMember(scan func(dest ...any) error) (Member, error) {
+	var (
+		row       memberRow
+		createdAt string
+	)
+	if err := scan(&row.WorkspaceID, &row.UserID, &row.Role, &createdAt); err != nil {
+		return Member{}, err
+	}
+	var err error
+	if row.CreatedAt, err = db.ParseSQLiteTime(createdAt); err != nil {
+		return Member{}, err
+	}
+	return row.toDomain()
+}
+
+func (r *SQLiteRepository) Create(ctx context.Context, w Workspace) error {
+	_, err := r.db.Q(ctx).ExecContext(ctx,
+		`INSERT INTO workspaces (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		w.ID.String(), w.Name, db.SQLiteTime(w.CreatedAt), db.SQLiteTime(w.UpdatedAt),
+	)
+	return err
+}
+
+func (r *SQLiteRepository) GetByID(ctx context.Context, id ID) (Workspace, error) {
+	w, err := scanSQLiteWorkspace(r.db.Q(ctx).QueryRowContext(ctx,
+		`SELECT id, name, created_at, updated_at FROM workspaces WHERE id = ?`,
+		id.String(),
+	).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Workspace{}, ErrNotFound
+	}
+	return w, err
+}
+
+func (r *SQLiteRepository) GetByUser(ctx context.Context, userID UserID) ([]Workspace, error) {
+	rows, err := r.db.Q(ctx).QueryContext(ctx,
+		`SELECT w.id, w.name, w.created_at, w.updated_at
+		 FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id
+		 WHERE m.user_id = ? ORDER BY w.created_at`,
+		userID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []Workspace
+	for rows.Next() {
+		w, err := scanSQLiteWorkspace(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, rows.Err()
+}
+
+func (r *SQLiteRepository) AddMember(ctx context.Context, m Member) error {
+	_, err := r.db.Q(ctx).ExecContext(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		m.WorkspaceID.String(), m.UserID.String(), string(m.Role), db.SQLiteTime(m.CreatedAt),
+	)
+	if errors.Is(db.SQLiteError(err), db.ErrUniqueViolation) {
+		return ErrMemberExists
+	}
+	return err
+}
+
+func (r *SQLiteRepository) GetMember(ctx context.Context, id ID, userID UserID) (Member, error) {
+	m, err := scanSQLiteMember(r.db.Q(ctx).QueryRowContext(ctx,
+		`SELECT workspace_id, user_id, role, created_at FROM workspace_members
+		 WHERE workspace_id = ? AND user_id = ?`,
+		id.String(), userID.String(),
+	).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Member{}, ErrMemberNotFound
+	}
+	return m, err
+}
+
+func (r *SQLiteRepository) GetMembers(ctx context.Context, id ID) ([]Member, error) {
+	rows, err := r.db.Q(ctx).QueryContext(ctx,
+		`SELECT workspace_id, user_id, role, created_at FROM workspace_members
+		 WHERE workspace_id = ? ORDER BY created_at`,
+		id.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []Member
+	for rows.Next() {
+		m, err := scanSQLiteMember(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (r *SQLiteRepository) UpdateMember(ctx context.Context, m Member) error {
+	result, err := r.db.Q(ctx).ExecContext(ctx,
+		`UPDATE workspace_members SET role = ? WHERE workspace_id = ? AND user_id = ?`,
+		string(m.Role), m.WorkspaceID.String(), m.UserID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return sqliteMemberAffected(result)
+}
+
+func (r *SQLiteRepository) RemoveMember(ctx context.Context, id ID, userID UserID) error {
+	result, err := r.db.Q(ctx).ExecContext(ctx,
+		`DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?`,
+		id.String(), userID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return sqliteMemberAffected(result)
+}
+
+// sqliteMemberAffected returns ErrMemberNotFound if result affected no rows.
+func sqliteMemberAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
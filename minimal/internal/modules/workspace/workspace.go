@@ -0,0 +1,91 @@
+package workspace
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+)
+
+// ID represents a workspace identifier.
+type ID = uuid.UUID
+
+// UserID represents the identifier of a member.
+type UserID = uuid.UUID
+
+// Workspace is an isolated set of categories and notes shared by its members.
+type Workspace struct {
+	ID        ID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Role is a member's level of access to a workspace.
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+// ParseRole validates s as a known role.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleOwner, RoleAdmin, RoleMember, RoleViewer:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// Scopes returns the scopes a role permits. A credential acting in a
+// workspace keeps only the scopes its member's role permits.
+func (r Role) Scopes() []auth.Scope {
+	switch r {
+	case RoleOwner, RoleAdmin:
+		return auth.AllScopes
+	case RoleMember:
+		return []auth.Scope{auth.ScopeNotesRead, auth.ScopeNotesWrite}
+	case RoleViewer:
+		return []auth.Scope{auth.ScopeNotesRead}
+	}
+	return nil
+}
+
+// CanManageMembers reports whether the role may change the member list.
+func (r Role) CanManageMembers() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+// Member is a user's membership in a workspace.
+type Member struct {
+	WorkspaceID ID
+	UserID      UserID
+	Role        Role
+	CreatedAt   time.Time
+}
+
+// NewID generates a new workspace ID.
+func NewID() ID {
+	return uuid.New()
+}
+
+// ParseID parses a string into a workspace ID.
+func ParseID(s string) (ID, error) {
+	return uuid.Parse(s)
+}
+
+// restrict returns the scopes present in both scopes and allowed.
+func restrict(scopes, allowed []auth.Scope) []auth.Scope {
+	var result []auth.Scope
+	for _, s := range scopes {
+		if slices.Contains(allowed, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	notesv1 "github.com/piotmni/go-mini-templates/minimal/internal/gen/notes/v1"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CategoryServer implements notesv1connect.CategoryServiceHandler.
+type CategoryServer struct {
+	service *category.Service
+	logger  *zap.Logger
+}
+
+func toCategoryMessage(c category.Category) *notesv1.Category {
+	return &notesv1.Category{
+		Id:         c.ID.String(),
+		Name:       c.Name,
+		CreateTime: timestamppb.New(c.CreatedAt),
+		UpdateTime: timestamppb.New(c.UpdatedAt),
+	}
+}
+
+func (s *CategoryServer) CreateCategory(ctx context.Context, req *connect.Request[notesv1.CreateCategoryRequest]) (*connect.Response[notesv1.CreateCategoryResponse], error) {
+	if req.Msg.Name == "" {
+		return nil, invalidArgument("name is required")
+	}
+
+	c, err := s.service.Create(ctx, category.CreateInput{Name: req.Msg.Name})
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to create category")
+	}
+
+	return connect.NewResponse(&notesv1.CreateCategoryResponse{Category: toCategoryMessage(c)}), nil
+}
+
+func (s *CategoryServer) GetCategory(ctx context.Context, req *connect.Request[notesv1.GetCategoryRequest]) (*connect.Response[notesv1.GetCategoryResponse], error) {
+	id, err := category.ParseID(req.Msg.Id)
+	if err != nil {
+		return nil, invalidArgument("invalid category id")
+	}
+
+	c, err := s.service.GetByID(ctx, id)
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to get category")
+	}
+
+	return connect.NewResponse(&notesv1.GetCategoryResponse{Category: toCategoryMessage(c)}), nil
+}
+
+func (s *CategoryServer) ListCategories(ctx context.Context, req *connect.Request[notesv1.ListCategoriesRequest]) (*connect.Response[notesv1.ListCategoriesResponse], error) {
+	categories, err := s.service.GetAll(ctx)
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to get categories")
+	}
+
+	res := &notesv1.ListCategoriesResponse{Categories: make([]*notesv1.Category, len(categories))}
+	for i, c := range categories {
+		res.Categories[i] = toCategoryMessage(c)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *CategoryServer) UpdateCategory(ctx context.Context, req *connect.Request[notesv1.UpdateCategoryRequest]) (*connect.Response[notesv1.UpdateCategoryResponse], error) {
+	id, err := category.ParseID(req.Msg.Id)
+	if err != nil {
+		return nil, invalidArgument("invalid category id")
+	}
+	if req.Msg.Name == "" {
+		return nil, invalidArgument("name is required")
+	}
+
+	c, err := s.service.Update(ctx, category.UpdateInput{ID: id, Name: req.Msg.Name})
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to update category")
+	}
+
+	return connect.NewResponse(&notesv1.UpdateCategoryResponse{Category: toCategoryMessage(c)}), nil
+}
+
+func (s *CategoryServer) DeleteCategory(ctx context.Context, req *connect.Request[notesv1.DeleteCategoryRequest]) (*connect.Response[notesv1.DeleteCategoryResponse], error) {
+	id, err := category.ParseID(req.Msg.Id)
+	if err != nil {
+		return nil, invalidArgument("invalid category id")
+	}
+
+	if err := s.service.Delete(ctx, id); err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to delete category")
+	}
+
+	return connect.NewResponse(&notesv1.DeleteCategoryResponse{}), nil
+}
@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/logger"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+)
+
+// domainCodes maps domain errors to the status codes reported for them.
+// With detail, the message of the error itself is reported, as it
+// describes what is wrong with the request.
+var domainCodes = []struct {
+	err    error
+	code   connect.Code
+	detail bool
+}{
+	{category.ErrNotFound, connect.CodeNotFound, false},
+	{category.ErrAlreadyExists, connect.CodeAlreadyExists, false},
+	{note.ErrNotFound, connect.CodeNotFound, false},
+	// Moving a note to a category whose schema its metadata fails
+	{note.ErrInvalidMetadata, connect.CodeInvalidArgument, true},
+}
+
+// toConnectError translates a service error. Unknown errors are reported as
+// internal with the generic message so that details do not leak, and
+// logged with their cause.
+func toConnectError(ctx context.Context, l *zap.Logger, err error, message string) error {
+	for _, d := range domainCodes {
+		if errors.Is(err, d.err) {
+			if d.detail {
+				return connect.NewError(d.code, err)
+			}
+			return connect.NewError(d.code, d.err)
+		}
+	}
+	logger.FromContext(ctx, l).Error(message, zap.Error(err))
+	return connect.NewError(connect.CodeInternal, errors.New(message))
+}
+
+// invalidArgument reports a malformed request field.
+func invalidArgument(format string, args ...any) error {
+	return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf(format, args...))
+}
+
+// fromHTTPError translates errors shared with the REST middleware.
+func fromHTTPError(he *echo.HTTPError) error {
+	code := connect.CodeInternal
+	switch he.Code {
+	case http.StatusUnauthorized:
+		code = connect.CodeUnauthenticated
+	case http.StatusForbidden:
+		code = connect.CodePermissionDenied
+	}
+	return connect.NewError(code, fmt.Errorf("%v", he.Message))
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	notesv1 "github.com/piotmni/go-mini-templates/minimal/internal/gen/notes/v1"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NoteServer implements notesv1connect.NoteServiceHandler.
+type NoteServer struct {
+	service *note.Service
+	logger  *zap.Logger
+}
+
+func toNoteMessage(n note.Note) *notesv1.Note {
+	return &notesv1.Note{
+		Id:         n.ID.String(),
+		CategoryId: n.CategoryID.String(),
+		Title:      n.Title,
+		Content:    n.Content,
+		CreateTime: timestamppb.New(n.CreatedAt),
+		UpdateTime: timestamppb.New(n.UpdatedAt),
+	}
+}
+
+func (s *NoteServer) CreateNote(ctx context.Context, req *connect.Request[notesv1.CreateNoteRequest]) (*connect.Response[notesv1.CreateNoteResponse], error) {
+	if req.Msg.Title == "" {
+		return nil, invalidArgument("title is required")
+	}
+	categoryID, err := category.ParseID(req.Msg.CategoryId)
+	if err != nil {
+		return nil, invalidArgument("invalid category_id")
+	}
+
+	n, err := s.service.Create(ctx, note.CreateInput{
+		CategoryID: categoryID,
+		Title:      req.Msg.Title,
+		Content:    req.Msg.Content,
+	})
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to create note")
+	}
+
+	return connect.NewResponse(&notesv1.CreateNoteResponse{Note: toNoteMessage(n)}), nil
+}
+
+func (s *NoteServer) GetNote(ctx context.Context, req *connect.Request[notesv1.GetNoteRequest]) (*connect.Response[notesv1.GetNoteResponse], error) {
+	id, err := note.ParseID(req.Msg.Id)
+	if err != nil {
+		return nil, invalidArgument("invalid note id")
+	}
+
+	n, err := s.service.GetByID(ctx, id)
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to get note")
+	}
+
+	return connect.NewResponse(&notesv1.GetNoteResponse{Note: toNoteMessage(n)}), nil
+}
+
+func (s *NoteServer) ListNotes(ctx context.Context, req *connect.Request[notesv1.ListNotesRequest]) (*connect.Response[notesv1.ListNotesResponse], error) {
+	var (
+		notes []note.Note
+		err   error
+	)
+	if req.Msg.CategoryId != "" {
+		categoryID, parseErr := category.ParseID(req.Msg.CategoryId)
+		if parseErr != nil {
+			return nil, invalidArgument("invalid category_id")
+		}
+		notes, err = s.service.GetByCategory(ctx, categoryID)
+	} else {
+		notes, err = s.service.GetAll(ctx)
+	}
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to get notes")
+	}
+
+	res := &notesv1.ListNotesResponse{Notes: make([]*notesv1.Note, len(notes))}
+	for i, n := range notes {
+		res.Notes[i] = toNoteMessage(n)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *NoteServer) UpdateNote(ctx context.Context, req *connect.Request[notesv1.UpdateNoteRequest]) (*connect.Response[notesv1.UpdateNoteResponse], error) {
+	id, err := note.ParseID(req.Msg.Id)
+	if err != nil {
+		return nil, invalidArgument("invalid note id")
+	}
+	if req.Msg.Title == "" {
+		return nil, invalidArgument("title is required")
+	}
+	categoryID, err := category.ParseID(req.Msg.CategoryId)
+	if err != nil {
+		return nil, invalidArgument("invalid category_id")
+	}
+
+	n, err := s.service.Update(ctx, note.UpdateInput{
+		ID:         id,
+		CategoryID: categoryID,
+		Title:      req.Msg.Title,
+		Content:    req.Msg.Content,
+	})
+	if err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to update note")
+	}
+
+	return connect.NewResponse(&notesv1.UpdateNoteResponse{Note: toNoteMessage(n)}), nil
+}
+
+func (s *NoteServer) DeleteNote(ctx context.Context, req *connect.Request[notesv1.DeleteNoteRequest]) (*connect.Response[notesv1.DeleteNoteResponse], error) {
+	id, err := note.ParseID(req.Msg.Id)
+	if err != nil {
+		return nil, invalidArgument("invalid note id")
+	}
+
+	if err := s.service.Delete(ctx, id); err != nil {
+		return nil, toConnectError(ctx, s.logger, err, "failed to delete note")
+	}
+
+	return connect.NewResponse(&notesv1.DeleteNoteResponse{}), nil
+}
@@ -0,0 +1,130 @@
+// Package rpc serves the note and category operations over Connect, gRPC
+// and gRPC-Web, next to the REST API and backed by the same services. Note
+// metadata and category metadata schemas are not exposed.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpcreflect"
+	"github.com/labstack/echo/v4"
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/gen/notes/v1/notesv1connect"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/middleware"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/category"
+	"github.com/piotmni/go-mini-templates/minimal/internal/modules/note"
+	"go.uber.org/zap"
+)
+
+// procedureScopes lists the scope each procedure requires, mirroring the
+// REST routes.
+var procedureScopes = map[string]auth.Scope{
+	notesv1connect.CategoryServiceCreateCategoryProcedure: auth.ScopeCategoriesAdmin,
+	notesv1connect.CategoryServiceGetCategoryProcedure:    auth.ScopeNotesRead,
+	notesv1connect.CategoryServiceListCategoriesProcedure: auth.ScopeNotesRead,
+	notesv1connect.CategoryServiceUpdateCategoryProcedure: auth.ScopeCategoriesAdmin,
+	notesv1connect.CategoryServiceDeleteCategoryProcedure: auth.ScopeCategoriesAdmin,
+	notesv1connect.NoteServiceCreateNoteProcedure:         auth.ScopeNotesWrite,
+	notesv1connect.NoteServiceGetNoteProcedure:            auth.ScopeNotesRead,
+	notesv1connect.NoteServiceListNotesProcedure:          auth.ScopeNotesRead,
+	notesv1connect.NoteServiceUpdateNoteProcedure:         auth.ScopeNotesWrite,
+	notesv1connect.NoteServiceDeleteNoteProcedure:         auth.ScopeNotesWrite,
+}
+
+// Server holds the RPC service implementations. It is the module serving
+// them.
+type Server struct {
+	modules.Base
+	authenticator middleware.Authenticator
+	categories    *CategoryServer
+	notes         *NoteServer
+}
+
+// NewServer creates a new RPC server.
+func NewServer(authenticator middleware.Authenticator, categories *category.Service, notes *note.Service, logger *zap.Logger) *Server {
+	logger = logger.Named("rpc.server")
+	return &Server{
+		authenticator: authenticator,
+		categories:    &CategoryServer{service: categories, logger: logger},
+		notes:         &NoteServer{service: notes, logger: logger},
+	}
+}
+
+func (s *Server) Name() string { return "rpc" }
+
+// The services serve categories and notes.
+func (s *Server) Dependencies() []string { return []string{"category", "note"} }
+
+// RegisterRoutes registers nothing under /api/v1; the services are served
+// by RegisterRootRoutes.
+func (s *Server) RegisterRoutes(*echo.Group) {}
+
+// RegisterRootRoutes mounts the RPC services and server reflection on e,
+// so they share its port and global middleware. Reflection requires
+// authentication, so only callers of the API may read its schema.
+func (s *Server) RegisterRootRoutes(e *echo.Echo) {
+	interceptors := connect.WithInterceptors(s.authInterceptor())
+
+	mount := func(path string, handler http.Handler) {
+		e.Any(path+"*", echo.WrapHandler(handler))
+	}
+	mount(notesv1connect.NewCategoryServiceHandler(s.categories, interceptors))
+	mount(notesv1connect.NewNoteServiceHandler(s.notes, interceptors))
+
+	reflector := grpcreflect.NewStaticReflector(
+		notesv1connect.CategoryServiceName,
+		notesv1connect.NoteServiceName,
+	)
+	reflection := connect.WithInterceptors(reflectionAuth{authenticator: s.authenticator})
+	mount(grpcreflect.NewHandlerV1(reflector, reflection))
+	mount(grpcreflect.NewHandlerV1Alpha(reflector, reflection))
+}
+
+// reflectionAuth authenticates reflection streams like the REST API does.
+// Any scope may read the schema.
+type reflectionAuth struct {
+	authenticator middleware.Authenticator
+}
+
+func (a reflectionAuth) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc { return next }
+
+func (a reflectionAuth) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (a reflectionAuth) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := middleware.Authenticate(ctx, a.authenticator, conn.RequestHeader().Get("Authorization"))
+		if err != nil {
+			return fromHTTPError(err)
+		}
+		return next(ctx, conn)
+	}
+}
+
+// authInterceptor authenticates calls like the REST API does and checks
+// the scope the procedure requires.
+func (s *Server) authInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			ctx, err := middleware.Authenticate(ctx, s.authenticator, req.Header().Get("Authorization"))
+			if err != nil {
+				return nil, fromHTTPError(err)
+			}
+
+			scope, ok := procedureScopes[req.Spec().Procedure]
+			if !ok {
+				return nil, connect.NewError(connect.CodeUnimplemented, errors.New("unknown procedure"))
+			}
+			if err := middleware.CheckScopes(ctx, scope); err != nil {
+				return nil, fromHTTPError(err)
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
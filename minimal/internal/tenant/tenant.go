@@ -0,0 +1,29 @@
+// Package tenant carries the workspace a request operates in. Repositories
+// scope every query by it.
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoWorkspace is returned when a context carries no workspace.
+var ErrNoWorkspace = errors.New("no workspace in context")
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a copy of ctx scoped to the workspace id.
+func WithWorkspace(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, id)
+}
+
+// WorkspaceID returns the workspace ctx is scoped to.
+func WorkspaceID(ctx context.Context) (uuid.UUID, error) {
+	id, ok := ctx.Value(workspaceKey{}).(uuid.UUID)
+	if !ok || id == uuid.Nil {
+		return uuid.Nil, ErrNoWorkspace
+	}
+	return id, nil
+}
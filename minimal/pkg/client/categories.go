@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Category groups notes.
+type Category struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type categoryRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateCategory creates a category. It fails with ErrCategoryAlreadyExists
+// if the name is taken.
+func (c *Client) CreateCategory(ctx context.Context, name string) (Category, error) {
+	var cat Category
+	_, err := c.do(ctx, http.MethodPost, "/categories", nil, categoryRequest{Name: name}, &cat)
+	return cat, err
+}
+
+// ListCategories returns all categories.
+func (c *Client) ListCategories(ctx context.Context) ([]Category, error) {
+	var categories []Category
+	_, err := c.do(ctx, http.MethodGet, "/categories", nil, nil, &categories)
+	return categories, err
+}
+
+// GetCategory returns the category with the given ID.
+func (c *Client) GetCategory(ctx context.Context, id string) (Category, error) {
+	var cat Category
+	_, err := c.do(ctx, http.MethodGet, "/categories/"+url.PathEscape(id), nil, nil, &cat)
+	return cat, err
+}
+
+// UpdateCategory renames a category.
+func (c *Client) UpdateCategory(ctx context.Context, id, name string) (Category, error) {
+	var cat Category
+	_, err := c.do(ctx, http.MethodPut, "/categories/"+url.PathEscape(id), nil, categoryRequest{Name: name}, &cat)
+	return cat, err
+}
+
+// DeleteCategory removes a category.
+func (c *Client) DeleteCategory(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/categories/"+url.PathEscape(id), nil, nil, nil)
+	return err
+}
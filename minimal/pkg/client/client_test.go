@@ -0,0 +1,220 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/piotmni/go-mini-templates/minimal/internal/auth"
+	"github.com/piotmni/go-mini-templates/minimal/internal/http/apitest"
+	"github.com/piotmni/go-mini-templates/minimal/pkg/client"
+)
+
+const missingID = "00000000-0000-0000-0000-000000000000"
+
+func newClient(t *testing.T, baseURL string, opts ...client.Option) *client.Client {
+	t.Helper()
+	c, err := client.New(baseURL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCategories(t *testing.T) {
+	h := apitest.New(t)
+	c := newClient(t, h.URL(), client.WithToken(h.Token))
+	ctx := t.Context()
+
+	work, err := c.CreateCategory(ctx, "Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateCategory(ctx, "Work"); !errors.Is(err, client.ErrCategoryAlreadyExists) {
+		t.Fatalf("create duplicate: err = %v, want ErrCategoryAlreadyExists", err)
+	}
+
+	renamed, err := c.UpdateCategory(ctx, work.ID, "Office")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Name != "Office" || renamed.ID != work.ID {
+		t.Fatalf("renamed = %+v", renamed)
+	}
+
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 1 || categories[0].Name != "Office" {
+		t.Fatalf("categories = %+v", categories)
+	}
+
+	if err := c.DeleteCategory(ctx, work.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetCategory(ctx, work.ID); !errors.Is(err, client.ErrCategoryNotFound) {
+		t.Fatalf("get deleted: err = %v, want ErrCategoryNotFound", err)
+	}
+}
+
+func TestNotes(t *testing.T) {
+	h := apitest.New(t)
+	c := newClient(t, h.URL(), client.WithToken(h.Token))
+	ctx := t.Context()
+	cat := h.Category().Create()
+
+	n, err := c.CreateNote(ctx, client.NoteInput{CategoryID: cat.ID.String(), Title: "Plan", Content: "Ship it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.GetNote(ctx, n.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Plan" || got.CategoryID != cat.ID.String() || got.CreatedAt.IsZero() {
+		t.Fatalf("note = %+v", got)
+	}
+
+	updated, err := c.UpdateNote(ctx, n.ID, client.NoteInput{CategoryID: cat.ID.String(), Title: "Shipped"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != "Shipped" || updated.Content != "" {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	if err := c.DeleteNote(ctx, n.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteNote(ctx, n.ID); !errors.Is(err, client.ErrNoteNotFound) {
+		t.Fatalf("delete twice: err = %v, want ErrNoteNotFound", err)
+	}
+
+	var apiErr *client.Error
+	_, err = c.CreateNote(ctx, client.NoteInput{CategoryID: cat.ID.String()})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "title is required" {
+		t.Fatalf("create without title: err = %v", err)
+	}
+	if apiErr.RequestID == "" {
+		t.Fatal("error carries no request ID")
+	}
+}
+
+func TestNotesIterator(t *testing.T) {
+	h := apitest.New(t)
+	c := newClient(t, h.URL(), client.WithToken(h.Token))
+	work := h.Category().Create()
+	other := h.Category().Create()
+	for i := range 5 {
+		h.Note().InCategory(work).Titled(fmt.Sprintf("Note %d", i)).Create()
+	}
+	h.Note().InCategory(other).Create()
+
+	page, err := c.ListNotes(t.Context(), client.ListNotesOptions{CategoryID: work.ID.String(), Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Notes) != 2 || page.NextCursor == "" {
+		t.Fatalf("first page has %d notes and cursor %q", len(page.Notes), page.NextCursor)
+	}
+
+	seen := map[string]bool{}
+	for n, err := range c.Notes(t.Context(), client.ListNotesOptions{CategoryID: work.ID.String(), Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[n.ID] {
+			t.Fatalf("note %s listed twice", n.ID)
+		}
+		seen[n.ID] = true
+	}
+	if len(seen) != 5 {
+		t.Fatalf("iterated %d notes, want 5", len(seen))
+	}
+
+	for _, err := range c.Notes(t.Context(), client.ListNotesOptions{CategoryID: "invalid"}) {
+		if err == nil {
+			t.Fatal("iterating with an invalid category succeeded")
+		}
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	h := apitest.New(t)
+	ctx := t.Context()
+
+	anonymous := newClient(t, h.URL())
+	if _, err := anonymous.ListCategories(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+
+	reader := newClient(t, h.URL(), client.WithToken(h.Key(auth.ScopeNotesRead)))
+	if _, err := reader.CreateCategory(ctx, "Work"); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("err = %v, want ErrForbidden", err)
+	}
+	if _, err := reader.GetNote(ctx, missingID); !errors.Is(err, client.ErrNoteNotFound) {
+		t.Fatalf("err = %v, want ErrNoteNotFound", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"message":"try again"}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	}))
+	t.Cleanup(srv.Close)
+	c := newClient(t, srv.URL, client.WithRetries(3, time.Millisecond))
+
+	t.Run("idempotent", func(t *testing.T) {
+		calls.Store(0)
+		if _, err := c.ListCategories(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		if got := calls.Load(); got != 3 {
+			t.Fatalf("server called %d times, want 3", got)
+		}
+	})
+
+	t.Run("not idempotent", func(t *testing.T) {
+		calls.Store(0)
+		_, err := c.CreateCategory(t.Context(), "Work")
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("err = %v, want 503", err)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Fatalf("server called %d times, want 1", got)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		calls.Store(-10)
+		c := newClient(t, srv.URL, client.WithRetries(1, time.Millisecond))
+		if _, err := c.ListCategories(t.Context()); err == nil {
+			t.Fatal("request succeeded after exhausting retries")
+		}
+		if got := calls.Load(); got != -8 {
+			t.Fatalf("server called %d times, want 2", got+10)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, u := range []string{"localhost:8080", "ftp://example.com", "http://[::1"} {
+		if _, err := client.New(u); err == nil {
+			t.Errorf("New(%q) succeeded", u)
+		}
+	}
+}
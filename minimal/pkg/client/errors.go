@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Errors reported by the API. They mirror the server's domain errors and
+// are matched with errors.Is against an *Error.
+var (
+	ErrNoteNotFound          = errors.New("note not found")
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryAlreadyExists = errors.New("category already exists")
+	ErrUnauthorized          = errors.New("unauthorized")
+	ErrForbidden             = errors.New("forbidden")
+)
+
+// domainErrors maps error messages of the API to the errors above.
+var domainErrors = map[string]error{
+	ErrNoteNotFound.Error():          ErrNoteNotFound,
+	ErrCategoryNotFound.Error():      ErrCategoryNotFound,
+	ErrCategoryAlreadyExists.Error(): ErrCategoryAlreadyExists,
+}
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int
+	Message    string
+	// RequestID identifies the request in the server's logs.
+	RequestID string
+	// RetryAfter is the delay the server asked for before a retry.
+	RetryAfter time.Duration
+}
+
+func newError(res *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-Request-Id"),
+		RetryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
+	}
+
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Message != "" {
+		e.Message = payload.Message
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+		if e.Message == "" {
+			e.Message = http.StatusText(res.StatusCode)
+		}
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("notes api: %d %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the domain error the response reports, if any.
+func (e *Error) Unwrap() error {
+	if err, ok := domainErrors[e.Message]; ok {
+		return err
+	}
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultPageSize is the page size Notes uses when none is given.
+const DefaultPageSize = 50
+
+// Note is a note in a category.
+type Note struct {
+	ID         string         `json:"id"`
+	CategoryID string         `json:"category_id"`
+	Title      string         `json:"title"`
+	Content    string         `json:"content"`
+	Metadata   map[string]any `json:"metadata"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// NoteInput holds the fields of a note to create or update.
+type NoteInput struct {
+	CategoryID string `json:"category_id"`
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+	// Metadata is kept by UpdateNote when nil.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// CreateNote creates a note.
+func (c *Client) CreateNote(ctx context.Context, in NoteInput) (Note, error) {
+	var n Note
+	_, err := c.do(ctx, http.MethodPost, "/notes", nil, in, &n)
+	return n, err
+}
+
+// GetNote returns the note with the given ID.
+func (c *Client) GetNote(ctx context.Context, id string) (Note, error) {
+	var n Note
+	_, err := c.do(ctx, http.MethodGet, "/notes/"+url.PathEscape(id), nil, nil, &n)
+	return n, err
+}
+
+// UpdateNote replaces the fields of a note.
+func (c *Client) UpdateNote(ctx context.Context, id string, in NoteInput) (Note, error) {
+	var n Note
+	_, err := c.do(ctx, http.MethodPut, "/notes/"+url.PathEscape(id), nil, in, &n)
+	return n, err
+}
+
+// DeleteNote removes a note.
+func (c *Client) DeleteNote(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/notes/"+url.PathEscape(id), nil, nil, nil)
+	return err
+}
+
+// ListNotesOptions filters and pages note listings.
+type ListNotesOptions struct {
+	// CategoryID restricts the listing to one category.
+	CategoryID string
+	// Limit is the page size, at most 100. Defaults to DefaultPageSize.
+	Limit int
+	// After is the NextCursor of the previous page.
+	After string
+}
+
+// NotePage is one page of a note listing.
+type NotePage struct {
+	Notes []Note
+	// NextCursor continues the listing; it is empty on the last page.
+	NextCursor string
+}
+
+// ListNotes returns one page of notes, newest first.
+func (c *Client) ListNotes(ctx context.Context, opts ListNotesOptions) (NotePage, error) {
+	limit := opts.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if opts.CategoryID != "" {
+		query.Set("category_id", opts.CategoryID)
+	}
+	if opts.After != "" {
+		query.Set("after", opts.After)
+	}
+
+	var page NotePage
+	header, err := c.do(ctx, http.MethodGet, "/notes", query, nil, &page.Notes)
+	if err != nil {
+		return NotePage{}, err
+	}
+	page.NextCursor = header.Get("X-Next-Cursor")
+	return page, nil
+}
+
+// Notes iterates over all notes matching opts, newest first, fetching
+// pages as needed. Iteration stops after the first error.
+//
+//	for n, err := range c.Notes(ctx, client.ListNotesOptions{}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) Notes(ctx context.Context, opts ListNotesOptions) iter.Seq2[Note, error] {
+	return func(yield func(Note, error) bool) {
+		for {
+			page, err := c.ListNotes(ctx, opts)
+			if err != nil {
+				yield(Note{}, err)
+				return
+			}
+			for _, n := range page.Notes {
+				if !yield(n, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			opts.After = page.NextCursor
+		}
+	}
+}